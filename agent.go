@@ -0,0 +1,94 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/UnAfraid/wg-ui/pkg/backend"
+	"github.com/UnAfraid/wg-ui/pkg/config"
+	"github.com/UnAfraid/wg-ui/pkg/wireguard/agent"
+	"github.com/UnAfraid/wg-ui/pkg/wireguard/builtin"
+	"github.com/UnAfraid/wg-ui/pkg/wireguard/driver"
+)
+
+// runAgent serves the local backend to remote wg-ui instances until a shutdown signal is received.
+func runAgent(shutdownChan <-chan os.Signal) {
+	conf, err := config.LoadAgent(appName)
+	if err != nil {
+		logrus.
+			WithError(err).
+			Fatal("failed to initialize agent config")
+		return
+	}
+
+	builtin.RegisterAll(conf.MemoryBackendEnabled)
+
+	parsedURL, err := backend.ParseURL(conf.BackendUrl)
+	if err != nil {
+		logrus.
+			WithError(err).
+			Fatal("failed to parse agent backend url")
+		return
+	}
+	if parsedURL.Type == "agent" {
+		logrus.Fatal("agent backend cannot be served by an agent")
+		return
+	}
+
+	localBackend, err := driver.Create(context.Background(), parsedURL.Type, conf.BackendUrl)
+	if err != nil {
+		logrus.
+			WithError(err).
+			Fatal("failed to initialize agent backend")
+		return
+	}
+	defer func() {
+		if err := localBackend.Close(context.Background()); err != nil {
+			logrus.
+				WithError(err).
+				Error("failed to close agent backend")
+		}
+	}()
+
+	tlsConfig, err := agent.ServerTLSConfig(conf.TLSCertFile, conf.TLSKeyFile, conf.TLSClientCAFile)
+	if err != nil {
+		logrus.
+			WithError(err).
+			Fatal("failed to initialize agent tls")
+		return
+	}
+
+	httpServer := http.Server{
+		Addr:      conf.Address(),
+		Handler:   agent.NewHandler(localBackend, conf.Token),
+		TLSConfig: tlsConfig,
+	}
+
+	go func() {
+		logrus.
+			WithField("address", conf.Address()).
+			WithField("type", parsedURL.Type).
+			Info("Starting serving agent")
+		if err := httpServer.ListenAndServeTLS("", ""); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logrus.
+				WithError(err).
+				Fatal("failed to listen and serve agent")
+		}
+	}()
+
+	<-shutdownChan
+	logrus.Info("Shutting down agent")
+
+	shutdownTimeoutCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	if err := httpServer.Shutdown(shutdownTimeoutCtx); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		logrus.
+			WithError(err).
+			Error("failed to shutdown agent")
+	}
+}
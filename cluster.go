@@ -0,0 +1,99 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/UnAfraid/wg-ui/pkg/cluster"
+	"github.com/UnAfraid/wg-ui/pkg/config"
+	"github.com/UnAfraid/wg-ui/pkg/manage"
+)
+
+// startClusterElection takes part in the leader election until this node is elected, meanwhile queries are served
+// from the database snapshot of the leader and the other requests are forwarded to it. It returns false when
+// a shutdown signal is received first. The returned function releases the lease.
+func startClusterElection(conf *config.Config, shutdownChan <-chan os.Signal) (*cluster.Elector, func(), bool) {
+	nodeId, err := conf.Cluster.NodeIdOrHostname()
+	if err != nil {
+		logrus.
+			WithError(err).
+			Fatal("failed to resolve cluster node id")
+		return nil, nil, false
+	}
+
+	elector := cluster.NewElector(cluster.NewLeaseFile(conf.Cluster.LeaseFile), cluster.ElectorOptions{
+		NodeId:        nodeId,
+		Address:       conf.Cluster.AdvertiseAddress,
+		LeaseDuration: conf.Cluster.LeaseDuration,
+		RenewInterval: conf.Cluster.RenewInterval,
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go elector.Run(ctx)
+
+	release := func() {
+		cancel()
+		if err := elector.Release(context.Background()); err != nil {
+			logrus.
+				WithError(err).
+				Error("failed to release cluster lease")
+		}
+	}
+
+	queries := newSnapshotQueries(conf)
+	defer queries.Close()
+
+	followerServer := http.Server{
+		Addr:    conf.HttpServer.Address(),
+		Handler: cluster.NewFollowerHandler(elector, queries),
+	}
+
+	go func() {
+		logrus.
+			WithField("address", conf.HttpServer.Address()).
+			WithField("nodeId", nodeId).
+			Info("Starting serving as cluster follower")
+		if err := followerServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logrus.
+				WithError(err).
+				Fatal("failed to listen and serve follower http server")
+		}
+	}()
+
+	elected := true
+	select {
+	case <-elector.Elected():
+	case <-shutdownChan:
+		elected = false
+	}
+
+	shutdownTimeoutCtx, shutdownCancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer shutdownCancel()
+	if err := followerServer.Shutdown(shutdownTimeoutCtx); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		logrus.
+			WithError(err).
+			Error("failed to shutdown follower http server")
+	}
+
+	if !elected {
+		release()
+		return nil, nil, false
+	}
+	return elector, release, true
+}
+
+// closeManageService applies the shutdown policies of the servers, unless the cluster leadership was lost,
+// the devices belong to the new leader then and are left as they are.
+func closeManageService(manageService manage.Service, clusterLost <-chan struct{}) {
+	select {
+	case <-clusterLost:
+		manageService.Abandon()
+	default:
+		manageService.Close()
+	}
+}
@@ -0,0 +1,228 @@
+package main
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/sirupsen/logrus"
+	bboltdb "go.etcd.io/bbolt"
+
+	"github.com/UnAfraid/wg-ui/pkg/api"
+	"github.com/UnAfraid/wg-ui/pkg/auth"
+	"github.com/UnAfraid/wg-ui/pkg/backend"
+	"github.com/UnAfraid/wg-ui/pkg/config"
+	"github.com/UnAfraid/wg-ui/pkg/datastore"
+	"github.com/UnAfraid/wg-ui/pkg/datastore/bbolt"
+	"github.com/UnAfraid/wg-ui/pkg/dbx"
+	"github.com/UnAfraid/wg-ui/pkg/hook"
+	"github.com/UnAfraid/wg-ui/pkg/manage"
+	"github.com/UnAfraid/wg-ui/pkg/peer"
+	"github.com/UnAfraid/wg-ui/pkg/server"
+	"github.com/UnAfraid/wg-ui/pkg/subscription"
+	"github.com/UnAfraid/wg-ui/pkg/user"
+	"github.com/UnAfraid/wg-ui/pkg/wireguard"
+)
+
+// startClusterSnapshots writes a snapshot of the database for the followers every snapshot interval,
+// the returned function stops writing them.
+func startClusterSnapshots(conf *config.Config, db *bboltdb.DB) func() {
+	snapshotPath := conf.Cluster.SnapshotFileOrDefault()
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+
+		ticker := time.NewTicker(conf.Cluster.SnapshotInterval)
+		defer ticker.Stop()
+
+		for {
+			if err := datastore.WriteSnapshot(db, snapshotPath); err != nil {
+				logrus.
+					WithError(err).
+					WithField("path", snapshotPath).
+					Warn("failed to write cluster database snapshot")
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+
+	return func() {
+		cancel()
+		<-done
+	}
+}
+
+// snapshotQueries serves the GraphQL queries of a follower from the latest database snapshot of the leader,
+// the snapshot is opened again once the leader replaced it.
+type snapshotQueries struct {
+	conf         *config.Config
+	snapshotPath string
+
+	lock        sync.RWMutex
+	modTime     time.Time
+	router      http.Handler
+	closeRouter func()
+}
+
+func newSnapshotQueries(conf *config.Config) *snapshotQueries {
+	return &snapshotQueries{
+		conf:         conf,
+		snapshotPath: conf.Cluster.SnapshotFileOrDefault(),
+	}
+}
+
+func (q *snapshotQueries) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
+	if err := q.open(); err != nil {
+		logrus.
+			WithError(err).
+			WithField("path", q.snapshotPath).
+			Warn("failed to open cluster database snapshot")
+	}
+
+	q.lock.RLock()
+	defer q.lock.RUnlock()
+
+	if q.router == nil {
+		http.Error(writer, "no database snapshot is available", http.StatusServiceUnavailable)
+		return
+	}
+	q.router.ServeHTTP(writer, request)
+}
+
+// open opens the snapshot when it changed since it was last opened, the previous one is closed once
+// the requests served from it are done.
+func (q *snapshotQueries) open() error {
+	info, err := os.Stat(q.snapshotPath)
+	if err != nil {
+		return err
+	}
+
+	q.lock.RLock()
+	current := q.router != nil && info.ModTime().Equal(q.modTime)
+	q.lock.RUnlock()
+	if current {
+		return nil
+	}
+
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	if q.router != nil && info.ModTime().Equal(q.modTime) {
+		return nil
+	}
+
+	db, err := datastore.NewReadOnlyBBoltDB(q.snapshotPath, q.conf.BoltDB.Timeout)
+	if err != nil {
+		return err
+	}
+
+	router, closeRouter, err := newSnapshotRouter(q.conf, db)
+	if err != nil {
+		_ = db.Close()
+		return err
+	}
+
+	if q.closeRouter != nil {
+		q.closeRouter()
+	}
+	q.modTime = info.ModTime()
+	q.router = router
+	q.closeRouter = closeRouter
+	return nil
+}
+
+func (q *snapshotQueries) Close() {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	if q.closeRouter != nil {
+		q.closeRouter()
+	}
+	q.router = nil
+	q.closeRouter = nil
+}
+
+// newSnapshotRouter serves the api from the read only snapshot database, its wireguard service never changes
+// the devices and device data is not read.
+func newSnapshotRouter(conf *config.Config, db *bboltdb.DB) (http.Handler, func(), error) {
+	jwtSecretBytes, err := base64.StdEncoding.DecodeString(conf.JwtSecret)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to base64 decode jwt secret: %w", err)
+	}
+
+	transactionScoper := dbx.NewBBoltTransactionScoper(db)
+
+	subscriptionImpl, err := subscription.NewSubscription(subscription.Options{}, nil, nil)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to initialize subscription: %w", err)
+	}
+
+	hookService := hook.NewService(bbolt.NewHookExecutionRepository(db), transactionScoper, conf.HookTimeout, conf.HookOutputLimit, conf.HookHistoryLimit, hook.Policy{})
+
+	serverRepository := bbolt.NewServerRepository(db)
+	serverService := server.NewService(serverRepository, transactionScoper, hookService, subscriptionImpl)
+	peerService := peer.NewService(bbolt.NewPeerRepository(db), bbolt.NewPeerGroupRepository(db), transactionScoper, serverService, hookService, subscriptionImpl)
+	backendService := backend.NewService(bbolt.NewBackendRepository(db), backend.NewServerCounter(serverRepository), transactionScoper, subscriptionImpl)
+
+	// the users were created by the leader, the snapshot has at least the initial one
+	userService, err := user.NewService(bbolt.NewUserRepository(db), transactionScoper, subscriptionImpl, "", "")
+	if err != nil {
+		hookService.Close()
+		_ = subscriptionImpl.Close()
+		return nil, nil, fmt.Errorf("failed to initialize user service: %w", err)
+	}
+
+	wireguardRegistry := wireguard.NewRegistry(wireguard.HealthPolicy{})
+	wireguardRegistry.SetFence(func(context.Context) error {
+		return manage.ErrLeaderOnly
+	})
+	wireguardService := wireguard.NewService(wireguardRegistry)
+
+	manageService := manage.NewReadOnlyService(transactionScoper, userService, backendService, serverService, peerService, wireguardService)
+	authService := auth.NewService(jwt.SigningMethodHS256, jwtSecretBytes, jwtSecretBytes, conf.JwtDuration)
+
+	router := api.NewRouter(
+		conf,
+		authService,
+		userService,
+		serverService,
+		peerService,
+		backendService,
+		manageService,
+		hookService,
+		subscriptionImpl,
+	)
+
+	closeRouter := func() {
+		manageService.Close()
+		if err := wireguardService.Close(context.Background()); err != nil {
+			logrus.
+				WithError(err).
+				Error("failed to close snapshot wireguard service")
+		}
+		hookService.Close()
+		if err := subscriptionImpl.Close(); err != nil {
+			logrus.
+				WithError(err).
+				Error("failed to close snapshot subscription")
+		}
+		if err := db.Close(); err != nil {
+			logrus.
+				WithError(err).
+				Error("failed to close database snapshot")
+		}
+	}
+	return router, closeRouter, nil
+}
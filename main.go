@@ -21,6 +21,7 @@ import (
 	"github.com/UnAfraid/wg-ui/pkg/datastore"
 	"github.com/UnAfraid/wg-ui/pkg/datastore/bbolt"
 	"github.com/UnAfraid/wg-ui/pkg/dbx"
+	"github.com/UnAfraid/wg-ui/pkg/hook"
 	"github.com/UnAfraid/wg-ui/pkg/manage"
 	"github.com/UnAfraid/wg-ui/pkg/peer"
 	"github.com/UnAfraid/wg-ui/pkg/server"
@@ -44,6 +45,14 @@ func main() {
 		TimestampFormat: time.RFC3339,
 	})
 
+	shutdownChan := make(chan os.Signal, 1)
+	signal.Notify(shutdownChan, syscall.SIGTERM, syscall.SIGINT)
+
+	if len(os.Args) > 1 && os.Args[1] == "agent" {
+		runAgent(shutdownChan)
+		return
+	}
+
 	conf, err := config.Load(appName)
 	if err != nil {
 		logrus.
@@ -52,9 +61,6 @@ func main() {
 		return
 	}
 
-	shutdownChan := make(chan os.Signal, 1)
-	signal.Notify(shutdownChan, syscall.SIGTERM, syscall.SIGINT)
-
 	if _, err := maxprocs.Set(maxprocs.Logger(logrus.Printf)); err != nil {
 		logrus.
 			WithError(err).
@@ -78,8 +84,22 @@ func main() {
 		}()
 	}
 
+	var (
+		clusterLost  <-chan struct{}
+		clusterFence func(ctx context.Context) error
+	)
+	if conf.Cluster.Enabled {
+		elector, release, elected := startClusterElection(conf, shutdownChan)
+		if !elected {
+			return
+		}
+		defer release()
+		clusterLost = elector.Lost()
+		clusterFence = elector.Fence
+	}
+
 	logrus.Info("initializing database..")
-	builtin.RegisterAll()
+	builtin.RegisterAll(conf.MemoryBackendEnabled)
 
 	db, err := datastore.NewBBoltDB(conf.BoltDB.Path, conf.BoltDB.Timeout)
 	if err != nil {
@@ -89,6 +109,11 @@ func main() {
 		return
 	}
 
+	if conf.Cluster.Enabled {
+		stopSnapshots := startClusterSnapshots(conf, db)
+		defer stopSnapshots()
+	}
+
 	jwtSecretBytes, err := base64.StdEncoding.DecodeString(conf.JwtSecret)
 	if err != nil {
 		logrus.
@@ -98,13 +123,53 @@ func main() {
 	}
 
 	transactionScoper := dbx.NewBBoltTransactionScoper(db)
-	subscriptionImpl := subscription.NewInMemorySubscription()
+
+	var subscriptionTransport subscription.Transport
+	if conf.SubscriptionNatsUrl != "" {
+		subscriptionTransport, err = subscription.NewNatsTransport(conf.SubscriptionNatsUrl, conf.SubscriptionNatsSubject)
+		if err != nil {
+			logrus.
+				WithError(err).
+				Fatal("failed to initialize subscription transport")
+			return
+		}
+	}
+
+	subscriptionImpl, err := subscription.NewSubscription(subscription.Options{
+		QueueSize:            conf.SubscriptionQueueSize,
+		OverflowPolicy:       subscription.OverflowPolicy(conf.SubscriptionOverflowPolicy),
+		JournalSize:          conf.SubscriptionJournalSize,
+		PersistedJournalSize: conf.SubscriptionPersistedJournalSize,
+	}, subscriptionTransport, bbolt.NewEventJournalRepository(db))
+	if err != nil {
+		logrus.
+			WithError(err).
+			Fatal("failed to initialize subscription")
+		return
+	}
+	defer func() {
+		if err := subscriptionImpl.Close(); err != nil {
+			logrus.
+				WithError(err).
+				Error("failed to close subscription")
+		}
+	}()
+
+	hookExecutionRepository := bbolt.NewHookExecutionRepository(db)
+	hookPolicy := hook.Policy{
+		RawCommandsEnabled: conf.HookRawCommandsEnabled,
+		ScriptsDirectory:   conf.HookScriptsDirectory,
+		User:               conf.HookUser,
+		Group:              conf.HookGroup,
+	}
+	hookService := hook.NewService(hookExecutionRepository, transactionScoper, conf.HookTimeout, conf.HookOutputLimit, conf.HookHistoryLimit, hookPolicy)
+	defer hookService.Close()
 
 	serverRepository := bbolt.NewServerRepository(db)
-	serverService := server.NewService(serverRepository, transactionScoper, subscriptionImpl)
+	serverService := server.NewService(serverRepository, transactionScoper, hookService, subscriptionImpl)
 
 	peerRepository := bbolt.NewPeerRepository(db)
-	peerService := peer.NewService(peerRepository, transactionScoper, serverService, subscriptionImpl)
+	peerService := peer.NewService(peerRepository, bbolt.NewPeerGroupRepository(db), transactionScoper, serverService, hookService, subscriptionImpl)
 
 	userRepository := bbolt.NewUserRepository(db)
 	userService, err := user.NewService(userRepository, transactionScoper, subscriptionImpl, conf.Initial.Email, conf.Initial.Password)
@@ -119,7 +184,22 @@ func main() {
 	serverCounter := backend.NewServerCounter(serverRepository)
 	backendService := backend.NewService(backendRepository, serverCounter, transactionScoper, subscriptionImpl)
 
-	wireguardRegistry := wireguard.NewRegistry()
+	wireguardRegistry := wireguard.NewRegistry(wireguard.HealthPolicy{
+		FailureThreshold: conf.BackendFailureThreshold,
+		RetryBackoff:     conf.BackendRetryBackoff,
+		MaxRetryBackoff:  conf.BackendMaxRetryBackoff,
+	})
+	wireguardRegistry.OnHealthChanged(func(backendId string, health backend.Health) {
+		if err := backendService.NotifyHealthChanged(context.Background(), backendId, &health); err != nil {
+			logrus.
+				WithError(err).
+				WithField("backendId", backendId).
+				Warn("failed to notify backend health changed event")
+		}
+	})
+	if clusterFence != nil {
+		wireguardRegistry.SetFence(clusterFence)
+	}
 	wireguardService := wireguard.NewService(wireguardRegistry)
 	defer func() {
 		if err := wireguardService.Close(context.Background()); err != nil {
@@ -140,8 +220,11 @@ func main() {
 		wireguardService,
 		conf.AutomaticStatsUpdateInterval,
 		conf.AutomaticStatsUpdateOnlyWithSubscribers,
+		conf.DeviceReconfigureDelay,
+		conf.DriftCheckInterval,
+		conf.ServerShutdownTimeout,
 	)
-	defer manageService.Close()
+	defer closeManageService(manageService, clusterLost)
 
 	router := api.NewRouter(
 		conf,
@@ -151,6 +234,8 @@ func main() {
 		peerService,
 		backendService,
 		manageService,
+		hookService,
+		subscriptionImpl,
 	)
 
 	httpServer := http.Server{
@@ -167,7 +252,11 @@ func main() {
 		}
 	}()
 
-	<-shutdownChan
+	select {
+	case <-shutdownChan:
+	case <-clusterLost:
+		logrus.Warn("lost cluster leadership")
+	}
 	logrus.Info("Shutting down")
 
 	logrus.Info("Shutting down http server")
@@ -13,9 +13,11 @@ import (
 	userResolver "github.com/UnAfraid/wg-ui/pkg/api/internal/user"
 	"github.com/UnAfraid/wg-ui/pkg/auth"
 	"github.com/UnAfraid/wg-ui/pkg/backend"
+	"github.com/UnAfraid/wg-ui/pkg/hook"
 	"github.com/UnAfraid/wg-ui/pkg/manage"
 	"github.com/UnAfraid/wg-ui/pkg/peer"
 	"github.com/UnAfraid/wg-ui/pkg/server"
+	"github.com/UnAfraid/wg-ui/pkg/subscription"
 	"github.com/UnAfraid/wg-ui/pkg/user"
 )
 
@@ -27,6 +29,8 @@ func newConfig(
 	peerService peer.Service,
 	backendService backend.Service,
 	manageService manage.Service,
+	hookService hook.Service,
+	subscription subscription.Subscription,
 ) resolver.Config {
 	return resolver.Config{
 		Resolvers: &resolverRoot{
@@ -46,6 +50,7 @@ func newConfig(
 				serverService,
 				peerService,
 				backendService,
+				subscription,
 			),
 			userResolver: userResolver.NewUserResolver(
 				serverService,
@@ -53,9 +58,14 @@ func newConfig(
 			),
 			serverResolver: serverResolver.NewServerResolver(
 				peerService,
+				hookService,
 			),
 			peerResolver: peerResolver.NewPeerResolver(
 				manageService,
+				hookService,
+			),
+			peerGroupResolver: peerResolver.NewPeerGroupResolver(
+				peerService,
 			),
 			backendResolver: backendResolver.NewBackendResolver(
 				backendService,
@@ -63,6 +63,43 @@ func (r *backendResolver) Supported(ctx context.Context, b *model.Backend) (bool
 	return driver.IsSupported(parsedURL.Type), nil
 }
 
+func (r *backendResolver) Capabilities(ctx context.Context, b *model.Backend) (*model.BackendCapabilities, error) {
+	backendId, err := b.ID.String(model.IdKindBackend)
+	if err != nil {
+		return nil, err
+	}
+
+	backendLoader, err := handler.BackendLoaderFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	backendEntity, err := backendLoader.Load(ctx, backendId)()
+	if err != nil {
+		return nil, err
+	}
+
+	if backendEntity == nil {
+		return model.ToBackendCapabilities(driver.Capabilities{}), nil
+	}
+
+	parsedURL, err := backend.ParseURL(backendEntity.URL)
+	if err != nil {
+		return model.ToBackendCapabilities(driver.Capabilities{}), nil
+	}
+
+	return model.ToBackendCapabilities(driver.GetCapabilities(parsedURL.Type)), nil
+}
+
+func (r *backendResolver) Health(_ context.Context, b *model.Backend) (*model.BackendHealth, error) {
+	backendId, err := b.ID.String(model.IdKindBackend)
+	if err != nil {
+		return nil, err
+	}
+
+	return model.ToBackendHealth(r.manageService.BackendHealth(backendId)), nil
+}
+
 func (r *backendResolver) Servers(ctx context.Context, b *model.Backend, query *string, enabled *bool) ([]*model.Server, error) {
 	backendId, err := b.ID.String(model.IdKindBackend)
 	if err != nil {
@@ -17,10 +17,11 @@ import (
 )
 
 var (
-	userLoaderCtxKey    = &contextKey{"userLoader"}
-	serverLoaderCtxKey  = &contextKey{"serverLoader"}
-	peerLoaderCtxKey    = &contextKey{"peerLoader"}
-	backendLoaderCtxKey = &contextKey{"backendLoader"}
+	userLoaderCtxKey      = &contextKey{"userLoader"}
+	serverLoaderCtxKey    = &contextKey{"serverLoader"}
+	peerLoaderCtxKey      = &contextKey{"peerLoader"}
+	peerGroupLoaderCtxKey = &contextKey{"peerGroupLoader"}
+	backendLoaderCtxKey   = &contextKey{"backendLoader"}
 )
 
 func NewDataLoaderMiddleware(
@@ -38,6 +39,7 @@ func NewDataLoaderMiddleware(
 			ctx = context.WithValue(ctx, userLoaderCtxKey, newBatchedLoader(userBatchFn(userService), wait, maxBatch))
 			ctx = context.WithValue(ctx, serverLoaderCtxKey, newBatchedLoader(serverBatchFn(serverService), wait, maxBatch))
 			ctx = context.WithValue(ctx, peerLoaderCtxKey, newBatchedLoader(peerBatchFn(peerService), wait, maxBatch))
+			ctx = context.WithValue(ctx, peerGroupLoaderCtxKey, newBatchedLoader(peerGroupBatchFn(peerService), wait, maxBatch))
 			ctx = context.WithValue(ctx, backendLoaderCtxKey, newBatchedLoader(backendBatchFn(backendService), wait, maxBatch))
 
 			next.ServeHTTP(w, r.WithContext(ctx))
@@ -57,6 +59,10 @@ func PeerLoaderFromContext(ctx context.Context) (*dataloader.Loader[string, *mod
 	return dataLoaderFromContext[string, *model.Peer](ctx, peerLoaderCtxKey)
 }
 
+func PeerGroupLoaderFromContext(ctx context.Context) (*dataloader.Loader[string, *model.PeerGroup], error) {
+	return dataLoaderFromContext[string, *model.PeerGroup](ctx, peerGroupLoaderCtxKey)
+}
+
 func BackendLoaderFromContext(ctx context.Context) (*dataloader.Loader[string, *model.Backend], error) {
 	return dataLoaderFromContext[string, *model.Backend](ctx, backendLoaderCtxKey)
 }
@@ -112,6 +118,20 @@ func peerBatchFn(peerService peer.Service) func(context.Context, []string) []*da
 	}
 }
 
+func peerGroupBatchFn(peerService peer.Service) func(context.Context, []string) []*dataloader.Result[*model.PeerGroup] {
+	return func(ctx context.Context, ids []string) []*dataloader.Result[*model.PeerGroup] {
+		groups, err := peerService.FindGroups(ctx, &peer.GroupFindOptions{
+			Ids: ids,
+		})
+		return resultAndErrorToDataloaderResult(ids, adapt.Array(groups, model.ToPeerGroup), func(item *model.PeerGroup) string {
+			if item == nil {
+				return ""
+			}
+			return item.ID.Value
+		}, err)
+	}
+}
+
 func backendBatchFn(backendService backend.Service) func(context.Context, []string) []*dataloader.Result[*model.Backend] {
 	return func(ctx context.Context, ids []string) []*dataloader.Result[*model.Backend] {
 		backends, err := backendService.FindBackends(ctx, &backend.FindOptions{
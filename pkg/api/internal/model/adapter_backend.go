@@ -3,6 +3,9 @@ package model
 import (
 	"github.com/UnAfraid/wg-ui/pkg/backend"
 	"github.com/UnAfraid/wg-ui/pkg/internal/adapt"
+	"github.com/UnAfraid/wg-ui/pkg/manage"
+	"github.com/UnAfraid/wg-ui/pkg/pagination"
+	"github.com/UnAfraid/wg-ui/pkg/wireguard/driver"
 )
 
 func CreateBackendInputToCreateOptions(input CreateBackendInput) *backend.CreateOptions {
@@ -74,3 +77,94 @@ func ToBackend(b *backend.Backend) *Backend {
 		DeletedAt:   b.DeletedAt,
 	}
 }
+
+func ToBackendCapabilities(capabilities driver.Capabilities) *BackendCapabilities {
+	return &BackendCapabilities{
+		HookExecution:   capabilities.HookExecution,
+		DNS:             capabilities.DNS,
+		Firewall:        capabilities.Firewall,
+		LivePeerUpdates: capabilities.LivePeerUpdates,
+		ForeignImport:   capabilities.ForeignImport,
+		PeerStats:       capabilities.PeerStats,
+	}
+}
+
+func ToBackendHealth(health backend.Health) *BackendHealth {
+	var lastError *string
+	if health.LastError != "" {
+		lastError = adapt.ToPointer(health.LastError)
+	}
+
+	status := BackendHealthStatus(health.Status)
+	if health.Status == "" {
+		status = BackendHealthStatusUnknown
+	}
+
+	return &BackendHealth{
+		Status:              status,
+		LastSuccessAt:       health.LastSuccessAt,
+		LastFailureAt:       health.LastFailureAt,
+		LastError:           lastError,
+		LatencyMilliseconds: int(health.Latency.Milliseconds()),
+		ConsecutiveFailures: health.ConsecutiveFailures,
+		NextRetryAt:         health.NextRetryAt,
+	}
+}
+
+func ToBackendDiagnostics(diagnostics *manage.BackendDiagnostics) *BackendDiagnostics {
+	if diagnostics == nil {
+		return nil
+	}
+
+	var version *string
+	if diagnostics.Version != "" {
+		version = adapt.ToPointer(diagnostics.Version)
+	}
+
+	steps := make([]*BackendDiagnosticStep, 0, len(diagnostics.Steps))
+	for _, step := range diagnostics.Steps {
+		var message *string
+		if step.Message != "" {
+			message = adapt.ToPointer(step.Message)
+		}
+
+		steps = append(steps, &BackendDiagnosticStep{
+			Name:                 BackendDiagnosticStepName(step.Name),
+			Status:               BackendDiagnosticStatus(step.Status),
+			Message:              message,
+			DurationMilliseconds: int(step.Duration.Milliseconds()),
+		})
+	}
+
+	return &BackendDiagnostics{
+		Type:         diagnostics.Type,
+		Success:      diagnostics.Success(),
+		Version:      version,
+		Capabilities: ToBackendCapabilities(diagnostics.Capabilities),
+		Steps:        steps,
+	}
+}
+
+func BackendFilterToFilter(filter *BackendFilter) *backend.Filter {
+	if filter == nil {
+		return nil
+	}
+
+	return &backend.Filter{
+		Types:   filter.Types.Value(),
+		Enabled: filter.Enabled.Value(),
+	}
+}
+
+func ToBackendConnection(backends []*backend.Backend, options *pagination.Options) *BackendConnection {
+	edges, pageInfo := toConnection(backends, options, (*backend.Backend).Cursor, func(cursor string, b *backend.Backend) *BackendEdge {
+		return &BackendEdge{
+			Cursor: cursor,
+			Node:   ToBackend(b),
+		}
+	})
+	return &BackendConnection{
+		Edges:    edges,
+		PageInfo: pageInfo,
+	}
+}
@@ -0,0 +1,94 @@
+package model
+
+import (
+	"cmp"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/UnAfraid/wg-ui/pkg/hook"
+	"github.com/UnAfraid/wg-ui/pkg/internal/adapt"
+)
+
+func ToHookExecution(execution *hook.Execution) *HookExecution {
+	if execution == nil {
+		return nil
+	}
+	return &HookExecution{
+		Command:              execution.Command,
+		Action:               execution.Action,
+		Attempt:              execution.Attempt,
+		ExitCode:             execution.ExitCode,
+		Error:                adapt.ToPointerNilZero(execution.Error),
+		Output:               execution.Output,
+		OutputTruncated:      execution.OutputTruncated,
+		DurationMilliseconds: int(execution.Duration.Milliseconds()),
+		StartedAt:            execution.StartedAt,
+	}
+}
+
+func ToHookAction(action *hook.Action) *HookAction {
+	if action == nil {
+		return nil
+	}
+
+	hookAction := &HookAction{
+		Type: HookActionType(action.Type),
+	}
+	if action.Firewall != nil {
+		hookAction.Firewall = &FirewallHookAction{
+			Tool: FirewallTool(action.Firewall.Tool),
+			Rule: action.Firewall.Rule,
+		}
+	}
+	if action.HTTP != nil {
+		hookAction.HTTP = &HTTPHookAction{
+			URL:    action.HTTP.URL,
+			Method: cmp.Or(strings.ToUpper(action.HTTP.Method), http.MethodPost),
+		}
+	}
+	if action.Script != nil {
+		hookAction.Script = &ScriptHookAction{
+			Name: action.Script.Name,
+			Args: action.Script.Args,
+		}
+	}
+	return hookAction
+}
+
+func HookActionInputToHookAction(input *HookActionInput) *hook.Action {
+	if input == nil {
+		return nil
+	}
+
+	action := &hook.Action{
+		Type: hook.ActionType(input.Type),
+	}
+	if firewall := input.Firewall.Value(); firewall != nil {
+		action.Firewall = &hook.FirewallAction{
+			Tool: hook.FirewallTool(firewall.Tool),
+			Rule: firewall.Rule,
+		}
+	}
+	if http := input.HTTP.Value(); http != nil {
+		action.HTTP = &hook.HTTPAction{
+			URL:    http.URL,
+			Method: adapt.Dereference(http.Method.Value()),
+		}
+	}
+	if script := input.Script.Value(); script != nil {
+		action.Script = &hook.ScriptAction{
+			Name: script.Name,
+			Args: script.Args.Value(),
+		}
+	}
+	return action
+}
+
+func secondsToDuration(seconds *int) time.Duration {
+	return time.Duration(adapt.Dereference(seconds)) * time.Second
+}
+
+func durationToSeconds(duration time.Duration) int {
+	return int(duration / time.Second)
+}
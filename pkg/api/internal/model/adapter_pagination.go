@@ -0,0 +1,78 @@
+package model
+
+import (
+	"fmt"
+
+	"github.com/UnAfraid/wg-ui/pkg/internal/adapt"
+	"github.com/UnAfraid/wg-ui/pkg/pagination"
+)
+
+const (
+	defaultPageSize = 50
+	maxPageSize     = 500
+)
+
+// ToPaginationOptions converts relay connection arguments to pagination options.
+// One extra item is requested so that the connection can tell whether there is a next page.
+func ToPaginationOptions(first *int, after *string, sortField string, sortDirection *SortDirection) (*pagination.Options, error) {
+	pageSize := defaultPageSize
+	if first != nil {
+		pageSize = *first
+	}
+	if pageSize < 0 || pageSize > maxPageSize {
+		return nil, fmt.Errorf("first must be between 0 and %d", maxPageSize)
+	}
+
+	var cursor *pagination.Cursor
+	if afterCursor := adapt.Dereference(after); afterCursor != "" {
+		var err error
+		cursor, err = pagination.DecodeCursor(afterCursor)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &pagination.Options{
+		SortField:  sortField,
+		Descending: adapt.Dereference(sortDirection) == SortDirectionDesc,
+		Limit:      pageSize + 1,
+		After:      cursor,
+	}, nil
+}
+
+func toConnection[T any, E any](
+	items []T,
+	options *pagination.Options,
+	cursorFn func(T, string) pagination.Cursor,
+	edgeFn func(cursor string, item T) E,
+) ([]E, *PageInfo) {
+	hasNextPage := options.Limit > 0 && len(items) >= options.Limit
+	if hasNextPage {
+		items = items[:options.Limit-1]
+	}
+
+	pageInfo := &PageInfo{
+		HasNextPage:     hasNextPage,
+		HasPreviousPage: options.After != nil,
+	}
+
+	edges := make([]E, 0, len(items))
+	for i, item := range items {
+		cursor := cursorFn(item, options.SortField).Encode()
+		if i == 0 {
+			pageInfo.StartCursor = &cursor
+		}
+		if i == len(items)-1 {
+			pageInfo.EndCursor = &cursor
+		}
+		edges = append(edges, edgeFn(cursor, item))
+	}
+
+	return edges, pageInfo
+}
+
+func idsToStrings(ids []*ID, idKind IdKind) ([]string, error) {
+	return adapt.ArrayErr(ids, func(id *ID) (string, error) {
+		return id.String(idKind)
+	})
+}
@@ -1,45 +1,76 @@
 package model
 
 import (
+	"cmp"
+	"fmt"
+	"path"
+	"strings"
+	"time"
+
 	"github.com/UnAfraid/wg-ui/pkg/internal/adapt"
+	"github.com/UnAfraid/wg-ui/pkg/manage"
+	"github.com/UnAfraid/wg-ui/pkg/pagination"
 	"github.com/UnAfraid/wg-ui/pkg/peer"
 	"github.com/UnAfraid/wg-ui/pkg/wireguard/driver"
 )
 
-func CreatePeerInputToCreateOptions(input CreatePeerInput) *peer.CreateOptions {
+func CreatePeerInputToCreateOptions(input CreatePeerInput) (*peer.CreateOptions, error) {
+	groupId, err := input.GroupID.Value().String(IdKindPeerGroup)
+	if err != nil {
+		return nil, err
+	}
+
+	enabled := input.Enabled.Value()
 	return &peer.CreateOptions{
 		Name:                input.Name,
 		Description:         adapt.Dereference(input.Description.Value()),
+		Tags:                input.Tags.Value(),
+		GroupId:             groupId,
+		Disabled:            enabled != nil && !*enabled,
+		ExpiresAt:           input.ExpiresAt.Value(),
 		PublicKey:           input.PublicKey,
 		Endpoint:            adapt.Dereference(input.Endpoint.Value()),
 		AllowedIPs:          input.AllowedIPs,
 		PresharedKey:        adapt.Dereference(input.PresharedKey.Value()),
 		PersistentKeepalive: adapt.Dereference(input.PersistentKeepalive.Value()),
+		AllowedDestinations: input.AllowedDestinations.Value(),
+		ACL:                 adapt.Array(input.ACL.Value(), PeerACLRuleInputToPeerACLRule),
 		Hooks:               adapt.Array(input.Hooks.Value(), PeerHookInputToPeerHook),
-	}
+	}, nil
 }
 
-func UpdatePeerInputToUpdatePeerOptionsAndUpdatePeerFieldMask(input UpdatePeerInput) (options *peer.UpdateOptions, fieldMask *peer.UpdateFieldMask) {
+func UpdatePeerInputToUpdatePeerOptionsAndUpdatePeerFieldMask(input UpdatePeerInput) (options *peer.UpdateOptions, fieldMask *peer.UpdateFieldMask, err error) {
 	fieldMask = &peer.UpdateFieldMask{
 		Name:                input.Name.IsSet(),
 		Description:         input.Description.IsSet(),
+		Tags:                input.Tags.IsSet(),
+		GroupId:             input.GroupID.IsSet(),
+		Disabled:            input.Enabled.IsSet(),
+		ExpiresAt:           input.ExpiresAt.IsSet(),
 		PublicKey:           input.PublicKey.IsSet(),
 		Endpoint:            input.Endpoint.IsSet(),
 		AllowedIPs:          input.AllowedIPs.IsSet(),
 		PresharedKey:        input.PresharedKey.IsSet(),
 		PersistentKeepalive: input.PersistentKeepalive.IsSet(),
+		AllowedDestinations: input.AllowedDestinations.IsSet(),
+		ACL:                 input.ACL.IsSet(),
 		Hooks:               input.Hooks.IsSet(),
 	}
 
 	var (
 		name                string
 		description         string
-		enabled             bool
+		tags                []string
+		groupId             string
+		disabled            bool
+		expiresAt           *time.Time
 		publicKey           string
 		allowedIPs          []string
 		endpoint            string
 		presharedKey        string
 		persistentKeepalive int
+		allowedDestinations []string
+		acl                 []*peer.ACLRule
 		hooks               []*peer.Hook
 	)
 
@@ -51,6 +82,25 @@ func UpdatePeerInputToUpdatePeerOptionsAndUpdatePeerFieldMask(input UpdatePeerIn
 		description = adapt.Dereference(input.Description.Value())
 	}
 
+	if fieldMask.Tags {
+		tags = input.Tags.Value()
+	}
+
+	if fieldMask.GroupId {
+		groupId, err = input.GroupID.Value().String(IdKindPeerGroup)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	if fieldMask.Disabled {
+		disabled = !adapt.Dereference(input.Enabled.Value())
+	}
+
+	if fieldMask.ExpiresAt {
+		expiresAt = input.ExpiresAt.Value()
+	}
+
 	if fieldMask.PublicKey {
 		publicKey = adapt.Dereference(input.PublicKey.Value())
 	}
@@ -71,6 +121,14 @@ func UpdatePeerInputToUpdatePeerOptionsAndUpdatePeerFieldMask(input UpdatePeerIn
 		persistentKeepalive = adapt.Dereference(input.PersistentKeepalive.Value())
 	}
 
+	if fieldMask.AllowedDestinations {
+		allowedDestinations = input.AllowedDestinations.Value()
+	}
+
+	if fieldMask.ACL {
+		acl = adapt.Array(input.ACL.Value(), PeerACLRuleInputToPeerACLRule)
+	}
+
 	if fieldMask.Hooks {
 		hooks = adapt.Array(input.Hooks.Value(), PeerHookInputToPeerHook)
 	}
@@ -78,16 +136,21 @@ func UpdatePeerInputToUpdatePeerOptionsAndUpdatePeerFieldMask(input UpdatePeerIn
 	options = &peer.UpdateOptions{
 		Name:                name,
 		Description:         description,
-		Enabled:             enabled,
+		Tags:                tags,
+		GroupId:             groupId,
+		Disabled:            disabled,
+		ExpiresAt:           expiresAt,
 		PublicKey:           publicKey,
 		Endpoint:            endpoint,
 		AllowedIPs:          allowedIPs,
 		PresharedKey:        presharedKey,
 		PersistentKeepalive: persistentKeepalive,
+		AllowedDestinations: allowedDestinations,
+		ACL:                 acl,
 		Hooks:               hooks,
 	}
 
-	return options, fieldMask
+	return options, fieldMask, nil
 }
 
 func ToPeer(peer *peer.Peer) *Peer {
@@ -101,11 +164,17 @@ func ToPeer(peer *peer.Peer) *Peer {
 		},
 		Name:                peer.Name,
 		Description:         peer.Description,
+		Enabled:             !peer.Disabled,
+		Tags:                peer.Tags,
+		Group:               peerGroupIdToPeerGroup(peer.GroupId),
+		ExpiresAt:           peer.ExpiresAt,
 		PublicKey:           peer.PublicKey,
 		Endpoint:            peer.Endpoint,
 		AllowedIPs:          peer.AllowedIPs,
 		PresharedKey:        peer.PresharedKey,
 		PersistentKeepalive: adapt.ToPointerNilZero(peer.PersistentKeepalive),
+		AllowedDestinations: peer.AllowedDestinations,
+		ACL:                 adapt.Array(peer.ACL, ToPeerACLRule),
 		Hooks:               adapt.Array(peer.Hooks, ToPeerHook),
 		CreateUser:          userIdToUser(peer.CreateUserId),
 		UpdateUser:          userIdToUser(peer.UpdateUserId),
@@ -121,10 +190,19 @@ func ToPeerHook(hook *peer.Hook) *PeerHook {
 		return nil
 	}
 	return &PeerHook{
-		Command:     hook.Command,
-		RunOnCreate: hook.RunOnCreate,
-		RunOnUpdate: hook.RunOnUpdate,
-		RunOnDelete: hook.RunOnDelete,
+		Command:             hook.Command,
+		Action:              ToHookAction(hook.Action),
+		RunOnCreate:         hook.RunOnCreate,
+		RunOnUpdate:         hook.RunOnUpdate,
+		RunOnDelete:         hook.RunOnDelete,
+		RunOnConnect:        hook.RunOnConnect,
+		RunOnDisconnect:     hook.RunOnDisconnect,
+		RunOnEndpointChange: hook.RunOnEndpointChange,
+		RunOnApply:          hook.RunOnApply,
+		RunOnRemove:         hook.RunOnRemove,
+		TimeoutSeconds:      adapt.ToPointerNilZero(durationToSeconds(hook.Timeout)),
+		MaxAttempts:         max(hook.MaxAttempts, 1),
+		RetryDelaySeconds:   durationToSeconds(hook.RetryDelay),
 	}
 }
 
@@ -133,10 +211,19 @@ func PeerHookInputToPeerHook(hook *PeerHookInput) *peer.Hook {
 		return nil
 	}
 	return &peer.Hook{
-		Command:     hook.Command,
-		RunOnCreate: hook.RunOnCreate,
-		RunOnUpdate: hook.RunOnUpdate,
-		RunOnDelete: hook.RunOnDelete,
+		Command:             adapt.Dereference(hook.Command.Value()),
+		Action:              HookActionInputToHookAction(hook.Action.Value()),
+		RunOnCreate:         hook.RunOnCreate,
+		RunOnUpdate:         hook.RunOnUpdate,
+		RunOnDelete:         hook.RunOnDelete,
+		RunOnConnect:        adapt.Dereference(hook.RunOnConnect.Value()),
+		RunOnDisconnect:     adapt.Dereference(hook.RunOnDisconnect.Value()),
+		RunOnEndpointChange: adapt.Dereference(hook.RunOnEndpointChange.Value()),
+		RunOnApply:          adapt.Dereference(hook.RunOnApply.Value()),
+		RunOnRemove:         adapt.Dereference(hook.RunOnRemove.Value()),
+		Timeout:             secondsToDuration(hook.TimeoutSeconds.Value()),
+		MaxAttempts:         adapt.Dereference(hook.MaxAttempts.Value()),
+		RetryDelay:          secondsToDuration(hook.RetryDelaySeconds.Value()),
 	}
 }
 
@@ -152,3 +239,172 @@ func ToPeerStats(stats *driver.PeerStats) *PeerStats {
 		ProtocolVersion:   stats.ProtocolVersion,
 	}
 }
+
+func PeerFilterToFilter(filter *PeerFilter) (*peer.Filter, error) {
+	if filter == nil {
+		return nil, nil
+	}
+
+	serverIds, err := idsToStrings(filter.ServerIds.Value(), IdKindServer)
+	if err != nil {
+		return nil, err
+	}
+
+	backendIds, err := idsToStrings(filter.BackendIds.Value(), IdKindBackend)
+	if err != nil {
+		return nil, err
+	}
+
+	groupIds, err := idsToStrings(filter.GroupIds.Value(), IdKindPeerGroup)
+	if err != nil {
+		return nil, err
+	}
+
+	return &peer.Filter{
+		ServerIds:  serverIds,
+		BackendIds: backendIds,
+		GroupIds:   groupIds,
+		Online:     filter.Online.Value(),
+		Enabled:    filter.Enabled.Value(),
+		Tags:       filter.Tags.Value(),
+	}, nil
+}
+
+func ToPeerConnection(peers []*peer.Peer, options *pagination.Options) *PeerConnection {
+	edges, pageInfo := toConnection(peers, options, (*peer.Peer).Cursor, func(cursor string, p *peer.Peer) *PeerEdge {
+		return &PeerEdge{
+			Cursor: cursor,
+			Node:   ToPeer(p),
+		}
+	})
+	return &PeerConnection{
+		Edges:    edges,
+		PageInfo: pageInfo,
+	}
+}
+
+func ImportPeersInputToFileFormat(input ImportPeersInput) (peer.FileFormat, error) {
+	if format := input.Format.Value(); format != nil {
+		return peer.FileFormat(*format), nil
+	}
+
+	switch strings.ToLower(path.Ext(input.File.Filename)) {
+	case ".csv":
+		return peer.FileFormatCSV, nil
+	case ".json":
+		return peer.FileFormatJSON, nil
+	}
+
+	switch {
+	case strings.Contains(input.File.ContentType, "csv"):
+		return peer.FileFormatCSV, nil
+	case strings.Contains(input.File.ContentType, "json"):
+		return peer.FileFormatJSON, nil
+	}
+
+	return "", fmt.Errorf("%w: unable to detect format of %s", peer.ErrUnsupportedFileFormat, input.File.Filename)
+}
+
+func ToImportedPeer(importedPeer *peer.ImportedPeer) *ImportedPeer {
+	if importedPeer == nil {
+		return nil
+	}
+	return &ImportedPeer{
+		Peer:       ToPeer(importedPeer.Peer),
+		PrivateKey: adapt.ToPointerNilZero(importedPeer.PrivateKey),
+	}
+}
+
+func ToImportPeersRowError(rowError *peer.RowError) *ImportPeersRowError {
+	if rowError == nil {
+		return nil
+	}
+	return &ImportPeersRowError{
+		Row:     rowError.Row,
+		Message: rowError.Err.Error(),
+	}
+}
+
+func ToPeerExport(serverName string, format peer.FileFormat, content []byte) *PeerExport {
+	extension, contentType := "csv", "text/csv"
+	if format == peer.FileFormatJSON {
+		extension, contentType = "json", "application/json"
+	}
+	return &PeerExport{
+		FileName:    fmt.Sprintf("%s-peers.%s", serverName, extension),
+		ContentType: contentType,
+		Content:     string(content),
+	}
+}
+
+func ApplyPeerChangesInputToPeerChanges(input ApplyPeerChangesInput) (*manage.PeerChanges, error) {
+	creates, err := adapt.ArrayErr(input.Create.Value(), func(createInput *CreatePeerInput) (*manage.PeerCreate, error) {
+		serverId, err := createInput.ServerID.String(IdKindServer)
+		if err != nil {
+			return nil, err
+		}
+		options, err := CreatePeerInputToCreateOptions(*createInput)
+		if err != nil {
+			return nil, err
+		}
+		return &manage.PeerCreate{
+			ServerId: serverId,
+			Options:  options,
+		}, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	updates, err := adapt.ArrayErr(input.Update.Value(), func(updateInput *UpdatePeerInput) (*manage.PeerUpdate, error) {
+		peerId, err := updateInput.ID.String(IdKindPeer)
+		if err != nil {
+			return nil, err
+		}
+		options, fieldMask, err := UpdatePeerInputToUpdatePeerOptionsAndUpdatePeerFieldMask(*updateInput)
+		if err != nil {
+			return nil, err
+		}
+		return &manage.PeerUpdate{
+			PeerId:    peerId,
+			Options:   options,
+			FieldMask: fieldMask,
+		}, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	deletes, err := adapt.ArrayErr(input.Delete.Value(), func(deleteInput *DeletePeerInput) (string, error) {
+		return deleteInput.ID.String(IdKindPeer)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &manage.PeerChanges{
+		Create: creates,
+		Update: updates,
+		Delete: deletes,
+	}, nil
+}
+
+func ToPeerACLRule(rule *peer.ACLRule) *PeerACLRule {
+	return &PeerACLRule{
+		Destination: rule.Destination,
+		Protocol:    PeerACLProtocol(rule.Protocol),
+		FromPort:    adapt.ToPointerNilZero(rule.FromPort),
+		ToPort:      adapt.ToPointerNilZero(rule.ToPort),
+		Action:      PeerACLAction(rule.Action),
+	}
+}
+
+func PeerACLRuleInputToPeerACLRule(input *PeerACLRuleInput) *peer.ACLRule {
+	return &peer.ACLRule{
+		Destination: input.Destination,
+		Protocol:    peer.ACLProtocol(cmp.Or(adapt.Dereference(input.Protocol.Value()), PeerACLProtocolAny)),
+		FromPort:    adapt.Dereference(input.FromPort.Value()),
+		ToPort:      adapt.Dereference(input.ToPort.Value()),
+		Action:      peer.ACLAction(input.Action),
+	}
+}
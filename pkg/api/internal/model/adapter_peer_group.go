@@ -0,0 +1,73 @@
+package model
+
+import (
+	"github.com/UnAfraid/wg-ui/pkg/internal/adapt"
+	"github.com/UnAfraid/wg-ui/pkg/peer"
+)
+
+func CreatePeerGroupInputToCreateOptions(input CreatePeerGroupInput) *peer.GroupCreateOptions {
+	return &peer.GroupCreateOptions{
+		Name:                input.Name,
+		Description:         adapt.Dereference(input.Description.Value()),
+		PersistentKeepalive: adapt.Dereference(input.PersistentKeepalive.Value()),
+		DNS:                 input.DNS.Value(),
+		ACL:                 adapt.Array(input.ACL.Value(), PeerACLRuleInputToPeerACLRule),
+		Hooks:               adapt.Array(input.Hooks.Value(), PeerHookInputToPeerHook),
+		ExpiresAfter:        secondsToDuration(input.ExpiresAfterSeconds.Value()),
+	}
+}
+
+func UpdatePeerGroupInputToUpdateOptionsAndFieldMask(input UpdatePeerGroupInput) (*peer.GroupUpdateOptions, *peer.GroupUpdateFieldMask) {
+	fieldMask := &peer.GroupUpdateFieldMask{
+		Name:                input.Name.IsSet(),
+		Description:         input.Description.IsSet(),
+		PersistentKeepalive: input.PersistentKeepalive.IsSet(),
+		DNS:                 input.DNS.IsSet(),
+		ACL:                 input.ACL.IsSet(),
+		Hooks:               input.Hooks.IsSet(),
+		ExpiresAfter:        input.ExpiresAfterSeconds.IsSet(),
+	}
+
+	options := &peer.GroupUpdateOptions{
+		Name:                adapt.Dereference(input.Name.Value()),
+		Description:         adapt.Dereference(input.Description.Value()),
+		PersistentKeepalive: adapt.Dereference(input.PersistentKeepalive.Value()),
+		DNS:                 input.DNS.Value(),
+		ACL:                 adapt.Array(input.ACL.Value(), PeerACLRuleInputToPeerACLRule),
+		Hooks:               adapt.Array(input.Hooks.Value(), PeerHookInputToPeerHook),
+		ExpiresAfter:        secondsToDuration(input.ExpiresAfterSeconds.Value()),
+	}
+
+	return options, fieldMask
+}
+
+func ToPeerGroup(group *peer.Group) *PeerGroup {
+	if group == nil {
+		return nil
+	}
+	return &PeerGroup{
+		ID:                  StringID(IdKindPeerGroup, group.Id),
+		Name:                group.Name,
+		Description:         group.Description,
+		PersistentKeepalive: adapt.ToPointerNilZero(group.PersistentKeepalive),
+		DNS:                 group.DNS,
+		ACL:                 adapt.Array(group.ACL, ToPeerACLRule),
+		Hooks:               adapt.Array(group.Hooks, ToPeerHook),
+		ExpiresAfterSeconds: adapt.ToPointerNilZero(durationToSeconds(group.ExpiresAfter)),
+		CreateUser:          userIdToUser(group.CreateUserId),
+		UpdateUser:          userIdToUser(group.UpdateUserId),
+		DeleteUser:          userIdToUser(group.DeleteUserId),
+		CreatedAt:           group.CreatedAt,
+		UpdatedAt:           group.UpdatedAt,
+		DeletedAt:           group.DeletedAt,
+	}
+}
+
+func peerGroupIdToPeerGroup(groupId string) *PeerGroup {
+	if groupId == "" {
+		return nil
+	}
+	return &PeerGroup{
+		ID: StringID(IdKindPeerGroup, groupId),
+	}
+}
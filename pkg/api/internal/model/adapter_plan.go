@@ -0,0 +1,26 @@
+package model
+
+import (
+	"github.com/UnAfraid/wg-ui/pkg/internal/adapt"
+	"github.com/UnAfraid/wg-ui/pkg/manage"
+)
+
+func ToConfigurationPlan(plan *manage.Plan) *ConfigurationPlan {
+	if plan == nil {
+		return nil
+	}
+	return &ConfigurationPlan{
+		Changes: adapt.Array(plan.Changes, func(change *manage.PlanChange) *ConfigurationPlanChange {
+			return &ConfigurationPlanChange{
+				Field:  change.Field,
+				Before: change.Before,
+				After:  change.After,
+			}
+		}),
+		AddressesToAdd:    plan.AddressesToAdd,
+		AddressesToRemove: plan.AddressesToRemove,
+		RoutesToAdd:       plan.RoutesToAdd,
+		RoutesToRemove:    plan.RoutesToRemove,
+		Restart:           plan.Restart,
+	}
+}
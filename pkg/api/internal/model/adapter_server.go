@@ -2,6 +2,7 @@ package model
 
 import (
 	"github.com/UnAfraid/wg-ui/pkg/internal/adapt"
+	"github.com/UnAfraid/wg-ui/pkg/pagination"
 	"github.com/UnAfraid/wg-ui/pkg/server"
 )
 
@@ -12,17 +13,22 @@ func CreateServerInputToCreateServerOptions(input CreateServerInput) (*server.Cr
 	}
 
 	return &server.CreateOptions{
-		Name:         input.Name,
-		Description:  adapt.Dereference(input.Description.Value()),
-		BackendId:    backendId,
-		Enabled:      adapt.Dereference(input.Enabled.Value()),
-		PrivateKey:   adapt.Dereference(input.PrivateKey.Value()),
-		ListenPort:   input.ListenPort.Value(),
-		FirewallMark: nilIfZeroIntPointer(input.FirewallMark.Value()),
-		Address:      input.Address,
-		DNS:          input.DNS.Value(),
-		MTU:          adapt.Dereference(input.Mtu.Value()),
-		Hooks:        adapt.Array(input.Hooks.Value(), ServerHookInputToServerHook),
+		Name:           input.Name,
+		Description:    adapt.Dereference(input.Description.Value()),
+		Tags:           input.Tags.Value(),
+		BackendId:      backendId,
+		Enabled:        adapt.Dereference(input.Enabled.Value()),
+		PrivateKey:     adapt.Dereference(input.PrivateKey.Value()),
+		ListenPort:     input.ListenPort.Value(),
+		FirewallMark:   nilIfZeroIntPointer(input.FirewallMark.Value()),
+		Address:        input.Address,
+		DNS:            input.DNS.Value(),
+		MTU:            adapt.Dereference(input.Mtu.Value()),
+		Hooks:          adapt.Array(input.Hooks.Value(), ServerHookInputToServerHook),
+		DriftMode:      server.DriftMode(adapt.Dereference(input.DriftMode.Value())),
+		StartupPolicy:  server.StartupPolicy(adapt.Dereference(input.StartupPolicy.Value())),
+		ShutdownPolicy: server.ShutdownPolicy(adapt.Dereference(input.ShutdownPolicy.Value())),
+		Firewall:       ServerFirewallInputToServerFirewall(input.Firewall.Value()),
 	}, nil
 }
 
@@ -42,6 +48,7 @@ func ToServer(server *server.Server) *Server {
 		ID:             StringID(IdKindServer, server.Id),
 		Name:           server.Name,
 		Description:    server.Description,
+		Tags:           server.Tags,
 		Backend:        backendRef,
 		Enabled:        server.Enabled,
 		Running:        server.Running,
@@ -52,6 +59,11 @@ func ToServer(server *server.Server) *Server {
 		DNS:            server.DNS,
 		Mtu:            server.MTU,
 		Hooks:          adapt.Array(server.Hooks, ToServerHook),
+		DriftMode:      ToServerDriftMode(server.DriftMode),
+		StartupPolicy:  ToServerStartupPolicy(server.StartupPolicy),
+		ShutdownPolicy: ToServerShutdownPolicy(server.ShutdownPolicy),
+		Drift:          ToServerDrift(server.Drift),
+		Firewall:       ToServerFirewall(server.Firewall),
 		InterfaceStats: ToServerInterfaceStats(server.Stats),
 		CreateUser:     userIdToUser(server.CreateUserId),
 		UpdateUser:     userIdToUser(server.UpdateUserId),
@@ -67,11 +79,15 @@ func ToServerHook(hook *server.Hook) *ServerHook {
 		return nil
 	}
 	return &ServerHook{
-		Command:       hook.Command,
-		RunOnPreUp:    hook.RunOnPreUp,
-		RunOnPostUp:   hook.RunOnPostUp || hook.RunOnStart,
-		RunOnPreDown:  hook.RunOnPreDown,
-		RunOnPostDown: hook.RunOnPostDown || hook.RunOnStop,
+		Command:           hook.Command,
+		Action:            ToHookAction(hook.Action),
+		RunOnPreUp:        hook.RunOnPreUp,
+		RunOnPostUp:       hook.RunOnPostUp || hook.RunOnStart,
+		RunOnPreDown:      hook.RunOnPreDown,
+		RunOnPostDown:     hook.RunOnPostDown || hook.RunOnStop,
+		TimeoutSeconds:    adapt.ToPointerNilZero(durationToSeconds(hook.Timeout)),
+		MaxAttempts:       max(hook.MaxAttempts, 1),
+		RetryDelaySeconds: durationToSeconds(hook.RetryDelay),
 	}
 }
 
@@ -80,43 +96,61 @@ func ServerHookInputToServerHook(hook *ServerHookInput) *server.Hook {
 		return nil
 	}
 	return &server.Hook{
-		Command:       hook.Command,
+		Command:       adapt.Dereference(hook.Command.Value()),
+		Action:        HookActionInputToHookAction(hook.Action.Value()),
 		RunOnPreUp:    hook.RunOnPreUp,
 		RunOnPostUp:   hook.RunOnPostUp,
 		RunOnPreDown:  hook.RunOnPreDown,
 		RunOnPostDown: hook.RunOnPostDown,
+		Timeout:       secondsToDuration(hook.TimeoutSeconds.Value()),
+		MaxAttempts:   adapt.Dereference(hook.MaxAttempts.Value()),
+		RetryDelay:    secondsToDuration(hook.RetryDelaySeconds.Value()),
 	}
 }
 
 func UpdateServerInputToUpdateOptionsAndUpdateFieldMask(input UpdateServerInput) (options *server.UpdateOptions, fieldMask *server.UpdateFieldMask, err error) {
 	fieldMask = &server.UpdateFieldMask{
-		Description:  input.Description.IsSet(),
-		Enabled:      input.Enabled.IsSet(),
-		PrivateKey:   input.PrivateKey.IsSet(),
-		ListenPort:   input.ListenPort.IsSet(),
-		FirewallMark: input.FirewallMark.IsSet(),
-		Address:      input.Address.IsSet(),
-		DNS:          input.DNS.IsSet(),
-		MTU:          input.Mtu.IsSet(),
-		Hooks:        input.Hooks.IsSet(),
+		Description:    input.Description.IsSet(),
+		Tags:           input.Tags.IsSet(),
+		Enabled:        input.Enabled.IsSet(),
+		PrivateKey:     input.PrivateKey.IsSet(),
+		ListenPort:     input.ListenPort.IsSet(),
+		FirewallMark:   input.FirewallMark.IsSet(),
+		Address:        input.Address.IsSet(),
+		DNS:            input.DNS.IsSet(),
+		MTU:            input.Mtu.IsSet(),
+		Hooks:          input.Hooks.IsSet(),
+		DriftMode:      input.DriftMode.IsSet(),
+		StartupPolicy:  input.StartupPolicy.IsSet(),
+		ShutdownPolicy: input.ShutdownPolicy.IsSet(),
+		Firewall:       input.Firewall.IsSet(),
 	}
 
 	var (
-		description  string
-		enabled      bool
-		privateKey   string
-		listenPort   *int
-		firewallMark *int
-		address      string
-		dns          []string
-		mtu          int
-		hooks        []*server.Hook
+		description    string
+		tags           []string
+		enabled        bool
+		privateKey     string
+		listenPort     *int
+		firewallMark   *int
+		address        string
+		dns            []string
+		mtu            int
+		hooks          []*server.Hook
+		driftMode      server.DriftMode
+		startupPolicy  server.StartupPolicy
+		shutdownPolicy server.ShutdownPolicy
+		firewall       *server.Firewall
 	)
 
 	if fieldMask.Description {
 		description = adapt.Dereference(input.Description.Value())
 	}
 
+	if fieldMask.Tags {
+		tags = input.Tags.Value()
+	}
+
 	if fieldMask.Enabled {
 		enabled = adapt.Dereference(input.Enabled.Value())
 	}
@@ -149,16 +183,37 @@ func UpdateServerInputToUpdateOptionsAndUpdateFieldMask(input UpdateServerInput)
 		hooks = adapt.Array(input.Hooks.Value(), ServerHookInputToServerHook)
 	}
 
+	if fieldMask.DriftMode {
+		driftMode = server.DriftMode(adapt.Dereference(input.DriftMode.Value()))
+	}
+
+	if fieldMask.StartupPolicy {
+		startupPolicy = server.StartupPolicy(adapt.Dereference(input.StartupPolicy.Value()))
+	}
+
+	if fieldMask.ShutdownPolicy {
+		shutdownPolicy = server.ShutdownPolicy(adapt.Dereference(input.ShutdownPolicy.Value()))
+	}
+
+	if fieldMask.Firewall {
+		firewall = ServerFirewallInputToServerFirewall(input.Firewall.Value())
+	}
+
 	options = &server.UpdateOptions{
-		Description:  description,
-		Enabled:      enabled,
-		PrivateKey:   privateKey,
-		ListenPort:   listenPort,
-		FirewallMark: firewallMark,
-		Address:      address,
-		DNS:          dns,
-		MTU:          mtu,
-		Hooks:        hooks,
+		Description:    description,
+		Tags:           tags,
+		Enabled:        enabled,
+		PrivateKey:     privateKey,
+		ListenPort:     listenPort,
+		FirewallMark:   firewallMark,
+		Address:        address,
+		DNS:            dns,
+		MTU:            mtu,
+		Hooks:          hooks,
+		DriftMode:      driftMode,
+		StartupPolicy:  startupPolicy,
+		ShutdownPolicy: shutdownPolicy,
+		Firewall:       firewall,
 	}
 
 	return options, fieldMask, nil
@@ -177,3 +232,94 @@ func ToServerInterfaceStats(stats server.Stats) *ServerInterfaceStats {
 		TxBytes: float64(stats.TxBytes),
 	}
 }
+
+func ToServerDriftMode(driftMode server.DriftMode) ServerDriftMode {
+	if driftMode == "" {
+		return ServerDriftModeAlert
+	}
+	return ServerDriftMode(driftMode)
+}
+
+func ToServerStartupPolicy(startupPolicy server.StartupPolicy) ServerStartupPolicy {
+	if startupPolicy == "" {
+		return ServerStartupPolicyStartIfEnabled
+	}
+	return ServerStartupPolicy(startupPolicy)
+}
+
+func ToServerShutdownPolicy(shutdownPolicy server.ShutdownPolicy) ServerShutdownPolicy {
+	if shutdownPolicy == "" {
+		return ServerShutdownPolicyLeaveRunning
+	}
+	return ServerShutdownPolicy(shutdownPolicy)
+}
+
+func ToServerFirewall(firewall *server.Firewall) *ServerFirewall {
+	if firewall == nil {
+		return nil
+	}
+	return &ServerFirewall{
+		MasqueradeInterface: adapt.ToPointerNilZero(firewall.MasqueradeInterface),
+		IPForwarding:        firewall.IPForwarding,
+		PeerIsolation:       firewall.PeerIsolation,
+	}
+}
+
+func ServerFirewallInputToServerFirewall(input *ServerFirewallInput) *server.Firewall {
+	if input == nil {
+		return nil
+	}
+	return &server.Firewall{
+		MasqueradeInterface: adapt.Dereference(input.MasqueradeInterface.Value()),
+		IPForwarding:        input.IPForwarding,
+		PeerIsolation:       input.PeerIsolation,
+	}
+}
+
+func ToServerDrift(drift *server.Drift) *ServerDrift {
+	if drift == nil {
+		return nil
+	}
+	return &ServerDrift{
+		DetectedAt: drift.DetectedAt,
+		Corrected:  drift.Corrected,
+		Differences: adapt.Array(drift.Differences, func(difference *server.DriftDifference) *ServerDriftDifference {
+			return &ServerDriftDifference{
+				Field:    difference.Field,
+				Expected: difference.Expected,
+				Actual:   difference.Actual,
+			}
+		}),
+	}
+}
+
+func ServerFilterToFilter(filter *ServerFilter) (*server.Filter, error) {
+	if filter == nil {
+		return nil, nil
+	}
+
+	backendIds, err := idsToStrings(filter.BackendIds.Value(), IdKindBackend)
+	if err != nil {
+		return nil, err
+	}
+
+	return &server.Filter{
+		BackendIds: backendIds,
+		Enabled:    filter.Enabled.Value(),
+		Running:    filter.Running.Value(),
+		Tags:       filter.Tags.Value(),
+	}, nil
+}
+
+func ToServerConnection(servers []*server.Server, options *pagination.Options) *ServerConnection {
+	edges, pageInfo := toConnection(servers, options, (*server.Server).Cursor, func(cursor string, s *server.Server) *ServerEdge {
+		return &ServerEdge{
+			Cursor: cursor,
+			Node:   ToServer(s),
+		}
+	})
+	return &ServerConnection{
+		Edges:    edges,
+		PageInfo: pageInfo,
+	}
+}
@@ -2,6 +2,7 @@ package model
 
 import (
 	"github.com/UnAfraid/wg-ui/pkg/internal/adapt"
+	"github.com/UnAfraid/wg-ui/pkg/pagination"
 	"github.com/UnAfraid/wg-ui/pkg/user"
 )
 
@@ -63,3 +64,16 @@ func userIdToUser(userId string) *User {
 		ID: StringID(IdKindUser, userId),
 	}
 }
+
+func ToUserConnection(users []*user.User, options *pagination.Options) *UserConnection {
+	edges, pageInfo := toConnection(users, options, (*user.User).Cursor, func(cursor string, u *user.User) *UserEdge {
+		return &UserEdge{
+			Cursor: cursor,
+			Node:   ToUser(u),
+		}
+	})
+	return &UserConnection{
+		Edges:    edges,
+		PageInfo: pageInfo,
+	}
+}
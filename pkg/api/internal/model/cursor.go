@@ -0,0 +1,58 @@
+package model
+
+import (
+	"errors"
+	"io"
+	"strconv"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+
+	"github.com/UnAfraid/wg-ui/pkg/subscription"
+)
+
+const (
+	ErrorCodeInvalidCursor = "INVALID_CURSOR"
+	ErrorCodeCursorExpired = "CURSOR_EXPIRED"
+)
+
+func MarshalCursor(cursor subscription.Cursor) graphql.Marshaler {
+	return graphql.WriterFunc(func(w io.Writer) {
+		if _, err := io.WriteString(w, strconv.Quote(subscription.EncodeCursor(cursor))); err != nil {
+			panic(err)
+		}
+	})
+}
+
+func UnmarshalCursor(v interface{}) (subscription.Cursor, error) {
+	value, ok := v.(string)
+	if !ok {
+		return subscription.Cursor{}, CursorError(subscription.ErrInvalidCursor)
+	}
+
+	cursor, err := subscription.DecodeCursor(value)
+	if err != nil {
+		return subscription.Cursor{}, CursorError(err)
+	}
+	return cursor, nil
+}
+
+// CursorError returns the error with the code telling the clients whether the cursor is invalid or expired,
+// the other errors are returned as they are.
+func CursorError(err error) error {
+	var code string
+	switch {
+	case errors.Is(err, subscription.ErrInvalidCursor):
+		code = ErrorCodeInvalidCursor
+	case errors.Is(err, subscription.ErrCursorExpired):
+		code = ErrorCodeCursorExpired
+	default:
+		return err
+	}
+
+	return &gqlerror.Error{
+		Message:    err.Error(),
+		Err:        err,
+		Extensions: map[string]interface{}{"code": code},
+	}
+}
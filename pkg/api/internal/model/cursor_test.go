@@ -0,0 +1,48 @@
+package model
+
+import (
+	"bytes"
+	"errors"
+	"strconv"
+	"testing"
+
+	"github.com/vektah/gqlparser/v2/gqlerror"
+
+	"github.com/UnAfraid/wg-ui/pkg/subscription"
+)
+
+func TestCursorRoundTrip(t *testing.T) {
+	cursor := subscription.Cursor{Epoch: "epoch", Sequence: 42}
+
+	var buffer bytes.Buffer
+	MarshalCursor(cursor).MarshalGQL(&buffer)
+	value, err := strconv.Unquote(buffer.String())
+	if err != nil {
+		t.Fatalf("failed to unquote %s: %v", buffer.String(), err)
+	}
+
+	decoded, err := UnmarshalCursor(value)
+	if err != nil {
+		t.Fatalf("UnmarshalCursor returned error: %v", err)
+	}
+	if decoded != cursor {
+		t.Fatalf("expected %+v, got %+v", cursor, decoded)
+	}
+}
+
+func TestUnmarshalCursorRejectsMalformedValueWithErrorCode(t *testing.T) {
+	for _, value := range []interface{}{"not a cursor", 42} {
+		_, err := UnmarshalCursor(value)
+
+		var gqlErr *gqlerror.Error
+		if !errors.As(err, &gqlErr) {
+			t.Fatalf("expected a graphql error for %v, got %v", value, err)
+		}
+		if code := gqlErr.Extensions["code"]; code != ErrorCodeInvalidCursor {
+			t.Fatalf("expected code %s for %v, got %v", ErrorCodeInvalidCursor, value, code)
+		}
+		if !errors.Is(err, subscription.ErrInvalidCursor) {
+			t.Fatalf("expected %v for %v, got %v", subscription.ErrInvalidCursor, value, err)
+		}
+	}
+}
@@ -3,9 +3,14 @@
 package model
 
 import (
+	"bytes"
+	"fmt"
+	"io"
+	"strconv"
 	"time"
 
 	"github.com/99designs/gqlgen/graphql"
+	"github.com/UnAfraid/wg-ui/pkg/subscription"
 )
 
 type Node interface {
@@ -17,23 +22,56 @@ type NodeChangedEvent interface {
 	IsNodeChangedEvent()
 }
 
+type ApplyPeerChangesInput struct {
+	ClientMutationID graphql.Omittable[*string]            `json:"clientMutationId,omitempty"`
+	Create           graphql.Omittable[[]*CreatePeerInput] `json:"create,omitempty"`
+	Update           graphql.Omittable[[]*UpdatePeerInput] `json:"update,omitempty"`
+	Delete           graphql.Omittable[[]*DeletePeerInput] `json:"delete,omitempty"`
+}
+
+type ApplyPeerChangesPayload struct {
+	ClientMutationID *string `json:"clientMutationId,omitempty"`
+	Created          []*Peer `json:"created"`
+	Updated          []*Peer `json:"updated"`
+	Deleted          []*Peer `json:"deleted"`
+}
+
+type ApplyPeerGroupActionInput struct {
+	ClientMutationID graphql.Omittable[*string] `json:"clientMutationId,omitempty"`
+	ID               ID                         `json:"id"`
+	Action           PeerGroupAction            `json:"action"`
+}
+
+type ApplyPeerGroupActionPayload struct {
+	ClientMutationID *string `json:"clientMutationId,omitempty"`
+	// The peers of the group after the action, deleted ones included
+	Peers []*Peer `json:"peers"`
+}
+
 // Represents a backend type that can be registered
 type AvailableBackend struct {
 	// The backend type identifier (e.g., "linux", "networkmanager", "macos")
 	Type string `json:"type"`
 	// Whether this backend type is supported on the current platform
 	Supported bool `json:"supported"`
-	// Whether a backend of this type has already been created (only one per type allowed)
+	// Whether a backend of this type has already been created, only one backend of a local type is allowed
 	Registered bool `json:"registered"`
+	// Whether backends of this type manage the host addressed by their url,
+	// several backends of the type can be created as long as they target different hosts
+	Remote bool `json:"remote"`
+	// Optional features supported by this backend type
+	Capabilities *BackendCapabilities `json:"capabilities"`
 }
 
 type Backend struct {
-	ID          ID     `json:"id"`
-	Name        string `json:"name"`
-	Description string `json:"description"`
-	URL         string `json:"url"`
-	Enabled     bool   `json:"enabled"`
-	Supported   bool   `json:"supported"`
+	ID           ID                   `json:"id"`
+	Name         string               `json:"name"`
+	Description  string               `json:"description"`
+	URL          string               `json:"url"`
+	Enabled      bool                 `json:"enabled"`
+	Supported    bool                 `json:"supported"`
+	Capabilities *BackendCapabilities `json:"capabilities"`
+	Health       *BackendHealth       `json:"health"`
 	// Use this query to find servers on this backend
 	Servers []*Server `json:"servers"`
 	// Use this query to find peers on this backend
@@ -51,9 +89,102 @@ type Backend struct {
 func (Backend) IsNode()        {}
 func (this Backend) GetID() ID { return this.ID }
 
+// Optional features of a backend type
+type BackendCapabilities struct {
+	// Raw command hooks are run by the backend as part of bringing the interface up and down
+	HookExecution bool `json:"hookExecution"`
+	// DNS servers of the interface are applied to the host
+	DNS bool `json:"dns"`
+	// NAT, forwarding and peer access control rules are managed
+	Firewall bool `json:"firewall"`
+	// Peer changes are applied without restarting the interface
+	LivePeerUpdates bool `json:"livePeerUpdates"`
+	// Unmanaged interfaces can be discovered and imported
+	ForeignImport bool `json:"foreignImport"`
+	// Per peer handshake and transfer stats are reported
+	PeerStats bool `json:"peerStats"`
+}
+
 type BackendChangedEvent struct {
 	Action string   `json:"action"`
 	Node   *Backend `json:"node"`
+	// Pass as the since argument of a subscription to resume after this event
+	Cursor subscription.Cursor `json:"cursor"`
+}
+
+type BackendConnection struct {
+	Edges    []*BackendEdge `json:"edges"`
+	PageInfo *PageInfo      `json:"pageInfo"`
+}
+
+type BackendDiagnosticStep struct {
+	Name   BackendDiagnosticStepName `json:"name"`
+	Status BackendDiagnosticStatus   `json:"status"`
+	// Details of the outcome or the error of a failed step
+	Message *string `json:"message,omitempty"`
+	// Duration of the step in milliseconds
+	DurationMilliseconds int `json:"durationMilliseconds"`
+}
+
+// Outcome of connecting to a backend url, nothing is saved
+type BackendDiagnostics struct {
+	// The backend type identifier of the url
+	Type string `json:"type"`
+	// Whether none of the steps failed
+	Success bool `json:"success"`
+	// Version of the software managing the host, when the backend reports it
+	Version *string `json:"version,omitempty"`
+	// Optional features supported by the backend type
+	Capabilities *BackendCapabilities `json:"capabilities"`
+	// The steps in the order they were run, steps after a failure are skipped
+	Steps []*BackendDiagnosticStep `json:"steps"`
+}
+
+type BackendEdge struct {
+	Cursor string   `json:"cursor"`
+	Node   *Backend `json:"node"`
+}
+
+type BackendFilter struct {
+	Types   graphql.Omittable[[]string] `json:"types,omitempty"`
+	Enabled graphql.Omittable[*bool]    `json:"enabled,omitempty"`
+}
+
+// Runtime health of the backend connection, it is tracked in memory and resets on restart
+type BackendHealth struct {
+	Status        BackendHealthStatus `json:"status"`
+	LastSuccessAt *time.Time          `json:"lastSuccessAt,omitempty"`
+	LastFailureAt *time.Time          `json:"lastFailureAt,omitempty"`
+	// The error of the last failed call
+	LastError *string `json:"lastError,omitempty"`
+	// Duration of the last call in milliseconds
+	LatencyMilliseconds int `json:"latencyMilliseconds"`
+	// Number of failed calls since the last successful one
+	ConsecutiveFailures int `json:"consecutiveFailures"`
+	// When an unhealthy backend is reconnected next
+	NextRetryAt *time.Time `json:"nextRetryAt,omitempty"`
+}
+
+type BackendHealthChangedEvent struct {
+	Node   *Backend       `json:"node"`
+	Health *BackendHealth `json:"health"`
+}
+
+type ConfigurationPlan struct {
+	Changes           []*ConfigurationPlanChange `json:"changes"`
+	AddressesToAdd    []string                   `json:"addressesToAdd"`
+	AddressesToRemove []string                   `json:"addressesToRemove"`
+	RoutesToAdd       []string                   `json:"routesToAdd"`
+	RoutesToRemove    []string                   `json:"routesToRemove"`
+	// Whether the interface would be taken down or its address, MTU, listen port or key changed,
+	// which interrupts the established tunnels
+	Restart bool `json:"restart"`
+}
+
+type ConfigurationPlanChange struct {
+	Field  string `json:"field"`
+	Before string `json:"before"`
+	After  string `json:"after"`
 }
 
 type CreateBackendInput struct {
@@ -69,43 +200,92 @@ type CreateBackendPayload struct {
 	Backend          *Backend `json:"backend"`
 }
 
+type CreatePeerGroupInput struct {
+	ClientMutationID graphql.Omittable[*string] `json:"clientMutationId,omitempty"`
+	Name             string                     `json:"name"`
+	Description      graphql.Omittable[*string] `json:"description,omitempty"`
+	// Used by the peers without a persistent keepalive of their own
+	PersistentKeepalive graphql.Omittable[*int] `json:"persistentKeepalive,omitempty"`
+	// DNS servers for the client configurations of the peers
+	DNS graphql.Omittable[[]string] `json:"dns,omitempty"`
+	// Access control rules evaluated after the rules of the peer
+	ACL graphql.Omittable[[]*PeerACLRuleInput] `json:"acl,omitempty"`
+	// Hooks run in addition to the hooks of the peer
+	Hooks graphql.Omittable[[]*PeerHookInput] `json:"hooks,omitempty"`
+	// Seconds after their creation the peers without an expiry of their own are taken off the device
+	ExpiresAfterSeconds graphql.Omittable[*int] `json:"expiresAfterSeconds,omitempty"`
+}
+
+type CreatePeerGroupPayload struct {
+	ClientMutationID *string    `json:"clientMutationId,omitempty"`
+	PeerGroup        *PeerGroup `json:"peerGroup"`
+}
+
 type CreatePeerInput struct {
-	ClientMutationID    graphql.Omittable[*string]          `json:"clientMutationId,omitempty"`
-	ServerID            ID                                  `json:"serverId"`
-	Name                string                              `json:"name"`
-	Description         graphql.Omittable[*string]          `json:"description,omitempty"`
-	PublicKey           string                              `json:"publicKey"`
-	AllowedIPs          []string                            `json:"allowedIPs"`
-	Endpoint            graphql.Omittable[*string]          `json:"endpoint,omitempty"`
-	PresharedKey        graphql.Omittable[*string]          `json:"presharedKey,omitempty"`
-	PersistentKeepalive graphql.Omittable[*int]             `json:"persistentKeepalive,omitempty"`
-	Hooks               graphql.Omittable[[]*PeerHookInput] `json:"hooks,omitempty"`
+	ClientMutationID graphql.Omittable[*string] `json:"clientMutationId,omitempty"`
+	ServerID         ID                         `json:"serverId"`
+	Name             string                     `json:"name"`
+	Description      graphql.Omittable[*string] `json:"description,omitempty"`
+	// Disabled peers are kept off the device of their server, defaults to true on create
+	Enabled graphql.Omittable[*bool] `json:"enabled,omitempty"`
+	// Labels to organize peers, compared case-insensitively
+	Tags graphql.Omittable[[]string] `json:"tags,omitempty"`
+	// Group providing the defaults of the peer
+	GroupID graphql.Omittable[*ID] `json:"groupId,omitempty"`
+	// When the peer is taken off the device, the group expiry applies when not set
+	ExpiresAt           graphql.Omittable[*time.Time] `json:"expiresAt,omitempty"`
+	PublicKey           string                        `json:"publicKey"`
+	AllowedIPs          []string                      `json:"allowedIPs"`
+	Endpoint            graphql.Omittable[*string]    `json:"endpoint,omitempty"`
+	PresharedKey        graphql.Omittable[*string]    `json:"presharedKey,omitempty"`
+	PersistentKeepalive graphql.Omittable[*int]       `json:"persistentKeepalive,omitempty"`
+	// Networks traffic from the peer may be forwarded to, any destination is allowed when empty
+	AllowedDestinations graphql.Omittable[[]string] `json:"allowedDestinations,omitempty"`
+	// Access control rules evaluated in order before the allowed destinations, the first matching rule wins.
+	// Once a rule allows traffic, anything the rules and allowed destinations do not accept is dropped, an acl of only deny rules accepts anything else
+	ACL   graphql.Omittable[[]*PeerACLRuleInput] `json:"acl,omitempty"`
+	Hooks graphql.Omittable[[]*PeerHookInput]    `json:"hooks,omitempty"`
+	// Compute the configuration plan without persisting anything or touching the backend
+	DryRun graphql.Omittable[*bool] `json:"dryRun,omitempty"`
 }
 
 type CreatePeerPayload struct {
 	ClientMutationID *string `json:"clientMutationId,omitempty"`
 	Peer             *Peer   `json:"peer,omitempty"`
+	// Changes to the server device, only set for dry runs
+	Plan *ConfigurationPlan `json:"plan,omitempty"`
 }
 
 type CreateServerInput struct {
-	ClientMutationID graphql.Omittable[*string]            `json:"clientMutationId,omitempty"`
-	Name             string                                `json:"name"`
-	Description      graphql.Omittable[*string]            `json:"description,omitempty"`
-	BackendID        ID                                    `json:"backendId"`
-	Enabled          graphql.Omittable[*bool]              `json:"enabled,omitempty"`
-	PrivateKey       graphql.Omittable[*string]            `json:"privateKey,omitempty"`
-	PublicKey        graphql.Omittable[*string]            `json:"publicKey,omitempty"`
-	ListenPort       graphql.Omittable[*int]               `json:"listenPort,omitempty"`
-	FirewallMark     graphql.Omittable[*int]               `json:"firewallMark,omitempty"`
-	Address          string                                `json:"address"`
-	DNS              graphql.Omittable[[]string]           `json:"dns,omitempty"`
-	Mtu              graphql.Omittable[*int]               `json:"mtu,omitempty"`
-	Hooks            graphql.Omittable[[]*ServerHookInput] `json:"hooks,omitempty"`
+	ClientMutationID graphql.Omittable[*string] `json:"clientMutationId,omitempty"`
+	Name             string                     `json:"name"`
+	Description      graphql.Omittable[*string] `json:"description,omitempty"`
+	// Labels to organize servers, compared case-insensitively
+	Tags           graphql.Omittable[[]string]              `json:"tags,omitempty"`
+	BackendID      ID                                       `json:"backendId"`
+	Enabled        graphql.Omittable[*bool]                 `json:"enabled,omitempty"`
+	PrivateKey     graphql.Omittable[*string]               `json:"privateKey,omitempty"`
+	PublicKey      graphql.Omittable[*string]               `json:"publicKey,omitempty"`
+	ListenPort     graphql.Omittable[*int]                  `json:"listenPort,omitempty"`
+	FirewallMark   graphql.Omittable[*int]                  `json:"firewallMark,omitempty"`
+	Address        string                                   `json:"address"`
+	DNS            graphql.Omittable[[]string]              `json:"dns,omitempty"`
+	Mtu            graphql.Omittable[*int]                  `json:"mtu,omitempty"`
+	Hooks          graphql.Omittable[[]*ServerHookInput]    `json:"hooks,omitempty"`
+	DriftMode      graphql.Omittable[*ServerDriftMode]      `json:"driftMode,omitempty"`
+	StartupPolicy  graphql.Omittable[*ServerStartupPolicy]  `json:"startupPolicy,omitempty"`
+	ShutdownPolicy graphql.Omittable[*ServerShutdownPolicy] `json:"shutdownPolicy,omitempty"`
+	// NAT and forwarding rules managed by the backend, set to null to stop managing the firewall
+	Firewall graphql.Omittable[*ServerFirewallInput] `json:"firewall,omitempty"`
+	// Compute the configuration plan without persisting anything or touching the backend
+	DryRun graphql.Omittable[*bool] `json:"dryRun,omitempty"`
 }
 
 type CreateServerPayload struct {
 	ClientMutationID *string `json:"clientMutationId,omitempty"`
 	Server           *Server `json:"server,omitempty"`
+	// Changes to the server device, only set for dry runs
+	Plan *ConfigurationPlan `json:"plan,omitempty"`
 }
 
 type CreateUserInput struct {
@@ -129,24 +309,42 @@ type DeleteBackendPayload struct {
 	Backend          *Backend `json:"backend"`
 }
 
+type DeletePeerGroupInput struct {
+	ClientMutationID graphql.Omittable[*string] `json:"clientMutationId,omitempty"`
+	ID               ID                         `json:"id"`
+}
+
+type DeletePeerGroupPayload struct {
+	ClientMutationID *string    `json:"clientMutationId,omitempty"`
+	PeerGroup        *PeerGroup `json:"peerGroup"`
+}
+
 type DeletePeerInput struct {
 	ClientMutationID graphql.Omittable[*string] `json:"clientMutationId,omitempty"`
 	ID               ID                         `json:"id"`
+	// Compute the configuration plan without persisting anything or touching the backend
+	DryRun graphql.Omittable[*bool] `json:"dryRun,omitempty"`
 }
 
 type DeletePeerPayload struct {
 	ClientMutationID *string `json:"clientMutationId,omitempty"`
 	Peer             *Peer   `json:"peer,omitempty"`
+	// Changes to the server device, only set for dry runs
+	Plan *ConfigurationPlan `json:"plan,omitempty"`
 }
 
 type DeleteServerInput struct {
 	ClientMutationID graphql.Omittable[*string] `json:"clientMutationId,omitempty"`
 	ID               ID                         `json:"id"`
+	// Compute the configuration plan without persisting anything or touching the backend
+	DryRun graphql.Omittable[*bool] `json:"dryRun,omitempty"`
 }
 
 type DeleteServerPayload struct {
 	ClientMutationID *string `json:"clientMutationId,omitempty"`
 	Server           *Server `json:"server,omitempty"`
+	// Changes to the server device, only set for dry runs
+	Plan *ConfigurationPlan `json:"plan,omitempty"`
 }
 
 type DeleteUserInput struct {
@@ -159,6 +357,17 @@ type DeleteUserPayload struct {
 	User             *User   `json:"user,omitempty"`
 }
 
+type FirewallHookAction struct {
+	Tool FirewallTool `json:"tool"`
+	Rule string       `json:"rule"`
+}
+
+type FirewallHookActionInput struct {
+	Tool FirewallTool `json:"tool"`
+	// Arguments of the firewall tool split on whitespace, every argument is a Go template rendered with the hook variables
+	Rule string `json:"rule"`
+}
+
 type ForeignInterface struct {
 	Name      string   `json:"name"`
 	Addresses []string `json:"addresses"`
@@ -197,6 +406,46 @@ type GenerateWireguardKeyPayload struct {
 	PublicKey        string  `json:"publicKey"`
 }
 
+type HookAction struct {
+	Type     HookActionType      `json:"type"`
+	Firewall *FirewallHookAction `json:"firewall,omitempty"`
+	HTTP     *HTTPHookAction     `json:"http,omitempty"`
+	Script   *ScriptHookAction   `json:"script,omitempty"`
+}
+
+// Exactly one of the settings matching the type is expected
+type HookActionInput struct {
+	Type     HookActionType                              `json:"type"`
+	Firewall graphql.Omittable[*FirewallHookActionInput] `json:"firewall,omitempty"`
+	HTTP     graphql.Omittable[*HTTPHookActionInput]     `json:"http,omitempty"`
+	Script   graphql.Omittable[*ScriptHookActionInput]   `json:"script,omitempty"`
+}
+
+type HookExecution struct {
+	Command string `json:"command"`
+	Action  string `json:"action"`
+	Attempt int    `json:"attempt"`
+	// Exit code of the command or the response status of an HTTP action, -1 when it could not be started or was killed
+	ExitCode int     `json:"exitCode"`
+	Error    *string `json:"error,omitempty"`
+	// Combined stdout and stderr of the command, up to the configured size limit
+	Output               string    `json:"output"`
+	OutputTruncated      bool      `json:"outputTruncated"`
+	DurationMilliseconds int       `json:"durationMilliseconds"`
+	StartedAt            time.Time `json:"startedAt"`
+}
+
+type HTTPHookAction struct {
+	URL    string `json:"url"`
+	Method string `json:"method"`
+}
+
+type HTTPHookActionInput struct {
+	URL string `json:"url"`
+	// HTTP method of the request, defaults to POST
+	Method graphql.Omittable[*string] `json:"method,omitempty"`
+}
+
 type ImportForeignServerInput struct {
 	ClientMutationID graphql.Omittable[*string] `json:"clientMutationId,omitempty"`
 	// The ID of the backend to import the foreign server from
@@ -210,52 +459,221 @@ type ImportForeignServerPayload struct {
 	Server           *Server `json:"server,omitempty"`
 }
 
+type ImportPeersInput struct {
+	ClientMutationID graphql.Omittable[*string] `json:"clientMutationId,omitempty"`
+	ServerID         ID                         `json:"serverId"`
+	File             graphql.Upload             `json:"file"`
+	// Format of the uploaded file, detected from the file name when omitted
+	Format graphql.Omittable[*PeerFileFormat] `json:"format,omitempty"`
+	// Generate a key pair for peers without a public key
+	GenerateKeys graphql.Omittable[*bool] `json:"generateKeys,omitempty"`
+	// Allocate a free address from the server subnet for peers without allowed IPs
+	GenerateAddresses graphql.Omittable[*bool] `json:"generateAddresses,omitempty"`
+}
+
+type ImportPeersPayload struct {
+	ClientMutationID *string `json:"clientMutationId,omitempty"`
+	// The created peers, empty when any row failed since nothing is imported in that case
+	Peers  []*ImportedPeer        `json:"peers"`
+	Errors []*ImportPeersRowError `json:"errors"`
+}
+
+type ImportPeersRowError struct {
+	Row     int    `json:"row"`
+	Message string `json:"message"`
+}
+
+type ImportedPeer struct {
+	Peer *Peer `json:"peer"`
+	// The private key of the peer, only returned when it was generated by the import
+	PrivateKey *string `json:"privateKey,omitempty"`
+}
+
+type MoveServerInput struct {
+	ClientMutationID graphql.Omittable[*string] `json:"clientMutationId,omitempty"`
+	ID               ID                         `json:"id"`
+	// The backend the server is moved to
+	BackendID ID `json:"backendId"`
+}
+
+type MoveServerPayload struct {
+	ClientMutationID *string `json:"clientMutationId,omitempty"`
+	Server           *Server `json:"server"`
+}
+
 type Mutation struct {
 }
 
+type PageInfo struct {
+	HasNextPage     bool    `json:"hasNextPage"`
+	HasPreviousPage bool    `json:"hasPreviousPage"`
+	StartCursor     *string `json:"startCursor,omitempty"`
+	EndCursor       *string `json:"endCursor,omitempty"`
+}
+
 type Peer struct {
-	ID                  ID          `json:"id"`
-	Server              *Server     `json:"server"`
-	Backend             *Backend    `json:"backend"`
-	Name                string      `json:"name"`
-	Description         string      `json:"description"`
-	PublicKey           string      `json:"publicKey"`
-	AllowedIPs          []string    `json:"allowedIPs,omitempty"`
-	Endpoint            string      `json:"endpoint"`
-	PresharedKey        string      `json:"presharedKey"`
-	PersistentKeepalive *int        `json:"persistentKeepalive,omitempty"`
-	Hooks               []*PeerHook `json:"hooks,omitempty"`
-	Stats               *PeerStats  `json:"stats,omitempty"`
-	CreateUser          *User       `json:"createUser,omitempty"`
-	UpdateUser          *User       `json:"updateUser,omitempty"`
-	DeleteUser          *User       `json:"deleteUser,omitempty"`
-	CreatedAt           time.Time   `json:"createdAt"`
-	UpdatedAt           time.Time   `json:"updatedAt"`
-	DeletedAt           *time.Time  `json:"deletedAt,omitempty"`
+	ID          ID       `json:"id"`
+	Server      *Server  `json:"server"`
+	Backend     *Backend `json:"backend"`
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	// Disabled peers are kept off the device of their server
+	Enabled bool `json:"enabled"`
+	// Labels to organize peers, compared case-insensitively
+	Tags []string `json:"tags"`
+	// Group providing the defaults of the peer
+	Group *PeerGroup `json:"group,omitempty"`
+	// When the peer is taken off the device, the group expiry applies when not set
+	ExpiresAt           *time.Time `json:"expiresAt,omitempty"`
+	PublicKey           string     `json:"publicKey"`
+	AllowedIPs          []string   `json:"allowedIPs,omitempty"`
+	Endpoint            string     `json:"endpoint"`
+	PresharedKey        string     `json:"presharedKey"`
+	PersistentKeepalive *int       `json:"persistentKeepalive,omitempty"`
+	// Networks traffic from the peer may be forwarded to, any destination is allowed when empty
+	AllowedDestinations []string `json:"allowedDestinations"`
+	// Access control rules evaluated in order before the allowed destinations, the first matching rule wins.
+	// Once a rule allows traffic, anything the rules and allowed destinations do not accept is dropped, an acl of only deny rules accepts anything else
+	ACL   []*PeerACLRule `json:"acl"`
+	Hooks []*PeerHook    `json:"hooks,omitempty"`
+	// Executions of the peer hooks, newest first
+	HookExecutions []*HookExecution `json:"hookExecutions"`
+	Stats          *PeerStats       `json:"stats,omitempty"`
+	CreateUser     *User            `json:"createUser,omitempty"`
+	UpdateUser     *User            `json:"updateUser,omitempty"`
+	DeleteUser     *User            `json:"deleteUser,omitempty"`
+	CreatedAt      time.Time        `json:"createdAt"`
+	UpdatedAt      time.Time        `json:"updatedAt"`
+	DeletedAt      *time.Time       `json:"deletedAt,omitempty"`
 }
 
 func (Peer) IsNode()        {}
 func (this Peer) GetID() ID { return this.ID }
 
+// Access control rule of a peer, when any rule of the peer allows traffic the traffic no rule allows is dropped
+type PeerACLRule struct {
+	Destination string          `json:"destination"`
+	Protocol    PeerACLProtocol `json:"protocol"`
+	// Destination port range of TCP and UDP rules, any port when not set
+	FromPort *int          `json:"fromPort,omitempty"`
+	ToPort   *int          `json:"toPort,omitempty"`
+	Action   PeerACLAction `json:"action"`
+}
+
+// Access control rule of a peer, when any rule of the peer allows traffic the traffic no rule allows is dropped
+type PeerACLRuleInput struct {
+	Destination string `json:"destination"`
+	// Defaults to ANY
+	Protocol graphql.Omittable[*PeerACLProtocol] `json:"protocol,omitempty"`
+	// Destination port range of TCP and UDP rules, toPort defaults to fromPort
+	FromPort graphql.Omittable[*int] `json:"fromPort,omitempty"`
+	ToPort   graphql.Omittable[*int] `json:"toPort,omitempty"`
+	Action   PeerACLAction           `json:"action"`
+}
+
 type PeerChangedEvent struct {
 	Node   *Peer  `json:"node"`
 	Action string `json:"action"`
+	// Pass as the since argument of a subscription to resume after this event
+	Cursor subscription.Cursor `json:"cursor"`
 }
 
 func (PeerChangedEvent) IsNodeChangedEvent() {}
 
+type PeerConnection struct {
+	Edges    []*PeerEdge `json:"edges"`
+	PageInfo *PageInfo   `json:"pageInfo"`
+}
+
+type PeerEdge struct {
+	Cursor string `json:"cursor"`
+	Node   *Peer  `json:"node"`
+}
+
+type PeerExport struct {
+	FileName    string `json:"fileName"`
+	ContentType string `json:"contentType"`
+	Content     string `json:"content"`
+}
+
+type PeerFilter struct {
+	ServerIds  graphql.Omittable[[]*ID] `json:"serverIds,omitempty"`
+	BackendIds graphql.Omittable[[]*ID] `json:"backendIds,omitempty"`
+	GroupIds   graphql.Omittable[[]*ID] `json:"groupIds,omitempty"`
+	Enabled    graphql.Omittable[*bool] `json:"enabled,omitempty"`
+	// Peers having all the tags
+	Tags graphql.Omittable[[]string] `json:"tags,omitempty"`
+	// A peer is online when it completed a handshake within the last three minutes
+	Online graphql.Omittable[*bool] `json:"online,omitempty"`
+}
+
+type PeerGroup struct {
+	ID          ID     `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	// Used by the peers without a persistent keepalive of their own
+	PersistentKeepalive *int `json:"persistentKeepalive,omitempty"`
+	// DNS servers for the client configurations of the peers
+	DNS []string `json:"dns"`
+	// Access control rules evaluated after the rules of the peer
+	ACL []*PeerACLRule `json:"acl"`
+	// Hooks run in addition to the hooks of the peer
+	Hooks []*PeerHook `json:"hooks"`
+	// Seconds after their creation the peers without an expiry of their own are taken off the device
+	ExpiresAfterSeconds *int       `json:"expiresAfterSeconds,omitempty"`
+	Peers               []*Peer    `json:"peers"`
+	CreateUser          *User      `json:"createUser,omitempty"`
+	UpdateUser          *User      `json:"updateUser,omitempty"`
+	DeleteUser          *User      `json:"deleteUser,omitempty"`
+	CreatedAt           time.Time  `json:"createdAt"`
+	UpdatedAt           time.Time  `json:"updatedAt"`
+	DeletedAt           *time.Time `json:"deletedAt,omitempty"`
+}
+
+func (PeerGroup) IsNode()        {}
+func (this PeerGroup) GetID() ID { return this.ID }
+
 type PeerHook struct {
-	Command     string `json:"command"`
-	RunOnCreate bool   `json:"runOnCreate"`
-	RunOnUpdate bool   `json:"runOnUpdate"`
-	RunOnDelete bool   `json:"runOnDelete"`
+	// Raw shell command, empty when the hook runs a typed action
+	Command     string      `json:"command"`
+	Action      *HookAction `json:"action,omitempty"`
+	RunOnCreate bool        `json:"runOnCreate"`
+	RunOnUpdate bool        `json:"runOnUpdate"`
+	RunOnDelete bool        `json:"runOnDelete"`
+	// Run on the first handshake after the peer was offline
+	RunOnConnect bool `json:"runOnConnect"`
+	// Run when the last handshake of a connected peer times out
+	RunOnDisconnect     bool `json:"runOnDisconnect"`
+	RunOnEndpointChange bool `json:"runOnEndpointChange"`
+	// Run when the peer shows up on the device of its server
+	RunOnApply bool `json:"runOnApply"`
+	// Run when the peer is no longer present on the device of its server
+	RunOnRemove bool `json:"runOnRemove"`
+	// Timeout of a single attempt in seconds, the configured default is used when not set
+	TimeoutSeconds    *int `json:"timeoutSeconds,omitempty"`
+	MaxAttempts       int  `json:"maxAttempts"`
+	RetryDelaySeconds int  `json:"retryDelaySeconds"`
 }
 
 type PeerHookInput struct {
-	Command     string `json:"command"`
-	RunOnCreate bool   `json:"runOnCreate"`
-	RunOnUpdate bool   `json:"runOnUpdate"`
-	RunOnDelete bool   `json:"runOnDelete"`
+	// Raw shell command, required unless a typed action is set
+	Command graphql.Omittable[*string] `json:"command,omitempty"`
+	// Typed action run instead of the raw command
+	Action      graphql.Omittable[*HookActionInput] `json:"action,omitempty"`
+	RunOnCreate bool                                `json:"runOnCreate"`
+	RunOnUpdate bool                                `json:"runOnUpdate"`
+	RunOnDelete bool                                `json:"runOnDelete"`
+	// Connection and device events are detected by the periodic stats update
+	RunOnConnect        graphql.Omittable[*bool] `json:"runOnConnect,omitempty"`
+	RunOnDisconnect     graphql.Omittable[*bool] `json:"runOnDisconnect,omitempty"`
+	RunOnEndpointChange graphql.Omittable[*bool] `json:"runOnEndpointChange,omitempty"`
+	RunOnApply          graphql.Omittable[*bool] `json:"runOnApply,omitempty"`
+	RunOnRemove         graphql.Omittable[*bool] `json:"runOnRemove,omitempty"`
+	// Timeout of a single attempt in seconds, the configured default is used when not set
+	TimeoutSeconds graphql.Omittable[*int] `json:"timeoutSeconds,omitempty"`
+	// How many times the hook is attempted, defaults to 1. Hooks run in the background after the change is saved, one at a time per server or peer
+	MaxAttempts       graphql.Omittable[*int] `json:"maxAttempts,omitempty"`
+	RetryDelaySeconds graphql.Omittable[*int] `json:"retryDelaySeconds,omitempty"`
 }
 
 type PeerStats struct {
@@ -269,21 +687,46 @@ type PeerStats struct {
 type Query struct {
 }
 
+type ScriptHookAction struct {
+	Name string   `json:"name"`
+	Args []string `json:"args"`
+}
+
+type ScriptHookActionInput struct {
+	// File name of the executable inside the configured hooks directory
+	Name string `json:"name"`
+	// Arguments as Go templates, rendered with the hook variables
+	Args graphql.Omittable[[]string] `json:"args,omitempty"`
+}
+
 type Server struct {
-	ID             ID                    `json:"id"`
-	Name           string                `json:"name"`
-	Description    string                `json:"description"`
-	Backend        *Backend              `json:"backend"`
-	Enabled        bool                  `json:"enabled"`
-	Running        bool                  `json:"running"`
-	PublicKey      string                `json:"publicKey"`
-	ListenPort     *int                  `json:"listenPort,omitempty"`
-	FirewallMark   *int                  `json:"firewallMark,omitempty"`
-	Address        string                `json:"address"`
-	DNS            []string              `json:"dns,omitempty"`
-	Mtu            int                   `json:"mtu"`
-	Hooks          []*ServerHook         `json:"hooks,omitempty"`
-	Peers          []*Peer               `json:"peers,omitempty"`
+	ID          ID     `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	// Labels to organize servers, compared case-insensitively
+	Tags         []string        `json:"tags"`
+	Backend      *Backend        `json:"backend"`
+	Enabled      bool            `json:"enabled"`
+	Running      bool            `json:"running"`
+	PublicKey    string          `json:"publicKey"`
+	ListenPort   *int            `json:"listenPort,omitempty"`
+	FirewallMark *int            `json:"firewallMark,omitempty"`
+	Address      string          `json:"address"`
+	DNS          []string        `json:"dns,omitempty"`
+	Mtu          int             `json:"mtu"`
+	Hooks        []*ServerHook   `json:"hooks,omitempty"`
+	DriftMode    ServerDriftMode `json:"driftMode"`
+	// What happens to the server when wg-ui starts
+	StartupPolicy ServerStartupPolicy `json:"startupPolicy"`
+	// What happens to the server when wg-ui shuts down
+	ShutdownPolicy ServerShutdownPolicy `json:"shutdownPolicy"`
+	// NAT and forwarding rules managed by the backend, the firewall is left untouched when not set
+	Firewall *ServerFirewall `json:"firewall,omitempty"`
+	// The last drift detected between the stored configuration and the device, null when they match
+	Drift *ServerDrift `json:"drift,omitempty"`
+	Peers []*Peer      `json:"peers,omitempty"`
+	// Executions of the server hooks, newest first
+	HookExecutions []*HookExecution      `json:"hookExecutions"`
 	InterfaceStats *ServerInterfaceStats `json:"interfaceStats,omitempty"`
 	CreateUser     *User                 `json:"createUser,omitempty"`
 	UpdateUser     *User                 `json:"updateUser,omitempty"`
@@ -299,24 +742,86 @@ func (this Server) GetID() ID { return this.ID }
 type ServerChangedEvent struct {
 	Node   *Server `json:"node"`
 	Action string  `json:"action"`
+	// Pass as the since argument of a subscription to resume after this event
+	Cursor subscription.Cursor `json:"cursor"`
 }
 
 func (ServerChangedEvent) IsNodeChangedEvent() {}
 
+type ServerConnection struct {
+	Edges    []*ServerEdge `json:"edges"`
+	PageInfo *PageInfo     `json:"pageInfo"`
+}
+
+type ServerDrift struct {
+	DetectedAt  time.Time                `json:"detectedAt"`
+	Corrected   bool                     `json:"corrected"`
+	Differences []*ServerDriftDifference `json:"differences"`
+}
+
+type ServerDriftDifference struct {
+	Field    string `json:"field"`
+	Expected string `json:"expected"`
+	Actual   string `json:"actual"`
+}
+
+type ServerEdge struct {
+	Cursor string  `json:"cursor"`
+	Node   *Server `json:"node"`
+}
+
+type ServerFilter struct {
+	BackendIds graphql.Omittable[[]*ID] `json:"backendIds,omitempty"`
+	Enabled    graphql.Omittable[*bool] `json:"enabled,omitempty"`
+	Running    graphql.Omittable[*bool] `json:"running,omitempty"`
+	// Servers having all the tags
+	Tags graphql.Omittable[[]string] `json:"tags,omitempty"`
+}
+
+type ServerFirewall struct {
+	// Egress interface traffic from the server networks is masqueraded on, NAT is disabled when not set
+	MasqueradeInterface *string `json:"masqueradeInterface,omitempty"`
+	IPForwarding        bool    `json:"ipForwarding"`
+	// Drop traffic between peers of the server
+	PeerIsolation bool `json:"peerIsolation"`
+}
+
+type ServerFirewallInput struct {
+	// Egress interface traffic from the server networks is masqueraded on, NAT is disabled when not set
+	MasqueradeInterface graphql.Omittable[*string] `json:"masqueradeInterface,omitempty"`
+	IPForwarding        bool                       `json:"ipForwarding"`
+	// Drop traffic between peers of the server
+	PeerIsolation bool `json:"peerIsolation"`
+}
+
 type ServerHook struct {
-	Command       string `json:"command"`
-	RunOnPreUp    bool   `json:"runOnPreUp"`
-	RunOnPostUp   bool   `json:"runOnPostUp"`
-	RunOnPreDown  bool   `json:"runOnPreDown"`
-	RunOnPostDown bool   `json:"runOnPostDown"`
+	// Raw shell command, empty when the hook runs a typed action
+	Command       string      `json:"command"`
+	Action        *HookAction `json:"action,omitempty"`
+	RunOnPreUp    bool        `json:"runOnPreUp"`
+	RunOnPostUp   bool        `json:"runOnPostUp"`
+	RunOnPreDown  bool        `json:"runOnPreDown"`
+	RunOnPostDown bool        `json:"runOnPostDown"`
+	// Timeout of a single attempt in seconds, the configured default is used when not set
+	TimeoutSeconds    *int `json:"timeoutSeconds,omitempty"`
+	MaxAttempts       int  `json:"maxAttempts"`
+	RetryDelaySeconds int  `json:"retryDelaySeconds"`
 }
 
 type ServerHookInput struct {
-	Command       string `json:"command"`
-	RunOnPreUp    bool   `json:"runOnPreUp"`
-	RunOnPostUp   bool   `json:"runOnPostUp"`
-	RunOnPreDown  bool   `json:"runOnPreDown"`
-	RunOnPostDown bool   `json:"runOnPostDown"`
+	// Raw shell command, required unless a typed action is set
+	Command graphql.Omittable[*string] `json:"command,omitempty"`
+	// Typed action run instead of the raw command
+	Action        graphql.Omittable[*HookActionInput] `json:"action,omitempty"`
+	RunOnPreUp    bool                                `json:"runOnPreUp"`
+	RunOnPostUp   bool                                `json:"runOnPostUp"`
+	RunOnPreDown  bool                                `json:"runOnPreDown"`
+	RunOnPostDown bool                                `json:"runOnPostDown"`
+	// Timeout of a single attempt in seconds, the configured default is used when not set
+	TimeoutSeconds graphql.Omittable[*int] `json:"timeoutSeconds,omitempty"`
+	// How many times the hook is attempted, defaults to 1. Hooks run in the background after the change is saved, one at a time per server or peer
+	MaxAttempts       graphql.Omittable[*int] `json:"maxAttempts,omitempty"`
+	RetryDelaySeconds graphql.Omittable[*int] `json:"retryDelaySeconds,omitempty"`
 }
 
 type ServerInterfaceStats struct {
@@ -360,9 +865,23 @@ type StopServerPayload struct {
 	Server           *Server `json:"server,omitempty"`
 }
 
+// The since argument takes the cursor of the last received event, the missed events are delivered before the live ones.
+// Subscribing fails when the cursor is older than the event journal, the data has to be fetched again then.
 type Subscription struct {
 }
 
+type TestBackendInput struct {
+	ClientMutationID graphql.Omittable[*string] `json:"clientMutationId,omitempty"`
+	// An existing backend whose password replaces a redacted password in the url
+	ID  graphql.Omittable[*ID] `json:"id,omitempty"`
+	URL string                 `json:"url"`
+}
+
+type TestBackendPayload struct {
+	ClientMutationID *string             `json:"clientMutationId,omitempty"`
+	Diagnostics      *BackendDiagnostics `json:"diagnostics"`
+}
+
 type UpdateBackendInput struct {
 	ClientMutationID graphql.Omittable[*string] `json:"clientMutationId,omitempty"`
 	ID               ID                         `json:"id"`
@@ -377,42 +896,92 @@ type UpdateBackendPayload struct {
 	Backend          *Backend `json:"backend"`
 }
 
+type UpdatePeerGroupInput struct {
+	ClientMutationID graphql.Omittable[*string] `json:"clientMutationId,omitempty"`
+	ID               ID                         `json:"id"`
+	Name             graphql.Omittable[*string] `json:"name,omitempty"`
+	Description      graphql.Omittable[*string] `json:"description,omitempty"`
+	// Used by the peers without a persistent keepalive of their own
+	PersistentKeepalive graphql.Omittable[*int] `json:"persistentKeepalive,omitempty"`
+	// DNS servers for the client configurations of the peers
+	DNS graphql.Omittable[[]string] `json:"dns,omitempty"`
+	// Access control rules evaluated after the rules of the peer
+	ACL graphql.Omittable[[]*PeerACLRuleInput] `json:"acl,omitempty"`
+	// Hooks run in addition to the hooks of the peer
+	Hooks graphql.Omittable[[]*PeerHookInput] `json:"hooks,omitempty"`
+	// Seconds after their creation the peers without an expiry of their own are taken off the device
+	ExpiresAfterSeconds graphql.Omittable[*int] `json:"expiresAfterSeconds,omitempty"`
+}
+
+type UpdatePeerGroupPayload struct {
+	ClientMutationID *string    `json:"clientMutationId,omitempty"`
+	PeerGroup        *PeerGroup `json:"peerGroup"`
+}
+
 type UpdatePeerInput struct {
-	ClientMutationID    graphql.Omittable[*string]          `json:"clientMutationId,omitempty"`
-	ID                  ID                                  `json:"id"`
-	Name                graphql.Omittable[*string]          `json:"name,omitempty"`
-	Description         graphql.Omittable[*string]          `json:"description,omitempty"`
-	PublicKey           graphql.Omittable[*string]          `json:"publicKey,omitempty"`
-	Endpoint            graphql.Omittable[*string]          `json:"endpoint,omitempty"`
-	AllowedIPs          graphql.Omittable[[]string]         `json:"allowedIPs,omitempty"`
-	PresharedKey        graphql.Omittable[*string]          `json:"presharedKey,omitempty"`
-	PersistentKeepalive graphql.Omittable[*int]             `json:"persistentKeepalive,omitempty"`
-	Hooks               graphql.Omittable[[]*PeerHookInput] `json:"hooks,omitempty"`
+	ClientMutationID graphql.Omittable[*string] `json:"clientMutationId,omitempty"`
+	ID               ID                         `json:"id"`
+	Name             graphql.Omittable[*string] `json:"name,omitempty"`
+	Description      graphql.Omittable[*string] `json:"description,omitempty"`
+	// Disabled peers are kept off the device of their server, defaults to true on create
+	Enabled graphql.Omittable[*bool] `json:"enabled,omitempty"`
+	// Labels to organize peers, compared case-insensitively
+	Tags graphql.Omittable[[]string] `json:"tags,omitempty"`
+	// Group providing the defaults of the peer
+	GroupID graphql.Omittable[*ID] `json:"groupId,omitempty"`
+	// When the peer is taken off the device, the group expiry applies when not set
+	ExpiresAt           graphql.Omittable[*time.Time] `json:"expiresAt,omitempty"`
+	PublicKey           graphql.Omittable[*string]    `json:"publicKey,omitempty"`
+	Endpoint            graphql.Omittable[*string]    `json:"endpoint,omitempty"`
+	AllowedIPs          graphql.Omittable[[]string]   `json:"allowedIPs,omitempty"`
+	PresharedKey        graphql.Omittable[*string]    `json:"presharedKey,omitempty"`
+	PersistentKeepalive graphql.Omittable[*int]       `json:"persistentKeepalive,omitempty"`
+	// Networks traffic from the peer may be forwarded to, any destination is allowed when empty
+	AllowedDestinations graphql.Omittable[[]string] `json:"allowedDestinations,omitempty"`
+	// Access control rules evaluated in order before the allowed destinations, the first matching rule wins.
+	// Once a rule allows traffic, anything the rules and allowed destinations do not accept is dropped, an acl of only deny rules accepts anything else
+	ACL   graphql.Omittable[[]*PeerACLRuleInput] `json:"acl,omitempty"`
+	Hooks graphql.Omittable[[]*PeerHookInput]    `json:"hooks,omitempty"`
+	// Compute the configuration plan without persisting anything or touching the backend
+	DryRun graphql.Omittable[*bool] `json:"dryRun,omitempty"`
 }
 
 type UpdatePeerPayload struct {
 	ClientMutationID *string `json:"clientMutationId,omitempty"`
 	Peer             *Peer   `json:"peer,omitempty"`
+	// Changes to the server device, only set for dry runs
+	Plan *ConfigurationPlan `json:"plan,omitempty"`
 }
 
 type UpdateServerInput struct {
-	ClientMutationID graphql.Omittable[*string]            `json:"clientMutationId,omitempty"`
-	ID               ID                                    `json:"id"`
-	Description      graphql.Omittable[*string]            `json:"description,omitempty"`
-	Enabled          graphql.Omittable[*bool]              `json:"enabled,omitempty"`
-	PublicKey        graphql.Omittable[*string]            `json:"publicKey,omitempty"`
-	PrivateKey       graphql.Omittable[*string]            `json:"privateKey,omitempty"`
-	ListenPort       graphql.Omittable[*int]               `json:"listenPort,omitempty"`
-	FirewallMark     graphql.Omittable[*int]               `json:"firewallMark,omitempty"`
-	Address          graphql.Omittable[*string]            `json:"address,omitempty"`
-	DNS              graphql.Omittable[[]string]           `json:"dns,omitempty"`
-	Mtu              graphql.Omittable[*int]               `json:"mtu,omitempty"`
-	Hooks            graphql.Omittable[[]*ServerHookInput] `json:"hooks,omitempty"`
+	ClientMutationID graphql.Omittable[*string] `json:"clientMutationId,omitempty"`
+	ID               ID                         `json:"id"`
+	Description      graphql.Omittable[*string] `json:"description,omitempty"`
+	// Labels to organize servers, compared case-insensitively
+	Tags           graphql.Omittable[[]string]              `json:"tags,omitempty"`
+	Enabled        graphql.Omittable[*bool]                 `json:"enabled,omitempty"`
+	PublicKey      graphql.Omittable[*string]               `json:"publicKey,omitempty"`
+	PrivateKey     graphql.Omittable[*string]               `json:"privateKey,omitempty"`
+	ListenPort     graphql.Omittable[*int]                  `json:"listenPort,omitempty"`
+	FirewallMark   graphql.Omittable[*int]                  `json:"firewallMark,omitempty"`
+	Address        graphql.Omittable[*string]               `json:"address,omitempty"`
+	DNS            graphql.Omittable[[]string]              `json:"dns,omitempty"`
+	Mtu            graphql.Omittable[*int]                  `json:"mtu,omitempty"`
+	Hooks          graphql.Omittable[[]*ServerHookInput]    `json:"hooks,omitempty"`
+	DriftMode      graphql.Omittable[*ServerDriftMode]      `json:"driftMode,omitempty"`
+	StartupPolicy  graphql.Omittable[*ServerStartupPolicy]  `json:"startupPolicy,omitempty"`
+	ShutdownPolicy graphql.Omittable[*ServerShutdownPolicy] `json:"shutdownPolicy,omitempty"`
+	// NAT and forwarding rules managed by the backend, set to null to stop managing the firewall
+	Firewall graphql.Omittable[*ServerFirewallInput] `json:"firewall,omitempty"`
+	// Compute the configuration plan without persisting anything or touching the backend
+	DryRun graphql.Omittable[*bool] `json:"dryRun,omitempty"`
 }
 
 type UpdateServerPayload struct {
 	ClientMutationID *string `json:"clientMutationId,omitempty"`
 	Server           *Server `json:"server,omitempty"`
+	// Changes to the server device, only set for dry runs
+	Plan *ConfigurationPlan `json:"plan,omitempty"`
 }
 
 type UpdateUserInput struct {
@@ -442,6 +1011,1011 @@ func (this User) GetID() ID { return this.ID }
 type UserChangedEvent struct {
 	Node   *User  `json:"node"`
 	Action string `json:"action"`
+	// Pass as the since argument of a subscription to resume after this event
+	Cursor subscription.Cursor `json:"cursor"`
 }
 
 func (UserChangedEvent) IsNodeChangedEvent() {}
+
+type UserConnection struct {
+	Edges    []*UserEdge `json:"edges"`
+	PageInfo *PageInfo   `json:"pageInfo"`
+}
+
+type UserEdge struct {
+	Cursor string `json:"cursor"`
+	Node   *User  `json:"node"`
+}
+
+type BackendDiagnosticStatus string
+
+const (
+	BackendDiagnosticStatusPassed BackendDiagnosticStatus = "PASSED"
+	BackendDiagnosticStatusFailed BackendDiagnosticStatus = "FAILED"
+	// The step does not apply to the backend or an earlier step failed
+	BackendDiagnosticStatusSkipped BackendDiagnosticStatus = "SKIPPED"
+)
+
+var AllBackendDiagnosticStatus = []BackendDiagnosticStatus{
+	BackendDiagnosticStatusPassed,
+	BackendDiagnosticStatusFailed,
+	BackendDiagnosticStatusSkipped,
+}
+
+func (e BackendDiagnosticStatus) IsValid() bool {
+	switch e {
+	case BackendDiagnosticStatusPassed, BackendDiagnosticStatusFailed, BackendDiagnosticStatusSkipped:
+		return true
+	}
+	return false
+}
+
+func (e BackendDiagnosticStatus) String() string {
+	return string(e)
+}
+
+func (e *BackendDiagnosticStatus) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = BackendDiagnosticStatus(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid BackendDiagnosticStatus", str)
+	}
+	return nil
+}
+
+func (e BackendDiagnosticStatus) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *BackendDiagnosticStatus) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e BackendDiagnosticStatus) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
+
+type BackendDiagnosticStepName string
+
+const (
+	// The backend is created from the url
+	BackendDiagnosticStepNameDriver BackendDiagnosticStepName = "DRIVER"
+	// The host name is resolved
+	BackendDiagnosticStepNameDNS BackendDiagnosticStepName = "DNS"
+	// A connection to the host is established
+	BackendDiagnosticStepNameTCP BackendDiagnosticStepName = "TCP"
+	// The TLS handshake is completed and the certificate verified
+	BackendDiagnosticStepNameTLS BackendDiagnosticStepName = "TLS"
+	// The credentials are accepted
+	BackendDiagnosticStepNameAuthentication BackendDiagnosticStepName = "AUTHENTICATION"
+	// The interfaces of the host can be listed
+	BackendDiagnosticStepNamePermission BackendDiagnosticStepName = "PERMISSION"
+)
+
+var AllBackendDiagnosticStepName = []BackendDiagnosticStepName{
+	BackendDiagnosticStepNameDriver,
+	BackendDiagnosticStepNameDNS,
+	BackendDiagnosticStepNameTCP,
+	BackendDiagnosticStepNameTLS,
+	BackendDiagnosticStepNameAuthentication,
+	BackendDiagnosticStepNamePermission,
+}
+
+func (e BackendDiagnosticStepName) IsValid() bool {
+	switch e {
+	case BackendDiagnosticStepNameDriver, BackendDiagnosticStepNameDNS, BackendDiagnosticStepNameTCP, BackendDiagnosticStepNameTLS, BackendDiagnosticStepNameAuthentication, BackendDiagnosticStepNamePermission:
+		return true
+	}
+	return false
+}
+
+func (e BackendDiagnosticStepName) String() string {
+	return string(e)
+}
+
+func (e *BackendDiagnosticStepName) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = BackendDiagnosticStepName(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid BackendDiagnosticStepName", str)
+	}
+	return nil
+}
+
+func (e BackendDiagnosticStepName) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *BackendDiagnosticStepName) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e BackendDiagnosticStepName) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
+
+type BackendHealthStatus string
+
+const (
+	// The backend has not been used yet
+	BackendHealthStatusUnknown BackendHealthStatus = "UNKNOWN"
+	// The last call to the backend succeeded
+	BackendHealthStatusHealthy BackendHealthStatus = "HEALTHY"
+	// The backend could not be reached, but not often enough to be short-circuited
+	BackendHealthStatusDegraded BackendHealthStatus = "DEGRADED"
+	// Calls to the backend are short-circuited until it is reconnected
+	BackendHealthStatusUnhealthy BackendHealthStatus = "UNHEALTHY"
+)
+
+var AllBackendHealthStatus = []BackendHealthStatus{
+	BackendHealthStatusUnknown,
+	BackendHealthStatusHealthy,
+	BackendHealthStatusDegraded,
+	BackendHealthStatusUnhealthy,
+}
+
+func (e BackendHealthStatus) IsValid() bool {
+	switch e {
+	case BackendHealthStatusUnknown, BackendHealthStatusHealthy, BackendHealthStatusDegraded, BackendHealthStatusUnhealthy:
+		return true
+	}
+	return false
+}
+
+func (e BackendHealthStatus) String() string {
+	return string(e)
+}
+
+func (e *BackendHealthStatus) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = BackendHealthStatus(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid BackendHealthStatus", str)
+	}
+	return nil
+}
+
+func (e BackendHealthStatus) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *BackendHealthStatus) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e BackendHealthStatus) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
+
+type BackendSortField string
+
+const (
+	BackendSortFieldName      BackendSortField = "NAME"
+	BackendSortFieldCreatedAt BackendSortField = "CREATED_AT"
+)
+
+var AllBackendSortField = []BackendSortField{
+	BackendSortFieldName,
+	BackendSortFieldCreatedAt,
+}
+
+func (e BackendSortField) IsValid() bool {
+	switch e {
+	case BackendSortFieldName, BackendSortFieldCreatedAt:
+		return true
+	}
+	return false
+}
+
+func (e BackendSortField) String() string {
+	return string(e)
+}
+
+func (e *BackendSortField) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = BackendSortField(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid BackendSortField", str)
+	}
+	return nil
+}
+
+func (e BackendSortField) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *BackendSortField) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e BackendSortField) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
+
+type FirewallTool string
+
+const (
+	FirewallToolIptables  FirewallTool = "IPTABLES"
+	FirewallToolIp6tables FirewallTool = "IP6TABLES"
+	FirewallToolNftables  FirewallTool = "NFTABLES"
+)
+
+var AllFirewallTool = []FirewallTool{
+	FirewallToolIptables,
+	FirewallToolIp6tables,
+	FirewallToolNftables,
+}
+
+func (e FirewallTool) IsValid() bool {
+	switch e {
+	case FirewallToolIptables, FirewallToolIp6tables, FirewallToolNftables:
+		return true
+	}
+	return false
+}
+
+func (e FirewallTool) String() string {
+	return string(e)
+}
+
+func (e *FirewallTool) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = FirewallTool(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid FirewallTool", str)
+	}
+	return nil
+}
+
+func (e FirewallTool) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *FirewallTool) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e FirewallTool) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
+
+type HookActionType string
+
+const (
+	// Run a single firewall rule through iptables, ip6tables or nft
+	HookActionTypeFirewall HookActionType = "FIREWALL"
+	// Call a URL with a JSON body describing the hook event
+	HookActionTypeHTTP HookActionType = "HTTP"
+	// Run an executable from the configured hooks directory
+	HookActionTypeScript HookActionType = "SCRIPT"
+)
+
+var AllHookActionType = []HookActionType{
+	HookActionTypeFirewall,
+	HookActionTypeHTTP,
+	HookActionTypeScript,
+}
+
+func (e HookActionType) IsValid() bool {
+	switch e {
+	case HookActionTypeFirewall, HookActionTypeHTTP, HookActionTypeScript:
+		return true
+	}
+	return false
+}
+
+func (e HookActionType) String() string {
+	return string(e)
+}
+
+func (e *HookActionType) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = HookActionType(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid HookActionType", str)
+	}
+	return nil
+}
+
+func (e HookActionType) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *HookActionType) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e HookActionType) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
+
+type PeerACLAction string
+
+const (
+	PeerACLActionAllow PeerACLAction = "ALLOW"
+	PeerACLActionDeny  PeerACLAction = "DENY"
+)
+
+var AllPeerACLAction = []PeerACLAction{
+	PeerACLActionAllow,
+	PeerACLActionDeny,
+}
+
+func (e PeerACLAction) IsValid() bool {
+	switch e {
+	case PeerACLActionAllow, PeerACLActionDeny:
+		return true
+	}
+	return false
+}
+
+func (e PeerACLAction) String() string {
+	return string(e)
+}
+
+func (e *PeerACLAction) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = PeerACLAction(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid PeerACLAction", str)
+	}
+	return nil
+}
+
+func (e PeerACLAction) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *PeerACLAction) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e PeerACLAction) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
+
+type PeerACLProtocol string
+
+const (
+	PeerACLProtocolAny  PeerACLProtocol = "ANY"
+	PeerACLProtocolTCP  PeerACLProtocol = "TCP"
+	PeerACLProtocolUDP  PeerACLProtocol = "UDP"
+	PeerACLProtocolICMP PeerACLProtocol = "ICMP"
+)
+
+var AllPeerACLProtocol = []PeerACLProtocol{
+	PeerACLProtocolAny,
+	PeerACLProtocolTCP,
+	PeerACLProtocolUDP,
+	PeerACLProtocolICMP,
+}
+
+func (e PeerACLProtocol) IsValid() bool {
+	switch e {
+	case PeerACLProtocolAny, PeerACLProtocolTCP, PeerACLProtocolUDP, PeerACLProtocolICMP:
+		return true
+	}
+	return false
+}
+
+func (e PeerACLProtocol) String() string {
+	return string(e)
+}
+
+func (e *PeerACLProtocol) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = PeerACLProtocol(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid PeerACLProtocol", str)
+	}
+	return nil
+}
+
+func (e PeerACLProtocol) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *PeerACLProtocol) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e PeerACLProtocol) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
+
+type PeerFileFormat string
+
+const (
+	PeerFileFormatCSV  PeerFileFormat = "CSV"
+	PeerFileFormatJSON PeerFileFormat = "JSON"
+)
+
+var AllPeerFileFormat = []PeerFileFormat{
+	PeerFileFormatCSV,
+	PeerFileFormatJSON,
+}
+
+func (e PeerFileFormat) IsValid() bool {
+	switch e {
+	case PeerFileFormatCSV, PeerFileFormatJSON:
+		return true
+	}
+	return false
+}
+
+func (e PeerFileFormat) String() string {
+	return string(e)
+}
+
+func (e *PeerFileFormat) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = PeerFileFormat(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid PeerFileFormat", str)
+	}
+	return nil
+}
+
+func (e PeerFileFormat) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *PeerFileFormat) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e PeerFileFormat) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
+
+type PeerGroupAction string
+
+const (
+	PeerGroupActionEnable  PeerGroupAction = "ENABLE"
+	PeerGroupActionDisable PeerGroupAction = "DISABLE"
+	PeerGroupActionDelete  PeerGroupAction = "DELETE"
+	// Reapply the peers of the group to the devices of their servers
+	PeerGroupActionRegenerateConfigs PeerGroupAction = "REGENERATE_CONFIGS"
+)
+
+var AllPeerGroupAction = []PeerGroupAction{
+	PeerGroupActionEnable,
+	PeerGroupActionDisable,
+	PeerGroupActionDelete,
+	PeerGroupActionRegenerateConfigs,
+}
+
+func (e PeerGroupAction) IsValid() bool {
+	switch e {
+	case PeerGroupActionEnable, PeerGroupActionDisable, PeerGroupActionDelete, PeerGroupActionRegenerateConfigs:
+		return true
+	}
+	return false
+}
+
+func (e PeerGroupAction) String() string {
+	return string(e)
+}
+
+func (e *PeerGroupAction) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = PeerGroupAction(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid PeerGroupAction", str)
+	}
+	return nil
+}
+
+func (e PeerGroupAction) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *PeerGroupAction) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e PeerGroupAction) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
+
+type PeerSortField string
+
+const (
+	PeerSortFieldName          PeerSortField = "NAME"
+	PeerSortFieldCreatedAt     PeerSortField = "CREATED_AT"
+	PeerSortFieldLastHandshake PeerSortField = "LAST_HANDSHAKE"
+)
+
+var AllPeerSortField = []PeerSortField{
+	PeerSortFieldName,
+	PeerSortFieldCreatedAt,
+	PeerSortFieldLastHandshake,
+}
+
+func (e PeerSortField) IsValid() bool {
+	switch e {
+	case PeerSortFieldName, PeerSortFieldCreatedAt, PeerSortFieldLastHandshake:
+		return true
+	}
+	return false
+}
+
+func (e PeerSortField) String() string {
+	return string(e)
+}
+
+func (e *PeerSortField) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = PeerSortField(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid PeerSortField", str)
+	}
+	return nil
+}
+
+func (e PeerSortField) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *PeerSortField) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e PeerSortField) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
+
+type ServerDriftMode string
+
+const (
+	// Only report drift between the stored configuration and the device
+	ServerDriftModeAlert ServerDriftMode = "ALERT"
+	// Reapply the stored configuration to the device when drift is detected
+	ServerDriftModeAutoCorrect ServerDriftMode = "AUTO_CORRECT"
+)
+
+var AllServerDriftMode = []ServerDriftMode{
+	ServerDriftModeAlert,
+	ServerDriftModeAutoCorrect,
+}
+
+func (e ServerDriftMode) IsValid() bool {
+	switch e {
+	case ServerDriftModeAlert, ServerDriftModeAutoCorrect:
+		return true
+	}
+	return false
+}
+
+func (e ServerDriftMode) String() string {
+	return string(e)
+}
+
+func (e *ServerDriftMode) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = ServerDriftMode(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid ServerDriftMode", str)
+	}
+	return nil
+}
+
+func (e ServerDriftMode) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *ServerDriftMode) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e ServerDriftMode) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
+
+type ServerShutdownPolicy string
+
+const (
+	// Leave the interface running
+	ServerShutdownPolicyLeaveRunning ServerShutdownPolicy = "LEAVE_RUNNING"
+	// Run the down hooks and bring the interface down
+	ServerShutdownPolicyStop ServerShutdownPolicy = "STOP"
+	// Only run the pre down hooks, the interface is left running
+	ServerShutdownPolicyPreDownHooks ServerShutdownPolicy = "PRE_DOWN_HOOKS"
+)
+
+var AllServerShutdownPolicy = []ServerShutdownPolicy{
+	ServerShutdownPolicyLeaveRunning,
+	ServerShutdownPolicyStop,
+	ServerShutdownPolicyPreDownHooks,
+}
+
+func (e ServerShutdownPolicy) IsValid() bool {
+	switch e {
+	case ServerShutdownPolicyLeaveRunning, ServerShutdownPolicyStop, ServerShutdownPolicyPreDownHooks:
+		return true
+	}
+	return false
+}
+
+func (e ServerShutdownPolicy) String() string {
+	return string(e)
+}
+
+func (e *ServerShutdownPolicy) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = ServerShutdownPolicy(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid ServerShutdownPolicy", str)
+	}
+	return nil
+}
+
+func (e ServerShutdownPolicy) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *ServerShutdownPolicy) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e ServerShutdownPolicy) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
+
+type ServerSortField string
+
+const (
+	ServerSortFieldName      ServerSortField = "NAME"
+	ServerSortFieldCreatedAt ServerSortField = "CREATED_AT"
+)
+
+var AllServerSortField = []ServerSortField{
+	ServerSortFieldName,
+	ServerSortFieldCreatedAt,
+}
+
+func (e ServerSortField) IsValid() bool {
+	switch e {
+	case ServerSortFieldName, ServerSortFieldCreatedAt:
+		return true
+	}
+	return false
+}
+
+func (e ServerSortField) String() string {
+	return string(e)
+}
+
+func (e *ServerSortField) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = ServerSortField(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid ServerSortField", str)
+	}
+	return nil
+}
+
+func (e ServerSortField) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *ServerSortField) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e ServerSortField) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
+
+type ServerStartupPolicy string
+
+const (
+	// Start the server when it is enabled
+	ServerStartupPolicyStartIfEnabled ServerStartupPolicy = "START_IF_ENABLED"
+	// Start the server, enabling it when it is disabled
+	ServerStartupPolicyAlways ServerStartupPolicy = "ALWAYS"
+	// Leave the server stopped until it is started manually
+	ServerStartupPolicyNever ServerStartupPolicy = "NEVER"
+	// Start the server only when it was running before wg-ui stopped
+	ServerStartupPolicyRestore ServerStartupPolicy = "RESTORE"
+)
+
+var AllServerStartupPolicy = []ServerStartupPolicy{
+	ServerStartupPolicyStartIfEnabled,
+	ServerStartupPolicyAlways,
+	ServerStartupPolicyNever,
+	ServerStartupPolicyRestore,
+}
+
+func (e ServerStartupPolicy) IsValid() bool {
+	switch e {
+	case ServerStartupPolicyStartIfEnabled, ServerStartupPolicyAlways, ServerStartupPolicyNever, ServerStartupPolicyRestore:
+		return true
+	}
+	return false
+}
+
+func (e ServerStartupPolicy) String() string {
+	return string(e)
+}
+
+func (e *ServerStartupPolicy) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = ServerStartupPolicy(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid ServerStartupPolicy", str)
+	}
+	return nil
+}
+
+func (e ServerStartupPolicy) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *ServerStartupPolicy) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e ServerStartupPolicy) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
+
+type SortDirection string
+
+const (
+	SortDirectionAsc  SortDirection = "ASC"
+	SortDirectionDesc SortDirection = "DESC"
+)
+
+var AllSortDirection = []SortDirection{
+	SortDirectionAsc,
+	SortDirectionDesc,
+}
+
+func (e SortDirection) IsValid() bool {
+	switch e {
+	case SortDirectionAsc, SortDirectionDesc:
+		return true
+	}
+	return false
+}
+
+func (e SortDirection) String() string {
+	return string(e)
+}
+
+func (e *SortDirection) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = SortDirection(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid SortDirection", str)
+	}
+	return nil
+}
+
+func (e SortDirection) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *SortDirection) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e SortDirection) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
+
+type UserSortField string
+
+const (
+	UserSortFieldEmail     UserSortField = "EMAIL"
+	UserSortFieldCreatedAt UserSortField = "CREATED_AT"
+)
+
+var AllUserSortField = []UserSortField{
+	UserSortFieldEmail,
+	UserSortFieldCreatedAt,
+}
+
+func (e UserSortField) IsValid() bool {
+	switch e {
+	case UserSortFieldEmail, UserSortFieldCreatedAt:
+		return true
+	}
+	return false
+}
+
+func (e UserSortField) String() string {
+	return string(e)
+}
+
+func (e *UserSortField) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = UserSortField(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid UserSortField", str)
+	}
+	return nil
+}
+
+func (e UserSortField) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *UserSortField) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e UserSortField) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
@@ -3,10 +3,11 @@ package model
 type IdKind string
 
 const (
-	IdKindUser    IdKind = "User"
-	IdKindServer  IdKind = "Server"
-	IdKindPeer    IdKind = "Peer"
-	IdKindBackend IdKind = "Backend"
+	IdKindUser      IdKind = "User"
+	IdKindServer    IdKind = "Server"
+	IdKindPeer      IdKind = "Peer"
+	IdKindPeerGroup IdKind = "PeerGroup"
+	IdKindBackend   IdKind = "Backend"
 )
 
 func (ik IdKind) String() string {
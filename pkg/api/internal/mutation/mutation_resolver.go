@@ -8,7 +8,9 @@ import (
 	"github.com/UnAfraid/wg-ui/pkg/api/internal/model"
 	"github.com/UnAfraid/wg-ui/pkg/api/internal/resolver"
 	"github.com/UnAfraid/wg-ui/pkg/auth"
+	"github.com/UnAfraid/wg-ui/pkg/internal/adapt"
 	"github.com/UnAfraid/wg-ui/pkg/manage"
+	"github.com/UnAfraid/wg-ui/pkg/peer"
 )
 
 type mutationResolver struct {
@@ -126,6 +128,19 @@ func (r *mutationResolver) CreateServer(ctx context.Context, input model.CreateS
 		return nil, err
 	}
 
+	if adapt.Dereference(input.DryRun.Value()) {
+		srv, plan, err := r.manageService.PlanCreateServer(ctx, createOptions, userId)
+		if err != nil {
+			return nil, err
+		}
+
+		return &model.CreateServerPayload{
+			ClientMutationID: input.ClientMutationID.Value(),
+			Server:           model.ToServer(srv),
+			Plan:             model.ToConfigurationPlan(plan),
+		}, nil
+	}
+
 	createdServer, err := r.manageService.CreateServer(ctx, createOptions, userId)
 	if err != nil {
 		return nil, err
@@ -158,6 +173,19 @@ func (r *mutationResolver) UpdateServer(ctx context.Context, input model.UpdateS
 		return nil, err
 	}
 
+	if adapt.Dereference(input.DryRun.Value()) {
+		srv, plan, err := r.manageService.PlanUpdateServer(ctx, serverId, updateOptions, updateFieldMask, userId)
+		if err != nil {
+			return nil, err
+		}
+
+		return &model.UpdateServerPayload{
+			ClientMutationID: input.ClientMutationID.Value(),
+			Server:           model.ToServer(srv),
+			Plan:             model.ToConfigurationPlan(plan),
+		}, nil
+	}
+
 	updatedServer, err := r.manageService.UpdateServer(ctx, serverId, updateOptions, updateFieldMask, userId)
 	if err != nil {
 		return nil, err
@@ -185,6 +213,19 @@ func (r *mutationResolver) DeleteServer(ctx context.Context, input model.DeleteS
 		return nil, err
 	}
 
+	if adapt.Dereference(input.DryRun.Value()) {
+		srv, plan, err := r.manageService.PlanDeleteServer(ctx, serverId)
+		if err != nil {
+			return nil, err
+		}
+
+		return &model.DeleteServerPayload{
+			ClientMutationID: input.ClientMutationID.Value(),
+			Server:           model.ToServer(srv),
+			Plan:             model.ToConfigurationPlan(plan),
+		}, nil
+	}
+
 	deletedServer, err := r.manageService.DeleteServer(ctx, serverId, userId)
 	if err != nil {
 		return nil, err
@@ -250,6 +291,38 @@ func (r *mutationResolver) StopServer(ctx context.Context, input model.StopServe
 	}, nil
 }
 
+func (r *mutationResolver) MoveServer(ctx context.Context, input model.MoveServerInput) (*model.MoveServerPayload, error) {
+	user, err := model.ContextToUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	userId, err := user.ID.String(model.IdKindUser)
+	if err != nil {
+		return nil, err
+	}
+
+	serverId, err := input.ID.String(model.IdKindServer)
+	if err != nil {
+		return nil, err
+	}
+
+	backendId, err := input.BackendID.String(model.IdKindBackend)
+	if err != nil {
+		return nil, err
+	}
+
+	srv, err := r.manageService.MoveServer(ctx, serverId, backendId, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.MoveServerPayload{
+		ClientMutationID: input.ClientMutationID.Value(),
+		Server:           model.ToServer(srv),
+	}, nil
+}
+
 func (r *mutationResolver) CreatePeer(ctx context.Context, input model.CreatePeerInput) (*model.CreatePeerPayload, error) {
 	user, err := model.ContextToUser(ctx)
 	if err != nil {
@@ -266,7 +339,25 @@ func (r *mutationResolver) CreatePeer(ctx context.Context, input model.CreatePee
 		return nil, err
 	}
 
-	peer, err := r.manageService.CreatePeer(ctx, serverId, model.CreatePeerInputToCreateOptions(input), userId)
+	createOptions, err := model.CreatePeerInputToCreateOptions(input)
+	if err != nil {
+		return nil, err
+	}
+
+	if adapt.Dereference(input.DryRun.Value()) {
+		p, plan, err := r.manageService.PlanCreatePeer(ctx, serverId, createOptions, userId)
+		if err != nil {
+			return nil, err
+		}
+
+		return &model.CreatePeerPayload{
+			ClientMutationID: input.ClientMutationID.Value(),
+			Peer:             model.ToPeer(p),
+			Plan:             model.ToConfigurationPlan(plan),
+		}, nil
+	}
+
+	peer, err := r.manageService.CreatePeer(ctx, serverId, createOptions, userId)
 	if err != nil {
 		return nil, err
 	}
@@ -293,7 +384,24 @@ func (r *mutationResolver) UpdatePeer(ctx context.Context, input model.UpdatePee
 		return nil, err
 	}
 
-	updateOptions, updateFieldMask := model.UpdatePeerInputToUpdatePeerOptionsAndUpdatePeerFieldMask(input)
+	updateOptions, updateFieldMask, err := model.UpdatePeerInputToUpdatePeerOptionsAndUpdatePeerFieldMask(input)
+	if err != nil {
+		return nil, err
+	}
+
+	if adapt.Dereference(input.DryRun.Value()) {
+		p, plan, err := r.manageService.PlanUpdatePeer(ctx, peerId, updateOptions, updateFieldMask, userId)
+		if err != nil {
+			return nil, err
+		}
+
+		return &model.UpdatePeerPayload{
+			ClientMutationID: input.ClientMutationID.Value(),
+			Peer:             model.ToPeer(p),
+			Plan:             model.ToConfigurationPlan(plan),
+		}, nil
+	}
+
 	peer, err := r.manageService.UpdatePeer(ctx, peerId, updateOptions, updateFieldMask, userId)
 	if err != nil {
 		return nil, err
@@ -321,6 +429,19 @@ func (r *mutationResolver) DeletePeer(ctx context.Context, input model.DeletePee
 		return nil, err
 	}
 
+	if adapt.Dereference(input.DryRun.Value()) {
+		p, plan, err := r.manageService.PlanDeletePeer(ctx, peerId)
+		if err != nil {
+			return nil, err
+		}
+
+		return &model.DeletePeerPayload{
+			ClientMutationID: input.ClientMutationID.Value(),
+			Peer:             model.ToPeer(p),
+			Plan:             model.ToConfigurationPlan(plan),
+		}, nil
+	}
+
 	peer, err := r.manageService.DeletePeer(ctx, peerId, userId)
 	if err != nil {
 		return nil, err
@@ -332,6 +453,188 @@ func (r *mutationResolver) DeletePeer(ctx context.Context, input model.DeletePee
 	}, nil
 }
 
+func (r *mutationResolver) ImportPeers(ctx context.Context, input model.ImportPeersInput) (*model.ImportPeersPayload, error) {
+	user, err := model.ContextToUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	userId, err := user.ID.String(model.IdKindUser)
+	if err != nil {
+		return nil, err
+	}
+
+	serverId, err := input.ServerID.String(model.IdKindServer)
+	if err != nil {
+		return nil, err
+	}
+
+	format, err := model.ImportPeersInputToFileFormat(input)
+	if err != nil {
+		return nil, err
+	}
+
+	createOptions, rowErrors, err := peer.DecodeFile(format, input.File.File)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(rowErrors) != 0 {
+		return &model.ImportPeersPayload{
+			ClientMutationID: input.ClientMutationID.Value(),
+			Errors:           adapt.Array(rowErrors, model.ToImportPeersRowError),
+		}, nil
+	}
+
+	result, err := r.manageService.ImportPeers(ctx, serverId, &peer.ImportOptions{
+		Peers:             createOptions,
+		GenerateKeys:      adapt.Dereference(input.GenerateKeys.Value()),
+		GenerateAddresses: adapt.Dereference(input.GenerateAddresses.Value()),
+	}, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.ImportPeersPayload{
+		ClientMutationID: input.ClientMutationID.Value(),
+		Peers:            adapt.Array(result.Peers, model.ToImportedPeer),
+		Errors:           adapt.Array(result.Errors, model.ToImportPeersRowError),
+	}, nil
+}
+
+func (r *mutationResolver) ApplyPeerChanges(ctx context.Context, input model.ApplyPeerChangesInput) (*model.ApplyPeerChangesPayload, error) {
+	user, err := model.ContextToUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	userId, err := user.ID.String(model.IdKindUser)
+	if err != nil {
+		return nil, err
+	}
+
+	changes, err := model.ApplyPeerChangesInputToPeerChanges(input)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := r.manageService.ApplyPeerChanges(ctx, changes, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.ApplyPeerChangesPayload{
+		ClientMutationID: input.ClientMutationID.Value(),
+		Created:          adapt.Array(result.Created, model.ToPeer),
+		Updated:          adapt.Array(result.Updated, model.ToPeer),
+		Deleted:          adapt.Array(result.Deleted, model.ToPeer),
+	}, nil
+}
+
+func (r *mutationResolver) CreatePeerGroup(ctx context.Context, input model.CreatePeerGroupInput) (*model.CreatePeerGroupPayload, error) {
+	user, err := model.ContextToUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	userId, err := user.ID.String(model.IdKindUser)
+	if err != nil {
+		return nil, err
+	}
+
+	group, err := r.manageService.CreatePeerGroup(ctx, model.CreatePeerGroupInputToCreateOptions(input), userId)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.CreatePeerGroupPayload{
+		ClientMutationID: input.ClientMutationID.Value(),
+		PeerGroup:        model.ToPeerGroup(group),
+	}, nil
+}
+
+func (r *mutationResolver) UpdatePeerGroup(ctx context.Context, input model.UpdatePeerGroupInput) (*model.UpdatePeerGroupPayload, error) {
+	user, err := model.ContextToUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	userId, err := user.ID.String(model.IdKindUser)
+	if err != nil {
+		return nil, err
+	}
+
+	groupId, err := input.ID.String(model.IdKindPeerGroup)
+	if err != nil {
+		return nil, err
+	}
+
+	updateOptions, updateFieldMask := model.UpdatePeerGroupInputToUpdateOptionsAndFieldMask(input)
+	group, err := r.manageService.UpdatePeerGroup(ctx, groupId, updateOptions, updateFieldMask, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.UpdatePeerGroupPayload{
+		ClientMutationID: input.ClientMutationID.Value(),
+		PeerGroup:        model.ToPeerGroup(group),
+	}, nil
+}
+
+func (r *mutationResolver) DeletePeerGroup(ctx context.Context, input model.DeletePeerGroupInput) (*model.DeletePeerGroupPayload, error) {
+	user, err := model.ContextToUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	userId, err := user.ID.String(model.IdKindUser)
+	if err != nil {
+		return nil, err
+	}
+
+	groupId, err := input.ID.String(model.IdKindPeerGroup)
+	if err != nil {
+		return nil, err
+	}
+
+	group, err := r.manageService.DeletePeerGroup(ctx, groupId, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.DeletePeerGroupPayload{
+		ClientMutationID: input.ClientMutationID.Value(),
+		PeerGroup:        model.ToPeerGroup(group),
+	}, nil
+}
+
+func (r *mutationResolver) ApplyPeerGroupAction(ctx context.Context, input model.ApplyPeerGroupActionInput) (*model.ApplyPeerGroupActionPayload, error) {
+	user, err := model.ContextToUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	userId, err := user.ID.String(model.IdKindUser)
+	if err != nil {
+		return nil, err
+	}
+
+	groupId, err := input.ID.String(model.IdKindPeerGroup)
+	if err != nil {
+		return nil, err
+	}
+
+	peers, err := r.manageService.ApplyPeerGroupAction(ctx, groupId, manage.PeerGroupAction(input.Action), userId)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.ApplyPeerGroupActionPayload{
+		ClientMutationID: input.ClientMutationID.Value(),
+		Peers:            adapt.Array(peers, model.ToPeer),
+	}, nil
+}
+
 func (r *mutationResolver) ImportForeignServer(ctx context.Context, input model.ImportForeignServerInput) (*model.ImportForeignServerPayload, error) {
 	user, err := model.ContextToUser(ctx)
 	if err != nil {
@@ -412,6 +715,27 @@ func (r *mutationResolver) UpdateBackend(ctx context.Context, input model.Update
 	}, nil
 }
 
+func (r *mutationResolver) TestBackend(ctx context.Context, input model.TestBackendInput) (*model.TestBackendPayload, error) {
+	var backendId string
+	if id := input.ID.Value(); id != nil {
+		var err error
+		backendId, err = id.String(model.IdKindBackend)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	diagnostics, err := r.manageService.TestBackend(ctx, backendId, input.URL)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.TestBackendPayload{
+		ClientMutationID: input.ClientMutationID.Value(),
+		Diagnostics:      model.ToBackendDiagnostics(diagnostics),
+	}, nil
+}
+
 func (r *mutationResolver) DeleteBackend(ctx context.Context, input model.DeleteBackendInput) (*model.DeleteBackendPayload, error) {
 	user, err := model.ContextToUser(ctx)
 	if err != nil {
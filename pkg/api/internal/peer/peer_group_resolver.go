@@ -0,0 +1,95 @@
+package peer
+
+import (
+	"context"
+
+	"github.com/UnAfraid/wg-ui/pkg/api/internal/handler"
+	"github.com/UnAfraid/wg-ui/pkg/api/internal/model"
+	"github.com/UnAfraid/wg-ui/pkg/api/internal/resolver"
+	"github.com/UnAfraid/wg-ui/pkg/internal/adapt"
+	"github.com/UnAfraid/wg-ui/pkg/peer"
+)
+
+type peerGroupResolver struct {
+	peerService peer.Service
+}
+
+func NewPeerGroupResolver(
+	peerService peer.Service,
+) resolver.PeerGroupResolver {
+	return &peerGroupResolver{
+		peerService: peerService,
+	}
+}
+
+func (r *peerGroupResolver) Peers(ctx context.Context, g *model.PeerGroup) ([]*model.Peer, error) {
+	groupId, err := g.ID.String(model.IdKindPeerGroup)
+	if err != nil {
+		return nil, err
+	}
+
+	peers, err := r.peerService.FindPeers(ctx, &peer.FindOptions{
+		Filter: &peer.Filter{
+			GroupIds: []string{groupId},
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return adapt.Array(peers, model.ToPeer), nil
+}
+
+func (r *peerGroupResolver) CreateUser(ctx context.Context, g *model.PeerGroup) (*model.User, error) {
+	if g.CreateUser == nil {
+		return nil, nil
+	}
+
+	userId, err := g.CreateUser.ID.String(model.IdKindUser)
+	if err != nil {
+		return nil, err
+	}
+
+	userLoader, err := handler.UserLoaderFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return userLoader.Load(ctx, userId)()
+}
+
+func (r *peerGroupResolver) UpdateUser(ctx context.Context, g *model.PeerGroup) (*model.User, error) {
+	if g.UpdateUser == nil {
+		return nil, nil
+	}
+
+	userId, err := g.UpdateUser.ID.String(model.IdKindUser)
+	if err != nil {
+		return nil, err
+	}
+
+	userLoader, err := handler.UserLoaderFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return userLoader.Load(ctx, userId)()
+}
+
+func (r *peerGroupResolver) DeleteUser(ctx context.Context, g *model.PeerGroup) (*model.User, error) {
+	if g.DeleteUser == nil {
+		return nil, nil
+	}
+
+	userId, err := g.DeleteUser.ID.String(model.IdKindUser)
+	if err != nil {
+		return nil, err
+	}
+
+	userLoader, err := handler.UserLoaderFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return userLoader.Load(ctx, userId)()
+}
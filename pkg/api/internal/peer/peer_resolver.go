@@ -7,18 +7,23 @@ import (
 	"github.com/UnAfraid/wg-ui/pkg/api/internal/handler"
 	"github.com/UnAfraid/wg-ui/pkg/api/internal/model"
 	"github.com/UnAfraid/wg-ui/pkg/api/internal/resolver"
+	"github.com/UnAfraid/wg-ui/pkg/hook"
+	"github.com/UnAfraid/wg-ui/pkg/internal/adapt"
 	"github.com/UnAfraid/wg-ui/pkg/manage"
 )
 
 type peerResolver struct {
 	manageService manage.Service
+	hookService   hook.Service
 }
 
 func NewPeerResolver(
 	manageService manage.Service,
+	hookService hook.Service,
 ) resolver.PeerResolver {
 	return &peerResolver{
 		manageService: manageService,
+		hookService:   hookService,
 	}
 }
 
@@ -81,6 +86,23 @@ func (r *peerResolver) Backend(ctx context.Context, p *model.Peer) (*model.Backe
 	return backendLoader.Load(ctx, backendId)()
 }
 
+func (r *peerResolver) Group(ctx context.Context, p *model.Peer) (*model.PeerGroup, error) {
+	if p.Group == nil {
+		return nil, nil
+	}
+
+	groupId, err := p.Group.ID.String(model.IdKindPeerGroup)
+	if err != nil {
+		return nil, err
+	}
+
+	peerGroupLoader, err := handler.PeerGroupLoaderFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return peerGroupLoader.Load(ctx, groupId)()
+}
+
 func (r *peerResolver) Stats(ctx context.Context, p *model.Peer) (*model.PeerStats, error) {
 	if p.Server == nil {
 		return nil, nil
@@ -166,3 +188,20 @@ func (r *peerResolver) DeleteUser(ctx context.Context, p *model.Peer) (*model.Us
 
 	return userLoader.Load(ctx, userId)()
 }
+
+func (r *peerResolver) HookExecutions(ctx context.Context, p *model.Peer, action *string, first *int) ([]*model.HookExecution, error) {
+	peerId, err := p.ID.String(model.IdKindPeer)
+	if err != nil {
+		return nil, err
+	}
+
+	executions, err := r.hookService.FindExecutions(ctx, &hook.FindOptions{
+		PeerId: &peerId,
+		Action: action,
+		Limit:  adapt.Dereference(first),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return adapt.Array(executions, model.ToHookExecution), nil
+}
@@ -68,6 +68,8 @@ func resolveIdKindNodes(ctx context.Context, idKind model.IdKind, ids []*model.I
 		return resolveNodes(ctx, stringIds, handler.ServerLoaderFromContext)
 	case model.IdKindPeer:
 		return resolveNodes(ctx, stringIds, handler.PeerLoaderFromContext)
+	case model.IdKindPeerGroup:
+		return resolveNodes(ctx, stringIds, handler.PeerGroupLoaderFromContext)
 	default:
 		return nil, fmt.Errorf("node type %s is %w", idKind, resolver.ErrNotImplemented)
 	}
@@ -1,10 +1,12 @@
 package query
 
 import (
+	"bytes"
 	"context"
 	"errors"
 	"fmt"
 	"slices"
+	"strings"
 	"sync"
 
 	"github.com/UnAfraid/wg-ui/pkg/api/internal/handler"
@@ -67,6 +69,12 @@ func (r *queryResolver) Node(ctx context.Context, id model.ID) (model.Node, erro
 			return nil, err
 		}
 		return peerLoader.Load(ctx, id.Value)()
+	case model.IdKindPeerGroup:
+		peerGroupLoader, err := handler.PeerGroupLoaderFromContext(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return peerGroupLoader.Load(ctx, id.Value)()
 	case model.IdKindBackend:
 		backendLoader, err := handler.BackendLoaderFromContext(ctx)
 		if err != nil {
@@ -128,6 +136,22 @@ func (r *queryResolver) Users(ctx context.Context, query *string) ([]*model.User
 	return adapt.Array(users, model.ToUser), nil
 }
 
+func (r *queryResolver) UsersConnection(ctx context.Context, first *int, after *string, query *string, sortBy *model.UserSortField, sortDirection *model.SortDirection) (*model.UserConnection, error) {
+	paginationOptions, err := model.ToPaginationOptions(first, after, string(adapt.Dereference(sortBy)), sortDirection)
+	if err != nil {
+		return nil, err
+	}
+
+	users, err := r.userService.FindUsers(ctx, &user.FindOptions{
+		Query:      adapt.Dereference(query),
+		Pagination: paginationOptions,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return model.ToUserConnection(users, paginationOptions), nil
+}
+
 func (r *queryResolver) Servers(ctx context.Context, query *string, enabled *bool) ([]*model.Server, error) {
 	servers, err := r.serverService.FindServers(ctx, &server.FindOptions{
 		Query:   adapt.Dereference(query),
@@ -139,6 +163,28 @@ func (r *queryResolver) Servers(ctx context.Context, query *string, enabled *boo
 	return adapt.Array(servers, model.ToServer), nil
 }
 
+func (r *queryResolver) ServersConnection(ctx context.Context, first *int, after *string, query *string, filter *model.ServerFilter, sortBy *model.ServerSortField, sortDirection *model.SortDirection) (*model.ServerConnection, error) {
+	paginationOptions, err := model.ToPaginationOptions(first, after, string(adapt.Dereference(sortBy)), sortDirection)
+	if err != nil {
+		return nil, err
+	}
+
+	serverFilter, err := model.ServerFilterToFilter(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	servers, err := r.serverService.FindServers(ctx, &server.FindOptions{
+		Query:      adapt.Dereference(query),
+		Filter:     serverFilter,
+		Pagination: paginationOptions,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return model.ToServerConnection(servers, paginationOptions), nil
+}
+
 func (r *queryResolver) Peers(ctx context.Context, query *string) ([]*model.Peer, error) {
 	servers, err := r.peerService.FindPeers(ctx, &peer.FindOptions{
 		Query: adapt.Dereference(query),
@@ -149,6 +195,28 @@ func (r *queryResolver) Peers(ctx context.Context, query *string) ([]*model.Peer
 	return adapt.Array(servers, model.ToPeer), nil
 }
 
+func (r *queryResolver) PeersConnection(ctx context.Context, first *int, after *string, query *string, filter *model.PeerFilter, sortBy *model.PeerSortField, sortDirection *model.SortDirection) (*model.PeerConnection, error) {
+	paginationOptions, err := model.ToPaginationOptions(first, after, string(adapt.Dereference(sortBy)), sortDirection)
+	if err != nil {
+		return nil, err
+	}
+
+	peerFilter, err := model.PeerFilterToFilter(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	peers, err := r.peerService.FindPeers(ctx, &peer.FindOptions{
+		Query:      adapt.Dereference(query),
+		Filter:     peerFilter,
+		Pagination: paginationOptions,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return model.ToPeerConnection(peers, paginationOptions), nil
+}
+
 func (r *queryResolver) AvailableBackends(ctx context.Context) ([]*model.AvailableBackend, error) {
 	registeredTypes, err := r.backendService.RegisteredTypes(ctx)
 	if err != nil {
@@ -158,9 +226,11 @@ func (r *queryResolver) AvailableBackends(ctx context.Context) ([]*model.Availab
 	allTypes := driver.ListTypes()
 	return adapt.Array(allTypes, func(t string) *model.AvailableBackend {
 		return &model.AvailableBackend{
-			Type:       t,
-			Supported:  driver.IsSupported(t),
-			Registered: slices.Contains(registeredTypes, t),
+			Type:         t,
+			Supported:    driver.IsSupported(t),
+			Registered:   slices.Contains(registeredTypes, t),
+			Remote:       driver.IsRemote(t),
+			Capabilities: model.ToBackendCapabilities(driver.GetCapabilities(t)),
 		}
 	}), nil
 }
@@ -172,9 +242,73 @@ func (r *queryResolver) Backends(ctx context.Context, typeArg *string) ([]*model
 	if err != nil {
 		return nil, err
 	}
+
+	slices.SortFunc(backends, func(a, b *backend.Backend) int {
+		return strings.Compare(a.Name, b.Name)
+	})
 	return adapt.Array(backends, model.ToBackend), nil
 }
 
+func (r *queryResolver) BackendsConnection(ctx context.Context, first *int, after *string, query *string, filter *model.BackendFilter, sortBy *model.BackendSortField, sortDirection *model.SortDirection) (*model.BackendConnection, error) {
+	paginationOptions, err := model.ToPaginationOptions(first, after, string(adapt.Dereference(sortBy)), sortDirection)
+	if err != nil {
+		return nil, err
+	}
+
+	backends, err := r.backendService.FindBackends(ctx, &backend.FindOptions{
+		Query:      adapt.Dereference(query),
+		Filter:     model.BackendFilterToFilter(filter),
+		Pagination: paginationOptions,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return model.ToBackendConnection(backends, paginationOptions), nil
+}
+
+func (r *queryResolver) PeerGroups(ctx context.Context, query *string) ([]*model.PeerGroup, error) {
+	groups, err := r.peerService.FindGroups(ctx, &peer.GroupFindOptions{
+		Query: adapt.Dereference(query),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return adapt.Array(groups, model.ToPeerGroup), nil
+}
+
+func (r *queryResolver) ExportPeers(ctx context.Context, serverID model.ID, format model.PeerFileFormat) (*model.PeerExport, error) {
+	serverId, err := serverID.String(model.IdKindServer)
+	if err != nil {
+		return nil, err
+	}
+
+	srv, err := r.serverService.FindServer(ctx, &server.FindOneOptions{
+		IdOption: &server.IdOption{
+			Id: serverId,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if srv == nil {
+		return nil, server.ErrServerNotFound
+	}
+
+	peers, err := r.peerService.FindPeers(ctx, &peer.FindOptions{
+		ServerId: &srv.Id,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var buffer bytes.Buffer
+	if err := peer.EncodeFile(peer.FileFormat(format), &buffer, peers); err != nil {
+		return nil, err
+	}
+
+	return model.ToPeerExport(srv.Name, peer.FileFormat(format), buffer.Bytes()), nil
+}
+
 func (r *queryResolver) ForeignServers(ctx context.Context) ([]*model.ForeignServer, error) {
 	foreignServers, err := r.manageService.ForeignServersAll(ctx)
 	if err != nil {
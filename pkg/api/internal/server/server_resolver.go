@@ -7,19 +7,23 @@ import (
 	"github.com/UnAfraid/wg-ui/pkg/api/internal/handler"
 	"github.com/UnAfraid/wg-ui/pkg/api/internal/model"
 	"github.com/UnAfraid/wg-ui/pkg/api/internal/resolver"
+	"github.com/UnAfraid/wg-ui/pkg/hook"
 	"github.com/UnAfraid/wg-ui/pkg/internal/adapt"
 	"github.com/UnAfraid/wg-ui/pkg/peer"
 )
 
 type serverResolver struct {
 	peerService peer.Service
+	hookService hook.Service
 }
 
 func NewServerResolver(
 	peerService peer.Service,
+	hookService hook.Service,
 ) resolver.ServerResolver {
 	return &serverResolver{
 		peerService: peerService,
+		hookService: hookService,
 	}
 }
 
@@ -38,6 +42,24 @@ func (r *serverResolver) Peers(ctx context.Context, svc *model.Server) ([]*model
 	return adapt.Array(peers, model.ToPeer), nil
 }
 
+func (r *serverResolver) HookExecutions(ctx context.Context, srv *model.Server, action *string, first *int) ([]*model.HookExecution, error) {
+	serverId, err := srv.ID.String(model.IdKindServer)
+	if err != nil {
+		return nil, err
+	}
+
+	executions, err := r.hookService.FindExecutions(ctx, &hook.FindOptions{
+		ServerId: &serverId,
+		PeerId:   adapt.ToPointer(""),
+		Action:   action,
+		Limit:    adapt.Dereference(first),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return adapt.Array(executions, model.ToHookExecution), nil
+}
+
 func (r *serverResolver) Backend(ctx context.Context, srv *model.Server) (*model.Backend, error) {
 	if srv.Backend == nil {
 		return nil, errors.New("server has no backend")
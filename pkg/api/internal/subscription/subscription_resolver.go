@@ -2,22 +2,29 @@ package api
 
 import (
 	"context"
+	"path"
+
+	"github.com/sirupsen/logrus"
 
 	"github.com/UnAfraid/wg-ui/pkg/api/internal/model"
 	"github.com/UnAfraid/wg-ui/pkg/api/internal/resolver"
 	"github.com/UnAfraid/wg-ui/pkg/backend"
+	"github.com/UnAfraid/wg-ui/pkg/internal/adapt"
 	"github.com/UnAfraid/wg-ui/pkg/peer"
 	"github.com/UnAfraid/wg-ui/pkg/server"
+	"github.com/UnAfraid/wg-ui/pkg/subscription"
 	"github.com/UnAfraid/wg-ui/pkg/user"
 )
 
-const totalSubscriptionNodeSources = 3
+// nodeSubscriptionPattern matches the channels of every node, the backend ones are not part of NodeChangedEvent.
+var nodeSubscriptionPattern = path.Join("node", "*", "*")
 
 type subscriptionResolver struct {
 	userService    user.Service
 	serverService  server.Service
 	peerService    peer.Service
 	backendService backend.Service
+	subscription   subscription.Subscription
 }
 
 func NewSubscriptionResolver(
@@ -25,124 +32,183 @@ func NewSubscriptionResolver(
 	serverService server.Service,
 	peerService peer.Service,
 	backendService backend.Service,
+	subscription subscription.Subscription,
 ) resolver.SubscriptionResolver {
 	return &subscriptionResolver{
 		userService:    userService,
 		serverService:  serverService,
 		peerService:    peerService,
 		backendService: backendService,
+		subscription:   subscription,
 	}
 }
 
-func (r *subscriptionResolver) BackendChanged(ctx context.Context) (<-chan *model.BackendChangedEvent, error) {
-	return domainEventToApiEvent[*backend.ChangedEvent, *model.BackendChangedEvent](ctx, r.backendService, func(event *backend.ChangedEvent) *model.BackendChangedEvent {
+func (r *subscriptionResolver) BackendChanged(ctx context.Context, since *subscription.Cursor) (<-chan *model.BackendChangedEvent, error) {
+	return domainEventToApiEvent[*backend.ChangedEvent, *model.BackendChangedEvent](ctx, since, r.backendService, func(event *backend.ChangedEvent) *model.BackendChangedEvent {
 		return &model.BackendChangedEvent{
 			Node:   model.ToBackend(event.Backend),
 			Action: event.Action,
+			Cursor: event.Cursor,
 		}
 	})
 }
 
-func (r *subscriptionResolver) UserChanged(ctx context.Context) (<-chan *model.UserChangedEvent, error) {
-	return domainEventToApiEvent[*user.ChangedEvent, *model.UserChangedEvent](ctx, r.userService, func(event *user.ChangedEvent) *model.UserChangedEvent {
+func (r *subscriptionResolver) BackendHealthChanged(ctx context.Context) (<-chan *model.BackendHealthChangedEvent, error) {
+	healthEvents, err := r.backendService.SubscribeHealth(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	apiEvents := make(chan *model.BackendHealthChangedEvent)
+	go func() {
+		defer close(apiEvents)
+
+		for event := range healthEvents {
+			apiEvents <- &model.BackendHealthChangedEvent{
+				Node:   model.ToBackend(event.Backend),
+				Health: model.ToBackendHealth(adapt.Dereference(event.Health)),
+			}
+		}
+	}()
+
+	return apiEvents, nil
+}
+
+func (r *subscriptionResolver) UserChanged(ctx context.Context, since *subscription.Cursor) (<-chan *model.UserChangedEvent, error) {
+	return domainEventToApiEvent[*user.ChangedEvent, *model.UserChangedEvent](ctx, since, r.userService, func(event *user.ChangedEvent) *model.UserChangedEvent {
 		return &model.UserChangedEvent{
 			Node:   model.ToUser(event.User),
 			Action: event.Action,
+			Cursor: event.Cursor,
 		}
 	})
 }
 
-func (r *subscriptionResolver) ServerChanged(ctx context.Context) (<-chan *model.ServerChangedEvent, error) {
-	return domainEventToApiEvent[*server.ChangedEvent, *model.ServerChangedEvent](ctx, r.serverService, func(event *server.ChangedEvent) *model.ServerChangedEvent {
+func (r *subscriptionResolver) ServerChanged(ctx context.Context, since *subscription.Cursor) (<-chan *model.ServerChangedEvent, error) {
+	return domainEventToApiEvent[*server.ChangedEvent, *model.ServerChangedEvent](ctx, since, r.serverService, func(event *server.ChangedEvent) *model.ServerChangedEvent {
 		return &model.ServerChangedEvent{
 			Node:   model.ToServer(event.Server),
 			Action: event.Action,
+			Cursor: event.Cursor,
 		}
 	})
 }
 
-func (r *subscriptionResolver) PeerChanged(ctx context.Context) (<-chan *model.PeerChangedEvent, error) {
-	return domainEventToApiEvent[*peer.ChangedEvent, *model.PeerChangedEvent](ctx, r.peerService, func(event *peer.ChangedEvent) *model.PeerChangedEvent {
+func (r *subscriptionResolver) ServerDriftDetected(ctx context.Context, since *subscription.Cursor) (<-chan *model.ServerChangedEvent, error) {
+	serverEvents, err := r.serverService.Subscribe(ctx, since)
+	if err != nil {
+		return nil, model.CursorError(err)
+	}
+
+	apiEvents := make(chan *model.ServerChangedEvent)
+	go func() {
+		defer close(apiEvents)
+
+		for event := range serverEvents {
+			if event.Action != server.ChangedActionDriftDetected {
+				continue
+			}
+
+			apiEvents <- &model.ServerChangedEvent{
+				Node:   model.ToServer(event.Server),
+				Action: event.Action,
+				Cursor: event.Cursor,
+			}
+		}
+	}()
+
+	return apiEvents, nil
+}
+
+func (r *subscriptionResolver) PeerChanged(ctx context.Context, since *subscription.Cursor) (<-chan *model.PeerChangedEvent, error) {
+	return domainEventToApiEvent[*peer.ChangedEvent, *model.PeerChangedEvent](ctx, since, r.peerService, func(event *peer.ChangedEvent) *model.PeerChangedEvent {
 		return &model.PeerChangedEvent{
 			Node:   model.ToPeer(event.Peer),
 			Action: event.Action,
+			Cursor: event.Cursor,
 		}
 	})
 }
 
-func (r *subscriptionResolver) NodeChanged(ctx context.Context) (<-chan model.NodeChangedEvent, error) {
-	userEvents, err := r.userService.Subscribe(ctx)
+// NodeChanged subscribes to the channels of all the nodes at once, so the events are delivered in the order of the
+// journal and the cursor of any of them resumes after the events of every node delivered before it.
+func (r *subscriptionResolver) NodeChanged(ctx context.Context, since *subscription.Cursor) (<-chan model.NodeChangedEvent, error) {
+	events, err := r.subscription.Subscribe(ctx, nodeSubscriptionPattern, since)
 	if err != nil {
-		return nil, err
-	}
-
-	serverEvents, err := r.serverService.Subscribe(ctx)
-	if err != nil {
-		return nil, err
-	}
-
-	peerEvents, err := r.peerService.Subscribe(ctx)
-	if err != nil {
-		return nil, err
+		return nil, model.CursorError(err)
 	}
 
 	nodeChangedEvents := make(chan model.NodeChangedEvent)
 	go func() {
 		defer close(nodeChangedEvents)
 
-		sourcesAvailable := totalSubscriptionNodeSources
-		for {
-			select {
-			case userEvent := <-userEvents:
-				if userEvent == nil {
-					sourcesAvailable--
-				} else {
-					nodeChangedEvents <- model.UserChangedEvent{
-						Node:   model.ToUser(userEvent.User),
-						Action: userEvent.Action,
-					}
-				}
-			case serverEvent := <-serverEvents:
-				if serverEvent == nil {
-					sourcesAvailable--
-				} else {
-					nodeChangedEvents <- model.ServerChangedEvent{
-						Node:   model.ToServer(serverEvent.Server),
-						Action: serverEvent.Action,
-					}
-				}
-			case peerEvent := <-peerEvents:
-				if peerEvent == nil {
-					sourcesAvailable--
-				} else {
-					nodeChangedEvents <- model.PeerChangedEvent{
-						Node:   model.ToPeer(peerEvent.Peer),
-						Action: peerEvent.Action,
-					}
-				}
-			}
-
-			if sourcesAvailable <= 0 {
+		for event := range events {
+			nodeChangedEvent, err := toNodeChangedEvent(event)
+			if err != nil {
+				logrus.WithError(err).Warn("failed to decode node changed event")
 				return
 			}
+			if nodeChangedEvent != nil {
+				nodeChangedEvents <- nodeChangedEvent
+			}
 		}
 	}()
 
 	return nodeChangedEvents, nil
 }
 
+func toNodeChangedEvent(event *subscription.Event) (model.NodeChangedEvent, error) {
+	userEvent, ok, err := user.DecodeChangedEvent(event)
+	if err != nil {
+		return nil, err
+	}
+	if ok {
+		return model.UserChangedEvent{
+			Node:   model.ToUser(userEvent.User),
+			Action: userEvent.Action,
+			Cursor: userEvent.Cursor,
+		}, nil
+	}
+
+	serverEvent, ok, err := server.DecodeChangedEvent(event)
+	if err != nil {
+		return nil, err
+	}
+	if ok {
+		return model.ServerChangedEvent{
+			Node:   model.ToServer(serverEvent.Server),
+			Action: serverEvent.Action,
+			Cursor: serverEvent.Cursor,
+		}, nil
+	}
+
+	peerEvent, ok, err := peer.DecodeChangedEvent(event)
+	if err != nil {
+		return nil, err
+	}
+	if ok {
+		return model.PeerChangedEvent{
+			Node:   model.ToPeer(peerEvent.Peer),
+			Action: peerEvent.Action,
+			Cursor: peerEvent.Cursor,
+		}, nil
+	}
+	return nil, nil
+}
+
 type Subscribe[T any] interface {
-	Subscribe(ctx context.Context) (<-chan T, error)
+	Subscribe(ctx context.Context, since *subscription.Cursor) (<-chan T, error)
 }
 
 func domainEventToApiEvent[FromType, ToType any](
 	ctx context.Context,
+	since *subscription.Cursor,
 	subscribe Subscribe[FromType],
 	fromToAdaptFn func(FromType) ToType,
 ) (<-chan ToType, error) {
-	domainEvents, err := subscribe.Subscribe(ctx)
+	domainEvents, err := subscribe.Subscribe(ctx, since)
 	if err != nil {
-		return nil, err
+		return nil, model.CursorError(err)
 	}
 
 	apiEvents := make(chan ToType)
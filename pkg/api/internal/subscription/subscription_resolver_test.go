@@ -0,0 +1,80 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/UnAfraid/wg-ui/pkg/api/internal/model"
+	"github.com/UnAfraid/wg-ui/pkg/backend"
+	"github.com/UnAfraid/wg-ui/pkg/peer"
+	"github.com/UnAfraid/wg-ui/pkg/server"
+	"github.com/UnAfraid/wg-ui/pkg/subscription"
+	"github.com/UnAfraid/wg-ui/pkg/user"
+)
+
+func notify(t *testing.T, s subscription.Subscription, channel string, event any) {
+	t.Helper()
+
+	bytes, err := json.Marshal(event)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Notify(bytes, channel); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func receiveNodeChangedEvent(t *testing.T, events <-chan model.NodeChangedEvent) model.NodeChangedEvent {
+	t.Helper()
+
+	select {
+	case event := <-events:
+		return event
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for node changed event")
+		return nil
+	}
+}
+
+func TestNodeChangedResumesAllNodesFromOneCursor(t *testing.T) {
+	s, err := subscription.NewSubscription(subscription.Options{}, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		_ = s.Close()
+	})
+	r := NewSubscriptionResolver(nil, nil, nil, nil, s)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	live, err := r.NodeChanged(ctx, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	notify(t, s, path.Join("node", "User", "user-id"), user.ChangedEvent{Action: user.ChangedActionUpdated, User: &user.User{Id: "user-id"}})
+	notify(t, s, path.Join("node", "Peer", "peer-id"), peer.ChangedEvent{Action: peer.ChangedActionCreated, Peer: &peer.Peer{Id: "peer-id"}})
+	notify(t, s, path.Join("node", "Backend", "backend-id"), backend.ChangedEvent{Action: backend.ChangedActionUpdated, Backend: &backend.Backend{Id: "backend-id"}})
+	notify(t, s, path.Join("node", "Server", "server-id"), server.ChangedEvent{Action: server.ChangedActionDeleted, Server: &server.Server{Id: "server-id"}})
+
+	first, ok := receiveNodeChangedEvent(t, live).(model.UserChangedEvent)
+	if !ok {
+		t.Fatalf("expected the user event first")
+	}
+
+	resumed, err := r.NodeChanged(ctx, &first.Cursor)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if event, ok := receiveNodeChangedEvent(t, resumed).(model.PeerChangedEvent); !ok || event.Action != peer.ChangedActionCreated {
+		t.Fatalf("expected the peer event after the cursor, got %+v", event)
+	}
+	if event, ok := receiveNodeChangedEvent(t, resumed).(model.ServerChangedEvent); !ok || event.Action != server.ChangedActionDeleted {
+		t.Fatalf("expected the server event after the peer one, got %+v", event)
+	}
+}
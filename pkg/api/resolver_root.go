@@ -11,6 +11,7 @@ type resolverRoot struct {
 	userResolver          resolver.UserResolver
 	serverResolver        resolver.ServerResolver
 	peerResolver          resolver.PeerResolver
+	peerGroupResolver     resolver.PeerGroupResolver
 	backendResolver       resolver.BackendResolver
 	foreignServerResolver resolver.ForeignServerResolver
 }
@@ -39,6 +40,10 @@ func (r *resolverRoot) Peer() resolver.PeerResolver {
 	return r.peerResolver
 }
 
+func (r *resolverRoot) PeerGroup() resolver.PeerGroupResolver {
+	return r.peerGroupResolver
+}
+
 func (r *resolverRoot) Backend() resolver.BackendResolver {
 	return r.backendResolver
 }
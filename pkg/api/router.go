@@ -23,9 +23,11 @@ import (
 	"github.com/UnAfraid/wg-ui/pkg/auth"
 	"github.com/UnAfraid/wg-ui/pkg/backend"
 	"github.com/UnAfraid/wg-ui/pkg/config"
+	"github.com/UnAfraid/wg-ui/pkg/hook"
 	"github.com/UnAfraid/wg-ui/pkg/manage"
 	"github.com/UnAfraid/wg-ui/pkg/peer"
 	"github.com/UnAfraid/wg-ui/pkg/server"
+	"github.com/UnAfraid/wg-ui/pkg/subscription"
 	"github.com/UnAfraid/wg-ui/pkg/user"
 	"github.com/UnAfraid/wg-ui/www"
 )
@@ -44,6 +46,8 @@ func NewRouter(
 	peerService peer.Service,
 	backendService backend.Service,
 	manageService manage.Service,
+	hookService hook.Service,
+	subscription subscription.Subscription,
 ) http.Handler {
 	corsMiddleware := cors.New(cors.Options{
 		AllowedOrigins:      conf.CorsAllowedOrigins,
@@ -61,6 +65,8 @@ func NewRouter(
 		peerService,
 		backendService,
 		manageService,
+		hookService,
+		subscription,
 	)
 
 	authHandler := handler.NewAuthenticationMiddleware(authService, userService)
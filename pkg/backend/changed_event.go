@@ -1,5 +1,9 @@
 package backend
 
+import (
+	"github.com/UnAfraid/wg-ui/pkg/subscription"
+)
+
 const (
 	ChangedActionCreated  = "CREATED"
 	ChangedActionUpdated  = "UPDATED"
@@ -11,4 +15,6 @@ const (
 type ChangedEvent struct {
 	Action  string   `json:"action"`
 	Backend *Backend `json:"backend"`
+	// Cursor of the event in the journal, it is not part of the notification.
+	Cursor subscription.Cursor `json:"-"`
 }
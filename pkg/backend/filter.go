@@ -0,0 +1,23 @@
+package backend
+
+import (
+	"slices"
+)
+
+// Filter narrows down the backends selected by FindOptions, every set field must match.
+type Filter struct {
+	Types   []string
+	Enabled *bool
+}
+
+func (f *Filter) Matches(b *Backend) bool {
+	if len(f.Types) != 0 && !slices.Contains(f.Types, b.Type()) {
+		return false
+	}
+
+	if f.Enabled != nil && b.Enabled != *f.Enabled {
+		return false
+	}
+
+	return true
+}
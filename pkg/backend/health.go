@@ -0,0 +1,32 @@
+package backend
+
+import "time"
+
+type HealthStatus string
+
+const (
+	// HealthStatusUnknown is the status of backends that have not been used yet.
+	HealthStatusUnknown HealthStatus = "UNKNOWN"
+	// HealthStatusHealthy is the status of backends whose last call succeeded.
+	HealthStatusHealthy HealthStatus = "HEALTHY"
+	// HealthStatusDegraded is the status of backends that failed, but not often enough to be short-circuited.
+	HealthStatusDegraded HealthStatus = "DEGRADED"
+	// HealthStatusUnhealthy is the status of backends whose calls are short-circuited until they reconnect.
+	HealthStatusUnhealthy HealthStatus = "UNHEALTHY"
+)
+
+// Health is the runtime health of a backend connection, it is tracked in memory and never stored.
+type Health struct {
+	Status              HealthStatus  `json:"status"`
+	LastSuccessAt       *time.Time    `json:"lastSuccessAt"`
+	LastFailureAt       *time.Time    `json:"lastFailureAt"`
+	LastError           string        `json:"lastError"`
+	Latency             time.Duration `json:"latency"`
+	ConsecutiveFailures int           `json:"consecutiveFailures"`
+	NextRetryAt         *time.Time    `json:"nextRetryAt"`
+}
+
+type HealthChangedEvent struct {
+	Backend *Backend `json:"backend"`
+	Health  *Health  `json:"health"`
+}
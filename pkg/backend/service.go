@@ -18,7 +18,8 @@ import (
 )
 
 var (
-	subscriptionPath = path.Join("node", "Backend")
+	subscriptionPath       = path.Join("node", "Backend")
+	healthSubscriptionPath = path.Join("health", "Backend")
 )
 
 type Service interface {
@@ -28,8 +29,12 @@ type Service interface {
 	UpdateBackend(ctx context.Context, backendId string, options *UpdateOptions, fieldMask *UpdateFieldMask, userId string) (*Backend, error)
 	DeleteBackend(ctx context.Context, backendId string, userId string) (*Backend, error)
 	RegisteredTypes(ctx context.Context) ([]string, error)
-	Subscribe(ctx context.Context) (<-chan *ChangedEvent, error)
+	// Subscribe delivers the changed events, when since is set the journaled events following it are delivered first.
+	Subscribe(ctx context.Context, since *subscription.Cursor) (<-chan *ChangedEvent, error)
 	HasSubscribers() bool
+	// NotifyHealthChanged publishes the health of the backend, it is a no-op for backends that no longer exist.
+	NotifyHealthChanged(ctx context.Context, backendId string, health *Health) error
+	SubscribeHealth(ctx context.Context) (<-chan *HealthChangedEvent, error)
 }
 
 // ServerCounter checks if a backend has servers (to avoid circular dependency with server package)
@@ -62,6 +67,9 @@ func (s *service) FindBackend(ctx context.Context, options *FindOneOptions) (*Ba
 }
 
 func (s *service) FindBackends(ctx context.Context, options *FindOptions) ([]*Backend, error) {
+	if err := options.Validate(); err != nil {
+		return nil, err
+	}
 	return s.backendRepository.FindAll(ctx, options)
 }
 
@@ -87,8 +95,8 @@ func (s *service) CreateBackend(ctx context.Context, options *CreateOptions, use
 		return nil, err
 	}
 
-	// Validate that no backend of this type already exists
-	if err := s.validateBackendType(ctx, backend.Type()); err != nil {
+	// Validate that no backend of this type, or for remote types of this target, already exists
+	if err := s.validateBackendType(ctx, backend, ""); err != nil {
 		return nil, err
 	}
 
@@ -139,6 +147,9 @@ func (s *service) UpdateBackend(ctx context.Context, backendId string, options *
 			if newType != originalType {
 				return nil, ErrBackendTypeChangeNotAllowed
 			}
+			if err := s.validateBackendType(ctx, backend, backend.Id); err != nil {
+				return nil, err
+			}
 		}
 
 		// Prevent disabling a backend that has enabled servers
@@ -233,15 +244,41 @@ func (s *service) validateBackendName(ctx context.Context, name string) error {
 	return nil
 }
 
-func (s *service) validateBackendType(ctx context.Context, backendType string) error {
+// validateBackendType allows a single backend of a local type, backends of remote types must target different hosts.
+func (s *service) validateBackendType(ctx context.Context, backend *Backend, excludeBackendId string) error {
+	backendType := backend.Type()
 	backends, err := s.backendRepository.FindAll(ctx, &FindOptions{
 		Type: &backendType,
 	})
 	if err != nil {
 		return fmt.Errorf("failed to find existing backends by type: %s - %w", backendType, err)
 	}
-	if len(backends) > 0 {
-		return ErrBackendTypeAlreadyExists
+
+	if !driver.IsRemote(backendType) {
+		for _, existingBackend := range backends {
+			if existingBackend.Id != excludeBackendId {
+				return ErrBackendTypeAlreadyExists
+			}
+		}
+		return nil
+	}
+
+	target, err := NormalizeTarget(backend.Url)
+	if err != nil {
+		return err
+	}
+	for _, existingBackend := range backends {
+		if existingBackend.Id == excludeBackendId {
+			continue
+		}
+
+		existingTarget, err := NormalizeTarget(existingBackend.Url)
+		if err != nil {
+			continue
+		}
+		if existingTarget == target {
+			return fmt.Errorf("%w: %s is used by backend %s", ErrBackendTargetAlreadyExists, target, existingBackend.Name)
+		}
 	}
 	return nil
 }
@@ -364,8 +401,8 @@ func (s *service) notify(action string, backend *Backend) error {
 	return nil
 }
 
-func (s *service) Subscribe(ctx context.Context) (<-chan *ChangedEvent, error) {
-	bytesChannel, err := s.subscription.Subscribe(ctx, path.Join(subscriptionPath, "*"))
+func (s *service) Subscribe(ctx context.Context, since *subscription.Cursor) (<-chan *ChangedEvent, error) {
+	events, err := s.subscription.Subscribe(ctx, path.Join(subscriptionPath, "*"), since)
 	if err != nil {
 		return nil, err
 	}
@@ -374,12 +411,13 @@ func (s *service) Subscribe(ctx context.Context) (<-chan *ChangedEvent, error) {
 	go func() {
 		defer close(observerChan)
 
-		for bytes := range bytesChannel {
+		for event := range events {
 			var changedEvent *ChangedEvent
-			if err := json.Unmarshal(bytes, &changedEvent); err != nil {
+			if err := json.Unmarshal(event.Bytes, &changedEvent); err != nil {
 				logrus.WithError(err).Warn("failed to decode backend changed event")
 				continue
 			}
+			changedEvent.Cursor = event.Cursor()
 			observerChan <- changedEvent
 		}
 	}()
@@ -390,3 +428,51 @@ func (s *service) Subscribe(ctx context.Context) (<-chan *ChangedEvent, error) {
 func (s *service) HasSubscribers() bool {
 	return s.subscription.HasSubscribers(path.Join(subscriptionPath, "*"))
 }
+
+func (s *service) NotifyHealthChanged(ctx context.Context, backendId string, health *Health) error {
+	backend, err := s.backendRepository.FindOne(ctx, &FindOneOptions{
+		IdOption: &IdOption{
+			Id: backendId,
+		},
+	})
+	if err != nil {
+		return err
+	}
+	if backend == nil {
+		return nil
+	}
+
+	bytes, err := json.Marshal(HealthChangedEvent{Backend: backend, Health: health})
+	if err != nil {
+		return err
+	}
+
+	// health flaps are not worth replaying, subscribers read the current health when they resume
+	if err := s.subscription.NotifyEphemeral(bytes, path.Join(healthSubscriptionPath, backend.Id)); err != nil {
+		return fmt.Errorf("failed to notify backend health changed event: %w", err)
+	}
+	return nil
+}
+
+func (s *service) SubscribeHealth(ctx context.Context) (<-chan *HealthChangedEvent, error) {
+	events, err := s.subscription.Subscribe(ctx, path.Join(healthSubscriptionPath, "*"), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	observerChan := make(chan *HealthChangedEvent)
+	go func() {
+		defer close(observerChan)
+
+		for event := range events {
+			var healthChangedEvent *HealthChangedEvent
+			if err := json.Unmarshal(event.Bytes, &healthChangedEvent); err != nil {
+				logrus.WithError(err).Warn("failed to decode backend health changed event")
+				continue
+			}
+			observerChan <- healthChangedEvent
+		}
+	}()
+
+	return observerChan, nil
+}
@@ -0,0 +1,26 @@
+package backend
+
+import (
+	"github.com/UnAfraid/wg-ui/pkg/pagination"
+)
+
+const (
+	SortFieldName      = "NAME"
+	SortFieldCreatedAt = "CREATED_AT"
+)
+
+func (b *Backend) Cursor(sortField string) pagination.Cursor {
+	var key string
+	switch sortField {
+	case SortFieldCreatedAt:
+		key = pagination.TimeKey(b.CreatedAt)
+	default:
+		key = pagination.StringKey(b.Name)
+	}
+
+	return pagination.Cursor{
+		Field: sortField,
+		Key:   key,
+		Id:    b.Id,
+	}
+}
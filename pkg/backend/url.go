@@ -3,8 +3,11 @@ package backend
 import (
 	"errors"
 	"fmt"
+	"net"
 	"net/url"
 	"strings"
+
+	"github.com/UnAfraid/wg-ui/pkg/wireguard/driver"
 )
 
 const redactedURLPassword = "***"
@@ -59,6 +62,28 @@ func ParseURL(rawURL string) (*ParsedURL, error) {
 	return result, nil
 }
 
+// NormalizeTarget returns the host a remote backend url addresses in the form type://host:port,
+// urls that differ only in credentials, path, options or the spelling of the host target the same host.
+func NormalizeTarget(rawURL string) (string, error) {
+	parsed, err := ParseURL(rawURL)
+	if err != nil {
+		return "", err
+	}
+
+	host := strings.TrimSuffix(strings.ToLower(strings.TrimSpace(parsed.Host)), ".")
+	port := strings.TrimSpace(parsed.Port)
+	if port == "" {
+		if registration, ok := driver.Get(parsed.Type); ok {
+			port = registration.DefaultPort
+		}
+	}
+
+	if port == "" {
+		return parsed.Type + "://" + host, nil
+	}
+	return parsed.Type + "://" + net.JoinHostPort(host, port), nil
+}
+
 // RedactURLPassword replaces URL password with a redaction marker.
 func RedactURLPassword(rawURL string) string {
 	parsed, err := url.Parse(rawURL)
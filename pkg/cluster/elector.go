@@ -0,0 +1,259 @@
+package cluster
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// ErrNotLeader is returned by Fence when the node does not hold the current lease.
+var ErrNotLeader = errors.New("node is not the cluster leader")
+
+type ElectorOptions struct {
+	// NodeId identifies the node in the lease, it must be unique in the cluster.
+	NodeId string
+	// Address is the url other nodes forward requests to while this node is the leader.
+	Address string
+	// LeaseDuration is how long a lease stays valid without being renewed.
+	LeaseDuration time.Duration
+	// RenewInterval is how often the leader renews its lease and followers check for an expired one.
+	RenewInterval time.Duration
+}
+
+// Elector elects a single leader among the nodes sharing a LeaseStore. Leases are only replaced with a
+// compare-and-swap on their generation, so of the nodes claiming an expired lease at the same time only one wins.
+// A node that lost its lease does not try to become leader again, it is expected to shut down and start over
+// as a follower.
+type Elector struct {
+	store         LeaseStore
+	nodeId        string
+	address       string
+	leaseDuration time.Duration
+	renewInterval time.Duration
+	now           func() time.Time
+
+	mu         sync.RWMutex
+	leader     *Lease
+	isLeader   bool
+	released   bool
+	generation uint64
+	expiresAt  time.Time
+	elected    chan struct{}
+	lost       chan struct{}
+	lostOnce   sync.Once
+}
+
+func NewElector(store LeaseStore, options ElectorOptions) *Elector {
+	return &Elector{
+		store:         store,
+		nodeId:        options.NodeId,
+		address:       options.Address,
+		leaseDuration: options.LeaseDuration,
+		renewInterval: options.RenewInterval,
+		now:           time.Now,
+		elected:       make(chan struct{}),
+		lost:          make(chan struct{}),
+	}
+}
+
+// Run takes part in the election until the context is done or the leadership is lost.
+func (e *Elector) Run(ctx context.Context) {
+	ticker := time.NewTicker(e.renewInterval)
+	defer ticker.Stop()
+
+	for {
+		if err := e.step(ctx); err != nil {
+			logrus.
+				WithError(err).
+				WithField("nodeId", e.nodeId).
+				Warn("failed to update cluster lease")
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-e.lost:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Elected is closed once the node becomes the leader.
+func (e *Elector) Elected() <-chan struct{} {
+	return e.elected
+}
+
+// Lost is closed when the leader could not renew its lease before it expired or another node took it over.
+func (e *Elector) Lost() <-chan struct{} {
+	return e.lost
+}
+
+// Leader returns the last lease read from the store, nil when no node holds a valid lease.
+func (e *Elector) Leader() *Lease {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.leader
+}
+
+func (e *Elector) IsLeader() bool {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.isLeader
+}
+
+// Fence returns ErrNotLeader unless the stored lease is still the unexpired lease this node was elected with.
+// It is checked before every device change, so a node that was replaced but did not notice yet does not
+// change the devices of the new leader.
+func (e *Elector) Fence(ctx context.Context) error {
+	e.mu.RLock()
+	isLeader := e.isLeader
+	generation := e.generation
+	e.mu.RUnlock()
+
+	if !isLeader {
+		return ErrNotLeader
+	}
+
+	lease, err := e.store.Read(ctx)
+	if err != nil {
+		return err
+	}
+	if lease == nil || lease.NodeId != e.nodeId || lease.Generation != generation || lease.Expired(e.now()) {
+		return ErrNotLeader
+	}
+	return nil
+}
+
+// Release expires the lease of the leader, so a follower takes over without waiting for the lease to expire.
+// The elector stops taking part in the election afterward.
+func (e *Elector) Release(ctx context.Context) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.released = true
+	if !e.isLeader {
+		return nil
+	}
+	e.isLeader = false
+
+	lease := e.newLease(e.now(), e.generation)
+	lease.ExpiresAt = e.now()
+	_, err := e.store.CompareAndSwap(ctx, e.generation, lease)
+	return err
+}
+
+func (e *Elector) step(ctx context.Context) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.released {
+		return nil
+	}
+
+	now := e.now()
+	lease, err := e.store.Read(ctx)
+	if err != nil {
+		e.checkExpired(now)
+		return err
+	}
+
+	switch {
+	case e.isLeader:
+		if lease == nil || lease.NodeId != e.nodeId || lease.Generation != e.generation {
+			e.leader = lease
+			e.loseLeadership("another node took over the cluster lease")
+			return nil
+		}
+		return e.renew(ctx, now)
+	case lease != nil && !lease.Expired(now):
+		e.leader = lease
+		return nil
+	default:
+		return e.acquire(ctx, now, lease)
+	}
+}
+
+func (e *Elector) renew(ctx context.Context, now time.Time) error {
+	lease := e.newLease(now, e.generation)
+	swapped, err := e.store.CompareAndSwap(ctx, e.generation, lease)
+	if err != nil {
+		e.checkExpired(now)
+		return err
+	}
+	if !swapped {
+		e.loseLeadership("another node took over the cluster lease")
+		return nil
+	}
+	e.leader = lease
+	e.expiresAt = lease.ExpiresAt
+	return nil
+}
+
+// acquire claims the expired or missing lease, the swap fails when another node claimed it first.
+func (e *Elector) acquire(ctx context.Context, now time.Time, expired *Lease) error {
+	e.leader = nil
+
+	var generation uint64
+	if expired != nil {
+		generation = expired.Generation
+	}
+
+	lease := e.newLease(now, generation+1)
+	swapped, err := e.store.CompareAndSwap(ctx, generation, lease)
+	if err != nil {
+		return err
+	}
+	if !swapped {
+		current, err := e.store.Read(ctx)
+		if err != nil {
+			return err
+		}
+		e.leader = current
+		return nil
+	}
+
+	e.leader = lease
+	e.generation = lease.Generation
+	e.expiresAt = lease.ExpiresAt
+	e.isLeader = true
+	close(e.elected)
+
+	logrus.
+		WithField("nodeId", e.nodeId).
+		WithField("generation", lease.Generation).
+		Info("elected as cluster leader")
+	return nil
+}
+
+// checkExpired gives up the leadership when the lease could not be renewed before it expired,
+// another node may have taken over meanwhile.
+func (e *Elector) checkExpired(now time.Time) {
+	if e.isLeader && !now.Before(e.expiresAt) {
+		e.loseLeadership("the cluster lease could not be renewed before it expired")
+	}
+}
+
+func (e *Elector) loseLeadership(reason string) {
+	e.isLeader = false
+	e.lostOnce.Do(func() {
+		close(e.lost)
+	})
+
+	logrus.
+		WithField("nodeId", e.nodeId).
+		WithField("reason", reason).
+		Warn("lost cluster leadership")
+}
+
+func (e *Elector) newLease(now time.Time, generation uint64) *Lease {
+	return &Lease{
+		NodeId:     e.nodeId,
+		Address:    e.address,
+		Generation: generation,
+		ExpiresAt:  now.Add(e.leaseDuration),
+	}
+}
@@ -0,0 +1,290 @@
+package cluster
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+type testNode struct {
+	*Elector
+	clock *time.Time
+}
+
+func newTestNodes(t *testing.T, count int) []*testNode {
+	t.Helper()
+
+	store := NewLeaseFile(filepath.Join(t.TempDir(), "lease.json"))
+	clock := time.Now()
+
+	nodes := make([]*testNode, count)
+	for i := range nodes {
+		elector := NewElector(store, ElectorOptions{
+			NodeId:        fmt.Sprintf("node-%d", i+1),
+			Address:       fmt.Sprintf("http://node-%d:4580", i+1),
+			LeaseDuration: 15 * time.Second,
+			RenewInterval: 5 * time.Second,
+		})
+		elector.now = func() time.Time { return clock }
+		nodes[i] = &testNode{Elector: elector, clock: &clock}
+	}
+	return nodes
+}
+
+func (n *testNode) advance(d time.Duration) {
+	*n.clock = n.clock.Add(d)
+}
+
+func stepAll(t *testing.T, nodes ...*testNode) {
+	t.Helper()
+	for _, node := range nodes {
+		if err := node.step(context.Background()); err != nil {
+			t.Fatalf("%s: %v", node.nodeId, err)
+		}
+	}
+}
+
+func isClosed(ch <-chan struct{}) bool {
+	select {
+	case <-ch:
+		return true
+	default:
+		return false
+	}
+}
+
+func TestElectorElectsSingleLeader(t *testing.T) {
+	nodes := newTestNodes(t, 3)
+	stepAll(t, nodes...)
+
+	if !nodes[0].IsLeader() || !isClosed(nodes[0].Elected()) {
+		t.Fatalf("expected the first node to be elected")
+	}
+	for _, node := range nodes[1:] {
+		if node.IsLeader() || isClosed(node.Elected()) {
+			t.Fatalf("expected %s to follow", node.nodeId)
+		}
+		if leader := node.Leader(); leader == nil || leader.NodeId != "node-1" {
+			t.Fatalf("expected %s to follow node-1, got %+v", node.nodeId, leader)
+		}
+	}
+
+	// renewals keep the lease with the leader
+	for range 5 {
+		nodes[0].advance(5 * time.Second)
+		stepAll(t, nodes...)
+	}
+	if !nodes[0].IsLeader() || nodes[1].IsLeader() || nodes[2].IsLeader() {
+		t.Fatalf("expected node-1 to keep the leadership")
+	}
+}
+
+func TestElectorFailsOverWhenLeaseExpires(t *testing.T) {
+	nodes := newTestNodes(t, 3)
+	stepAll(t, nodes...)
+
+	// the leader stops renewing
+	nodes[0].advance(16 * time.Second)
+	stepAll(t, nodes[1], nodes[2])
+
+	if !nodes[1].IsLeader() {
+		t.Fatalf("expected node-2 to take over the expired lease")
+	}
+	if nodes[2].IsLeader() {
+		t.Fatalf("expected node-3 to follow node-2")
+	}
+
+	stepAll(t, nodes[0])
+	if nodes[0].IsLeader() || !isClosed(nodes[0].Lost()) {
+		t.Fatalf("expected node-1 to lose the leadership")
+	}
+	if leader := nodes[0].Leader(); leader == nil || leader.NodeId != "node-2" {
+		t.Fatalf("expected node-1 to see node-2 as leader, got %+v", leader)
+	}
+}
+
+func TestElectorReleaseHandsOverLeadership(t *testing.T) {
+	nodes := newTestNodes(t, 2)
+	stepAll(t, nodes...)
+
+	if err := nodes[0].Release(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	stepAll(t, nodes...)
+
+	if nodes[0].IsLeader() {
+		t.Fatalf("expected the released node to stay out of the election")
+	}
+	if !nodes[1].IsLeader() {
+		t.Fatalf("expected node-2 to take over the released lease")
+	}
+}
+
+func TestElectorConcurrentClaimsElectSingleLeader(t *testing.T) {
+	nodes := newTestNodes(t, 2)
+	stepAll(t, nodes...)
+
+	// both followers see the expired lease before either of them claims it
+	nodes[0].advance(16 * time.Second)
+	expired, err := nodes[1].store.Read(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	stepAll(t, nodes[1])
+
+	nodes[0].mu.Lock()
+	nodes[0].isLeader = false
+	err = nodes[0].acquire(context.Background(), *nodes[0].clock, expired)
+	nodes[0].mu.Unlock()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !nodes[1].IsLeader() {
+		t.Fatalf("expected node-2 to win the claim")
+	}
+	if nodes[0].IsLeader() {
+		t.Fatalf("expected the stale claim of node-1 to fail")
+	}
+	if leader := nodes[0].Leader(); leader == nil || leader.NodeId != "node-2" || leader.Generation != 2 {
+		t.Fatalf("expected node-1 to follow generation 2 of node-2, got %+v", leader)
+	}
+}
+
+func TestElectorFenceRejectsReplacedLeader(t *testing.T) {
+	nodes := newTestNodes(t, 2)
+	stepAll(t, nodes...)
+
+	if err := nodes[0].Fence(context.Background()); err != nil {
+		t.Fatalf("expected the leader to pass the fence, got %v", err)
+	}
+	if err := nodes[1].Fence(context.Background()); !errors.Is(err, ErrNotLeader) {
+		t.Fatalf("expected %v for the follower, got %v", ErrNotLeader, err)
+	}
+
+	// node-1 did not notice yet that node-2 took over the lease it failed to renew
+	nodes[0].advance(16 * time.Second)
+	stepAll(t, nodes[1])
+	if !nodes[0].IsLeader() {
+		t.Fatalf("expected node-1 not to have stepped yet")
+	}
+	if err := nodes[0].Fence(context.Background()); !errors.Is(err, ErrNotLeader) {
+		t.Fatalf("expected %v for the replaced leader, got %v", ErrNotLeader, err)
+	}
+	if err := nodes[1].Fence(context.Background()); err != nil {
+		t.Fatalf("expected the new leader to pass the fence, got %v", err)
+	}
+}
+
+func TestFollowerHandlerForwardsToLeader(t *testing.T) {
+	leaderServer := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		_, _ = io.WriteString(writer, "leader "+request.URL.Path)
+	}))
+	t.Cleanup(leaderServer.Close)
+
+	store := NewLeaseFile(filepath.Join(t.TempDir(), "lease.json"))
+	leader := NewElector(store, ElectorOptions{NodeId: "leader", Address: leaderServer.URL, LeaseDuration: time.Minute, RenewInterval: time.Second})
+	follower := NewElector(store, ElectorOptions{NodeId: "follower", LeaseDuration: time.Minute, RenewInterval: time.Second})
+
+	followerServer := httptest.NewServer(NewFollowerHandler(follower, nil))
+	t.Cleanup(followerServer.Close)
+
+	response, err := http.Get(followerServer.URL + "/query")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = response.Body.Close()
+	if response.StatusCode != http.StatusServiceUnavailable {
+		t.Fatalf("expected status %d without a leader, got %d", http.StatusServiceUnavailable, response.StatusCode)
+	}
+
+	if err := leader.step(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if err := follower.step(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	response, err = http.Get(followerServer.URL + "/query")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer response.Body.Close()
+	body, _ := io.ReadAll(response.Body)
+	if string(body) != "leader /query" {
+		t.Fatalf("expected the request to reach the leader, got %q", body)
+	}
+}
+
+func TestFollowerHandlerServesQueriesLocally(t *testing.T) {
+	leaderServer := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		body, _ := io.ReadAll(request.Body)
+		_, _ = io.WriteString(writer, "leader "+string(body))
+	}))
+	t.Cleanup(leaderServer.Close)
+
+	queries := http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		_, _ = io.WriteString(writer, "local")
+	})
+
+	store := NewLeaseFile(filepath.Join(t.TempDir(), "lease.json"))
+	leader := NewElector(store, ElectorOptions{NodeId: "leader", Address: leaderServer.URL, LeaseDuration: time.Minute, RenewInterval: time.Second})
+	follower := NewElector(store, ElectorOptions{NodeId: "follower", LeaseDuration: time.Minute, RenewInterval: time.Second})
+
+	followerServer := httptest.NewServer(NewFollowerHandler(follower, queries))
+	t.Cleanup(followerServer.Close)
+
+	post := func(body string) (int, string) {
+		t.Helper()
+		response, err := http.Post(followerServer.URL+"/query", "application/json", strings.NewReader(body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer response.Body.Close()
+		responseBody, _ := io.ReadAll(response.Body)
+		return response.StatusCode, string(responseBody)
+	}
+
+	query := `{"query":"query Servers { servers { id } }"}`
+	mutation := `{"query":"mutation { deleteServer(input: {id: \"1\"}) { id } }"}`
+	namedMutation := `{"query":"query Servers { servers { id } } mutation Delete { deleteServer(input: {id: \"1\"}) { id } }","operationName":"Delete"}`
+
+	// queries are served without a leader
+	if status, body := post(query); status != http.StatusOK || body != "local" {
+		t.Fatalf("expected the query to be served locally, got %d %q", status, body)
+	}
+	if status, _ := post(mutation); status != http.StatusServiceUnavailable {
+		t.Fatalf("expected status %d for a mutation without a leader, got %d", http.StatusServiceUnavailable, status)
+	}
+
+	if err := leader.step(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if err := follower.step(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, body := range []string{mutation, namedMutation} {
+		if status, responseBody := post(body); status != http.StatusOK || responseBody != "leader "+body {
+			t.Fatalf("expected the mutation to be forwarded with its body, got %d %q", status, responseBody)
+		}
+	}
+
+	response, err := http.Get(followerServer.URL + "/query?query=" + url.QueryEscape("{ servers { id } }"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer response.Body.Close()
+	body, _ := io.ReadAll(response.Body)
+	if string(body) != "local" {
+		t.Fatalf("expected the GET query to be served locally, got %q", body)
+	}
+}
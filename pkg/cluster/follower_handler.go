@@ -0,0 +1,121 @@
+package cluster
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"mime"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+	"github.com/vektah/gqlparser/v2/ast"
+	"github.com/vektah/gqlparser/v2/parser"
+)
+
+const (
+	queryPath = "/query"
+	// maxQueryBodySize limits the request bodies read to find the operation, larger requests are forwarded as they are.
+	maxQueryBodySize = 1 << 20
+)
+
+// NewFollowerHandler serves the GraphQL queries with queries, when it is not nil, so they are answered while
+// no leader is available. Mutations, subscriptions and every other request are forwarded to the current leader.
+func NewFollowerHandler(elector *Elector, queries http.Handler) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/health", func(writer http.ResponseWriter, request *http.Request) {})
+	mux.HandleFunc("/", func(writer http.ResponseWriter, request *http.Request) {
+		if queries != nil && isQueryRequest(request) {
+			queries.ServeHTTP(writer, request)
+			return
+		}
+		forwardToLeader(elector, writer, request)
+	})
+	return mux
+}
+
+func forwardToLeader(elector *Elector, writer http.ResponseWriter, request *http.Request) {
+	leader := elector.Leader()
+	if leader == nil || leader.Address == "" {
+		http.Error(writer, "no cluster leader is available", http.StatusServiceUnavailable)
+		return
+	}
+
+	target, err := url.Parse(leader.Address)
+	if err != nil {
+		logrus.
+			WithError(err).
+			WithField("nodeId", leader.NodeId).
+			Error("invalid cluster leader address")
+		http.Error(writer, "invalid cluster leader address", http.StatusBadGateway)
+		return
+	}
+
+	proxy := &httputil.ReverseProxy{
+		Rewrite: func(request *httputil.ProxyRequest) {
+			request.SetURL(target)
+			request.SetXForwarded()
+		},
+	}
+	proxy.ServeHTTP(writer, request)
+}
+
+// isQueryRequest reports whether the request is a GraphQL query sent over plain http, subscriptions over
+// websockets or server-sent events are not. The read body is restored, so the request can still be forwarded.
+func isQueryRequest(request *http.Request) bool {
+	if request.URL.Path != queryPath || request.Header.Get("Upgrade") != "" ||
+		strings.Contains(request.Header.Get("Accept"), "text/event-stream") {
+		return false
+	}
+
+	var params struct {
+		Query         string `json:"query"`
+		OperationName string `json:"operationName"`
+	}
+
+	switch request.Method {
+	case http.MethodGet:
+		params.Query = request.URL.Query().Get("query")
+		params.OperationName = request.URL.Query().Get("operationName")
+	case http.MethodPost:
+		mediaType, _, err := mime.ParseMediaType(request.Header.Get("Content-Type"))
+		if err != nil || mediaType != "application/json" {
+			return false
+		}
+
+		body, err := io.ReadAll(io.LimitReader(request.Body, maxQueryBodySize+1))
+		request.Body = struct {
+			io.Reader
+			io.Closer
+		}{io.MultiReader(bytes.NewReader(body), request.Body), request.Body}
+		if err != nil || len(body) > maxQueryBodySize {
+			return false
+		}
+
+		if err := json.Unmarshal(body, &params); err != nil {
+			return false
+		}
+	default:
+		return false
+	}
+
+	// persisted queries without the query text are resolved by the leader
+	if params.Query == "" {
+		return false
+	}
+
+	document, err := parser.ParseQuery(&ast.Source{Input: params.Query})
+	if err != nil {
+		return false
+	}
+
+	var operation *ast.OperationDefinition
+	if params.OperationName == "" && len(document.Operations) == 1 {
+		operation = document.Operations[0]
+	} else {
+		operation = document.Operations.ForName(params.OperationName)
+	}
+	return operation != nil && operation.Operation == ast.Query
+}
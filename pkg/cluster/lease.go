@@ -0,0 +1,27 @@
+package cluster
+
+import (
+	"context"
+	"time"
+)
+
+// Lease is the leadership claim of a node, it is valid until it expires or is renewed.
+// Generation increases with every new leadership, renewals keep it.
+type Lease struct {
+	NodeId     string    `json:"nodeId"`
+	Address    string    `json:"address"`
+	Generation uint64    `json:"generation"`
+	ExpiresAt  time.Time `json:"expiresAt"`
+}
+
+func (l *Lease) Expired(now time.Time) bool {
+	return !now.Before(l.ExpiresAt)
+}
+
+// LeaseStore is the storage shared by the nodes, Read returns nil when no lease was written yet.
+type LeaseStore interface {
+	Read(ctx context.Context) (*Lease, error)
+	// CompareAndSwap replaces the lease when the generation of the stored one still equals generation, 0 when there
+	// is no lease yet, and reports whether it was replaced.
+	CompareAndSwap(ctx context.Context, generation uint64, lease *Lease) (bool, error)
+}
@@ -0,0 +1,137 @@
+package cluster
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+var (
+	// lockMutexes serialize the nodes of a single process, the os file locks only exclude other processes.
+	lockMutexesMu sync.Mutex
+	lockMutexes   = make(map[string]*sync.Mutex)
+)
+
+type leaseFile struct {
+	path string
+}
+
+// NewLeaseFile stores the lease in a file on storage shared by the nodes, writes replace the file atomically,
+// so readers never see a partially written lease. Swaps are serialized with a lock on the file with the .lock suffix,
+// the storage has to support file locks across the nodes.
+func NewLeaseFile(path string) LeaseStore {
+	return &leaseFile{
+		path: path,
+	}
+}
+
+func (f *leaseFile) Read(_ context.Context) (*Lease, error) {
+	data, err := os.ReadFile(f.path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read lease file: %w", err)
+	}
+
+	if len(data) == 0 {
+		return nil, nil
+	}
+
+	var lease Lease
+	if err := json.Unmarshal(data, &lease); err != nil {
+		return nil, fmt.Errorf("failed to parse lease file: %w", err)
+	}
+	return &lease, nil
+}
+
+func (f *leaseFile) CompareAndSwap(ctx context.Context, generation uint64, lease *Lease) (bool, error) {
+	unlock, err := f.lock()
+	if err != nil {
+		return false, err
+	}
+	defer unlock()
+
+	current, err := f.Read(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	var currentGeneration uint64
+	if current != nil {
+		currentGeneration = current.Generation
+	}
+	if currentGeneration != generation {
+		return false, nil
+	}
+
+	if err := f.write(lease); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (f *leaseFile) lock() (func(), error) {
+	lockPath := f.path + ".lock"
+
+	lockMutexesMu.Lock()
+	mutex, ok := lockMutexes[lockPath]
+	if !ok {
+		mutex = &sync.Mutex{}
+		lockMutexes[lockPath] = mutex
+	}
+	lockMutexesMu.Unlock()
+
+	mutex.Lock()
+	file, err := os.OpenFile(lockPath, os.O_RDWR|os.O_CREATE, 0600)
+	if err != nil {
+		mutex.Unlock()
+		return nil, fmt.Errorf("failed to open lease lock file: %w", err)
+	}
+	if err := lockFile(file); err != nil {
+		_ = file.Close()
+		mutex.Unlock()
+		return nil, fmt.Errorf("failed to lock lease lock file: %w", err)
+	}
+
+	return func() {
+		// closing the file releases the os lock
+		_ = file.Close()
+		mutex.Unlock()
+	}, nil
+}
+
+func (f *leaseFile) write(lease *Lease) error {
+	data, err := json.Marshal(lease)
+	if err != nil {
+		return fmt.Errorf("failed to marshal lease: %w", err)
+	}
+
+	tempFile, err := os.CreateTemp(filepath.Dir(f.path), filepath.Base(f.path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to create lease file: %w", err)
+	}
+	defer os.Remove(tempFile.Name())
+
+	if _, err := tempFile.Write(data); err != nil {
+		_ = tempFile.Close()
+		return fmt.Errorf("failed to write lease file: %w", err)
+	}
+	if err := tempFile.Sync(); err != nil {
+		_ = tempFile.Close()
+		return fmt.Errorf("failed to sync lease file: %w", err)
+	}
+	if err := tempFile.Close(); err != nil {
+		return fmt.Errorf("failed to close lease file: %w", err)
+	}
+
+	if err := os.Rename(tempFile.Name(), f.path); err != nil {
+		return fmt.Errorf("failed to replace lease file: %w", err)
+	}
+	return nil
+}
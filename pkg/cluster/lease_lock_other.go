@@ -0,0 +1,12 @@
+//go:build !unix && !windows
+
+package cluster
+
+import (
+	"os"
+)
+
+// lockFile does nothing, the platform has no file locks so only the nodes of a single process are serialized.
+func lockFile(_ *os.File) error {
+	return nil
+}
@@ -0,0 +1,17 @@
+//go:build unix
+
+package cluster
+
+import (
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+// lockFile waits for an exclusive record lock on the file, unlike flock it is also honored by nfs servers.
+func lockFile(file *os.File) error {
+	return unix.FcntlFlock(file.Fd(), unix.F_SETLKW, &unix.Flock_t{
+		Type:   unix.F_WRLCK,
+		Whence: 0,
+	})
+}
@@ -0,0 +1,40 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/kelseyhightower/envconfig"
+)
+
+// Agent is the configuration of the agent mode, it exposes a local backend to remote wg-ui instances.
+type Agent struct {
+	Host            string `default:""`
+	Port            uint16 `default:"4581"`
+	BackendUrl      string `split_words:"true" default:"linux://"`
+	Token           string
+	TLSCertFile     string `split_words:"true" required:"true"`
+	TLSKeyFile      string `split_words:"true" required:"true"`
+	TLSClientCAFile string `split_words:"true"`
+	// MemoryBackendEnabled allows the simulated memory:// backend as the local backend, for demos.
+	MemoryBackendEnabled bool `split_words:"true" default:"false"`
+}
+
+func (a *Agent) Address() string {
+	return fmt.Sprintf("%s:%d", a.Host, a.Port)
+}
+
+func LoadAgent(prefix string) (*Agent, error) {
+	prefix = strings.ToUpper(prefix)
+	prefix = strings.ReplaceAll(prefix, "-", "_")
+	prefix = strings.ReplaceAll(prefix, " ", "_")
+	var agent Agent
+	if err := envconfig.Process(prefix+"_AGENT", &agent); err != nil {
+		return nil, fmt.Errorf("failed to process env config: %w", err)
+	}
+	if agent.Token == "" && agent.TLSClientCAFile == "" {
+		return nil, errors.New("agent requires a token or a tls client ca file")
+	}
+	return &agent, nil
+}
@@ -0,0 +1,54 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"time"
+)
+
+// Cluster is the configuration of the active/passive mode, the nodes elect a leader through a lease file on
+// shared storage and only the leader opens the database and manages the backends. The leader writes a snapshot
+// of the database next to the lease file, followers serve read-only queries from it.
+type Cluster struct {
+	Enabled bool `default:"false"`
+	// NodeId identifies the node in the lease, defaults to the hostname.
+	NodeId string `split_words:"true"`
+	// AdvertiseAddress is the url other nodes forward requests to while this node is the leader.
+	AdvertiseAddress string        `split_words:"true"`
+	LeaseFile        string        `split_words:"true"`
+	LeaseDuration    time.Duration `split_words:"true" default:"15s"`
+	RenewInterval    time.Duration `split_words:"true" default:"5s"`
+	// SnapshotFile is where the leader writes the database snapshot, defaults to the lease file with a .db suffix.
+	SnapshotFile     string        `split_words:"true"`
+	SnapshotInterval time.Duration `split_words:"true" default:"10s"`
+}
+
+func (c *Cluster) NodeIdOrHostname() (string, error) {
+	if c.NodeId != "" {
+		return c.NodeId, nil
+	}
+	return os.Hostname()
+}
+
+func (c *Cluster) SnapshotFileOrDefault() string {
+	if c.SnapshotFile != "" {
+		return c.SnapshotFile
+	}
+	return c.LeaseFile + ".db"
+}
+
+func (c *Cluster) validate() error {
+	if c.LeaseFile == "" {
+		return errors.New("cluster requires a lease file")
+	}
+	if c.AdvertiseAddress == "" {
+		return errors.New("cluster requires an advertise address")
+	}
+	if c.RenewInterval <= 0 || c.RenewInterval >= c.LeaseDuration {
+		return errors.New("cluster renew interval must be positive and shorter than the lease duration")
+	}
+	if c.SnapshotInterval <= 0 {
+		return errors.New("cluster snapshot interval must be positive")
+	}
+	return nil
+}
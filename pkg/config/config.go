@@ -12,14 +12,35 @@ type Config struct {
 	BoltDB                                  *BoltDB       `split_words:"true"`
 	HttpServer                              *HttpServer   `split_words:"true"`
 	DebugServer                             *DebugServer  `split_words:"true"`
+	Cluster                                 *Cluster      `split_words:"true"`
 	Initial                                 *Initial      `required:"true"`
 	AutomaticStatsUpdateInterval            time.Duration `split_words:"true" default:"30s"`
 	AutomaticStatsUpdateOnlyWithSubscribers bool          `split_words:"true" default:"false"`
+	DeviceReconfigureDelay                  time.Duration `split_words:"true" default:"100ms"`
+	DriftCheckInterval                      time.Duration `split_words:"true" default:"1m"`
+	ServerShutdownTimeout                   time.Duration `split_words:"true" default:"30s"`
+	BackendFailureThreshold                 int           `split_words:"true" default:"3"`
+	BackendRetryBackoff                     time.Duration `split_words:"true" default:"5s"`
+	BackendMaxRetryBackoff                  time.Duration `split_words:"true" default:"5m"`
+	HookTimeout                             time.Duration `split_words:"true" default:"30s"`
+	HookOutputLimit                         int           `split_words:"true" default:"65536"`
+	HookHistoryLimit                        int           `split_words:"true" default:"50"`
+	HookRawCommandsEnabled                  bool          `split_words:"true" default:"true"`
+	HookScriptsDirectory                    string        `split_words:"true"`
+	HookUser                                string        `split_words:"true"`
+	HookGroup                               string        `split_words:"true"`
 	CorsAllowedOrigins                      []string      `split_words:"true" default:"*"`
 	CorsAllowCredentials                    bool          `split_words:"true" default:"true"`
 	CorsAllowPrivateNetwork                 bool          `split_words:"true" default:"false"`
 	CorsDebug                               bool          `split_words:"true" default:"false"`
 	SubscriptionAllowedOrigins              []string      `split_words:"true" default:"*"`
+	SubscriptionQueueSize                   int           `split_words:"true" default:"64"`
+	SubscriptionOverflowPolicy              string        `split_words:"true" default:"drop"`
+	SubscriptionNatsUrl                     string        `split_words:"true"`
+	SubscriptionNatsSubject                 string        `split_words:"true" default:"wg-ui"`
+	SubscriptionJournalSize                 int           `split_words:"true" default:"1000"`
+	SubscriptionPersistedJournalSize        int           `split_words:"true" default:"10000"`
+	MemoryBackendEnabled                    bool          `split_words:"true" default:"false"`
 	JwtSecret                               string        `required:"true" split_words:"true"`
 	JwtDuration                             time.Duration `split_words:"true" default:"8h"`
 }
@@ -32,5 +53,10 @@ func Load(prefix string) (*Config, error) {
 	if err := envconfig.Process(prefix, &config); err != nil {
 		return nil, fmt.Errorf("failed to process env config: %w", err)
 	}
+	if config.Cluster.Enabled {
+		if err := config.Cluster.validate(); err != nil {
+			return nil, err
+		}
+	}
 	return &config, nil
 }
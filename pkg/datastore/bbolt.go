@@ -29,3 +29,49 @@ func NewBBoltDB(databasePath string, timeout time.Duration) (*bbolt.DB, error) {
 
 	return db, nil
 }
+
+// NewReadOnlyBBoltDB opens an existing database for reading only, such as a snapshot written by WriteSnapshot.
+func NewReadOnlyBBoltDB(databasePath string, timeout time.Duration) (*bbolt.DB, error) {
+	return bbolt.Open(databasePath, 0644, &bbolt.Options{
+		Timeout:  timeout,
+		ReadOnly: true,
+	})
+}
+
+// WriteSnapshot writes a consistent copy of the database to the path, the copy replaces the file atomically,
+// so readers of the previous snapshot keep reading it until they open the path again.
+func WriteSnapshot(db *bbolt.DB, snapshotPath string) error {
+	dir := filepath.Dir(snapshotPath)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create directory %s - %w", dir, err)
+	}
+
+	file, err := os.CreateTemp(dir, filepath.Base(snapshotPath)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to create snapshot file: %w", err)
+	}
+	defer func() {
+		_ = file.Close()
+		_ = os.Remove(file.Name())
+	}()
+
+	if err := db.View(func(tx *bbolt.Tx) error {
+		_, err := tx.WriteTo(file)
+		return err
+	}); err != nil {
+		return fmt.Errorf("failed to write snapshot: %w", err)
+	}
+	if err := file.Chmod(0644); err != nil {
+		return fmt.Errorf("failed to change snapshot file mode: %w", err)
+	}
+	if err := file.Sync(); err != nil {
+		return fmt.Errorf("failed to sync snapshot file: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("failed to close snapshot file: %w", err)
+	}
+	if err := os.Rename(file.Name(), snapshotPath); err != nil {
+		return fmt.Errorf("failed to replace snapshot file: %w", err)
+	}
+	return nil
+}
@@ -13,6 +13,7 @@ import (
 
 	"github.com/UnAfraid/wg-ui/pkg/backend"
 	"github.com/UnAfraid/wg-ui/pkg/internal/adapt"
+	"github.com/UnAfraid/wg-ui/pkg/pagination"
 )
 
 const (
@@ -62,7 +63,7 @@ func (r *backendRepository) FindOne(ctx context.Context, options *backend.FindOn
 
 func (r *backendRepository) FindAll(ctx context.Context, options *backend.FindOptions) ([]*backend.Backend, error) {
 	return dbTx(ctx, r.db, backendBucket, false, func(tx *bbolt.Tx, bucket *bbolt.Bucket) ([]*backend.Backend, error) {
-		var backends []*backend.Backend
+		window := pagination.NewWindow(options.Pagination, (*backend.Backend).Cursor)
 		var backendsCount int
 		var searchList searchindex.SearchList[*backend.Backend]
 		c := bucket.Cursor()
@@ -74,19 +75,23 @@ func (r *backendRepository) FindAll(ctx context.Context, options *backend.FindOp
 				return nil, fmt.Errorf("failed to unmarshal backend: %w", err)
 			}
 
+			if options.Filter != nil && !options.Filter.Matches(b) {
+				continue
+			}
+
 			var optionsLen int
 			if len(options.Ids) != 0 {
 				optionsLen++
 				if slices.Contains(options.Ids, b.Id) {
-					backends = append(backends, b)
+					window.Add(b)
 					continue
 				}
 			}
 
 			if options.Type != nil {
 				optionsLen++
-				if b.Type() == *options.Type {
-					backends = append(backends, b)
+				if strings.EqualFold(b.Type(), *options.Type) {
+					window.Add(b)
 					continue
 				}
 			}
@@ -94,7 +99,7 @@ func (r *backendRepository) FindAll(ctx context.Context, options *backend.FindOp
 			if options.Enabled != nil {
 				optionsLen++
 				if b.Enabled == *options.Enabled {
-					backends = append(backends, b)
+					window.Add(b)
 					continue
 				}
 			}
@@ -102,7 +107,7 @@ func (r *backendRepository) FindAll(ctx context.Context, options *backend.FindOp
 			if options.CreateUserId != nil {
 				optionsLen++
 				if b.CreateUserId == *options.CreateUserId {
-					backends = append(backends, b)
+					window.Add(b)
 					continue
 				}
 			}
@@ -110,7 +115,7 @@ func (r *backendRepository) FindAll(ctx context.Context, options *backend.FindOp
 			if options.UpdateUserId != nil {
 				optionsLen++
 				if b.UpdateUserId == *options.UpdateUserId {
-					backends = append(backends, b)
+					window.Add(b)
 					continue
 				}
 			}
@@ -128,7 +133,7 @@ func (r *backendRepository) FindAll(ctx context.Context, options *backend.FindOp
 			}
 
 			if optionsLen == 0 {
-				backends = append(backends, b)
+				window.Add(b)
 			}
 		}
 
@@ -139,10 +144,18 @@ func (r *backendRepository) FindAll(ctx context.Context, options *backend.FindOp
 				OutputSize: backendsCount,
 				Matching:   searchindex.Beginning,
 			})
-			backends = append(backends, matches...)
+			// the name and the description are indexed apart, an item matching both must be added once
+			matched := make(map[string]struct{}, len(matches))
+			for _, match := range matches {
+				if _, ok := matched[match.Id]; ok {
+					continue
+				}
+				matched[match.Id] = struct{}{}
+				window.Add(match)
+			}
 		}
 
-		return backends, nil
+		return window.Items(), nil
 	})
 }
 
@@ -0,0 +1,128 @@
+package bbolt
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"slices"
+
+	"go.etcd.io/bbolt"
+
+	"github.com/UnAfraid/wg-ui/pkg/subscription"
+)
+
+const (
+	eventJournalBucket = "event_journal"
+)
+
+type eventJournalRepository struct {
+	db *bbolt.DB
+}
+
+// NewEventJournalRepository stores the events keyed by their big endian sequence, so the keys sort by sequence.
+func NewEventJournalRepository(db *bbolt.DB) subscription.JournalRepository {
+	return &eventJournalRepository{
+		db: db,
+	}
+}
+
+func (r *eventJournalRepository) Append(ctx context.Context, events []*subscription.Event, limit int) error {
+	_, err := dbTx(ctx, r.db, eventJournalBucket, true, func(tx *bbolt.Tx, bucket *bbolt.Bucket) (struct{}, error) {
+		for _, event := range events {
+			jsonState, err := json.Marshal(event)
+			if err != nil {
+				return struct{}{}, fmt.Errorf("failed to marshal event: %w", err)
+			}
+
+			if err := bucket.Put(sequenceKey(event.Sequence), jsonState); err != nil {
+				return struct{}{}, err
+			}
+		}
+
+		if len(events) == 0 {
+			return struct{}{}, nil
+		}
+
+		last := events[len(events)-1].Sequence
+		if limit <= 0 || last <= uint64(limit) {
+			return struct{}{}, nil
+		}
+
+		oldest := sequenceKey(last - uint64(limit) + 1)
+		c := bucket.Cursor()
+		for k, _ := c.First(); k != nil && bytes.Compare(k, oldest) < 0; k, _ = c.First() {
+			if err := c.Delete(); err != nil {
+				return struct{}{}, err
+			}
+		}
+		return struct{}{}, nil
+	})
+	return err
+}
+
+// FindAfter reads in a read-only transaction, the journal is read while notifications sent from within write
+// transactions wait for it.
+func (r *eventJournalRepository) FindAfter(_ context.Context, sequence uint64, limit int) ([]*subscription.Event, error) {
+	return viewEvents(r.db, func(bucket *bbolt.Bucket) ([]*subscription.Event, error) {
+		var events []*subscription.Event
+		c := bucket.Cursor()
+		for k, v := c.Seek(sequenceKey(sequence + 1)); k != nil; k, v = c.Next() {
+			if limit > 0 && len(events) == limit {
+				break
+			}
+
+			event, err := unmarshalEvent(v)
+			if err != nil {
+				return nil, err
+			}
+			events = append(events, event)
+		}
+		return events, nil
+	})
+}
+
+func (r *eventJournalRepository) FindLast(_ context.Context, limit int) ([]*subscription.Event, error) {
+	return viewEvents(r.db, func(bucket *bbolt.Bucket) ([]*subscription.Event, error) {
+		var events []*subscription.Event
+		c := bucket.Cursor()
+		for k, v := c.Last(); k != nil; k, v = c.Prev() {
+			if limit > 0 && len(events) == limit {
+				break
+			}
+
+			event, err := unmarshalEvent(v)
+			if err != nil {
+				return nil, err
+			}
+			events = append(events, event)
+		}
+		slices.Reverse(events)
+		return events, nil
+	})
+}
+
+func viewEvents(db *bbolt.DB, callback func(*bbolt.Bucket) ([]*subscription.Event, error)) (events []*subscription.Event, err error) {
+	err = db.View(func(tx *bbolt.Tx) error {
+		bucket := tx.Bucket([]byte(eventJournalBucket))
+		if bucket == nil {
+			return nil
+		}
+		events, err = callback(bucket)
+		return err
+	})
+	return events, err
+}
+
+func sequenceKey(sequence uint64) []byte {
+	return binary.BigEndian.AppendUint64(nil, sequence)
+}
+
+func unmarshalEvent(value []byte) (*subscription.Event, error) {
+	var event *subscription.Event
+	if err := json.Unmarshal(value, &event); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal event: %w", err)
+	}
+	return event, nil
+}
@@ -0,0 +1,70 @@
+package bbolt
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+
+	"go.etcd.io/bbolt"
+
+	"github.com/UnAfraid/wg-ui/pkg/hook"
+)
+
+const (
+	hookExecutionBucket = "hook_execution"
+)
+
+type hookExecutionRepository struct {
+	db *bbolt.DB
+}
+
+func NewHookExecutionRepository(db *bbolt.DB) hook.Repository {
+	return &hookExecutionRepository{
+		db: db,
+	}
+}
+
+func (r *hookExecutionRepository) FindAll(ctx context.Context, options *hook.FindOptions) ([]*hook.Execution, error) {
+	return dbTx(ctx, r.db, hookExecutionBucket, false, func(tx *bbolt.Tx, bucket *bbolt.Bucket) ([]*hook.Execution, error) {
+		var executions []*hook.Execution
+		c := bucket.Cursor()
+		for k, v := c.First(); k != nil; k, v = c.Next() {
+			var execution *hook.Execution
+			if err := json.Unmarshal(v, &execution); err != nil {
+				return nil, fmt.Errorf("failed to unmarshal hook execution: %w", err)
+			}
+
+			if options.Matches(execution) {
+				executions = append(executions, execution)
+			}
+		}
+
+		slices.SortStableFunc(executions, func(a *hook.Execution, b *hook.Execution) int {
+			return b.StartedAt.Compare(a.StartedAt)
+		})
+
+		if options.Limit > 0 && len(executions) > options.Limit {
+			executions = executions[:options.Limit]
+		}
+		return executions, nil
+	})
+}
+
+func (r *hookExecutionRepository) Create(ctx context.Context, execution *hook.Execution) (*hook.Execution, error) {
+	return dbTx(ctx, r.db, hookExecutionBucket, true, func(tx *bbolt.Tx, bucket *bbolt.Bucket) (*hook.Execution, error) {
+		jsonState, err := json.Marshal(execution)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal hook execution: %w", err)
+		}
+
+		return execution, bucket.Put([]byte(execution.Id), jsonState)
+	})
+}
+
+func (r *hookExecutionRepository) Delete(ctx context.Context, executionId string) error {
+	_, err := dbTx(ctx, r.db, hookExecutionBucket, false, func(tx *bbolt.Tx, bucket *bbolt.Bucket) (struct{}, error) {
+		return struct{}{}, bucket.Delete([]byte(executionId))
+	})
+	return err
+}
@@ -0,0 +1,204 @@
+package bbolt
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/UnAfraid/searchindex"
+	"go.etcd.io/bbolt"
+
+	"github.com/UnAfraid/wg-ui/pkg/internal/adapt"
+	"github.com/UnAfraid/wg-ui/pkg/pagination"
+	"github.com/UnAfraid/wg-ui/pkg/peer"
+)
+
+const (
+	peerGroupBucket = "peer_group"
+)
+
+type peerGroupRepository struct {
+	db *bbolt.DB
+}
+
+func NewPeerGroupRepository(db *bbolt.DB) peer.GroupRepository {
+	return &peerGroupRepository{
+		db: db,
+	}
+}
+
+func (r *peerGroupRepository) FindOne(ctx context.Context, options *peer.GroupFindOneOptions) (*peer.Group, error) {
+	return dbTx(ctx, r.db, peerGroupBucket, false, func(tx *bbolt.Tx, bucket *bbolt.Bucket) (*peer.Group, error) {
+		if idOption := options.IdOption; idOption != nil {
+			jsonState := bucket.Get([]byte(idOption.Id))
+			if jsonState == nil {
+				return nil, nil
+			}
+
+			var g *peer.Group
+			if err := json.Unmarshal(jsonState, &g); err != nil {
+				return nil, fmt.Errorf("failed to unmarshal peer group: %w", err)
+			}
+
+			return g, nil
+		}
+
+		return nil, nil
+	})
+}
+
+func (r *peerGroupRepository) FindAll(ctx context.Context, options *peer.GroupFindOptions) ([]*peer.Group, error) {
+	return dbTx(ctx, r.db, peerGroupBucket, false, func(tx *bbolt.Tx, bucket *bbolt.Bucket) ([]*peer.Group, error) {
+		window := pagination.NewWindow(options.Pagination, (*peer.Group).Cursor)
+		var groupsCount int
+		var searchList searchindex.SearchList[*peer.Group]
+		c := bucket.Cursor()
+		for k, v := c.First(); k != nil; k, v = c.Next() {
+			groupsCount++
+
+			var g *peer.Group
+			if err := json.Unmarshal(v, &g); err != nil {
+				return nil, fmt.Errorf("failed to unmarshal peer group: %w", err)
+			}
+
+			var optionsLen int
+			if len(options.Ids) != 0 {
+				optionsLen++
+				if slices.Contains(options.Ids, g.Id) {
+					window.Add(g)
+					continue
+				}
+			}
+
+			if len(options.Query) != 0 {
+				optionsLen++
+				searchList = append(searchList, &searchindex.SearchItem[*peer.Group]{
+					Key:  g.Name,
+					Data: g,
+				})
+				searchList = append(searchList, &searchindex.SearchItem[*peer.Group]{
+					Key:  g.Description,
+					Data: g,
+				})
+			}
+
+			if optionsLen == 0 {
+				window.Add(g)
+			}
+		}
+
+		if len(options.Query) != 0 {
+			searchIndex := searchindex.NewSearchIndex[*peer.Group](searchList, groupsCount, nil, nil, true, nil)
+			matches := searchIndex.Search(searchindex.SearchParams[*peer.Group]{
+				Text:       options.Query,
+				OutputSize: groupsCount,
+				Matching:   searchindex.Beginning,
+			})
+			// the name and the description are indexed apart, an item matching both must be added once
+			matched := make(map[string]struct{}, len(matches))
+			for _, match := range matches {
+				if _, ok := matched[match.Id]; ok {
+					continue
+				}
+				matched[match.Id] = struct{}{}
+				window.Add(match)
+			}
+		}
+
+		return window.Items(), nil
+	})
+}
+
+func (r *peerGroupRepository) Create(ctx context.Context, g *peer.Group) (*peer.Group, error) {
+	return dbTx(ctx, r.db, peerGroupBucket, true, func(tx *bbolt.Tx, bucket *bbolt.Bucket) (*peer.Group, error) {
+		id := []byte(g.Id)
+		if bucket.Get(id) != nil {
+			return nil, peer.ErrGroupIdAlreadyExists
+		}
+
+		jsonState, err := json.Marshal(g)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal peer group: %w", err)
+		}
+
+		return g, bucket.Put(id, jsonState)
+	})
+}
+
+func (r *peerGroupRepository) Update(ctx context.Context, g *peer.Group, fieldMask *peer.GroupUpdateFieldMask) (*peer.Group, error) {
+	return dbTx(ctx, r.db, peerGroupBucket, false, func(tx *bbolt.Tx, bucket *bbolt.Bucket) (*peer.Group, error) {
+		id := []byte(g.Id)
+		jsonState := bucket.Get(id)
+		if jsonState == nil {
+			return nil, peer.ErrGroupNotFound
+		}
+
+		var updatedGroup *peer.Group
+		if err := json.Unmarshal(jsonState, &updatedGroup); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal peer group: %w", err)
+		}
+
+		if fieldMask.Name {
+			updatedGroup.Name = g.Name
+		}
+
+		if fieldMask.Description {
+			updatedGroup.Description = g.Description
+		}
+
+		if fieldMask.PersistentKeepalive {
+			updatedGroup.PersistentKeepalive = g.PersistentKeepalive
+		}
+
+		if fieldMask.DNS {
+			updatedGroup.DNS = g.DNS
+		}
+
+		if fieldMask.ACL {
+			updatedGroup.ACL = g.ACL
+		}
+
+		if fieldMask.Hooks {
+			updatedGroup.Hooks = g.Hooks
+		}
+
+		if fieldMask.ExpiresAfter {
+			updatedGroup.ExpiresAfter = g.ExpiresAfter
+		}
+
+		if fieldMask.UpdateUserId {
+			updatedGroup.UpdateUserId = g.UpdateUserId
+		}
+
+		updatedGroup.UpdatedAt = time.Now()
+
+		jsonState, err := json.Marshal(updatedGroup)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal peer group: %w", err)
+		}
+
+		return updatedGroup, bucket.Put(id, jsonState)
+	})
+}
+
+func (r *peerGroupRepository) Delete(ctx context.Context, groupId string, deleteUserId string) (*peer.Group, error) {
+	return dbTx(ctx, r.db, peerGroupBucket, false, func(tx *bbolt.Tx, bucket *bbolt.Bucket) (*peer.Group, error) {
+		id := []byte(groupId)
+		jsonState := bucket.Get(id)
+		if jsonState == nil {
+			return nil, peer.ErrGroupNotFound
+		}
+
+		var deletedGroup *peer.Group
+		if err := json.Unmarshal(jsonState, &deletedGroup); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal peer group: %w", err)
+		}
+
+		deletedGroup.DeleteUserId = deleteUserId
+		deletedGroup.DeletedAt = adapt.ToPointer(time.Now())
+
+		return deletedGroup, bucket.Delete(id)
+	})
+}
@@ -12,6 +12,7 @@ import (
 	"go.etcd.io/bbolt"
 
 	"github.com/UnAfraid/wg-ui/pkg/internal/adapt"
+	"github.com/UnAfraid/wg-ui/pkg/pagination"
 	"github.com/UnAfraid/wg-ui/pkg/peer"
 )
 
@@ -63,7 +64,7 @@ func (r *peerRepository) FindOne(ctx context.Context, options *peer.FindOneOptio
 
 func (r *peerRepository) FindAll(ctx context.Context, options *peer.FindOptions) ([]*peer.Peer, error) {
 	return dbTx(ctx, r.db, peerBucket, false, func(tx *bbolt.Tx, bucket *bbolt.Bucket) ([]*peer.Peer, error) {
-		var peers []*peer.Peer
+		window := pagination.NewWindow(options.Pagination, (*peer.Peer).Cursor)
 		var peersCount int
 		var searchList searchindex.SearchList[*peer.Peer]
 		c := bucket.Cursor()
@@ -75,11 +76,15 @@ func (r *peerRepository) FindAll(ctx context.Context, options *peer.FindOptions)
 				return nil, fmt.Errorf("failed to unmarshal peer: %w", err)
 			}
 
+			if options.Filter != nil && !options.Filter.Matches(p) {
+				continue
+			}
+
 			var optionsLen int
 			if len(options.Ids) != 0 {
 				optionsLen++
 				if slices.Contains(options.Ids, p.Id) {
-					peers = append(peers, p)
+					window.Add(p)
 					continue
 				}
 			}
@@ -87,7 +92,7 @@ func (r *peerRepository) FindAll(ctx context.Context, options *peer.FindOptions)
 			if options.ServerId != nil {
 				optionsLen++
 				if p.ServerId == *options.ServerId {
-					peers = append(peers, p)
+					window.Add(p)
 					continue
 				}
 			}
@@ -95,7 +100,7 @@ func (r *peerRepository) FindAll(ctx context.Context, options *peer.FindOptions)
 			if len(options.ServerIds) != 0 {
 				optionsLen++
 				if slices.Contains(options.ServerIds, p.ServerId) {
-					peers = append(peers, p)
+					window.Add(p)
 					continue
 				}
 			}
@@ -103,7 +108,7 @@ func (r *peerRepository) FindAll(ctx context.Context, options *peer.FindOptions)
 			if options.CreateUserId != nil {
 				optionsLen++
 				if p.CreateUserId == *options.CreateUserId {
-					peers = append(peers, p)
+					window.Add(p)
 					continue
 				}
 			}
@@ -111,7 +116,7 @@ func (r *peerRepository) FindAll(ctx context.Context, options *peer.FindOptions)
 			if options.UpdateUserId != nil {
 				optionsLen++
 				if p.UpdateUserId == *options.UpdateUserId {
-					peers = append(peers, p)
+					window.Add(p)
 					continue
 				}
 			}
@@ -129,7 +134,7 @@ func (r *peerRepository) FindAll(ctx context.Context, options *peer.FindOptions)
 			}
 
 			if optionsLen == 0 {
-				peers = append(peers, p)
+				window.Add(p)
 			}
 		}
 
@@ -140,10 +145,18 @@ func (r *peerRepository) FindAll(ctx context.Context, options *peer.FindOptions)
 				OutputSize: peersCount,
 				Matching:   searchindex.Beginning,
 			})
-			peers = append(peers, matches...)
+			// the name and the description are indexed apart, an item matching both must be added once
+			matched := make(map[string]struct{}, len(matches))
+			for _, match := range matches {
+				if _, ok := matched[match.Id]; ok {
+					continue
+				}
+				matched[match.Id] = struct{}{}
+				window.Add(match)
+			}
 		}
 
-		return peers, nil
+		return window.Items(), nil
 	})
 }
 
@@ -184,6 +197,14 @@ func (r *peerRepository) Update(ctx context.Context, p *peer.Peer, fieldMask *pe
 			updatedPeer.Description = p.Description
 		}
 
+		if fieldMask.Tags {
+			updatedPeer.Tags = p.Tags
+		}
+
+		if fieldMask.GroupId {
+			updatedPeer.GroupId = p.GroupId
+		}
+
 		if fieldMask.PublicKey {
 			updatedPeer.PublicKey = p.PublicKey
 		}
@@ -204,10 +225,30 @@ func (r *peerRepository) Update(ctx context.Context, p *peer.Peer, fieldMask *pe
 			updatedPeer.PersistentKeepalive = p.PersistentKeepalive
 		}
 
+		if fieldMask.AllowedDestinations {
+			updatedPeer.AllowedDestinations = p.AllowedDestinations
+		}
+
+		if fieldMask.ACL {
+			updatedPeer.ACL = p.ACL
+		}
+
 		if fieldMask.Hooks {
 			updatedPeer.Hooks = p.Hooks
 		}
 
+		if fieldMask.Disabled {
+			updatedPeer.Disabled = p.Disabled
+		}
+
+		if fieldMask.ExpiresAt {
+			updatedPeer.ExpiresAt = p.ExpiresAt
+		}
+
+		if fieldMask.Stats {
+			updatedPeer.Stats = p.Stats
+		}
+
 		if fieldMask.CreateUserId {
 			updatedPeer.CreateUserId = p.CreateUserId
 		}
@@ -12,6 +12,7 @@ import (
 	"go.etcd.io/bbolt"
 
 	"github.com/UnAfraid/wg-ui/pkg/internal/adapt"
+	"github.com/UnAfraid/wg-ui/pkg/pagination"
 	"github.com/UnAfraid/wg-ui/pkg/server"
 )
 
@@ -44,12 +45,15 @@ func (r *serverRepository) FindOne(ctx context.Context, options *server.FindOneO
 
 			return s, nil
 		} else if nameOption := options.NameOption; nameOption != nil {
-			var s *server.Server
 			c := bucket.Cursor()
 			for k, v := c.First(); k != nil; k, v = c.Next() {
+				var s *server.Server
 				if err := json.Unmarshal(v, &s); err != nil {
 					return nil, fmt.Errorf("failed to unmarshal server: %w", err)
 				}
+				if nameOption.BackendId != "" && s.BackendId != nameOption.BackendId {
+					continue
+				}
 				if strings.EqualFold(s.Name, nameOption.Name) {
 					return s, nil
 				}
@@ -62,7 +66,7 @@ func (r *serverRepository) FindOne(ctx context.Context, options *server.FindOneO
 
 func (r *serverRepository) FindAll(ctx context.Context, options *server.FindOptions) ([]*server.Server, error) {
 	return dbTx(ctx, r.db, serverBucket, false, func(tx *bbolt.Tx, bucket *bbolt.Bucket) ([]*server.Server, error) {
-		var servers []*server.Server
+		window := pagination.NewWindow(options.Pagination, (*server.Server).Cursor)
 		var serversCount int
 		var searchList searchindex.SearchList[*server.Server]
 		c := bucket.Cursor()
@@ -74,11 +78,15 @@ func (r *serverRepository) FindAll(ctx context.Context, options *server.FindOpti
 				return nil, fmt.Errorf("failed to unmarshal server: %w", err)
 			}
 
+			if options.Filter != nil && !options.Filter.Matches(s) {
+				continue
+			}
+
 			var optionsLen int
 			if len(options.Ids) != 0 {
 				optionsLen++
 				if slices.Contains(options.Ids, s.Id) {
-					servers = append(servers, s)
+					window.Add(s)
 					continue
 				}
 			}
@@ -86,7 +94,7 @@ func (r *serverRepository) FindAll(ctx context.Context, options *server.FindOpti
 			if options.BackendId != nil {
 				optionsLen++
 				if s.BackendId == *options.BackendId {
-					servers = append(servers, s)
+					window.Add(s)
 					continue
 				}
 			}
@@ -94,7 +102,7 @@ func (r *serverRepository) FindAll(ctx context.Context, options *server.FindOpti
 			if options.Enabled != nil {
 				optionsLen++
 				if s.Enabled == *options.Enabled {
-					servers = append(servers, s)
+					window.Add(s)
 					continue
 				}
 			}
@@ -102,7 +110,7 @@ func (r *serverRepository) FindAll(ctx context.Context, options *server.FindOpti
 			if options.CreateUserId != nil {
 				optionsLen++
 				if s.CreateUserId == *options.CreateUserId {
-					servers = append(servers, s)
+					window.Add(s)
 					continue
 				}
 			}
@@ -110,7 +118,7 @@ func (r *serverRepository) FindAll(ctx context.Context, options *server.FindOpti
 			if options.UpdateUserId != nil {
 				optionsLen++
 				if s.UpdateUserId == *options.UpdateUserId {
-					servers = append(servers, s)
+					window.Add(s)
 					continue
 				}
 			}
@@ -128,7 +136,7 @@ func (r *serverRepository) FindAll(ctx context.Context, options *server.FindOpti
 			}
 
 			if optionsLen == 0 {
-				servers = append(servers, s)
+				window.Add(s)
 			}
 		}
 
@@ -139,10 +147,18 @@ func (r *serverRepository) FindAll(ctx context.Context, options *server.FindOpti
 				OutputSize: serversCount,
 				Matching:   searchindex.Beginning,
 			})
-			servers = append(servers, matches...)
+			// the name and the description are indexed apart, an item matching both must be added once
+			matched := make(map[string]struct{}, len(matches))
+			for _, match := range matches {
+				if _, ok := matched[match.Id]; ok {
+					continue
+				}
+				matched[match.Id] = struct{}{}
+				window.Add(match)
+			}
 		}
 
-		return servers, nil
+		return window.Items(), nil
 	})
 }
 
@@ -179,6 +195,10 @@ func (r *serverRepository) Update(ctx context.Context, s *server.Server, fieldMa
 			updatedServer.Description = s.Description
 		}
 
+		if fieldMask.Tags {
+			updatedServer.Tags = s.Tags
+		}
+
 		if fieldMask.BackendId {
 			updatedServer.BackendId = s.BackendId
 		}
@@ -224,6 +244,26 @@ func (r *serverRepository) Update(ctx context.Context, s *server.Server, fieldMa
 			updatedServer.Hooks = s.Hooks
 		}
 
+		if fieldMask.DriftMode {
+			updatedServer.DriftMode = s.DriftMode
+		}
+
+		if fieldMask.StartupPolicy {
+			updatedServer.StartupPolicy = s.StartupPolicy
+		}
+
+		if fieldMask.ShutdownPolicy {
+			updatedServer.ShutdownPolicy = s.ShutdownPolicy
+		}
+
+		if fieldMask.Drift {
+			updatedServer.Drift = s.Drift
+		}
+
+		if fieldMask.Firewall {
+			updatedServer.Firewall = s.Firewall
+		}
+
 		if fieldMask.CreateUserId {
 			updatedServer.CreateUserId = s.CreateUserId
 		}
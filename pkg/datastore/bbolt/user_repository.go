@@ -11,6 +11,7 @@ import (
 	"github.com/UnAfraid/searchindex"
 	"go.etcd.io/bbolt"
 
+	"github.com/UnAfraid/wg-ui/pkg/pagination"
 	"github.com/UnAfraid/wg-ui/pkg/user"
 )
 
@@ -65,7 +66,7 @@ func (r *userRepository) FindOne(ctx context.Context, options *user.FindOneOptio
 
 func (r *userRepository) FindAll(ctx context.Context, options *user.FindOptions) ([]*user.User, error) {
 	return dbTx(ctx, r.db, userBucket, false, func(tx *bbolt.Tx, bucket *bbolt.Bucket) ([]*user.User, error) {
-		var users []*user.User
+		window := pagination.NewWindow(options.Pagination, (*user.User).Cursor)
 		var usersCount int
 		var searchList searchindex.SearchList[*user.User]
 		c := bucket.Cursor()
@@ -80,7 +81,7 @@ func (r *userRepository) FindAll(ctx context.Context, options *user.FindOptions)
 			if len(options.Ids) != 0 {
 				optionsLen++
 				if slices.Contains(options.Ids, u.Id) {
-					users = append(users, u)
+					window.Add(u)
 					continue
 				}
 			}
@@ -94,7 +95,7 @@ func (r *userRepository) FindAll(ctx context.Context, options *user.FindOptions)
 			}
 
 			if optionsLen == 0 {
-				users = append(users, u)
+				window.Add(u)
 			}
 		}
 
@@ -105,10 +106,12 @@ func (r *userRepository) FindAll(ctx context.Context, options *user.FindOptions)
 				OutputSize: usersCount,
 				Matching:   searchindex.Beginning,
 			})
-			users = append(users, matches...)
+			for _, match := range matches {
+				window.Add(match)
+			}
 		}
 
-		return users, nil
+		return window.Items(), nil
 	})
 }
 
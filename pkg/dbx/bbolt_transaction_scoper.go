@@ -56,13 +56,15 @@ func InBBoltTransactionScopeWithResult[T any](ctx context.Context, db *bbolt.DB,
 func useOrStartBBoltTransaction(ctx context.Context, db *bbolt.DB) (*bbolt.Tx, func(err error) error, error) {
 	tx, ok := ctx.Value(bboltTxKey).(*bbolt.Tx)
 	if !ok {
-		tx, err := db.Begin(true)
+		// a read only database, such as the snapshot cluster followers serve queries from, only allows read transactions
+		writable := !db.IsReadOnly()
+		tx, err := db.Begin(writable)
 		if err != nil {
 			return nil, nil, err
 		}
 
 		transactionScope := func(err error) error {
-			if err != nil {
+			if err != nil || !writable {
 				if txErr := tx.Rollback(); txErr != nil {
 					err = errors.Join(err, txErr)
 				}
@@ -0,0 +1,72 @@
+package hook
+
+import (
+	"errors"
+	"fmt"
+)
+
+type ActionType string
+
+const (
+	ActionTypeFirewall ActionType = "FIREWALL"
+	ActionTypeHTTP     ActionType = "HTTP"
+	ActionTypeScript   ActionType = "SCRIPT"
+)
+
+// Action is a declarative alternative to a raw shell command,
+// exactly one of the settings matching its type is expected.
+type Action struct {
+	Type     ActionType
+	Firewall *FirewallAction
+	HTTP     *HTTPAction
+	Script   *ScriptAction
+}
+
+func (a *Action) Validate() error {
+	if a == nil {
+		return errors.New("action is required")
+	}
+
+	var settings int
+	for _, set := range []bool{a.Firewall != nil, a.HTTP != nil, a.Script != nil} {
+		if set {
+			settings++
+		}
+	}
+	if settings != 1 {
+		return fmt.Errorf("exactly one action setting is required, got %d", settings)
+	}
+
+	switch a.Type {
+	case ActionTypeFirewall:
+		if a.Firewall == nil {
+			return errors.New("firewall settings are required")
+		}
+		return a.Firewall.Validate()
+	case ActionTypeHTTP:
+		if a.HTTP == nil {
+			return errors.New("http settings are required")
+		}
+		return a.HTTP.Validate()
+	case ActionTypeScript:
+		if a.Script == nil {
+			return errors.New("script settings are required")
+		}
+		return a.Script.Validate()
+	default:
+		return fmt.Errorf("invalid action type: %s", a.Type)
+	}
+}
+
+// String describes the action, it is recorded as the command of its executions.
+func (a *Action) String() string {
+	switch {
+	case a.Firewall != nil:
+		return fmt.Sprintf("%s %s", a.Firewall.Tool.command(), a.Firewall.Rule)
+	case a.HTTP != nil:
+		return fmt.Sprintf("%s %s", a.HTTP.method(), a.HTTP.URL)
+	case a.Script != nil:
+		return fmt.Sprintf("script %s", a.Script.Name)
+	}
+	return string(a.Type)
+}
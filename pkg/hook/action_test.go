@@ -0,0 +1,156 @@
+package hook
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestActionValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		action  *Action
+		wantErr bool
+	}{
+		{name: "firewall", action: &Action{Type: ActionTypeFirewall, Firewall: &FirewallAction{Tool: FirewallToolIptables, Rule: "-A FORWARD -i {{.Interface}} -j ACCEPT"}}},
+		{name: "http", action: &Action{Type: ActionTypeHTTP, HTTP: &HTTPAction{URL: "https://example.com/hook"}}},
+		{name: "script", action: &Action{Type: ActionTypeScript, Script: &ScriptAction{Name: "notify.sh", Args: []string{"{{.Action}}"}}}},
+		{name: "missing settings", action: &Action{Type: ActionTypeFirewall}, wantErr: true},
+		{name: "mismatched settings", action: &Action{Type: ActionTypeHTTP, Script: &ScriptAction{Name: "notify.sh"}}, wantErr: true},
+		{name: "multiple settings", action: &Action{Type: ActionTypeHTTP, HTTP: &HTTPAction{URL: "https://example.com"}, Script: &ScriptAction{Name: "notify.sh"}}, wantErr: true},
+		{name: "multiline rule", action: &Action{Type: ActionTypeFirewall, Firewall: &FirewallAction{Tool: FirewallToolNftables, Rule: "add rule\nflush ruleset"}}, wantErr: true},
+		{name: "invalid rule template", action: &Action{Type: ActionTypeFirewall, Firewall: &FirewallAction{Tool: FirewallToolNftables, Rule: "{{.Interface"}}, wantErr: true},
+		{name: "invalid url scheme", action: &Action{Type: ActionTypeHTTP, HTTP: &HTTPAction{URL: "file:///etc/passwd"}}, wantErr: true},
+		{name: "invalid http method", action: &Action{Type: ActionTypeHTTP, HTTP: &HTTPAction{URL: "https://example.com", Method: "TRACE"}}, wantErr: true},
+		{name: "script path", action: &Action{Type: ActionTypeScript, Script: &ScriptAction{Name: "../bin/sh"}}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.action.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("expected error %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestFirewallActionArgs(t *testing.T) {
+	action := &FirewallAction{
+		Tool: FirewallToolIptables,
+		Rule: "-A FORWARD -i {{ .Interface }} -s {{.AllowedIPs}} -m comment --comment peer-{{ printf \"%s\" .Name }} -j ACCEPT",
+	}
+
+	args, err := action.args(map[string]string{"Interface": "wg0", "AllowedIPs": "10.0.0.2/32; reboot", "Name": "my laptop -j DROP"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := []string{"-A", "FORWARD", "-i", "wg0", "-s", "10.0.0.2/32; reboot", "-m", "comment", "--comment", "peer-my laptop -j DROP", "-j", "ACCEPT"}
+	if !slices.Equal(args, expected) {
+		t.Fatalf("expected args %q, got %q", expected, args)
+	}
+
+	if _, err := action.args(map[string]string{"Interface": "wg0", "Name": "laptop"}); err == nil {
+		t.Fatalf("expected error for a missing variable")
+	}
+}
+
+func TestRunCallsHTTPAction(t *testing.T) {
+	var body httpCallbackBody
+	status := http.StatusOK
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.Header.Get("Content-Type") != "application/json" {
+			t.Errorf("unexpected request %s %s", r.Method, r.Header.Get("Content-Type"))
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Errorf("failed to decode body: %v", err)
+		}
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte("handled"))
+	}))
+	defer srv.Close()
+
+	svc, repository := newTestService(time.Second, 1024, 0)
+	options := &RunOptions{
+		ServerId:    "server",
+		PeerId:      "peer",
+		Action:      "CONNECTED",
+		TypedAction: &Action{Type: ActionTypeHTTP, HTTP: &HTTPAction{URL: srv.URL}},
+		Variables:   map[string]string{"Name": "alpha"},
+	}
+
+	execution := runAndWait(t, svc, repository, options)[0]
+	if execution.ExitCode != http.StatusOK || execution.Output != "handled" {
+		t.Fatalf("expected status 200 and output %q, got %d %q", "handled", execution.ExitCode, execution.Output)
+	}
+	if execution.Command != "POST "+srv.URL {
+		t.Fatalf("unexpected execution command %q", execution.Command)
+	}
+	if body.ServerId != "server" || body.PeerId != "peer" || body.Action != "CONNECTED" || body.Variables["Name"] != "alpha" {
+		t.Fatalf("unexpected callback body %+v", body)
+	}
+
+	status = http.StatusBadGateway
+	execution = runAndWait(t, svc, repository, options)[1]
+	if execution.Succeeded() {
+		t.Fatalf("expected error for a failed callback")
+	}
+	if execution.ExitCode != http.StatusBadGateway {
+		t.Fatalf("expected exit code 502, got %d", execution.ExitCode)
+	}
+}
+
+func TestRunRejectsRawCommandsWhenDisabled(t *testing.T) {
+	svc := NewService(&memoryRepository{}, noopTransactionScoper{}, time.Second, 1024, 0, Policy{})
+
+	if err := svc.CheckHook(nil); !errors.Is(err, ErrRawCommandsDisabled) {
+		t.Fatalf("expected %v, got %v", ErrRawCommandsDisabled, err)
+	}
+
+	if err := svc.Run(context.Background(), shellOptions("true")); !errors.Is(err, ErrRawCommandsDisabled) {
+		t.Fatalf("expected %v, got %v", ErrRawCommandsDisabled, err)
+	}
+}
+
+func TestRunScriptAction(t *testing.T) {
+	directory := t.TempDir()
+	script := "#!/bin/sh\necho \"$1 $WG_SERVER\"\n"
+	if err := os.WriteFile(filepath.Join(directory, "notify.sh"), []byte(script), 0o755); err != nil {
+		t.Fatalf("failed to write script: %v", err)
+	}
+
+	outside := filepath.Join(t.TempDir(), "outside.sh")
+	if err := os.WriteFile(outside, []byte(script), 0o755); err != nil {
+		t.Fatalf("failed to write script: %v", err)
+	}
+	if err := os.Symlink(outside, filepath.Join(directory, "linked.sh")); err != nil {
+		t.Fatalf("failed to create symlink: %v", err)
+	}
+
+	svc, repository := newTestService(time.Second, 1024, 0)
+	svc.policy.ScriptsDirectory = directory
+
+	options := &RunOptions{
+		ServerId:    "server",
+		Action:      "POST_UP",
+		TypedAction: &Action{Type: ActionTypeScript, Script: &ScriptAction{Name: "notify.sh", Args: []string{"{{.Action}}"}}},
+		Variables:   map[string]string{"Action": "POST_UP"},
+		Env:         []string{"WG_SERVER=wg0"},
+	}
+	execution := runAndWait(t, svc, repository, options)[0]
+	if strings.TrimSpace(execution.Output) != "POST_UP wg0" {
+		t.Fatalf("unexpected script output %q", execution.Output)
+	}
+
+	if err := svc.CheckHook(&Action{Type: ActionTypeScript, Script: &ScriptAction{Name: "linked.sh"}}); err == nil {
+		t.Fatalf("expected error for a script linked outside of the hooks directory")
+	}
+}
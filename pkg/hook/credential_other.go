@@ -0,0 +1,15 @@
+//go:build !unix
+
+package hook
+
+import (
+	"errors"
+	"syscall"
+)
+
+func (p Policy) sysProcAttr() (*syscall.SysProcAttr, error) {
+	if p.User == "" && p.Group == "" {
+		return nil, nil
+	}
+	return nil, errors.New("running hooks as another user is not supported on this platform")
+}
@@ -0,0 +1,61 @@
+//go:build unix
+
+package hook
+
+import (
+	"fmt"
+	"os"
+	"os/user"
+	"strconv"
+	"syscall"
+)
+
+// sysProcAttr returns the process attributes that drop the privileges of script actions to the policy user and group.
+func (p Policy) sysProcAttr() (*syscall.SysProcAttr, error) {
+	if p.User == "" && p.Group == "" {
+		return nil, nil
+	}
+
+	credential := &syscall.Credential{
+		Uid:         uint32(os.Getuid()),
+		Gid:         uint32(os.Getgid()),
+		NoSetGroups: true,
+	}
+
+	if p.User != "" {
+		u, err := user.Lookup(p.User)
+		if err != nil {
+			if u, err = user.LookupId(p.User); err != nil {
+				return nil, fmt.Errorf("failed to find hook user %s: %w", p.User, err)
+			}
+		}
+
+		uid, err := strconv.ParseUint(u.Uid, 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("invalid uid of hook user %s: %w", p.User, err)
+		}
+		gid, err := strconv.ParseUint(u.Gid, 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("invalid gid of hook user %s: %w", p.User, err)
+		}
+		credential.Uid = uint32(uid)
+		credential.Gid = uint32(gid)
+	}
+
+	if p.Group != "" {
+		g, err := user.LookupGroup(p.Group)
+		if err != nil {
+			if g, err = user.LookupGroupId(p.Group); err != nil {
+				return nil, fmt.Errorf("failed to find hook group %s: %w", p.Group, err)
+			}
+		}
+
+		gid, err := strconv.ParseUint(g.Gid, 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("invalid gid of hook group %s: %w", p.Group, err)
+		}
+		credential.Gid = uint32(gid)
+	}
+
+	return &syscall.SysProcAttr{Credential: credential}, nil
+}
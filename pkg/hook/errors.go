@@ -0,0 +1,18 @@
+package hook
+
+import (
+	"errors"
+)
+
+var (
+	ErrRunOptionsRequired            = errors.New("run options are required")
+	ErrFindOptionsRequired           = errors.New("find options are required")
+	ErrCommandRequired               = errors.New("hook command is required")
+	ErrTargetRequired                = errors.New("server id or peer id is required")
+	ErrInvalidTimeout                = errors.New("hook timeout must not be negative")
+	ErrInvalidRetryPolicy            = errors.New("hook retry attempts and delay must not be negative")
+	ErrInvalidLimit                  = errors.New("limit must not be negative")
+	ErrRawCommandsDisabled           = errors.New("raw hook commands are disabled, use a typed hook action instead")
+	ErrScriptsDirectoryNotConfigured = errors.New("hooks directory is not configured")
+	ErrServiceClosed                 = errors.New("hook service is closed")
+)
@@ -0,0 +1,25 @@
+package hook
+
+import (
+	"time"
+)
+
+// Execution is a single attempt of running a hook command.
+type Execution struct {
+	Id              string
+	ServerId        string
+	PeerId          string
+	Command         string
+	Action          string
+	Attempt         int
+	ExitCode        int
+	Error           string
+	Output          string
+	OutputTruncated bool
+	Duration        time.Duration
+	StartedAt       time.Time
+}
+
+func (e *Execution) Succeeded() bool {
+	return e.Error == ""
+}
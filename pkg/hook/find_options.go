@@ -0,0 +1,33 @@
+package hook
+
+type FindOptions struct {
+	ServerId *string
+	// PeerId selects the executions of a peer, an empty id selects the executions of server hooks only.
+	PeerId *string
+	Action *string
+	// Limit of returned executions, newest first, zero returns all.
+	Limit int
+}
+
+func (o *FindOptions) Validate() error {
+	if o == nil {
+		return ErrFindOptionsRequired
+	}
+	if o.Limit < 0 {
+		return ErrInvalidLimit
+	}
+	return nil
+}
+
+func (o *FindOptions) Matches(execution *Execution) bool {
+	if o.ServerId != nil && execution.ServerId != *o.ServerId {
+		return false
+	}
+	if o.PeerId != nil && execution.PeerId != *o.PeerId {
+		return false
+	}
+	if o.Action != nil && execution.Action != *o.Action {
+		return false
+	}
+	return true
+}
@@ -0,0 +1,114 @@
+package hook
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"text/template"
+)
+
+type FirewallTool string
+
+const (
+	FirewallToolIptables  FirewallTool = "IPTABLES"
+	FirewallToolIp6tables FirewallTool = "IP6TABLES"
+	FirewallToolNftables  FirewallTool = "NFTABLES"
+)
+
+func (t FirewallTool) command() string {
+	switch t {
+	case FirewallToolIptables:
+		return "iptables"
+	case FirewallToolIp6tables:
+		return "ip6tables"
+	case FirewallToolNftables:
+		return "nft"
+	}
+	return ""
+}
+
+// FirewallAction runs a single firewall rule, the rule is split on whitespace outside of template actions
+// and every argument is rendered with the hook variables on its own, so variables never add arguments.
+// No shell is involved.
+type FirewallAction struct {
+	Tool FirewallTool
+	Rule string
+}
+
+func (a *FirewallAction) Validate() error {
+	if a.Tool.command() == "" {
+		return fmt.Errorf("invalid firewall tool: %s", a.Tool)
+	}
+	if strings.TrimSpace(a.Rule) == "" {
+		return errors.New("firewall rule is required")
+	}
+	if strings.ContainsAny(a.Rule, "\r\n") {
+		return errors.New("multiline firewall rules are not supported")
+	}
+	for i, token := range splitRule(a.Rule) {
+		if _, err := parseTemplate(token); err != nil {
+			return fmt.Errorf("invalid firewall rule argument #%d template: %w", i+1, err)
+		}
+	}
+	return nil
+}
+
+func (a *FirewallAction) args(variables map[string]string) ([]string, error) {
+	tokens := splitRule(a.Rule)
+	args := make([]string, 0, len(tokens))
+	for _, token := range tokens {
+		rendered, err := renderTemplate(token, variables)
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, rendered)
+	}
+	return args, nil
+}
+
+// splitRule splits the rule on whitespace, whitespace inside {{ }} template actions is kept.
+func splitRule(rule string) []string {
+	var tokens []string
+	var token strings.Builder
+	var inAction bool
+	for i := 0; i < len(rule); i++ {
+		switch {
+		case strings.HasPrefix(rule[i:], "{{"):
+			inAction = true
+			token.WriteString("{{")
+			i++
+		case strings.HasPrefix(rule[i:], "}}") && inAction:
+			inAction = false
+			token.WriteString("}}")
+			i++
+		case !inAction && (rule[i] == ' ' || rule[i] == '\t'):
+			if token.Len() != 0 {
+				tokens = append(tokens, token.String())
+				token.Reset()
+			}
+		default:
+			token.WriteByte(rule[i])
+		}
+	}
+	if token.Len() != 0 {
+		tokens = append(tokens, token.String())
+	}
+	return tokens
+}
+
+func parseTemplate(text string) (*template.Template, error) {
+	return template.New("hook").Option("missingkey=error").Parse(text)
+}
+
+func renderTemplate(text string, variables map[string]string) (string, error) {
+	tmpl, err := parseTemplate(text)
+	if err != nil {
+		return "", err
+	}
+
+	var sb strings.Builder
+	if err := tmpl.Execute(&sb, variables); err != nil {
+		return "", err
+	}
+	return sb.String(), nil
+}
@@ -0,0 +1,48 @@
+package hook
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+)
+
+var allowedHTTPMethods = []string{http.MethodGet, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete}
+
+// HTTPAction calls a URL with a JSON body describing the hook event.
+type HTTPAction struct {
+	URL string
+	// Method of the request, POST when empty.
+	Method string
+}
+
+func (a *HTTPAction) Validate() error {
+	if strings.TrimSpace(a.URL) == "" {
+		return errors.New("url is required")
+	}
+
+	u, err := url.Parse(a.URL)
+	if err != nil {
+		return fmt.Errorf("invalid url: %w", err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("invalid url scheme: %s, expected http or https", u.Scheme)
+	}
+	if u.Host == "" {
+		return errors.New("url host is required")
+	}
+
+	if !slices.Contains(allowedHTTPMethods, a.method()) {
+		return fmt.Errorf("invalid http method: %s", a.Method)
+	}
+	return nil
+}
+
+func (a *HTTPAction) method() string {
+	if a.Method == "" {
+		return http.MethodPost
+	}
+	return strings.ToUpper(a.Method)
+}
@@ -0,0 +1,66 @@
+package hook
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// Policy restricts what hooks are allowed to run.
+type Policy struct {
+	// RawCommandsEnabled allows hooks with free-form shell commands, typed actions are always allowed.
+	RawCommandsEnabled bool
+	// ScriptsDirectory is the only directory script actions may run executables from.
+	ScriptsDirectory string
+	// User and Group script actions are executed as, the wg-ui process user and group are kept when empty.
+	// Firewall actions keep the process privileges since changing the rules requires them, their arguments
+	// are rendered one by one so hook variables cannot add arguments to the firewall tool.
+	User  string
+	Group string
+}
+
+// Check reports whether a hook with the given raw command or typed action is allowed by the policy.
+func (p Policy) Check(action *Action) error {
+	if action == nil {
+		if !p.RawCommandsEnabled {
+			return ErrRawCommandsDisabled
+		}
+		return nil
+	}
+
+	if action.Script != nil {
+		if _, err := p.scriptPath(action.Script.Name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// scriptPath resolves the script inside the scripts directory, symbolic links pointing outside of it are rejected.
+func (p Policy) scriptPath(name string) (string, error) {
+	if p.ScriptsDirectory == "" {
+		return "", ErrScriptsDirectoryNotConfigured
+	}
+
+	directory, err := filepath.EvalSymlinks(p.ScriptsDirectory)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve hooks directory: %w", err)
+	}
+
+	path, err := filepath.EvalSymlinks(filepath.Join(directory, name))
+	if err != nil {
+		return "", fmt.Errorf("script %s not found: %w", name, err)
+	}
+	if filepath.Dir(path) != directory {
+		return "", fmt.Errorf("script %s resolves outside of the hooks directory", name)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", fmt.Errorf("script %s not found: %w", name, err)
+	}
+	if !info.Mode().IsRegular() || info.Mode().Perm()&0o111 == 0 {
+		return "", fmt.Errorf("script %s is not an executable file", name)
+	}
+	return path, nil
+}
@@ -0,0 +1,11 @@
+package hook
+
+import (
+	"context"
+)
+
+type Repository interface {
+	FindAll(ctx context.Context, options *FindOptions) ([]*Execution, error)
+	Create(ctx context.Context, execution *Execution) (*Execution, error)
+	Delete(ctx context.Context, executionId string) error
+}
@@ -0,0 +1,19 @@
+package hook
+
+import (
+	"time"
+)
+
+// RetryPolicy controls how many times a failed hook is attempted and how long to wait between attempts,
+// the attempts after the first one run in the background.
+type RetryPolicy struct {
+	MaxAttempts int
+	Delay       time.Duration
+}
+
+func (p RetryPolicy) attempts() int {
+	if p.MaxAttempts < 1 {
+		return 1
+	}
+	return p.MaxAttempts
+}
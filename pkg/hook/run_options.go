@@ -0,0 +1,56 @@
+package hook
+
+import (
+	"time"
+)
+
+type RunOptions struct {
+	ServerId string
+	PeerId   string
+	Action   string
+	// Command is the raw hook command as configured, it is recorded with each execution.
+	Command string
+	Path    string
+	Args    []string
+	// TypedAction is run instead of the raw command when set.
+	TypedAction *Action
+	// Variables are available to the templates of typed actions and sent with HTTP callbacks.
+	Variables map[string]string
+	// Env is passed to raw commands, typed actions get it on top of a minimal PATH.
+	Env []string
+	// InheritEnvironment passes the wg-ui process environment to raw commands as well.
+	InheritEnvironment bool
+	// Timeout of a single attempt, the service default is used when zero.
+	Timeout time.Duration
+	Retry   RetryPolicy
+}
+
+func (o *RunOptions) Validate() error {
+	if o == nil {
+		return ErrRunOptionsRequired
+	}
+	if o.TypedAction != nil {
+		if err := o.TypedAction.Validate(); err != nil {
+			return err
+		}
+	} else if o.Path == "" {
+		return ErrCommandRequired
+	}
+	if o.ServerId == "" && o.PeerId == "" {
+		return ErrTargetRequired
+	}
+	if o.Timeout < 0 {
+		return ErrInvalidTimeout
+	}
+	if o.Retry.MaxAttempts < 0 || o.Retry.Delay < 0 {
+		return ErrInvalidRetryPolicy
+	}
+	return nil
+}
+
+func (o *RunOptions) description() string {
+	if o.TypedAction != nil {
+		return o.TypedAction.String()
+	}
+	return o.Command
+}
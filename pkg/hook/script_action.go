@@ -0,0 +1,43 @@
+package hook
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// ScriptAction runs an executable from the configured hooks directory,
+// arguments are text/templates rendered with the hook variables.
+type ScriptAction struct {
+	Name string
+	Args []string
+}
+
+func (a *ScriptAction) Validate() error {
+	if strings.TrimSpace(a.Name) == "" {
+		return errors.New("script name is required")
+	}
+	if a.Name != filepath.Base(a.Name) || a.Name == "." || a.Name == ".." || strings.ContainsRune(a.Name, '\\') {
+		return fmt.Errorf("invalid script name: %s, expected a file name inside the hooks directory", a.Name)
+	}
+
+	for i, arg := range a.Args {
+		if _, err := parseTemplate(arg); err != nil {
+			return fmt.Errorf("invalid script argument #%d template: %w", i+1, err)
+		}
+	}
+	return nil
+}
+
+func (a *ScriptAction) args(variables map[string]string) ([]string, error) {
+	args := make([]string, 0, len(a.Args))
+	for _, arg := range a.Args {
+		rendered, err := renderTemplate(arg, variables)
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, rendered)
+	}
+	return args, nil
+}
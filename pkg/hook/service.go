@@ -0,0 +1,380 @@
+package hook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+
+	"github.com/UnAfraid/wg-ui/pkg/dbx"
+)
+
+const (
+	// waitDelay bounds how long a timed out hook may keep its output pipes open, for example through child processes.
+	waitDelay = time.Second
+	// restrictedPath is the only inherited setting of typed actions, the rest of their environment comes from the hook.
+	restrictedPath = "PATH=/usr/local/sbin:/usr/local/bin:/usr/sbin:/usr/bin:/sbin:/bin"
+)
+
+type Service interface {
+	// Run validates the hook and queues it for execution, the error only reports an invalid or disallowed hook.
+	Run(ctx context.Context, options *RunOptions) error
+	FindExecutions(ctx context.Context, options *FindOptions) ([]*Execution, error)
+	DeleteExecutions(ctx context.Context, options *FindOptions) error
+	CheckHook(action *Action) error
+	// Close stops the retries that are waiting for their delay and waits for the queued attempts.
+	Close()
+}
+
+type service struct {
+	repository        Repository
+	transactionScoper dbx.TransactionScoper
+	timeout           time.Duration
+	outputLimit       int
+	historyLimit      int
+	policy            Policy
+	httpClient        *http.Client
+	retryCtx          context.Context
+	cancelRetries     context.CancelFunc
+	queueLock         sync.Mutex
+	queues            map[string][]*RunOptions
+	closed            bool
+	running           sync.WaitGroup
+}
+
+func NewService(repository Repository, transactionScoper dbx.TransactionScoper, timeout time.Duration, outputLimit int, historyLimit int, policy Policy) Service {
+	retryCtx, cancelRetries := context.WithCancel(context.Background())
+	return &service{
+		repository:        repository,
+		transactionScoper: transactionScoper,
+		timeout:           timeout,
+		outputLimit:       outputLimit,
+		historyLimit:      historyLimit,
+		policy:            policy,
+		httpClient:        &http.Client{},
+		retryCtx:          retryCtx,
+		cancelRetries:     cancelRetries,
+		queues:            make(map[string][]*RunOptions),
+	}
+}
+
+// Run queues the hook, callers run hooks within their write transaction and bbolt allows a single writer,
+// so the hooks run in the background and every attempt is recorded in its own transaction once the caller
+// committed. The hooks of the same server or peer run one at a time, in the order they were queued.
+func (s *service) Run(_ context.Context, options *RunOptions) error {
+	if err := options.Validate(); err != nil {
+		return err
+	}
+	if err := s.policy.Check(options.TypedAction); err != nil {
+		return err
+	}
+
+	key := options.ServerId + "/" + options.PeerId
+
+	s.queueLock.Lock()
+	defer s.queueLock.Unlock()
+
+	if s.closed {
+		return ErrServiceClosed
+	}
+
+	queue := s.queues[key]
+	s.queues[key] = append(queue, options)
+	if len(queue) == 0 {
+		s.running.Add(1)
+		go s.drain(key)
+	}
+	return nil
+}
+
+func (s *service) Close() {
+	s.queueLock.Lock()
+	s.closed = true
+	s.queueLock.Unlock()
+
+	s.cancelRetries()
+	s.running.Wait()
+}
+
+// drain runs the queued hooks of a server or peer until its queue is empty.
+func (s *service) drain(key string) {
+	defer s.running.Done()
+
+	for {
+		s.queueLock.Lock()
+		options := s.queues[key][0]
+		s.queueLock.Unlock()
+
+		s.runAttempts(options)
+
+		s.queueLock.Lock()
+		queue := s.queues[key][1:]
+		if len(queue) == 0 {
+			delete(s.queues, key)
+			s.queueLock.Unlock()
+			return
+		}
+		s.queues[key] = queue
+		s.queueLock.Unlock()
+	}
+}
+
+// runAttempts runs the attempts of the retry policy until one succeeds, the retries waiting for their delay
+// are stopped when the service is closed.
+func (s *service) runAttempts(options *RunOptions) {
+	attempts := options.Retry.attempts()
+
+	var execution *Execution
+	for attempt := 1; attempt <= attempts; attempt++ {
+		if attempt > 1 {
+			select {
+			case <-s.retryCtx.Done():
+				return
+			case <-time.After(options.Retry.Delay):
+			}
+		}
+
+		execution = s.attempt(context.Background(), options, attempt)
+		if execution.Succeeded() {
+			return
+		}
+	}
+
+	logrus.
+		WithField("command", options.description()).
+		WithField("action", options.Action).
+		WithField("error", execution.Error).
+		Warnf("hook failed after %d attempt(s)", attempts)
+}
+
+func (s *service) attempt(ctx context.Context, options *RunOptions, attempt int) *Execution {
+	execution := s.execute(ctx, options, attempt)
+	if err := s.record(ctx, execution); err != nil {
+		logrus.
+			WithError(err).
+			WithField("command", options.description()).
+			WithField("action", options.Action).
+			Warn("failed to record hook execution")
+	}
+	return execution
+}
+
+func (s *service) FindExecutions(ctx context.Context, options *FindOptions) ([]*Execution, error) {
+	if err := options.Validate(); err != nil {
+		return nil, err
+	}
+	return s.repository.FindAll(ctx, options)
+}
+
+func (s *service) DeleteExecutions(ctx context.Context, options *FindOptions) error {
+	if err := options.Validate(); err != nil {
+		return err
+	}
+
+	return s.transactionScoper.InTransactionScope(ctx, func(ctx context.Context) error {
+		executions, err := s.repository.FindAll(ctx, options)
+		if err != nil {
+			return err
+		}
+
+		for _, execution := range executions {
+			if err := s.repository.Delete(ctx, execution.Id); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// CheckHook reports whether the policy allows a hook with the typed action, or a raw command when the action is nil.
+func (s *service) CheckHook(action *Action) error {
+	return s.policy.Check(action)
+}
+
+func (s *service) execute(ctx context.Context, options *RunOptions, attempt int) *Execution {
+	timeout := options.Timeout
+	if timeout == 0 {
+		timeout = s.timeout
+	}
+
+	runCtx := ctx
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		runCtx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	output := newLimitedBuffer(s.outputLimit)
+	startedAt := time.Now()
+	exitCode, err := s.dispatch(runCtx, options, output)
+
+	execution := &Execution{
+		ServerId:  options.ServerId,
+		PeerId:    options.PeerId,
+		Command:   options.description(),
+		Action:    options.Action,
+		Attempt:   attempt,
+		ExitCode:  exitCode,
+		Duration:  time.Since(startedAt),
+		StartedAt: startedAt,
+	}
+
+	if err != nil {
+		if errors.Is(runCtx.Err(), context.DeadlineExceeded) && ctx.Err() == nil {
+			execution.Error = fmt.Sprintf("timed out after %s", timeout)
+		} else {
+			execution.Error = err.Error()
+		}
+	}
+
+	execution.Output = output.String()
+	execution.OutputTruncated = output.Truncated()
+	return execution
+}
+
+func (s *service) dispatch(ctx context.Context, options *RunOptions, output *limitedBuffer) (int, error) {
+	action := options.TypedAction
+	switch {
+	case action == nil:
+		env := options.Env
+		if options.InheritEnvironment {
+			env = append(os.Environ(), options.Env...)
+		}
+		return runProcess(ctx, options.Path, options.Args, env, nil, output)
+	case action.Firewall != nil:
+		args, err := action.Firewall.args(options.Variables)
+		if err != nil {
+			return -1, fmt.Errorf("failed to render firewall rule: %w", err)
+		}
+		return runProcess(ctx, action.Firewall.Tool.command(), args, restrictedEnv(options.Env), nil, output)
+	case action.HTTP != nil:
+		return s.callHTTP(ctx, action.HTTP, options, output)
+	case action.Script != nil:
+		path, err := s.policy.scriptPath(action.Script.Name)
+		if err != nil {
+			return -1, err
+		}
+
+		args, err := action.Script.args(options.Variables)
+		if err != nil {
+			return -1, fmt.Errorf("failed to render script arguments: %w", err)
+		}
+
+		sysProcAttr, err := s.policy.sysProcAttr()
+		if err != nil {
+			return -1, err
+		}
+		return runProcess(ctx, path, args, restrictedEnv(options.Env), sysProcAttr, output)
+	}
+	return -1, fmt.Errorf("unsupported hook action: %s", action.Type)
+}
+
+// runProcess runs the executable without a shell and returns its exit code, -1 when it could not be started or was killed.
+func runProcess(ctx context.Context, path string, args []string, env []string, sysProcAttr *syscall.SysProcAttr, output *limitedBuffer) (int, error) {
+	cmd := exec.CommandContext(ctx, path, args...)
+	cmd.Env = env
+	cmd.Stdout = output
+	cmd.Stderr = output
+	cmd.SysProcAttr = sysProcAttr
+	cmd.WaitDelay = waitDelay
+
+	if err := cmd.Run(); err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return exitErr.ExitCode(), err
+		}
+		return -1, err
+	}
+	return 0, nil
+}
+
+// callHTTP sends the hook event as JSON and returns the response status code, the response body is captured as output.
+func (s *service) callHTTP(ctx context.Context, action *HTTPAction, options *RunOptions, output *limitedBuffer) (int, error) {
+	body, err := json.Marshal(httpCallbackBody{
+		ServerId:  options.ServerId,
+		PeerId:    options.PeerId,
+		Action:    options.Action,
+		Variables: options.Variables,
+	})
+	if err != nil {
+		return -1, fmt.Errorf("failed to marshal http callback body: %w", err)
+	}
+
+	request, err := http.NewRequestWithContext(ctx, action.method(), action.URL, bytes.NewReader(body))
+	if err != nil {
+		return -1, fmt.Errorf("failed to create http callback request: %w", err)
+	}
+	request.Header.Set("Content-Type", "application/json")
+
+	response, err := s.httpClient.Do(request)
+	if err != nil {
+		return -1, err
+	}
+	defer response.Body.Close()
+
+	if _, err := io.Copy(output, io.LimitReader(response.Body, int64(s.outputLimit)+1)); err != nil {
+		return response.StatusCode, fmt.Errorf("failed to read http callback response: %w", err)
+	}
+
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		return response.StatusCode, fmt.Errorf("unexpected http callback status: %s", response.Status)
+	}
+	return response.StatusCode, nil
+}
+
+type httpCallbackBody struct {
+	ServerId  string            `json:"serverId"`
+	PeerId    string            `json:"peerId,omitempty"`
+	Action    string            `json:"action"`
+	Variables map[string]string `json:"variables"`
+}
+
+func restrictedEnv(env []string) []string {
+	return append([]string{restrictedPath}, env...)
+}
+
+// record stores the execution and drops the oldest executions of the same server or peer above the history limit,
+// the context must not carry a transaction of a caller, so the record is not rolled back along with it.
+func (s *service) record(ctx context.Context, execution *Execution) error {
+	id, err := uuid.NewRandom()
+	if err != nil {
+		return fmt.Errorf("failed to generate new id: %w", err)
+	}
+	execution.Id = id.String()
+
+	return s.transactionScoper.InTransactionScope(ctx, func(ctx context.Context) error {
+		if _, err := s.repository.Create(ctx, execution); err != nil {
+			return err
+		}
+
+		if s.historyLimit <= 0 {
+			return nil
+		}
+
+		executions, err := s.repository.FindAll(ctx, &FindOptions{
+			ServerId: &execution.ServerId,
+			PeerId:   &execution.PeerId,
+		})
+		if err != nil {
+			return err
+		}
+
+		for i := s.historyLimit; i < len(executions); i++ {
+			if err := s.repository.Delete(ctx, executions[i].Id); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
@@ -0,0 +1,255 @@
+package hook
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+type memoryRepository struct {
+	mu         sync.Mutex
+	executions []*Execution
+}
+
+func (r *memoryRepository) FindAll(_ context.Context, options *FindOptions) ([]*Execution, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var executions []*Execution
+	for _, execution := range r.executions {
+		if options.Matches(execution) {
+			executions = append(executions, execution)
+		}
+	}
+	slices.SortStableFunc(executions, func(a *Execution, b *Execution) int {
+		return b.StartedAt.Compare(a.StartedAt)
+	})
+	return executions, nil
+}
+
+func (r *memoryRepository) Create(_ context.Context, execution *Execution) (*Execution, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.executions = append(r.executions, execution)
+	return execution, nil
+}
+
+func (r *memoryRepository) Delete(_ context.Context, executionId string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.executions = slices.DeleteFunc(r.executions, func(execution *Execution) bool {
+		return execution.Id == executionId
+	})
+	return nil
+}
+
+type noopTransactionScoper struct{}
+
+func (noopTransactionScoper) InTransactionScope(ctx context.Context, transactionScope func(ctx context.Context) error) error {
+	return transactionScope(ctx)
+}
+
+func newTestService(timeout time.Duration, outputLimit int, historyLimit int) (*service, *memoryRepository) {
+	repository := &memoryRepository{}
+	return NewService(repository, noopTransactionScoper{}, timeout, outputLimit, historyLimit, Policy{RawCommandsEnabled: true}).(*service), repository
+}
+
+func shellOptions(script string) *RunOptions {
+	return &RunOptions{
+		ServerId: "server",
+		Action:   "POST_UP",
+		Command:  script,
+		Path:     "sh",
+		Args:     []string{"-c", script},
+	}
+}
+
+// runAndWait queues the hook and returns its executions once the queued hooks are done.
+func runAndWait(t *testing.T, svc *service, repository *memoryRepository, options *RunOptions) []*Execution {
+	t.Helper()
+
+	if err := svc.Run(context.Background(), options); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	svc.running.Wait()
+
+	repository.mu.Lock()
+	defer repository.mu.Unlock()
+	return slices.Clone(repository.executions)
+}
+
+func TestRunCapturesOutputAndExitCode(t *testing.T) {
+	svc, repository := newTestService(time.Second, 1024, 0)
+
+	executions := runAndWait(t, svc, repository, shellOptions("echo out; echo err >&2; exit 3"))
+	if len(executions) != 1 {
+		t.Fatalf("expected 1 recorded execution, got %d", len(executions))
+	}
+	execution := executions[0]
+	if execution.Succeeded() || execution.ExitCode != 3 {
+		t.Fatalf("expected a failed execution with exit code 3, got %+v", execution)
+	}
+	if !strings.Contains(execution.Output, "out") || !strings.Contains(execution.Output, "err") {
+		t.Fatalf("expected stdout and stderr in output, got %q", execution.Output)
+	}
+}
+
+func TestRunTruncatesOutput(t *testing.T) {
+	svc, repository := newTestService(time.Second, 4, 0)
+
+	executions := runAndWait(t, svc, repository, shellOptions("printf 0123456789"))
+	if len(executions) != 1 || !executions[0].Succeeded() {
+		t.Fatalf("expected a successful execution, got %+v", executions)
+	}
+	if execution := executions[0]; execution.Output != "0123" || !execution.OutputTruncated {
+		t.Fatalf("expected truncated output %q, got %q truncated=%v", "0123", execution.Output, execution.OutputTruncated)
+	}
+}
+
+func TestRunEnforcesTimeout(t *testing.T) {
+	svc, repository := newTestService(time.Minute, 1024, 0)
+
+	options := shellOptions("sleep 5")
+	options.Timeout = 100 * time.Millisecond
+
+	started := time.Now()
+	executions := runAndWait(t, svc, repository, options)
+	if elapsed := time.Since(started); elapsed > 3*time.Second {
+		t.Fatalf("expected hook to be stopped by the timeout, took %s", elapsed)
+	}
+	if len(executions) != 1 || !strings.HasPrefix(executions[0].Error, "timed out after") {
+		t.Fatalf("expected timeout error, got %+v", executions)
+	}
+}
+
+func TestRunDoesNotWaitForTheHook(t *testing.T) {
+	svc, repository := newTestService(time.Minute, 1024, 0)
+
+	started := time.Now()
+	if err := svc.Run(context.Background(), shellOptions("sleep 1")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if elapsed := time.Since(started); elapsed > 500*time.Millisecond {
+		t.Fatalf("expected Run to return before the hook is done, took %s", elapsed)
+	}
+
+	svc.running.Wait()
+	if len(repository.executions) != 1 {
+		t.Fatalf("expected 1 recorded execution, got %d", len(repository.executions))
+	}
+}
+
+type callerTxKey struct{}
+
+// txTrackingScoper fails transactions joined from a context of the caller, it stands in for a transaction
+// that would be rolled back along with the one of the caller.
+type txTrackingScoper struct{}
+
+func (txTrackingScoper) InTransactionScope(ctx context.Context, transactionScope func(ctx context.Context) error) error {
+	if ctx.Value(callerTxKey{}) != nil {
+		return errors.New("joined the transaction of the caller")
+	}
+	return transactionScope(ctx)
+}
+
+func TestRunRecordsOutsideTheCallerTransaction(t *testing.T) {
+	repository := &memoryRepository{}
+	svc := NewService(repository, txTrackingScoper{}, time.Second, 1024, 0, Policy{RawCommandsEnabled: true}).(*service)
+
+	callerCtx := context.WithValue(context.Background(), callerTxKey{}, true)
+	if err := svc.Run(callerCtx, shellOptions("exit 1")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	svc.running.Wait()
+
+	if len(repository.executions) != 1 {
+		t.Fatalf("expected the failed execution to be recorded in its own transaction, got %d", len(repository.executions))
+	}
+}
+
+func TestRunKeepsTheOrderPerTarget(t *testing.T) {
+	svc, repository := newTestService(time.Second, 1024, 0)
+
+	output := t.TempDir() + "/order"
+	for _, step := range []string{"first", "second", "third"} {
+		options := shellOptions("sleep 0.05; echo " + step + " >> " + output)
+		options.Command = step
+		if err := svc.Run(context.Background(), options); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	svc.running.Wait()
+
+	var commands []string
+	for _, execution := range repository.executions {
+		commands = append(commands, execution.Command)
+	}
+	if !slices.Equal(commands, []string{"first", "second", "third"}) {
+		t.Fatalf("expected the hooks to run in order, got %v", commands)
+	}
+}
+
+func TestRunRetriesUntilSuccess(t *testing.T) {
+	svc, repository := newTestService(time.Second, 1024, 0)
+
+	marker := t.TempDir() + "/attempted"
+	options := shellOptions("if [ -f " + marker + " ]; then exit 0; fi; touch " + marker + "; exit 1")
+	options.Retry = RetryPolicy{MaxAttempts: 3, Delay: 10 * time.Millisecond}
+
+	executions := runAndWait(t, svc, repository, options)
+	if len(executions) != 2 {
+		t.Fatalf("expected 2 recorded executions, got %d", len(executions))
+	}
+	if first := executions[0]; first.Attempt != 1 || first.Succeeded() {
+		t.Fatalf("expected the first attempt to fail, got %+v", first)
+	}
+	if retried := executions[1]; retried.Attempt != 2 || !retried.Succeeded() {
+		t.Fatalf("expected success on attempt 2, got %+v", retried)
+	}
+}
+
+func TestCloseStopsWaitingRetries(t *testing.T) {
+	svc, repository := newTestService(time.Second, 1024, 0)
+
+	options := shellOptions("exit 1")
+	options.Retry = RetryPolicy{MaxAttempts: 3, Delay: time.Hour}
+
+	if err := svc.Run(context.Background(), options); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	svc.Close()
+	if len(repository.executions) != 1 {
+		t.Fatalf("expected only the first attempt to be recorded, got %d", len(repository.executions))
+	}
+	if err := svc.Run(context.Background(), shellOptions("true")); !errors.Is(err, ErrServiceClosed) {
+		t.Fatalf("expected %v after Close, got %v", ErrServiceClosed, err)
+	}
+}
+
+func TestRunKeepsHistoryLimitPerTarget(t *testing.T) {
+	svc, repository := newTestService(time.Second, 1024, 2)
+
+	peerOptions := shellOptions("true")
+	peerOptions.PeerId = "peer"
+	runAndWait(t, svc, repository, peerOptions)
+
+	for i := 0; i < 3; i++ {
+		runAndWait(t, svc, repository, shellOptions("true"))
+	}
+
+	serverExecutions, _ := repository.FindAll(context.Background(), &FindOptions{PeerId: new(string)})
+	if len(serverExecutions) != 2 {
+		t.Fatalf("expected 2 server executions, got %d", len(serverExecutions))
+	}
+
+	peerId := "peer"
+	peerExecutions, _ := repository.FindAll(context.Background(), &FindOptions{PeerId: &peerId})
+	if len(peerExecutions) != 1 {
+		t.Fatalf("expected the peer execution to be kept, got %d", len(peerExecutions))
+	}
+}
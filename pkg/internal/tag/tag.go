@@ -0,0 +1,50 @@
+package tag
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+const maxTags = 32
+
+var pattern = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9_.:=/-]{0,63}$`)
+
+// Validate checks the tags of a peer or a server, tags are compared case-insensitively.
+func Validate(tags []string) error {
+	if len(tags) > maxTags {
+		return fmt.Errorf("at most %d tags are allowed, got %d", maxTags, len(tags))
+	}
+
+	for i, tag := range tags {
+		if !pattern.MatchString(tag) {
+			return fmt.Errorf("invalid tag #%d: %q, expected up to 64 letters, digits or _.:=/- characters", i+1, tag)
+		}
+
+		for _, nextTag := range tags[i+1:] {
+			if strings.EqualFold(tag, nextTag) {
+				return fmt.Errorf("duplicate tag: %s", tag)
+			}
+		}
+	}
+	return nil
+}
+
+// ContainsAll reports whether every required tag is one of the tags.
+func ContainsAll(tags []string, required []string) bool {
+	for _, requiredTag := range required {
+		if !contains(tags, requiredTag) {
+			return false
+		}
+	}
+	return true
+}
+
+func contains(tags []string, tag string) bool {
+	for _, t := range tags {
+		if strings.EqualFold(t, tag) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,266 @@
+package manage
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/UnAfraid/wg-ui/pkg/wireguard/driver"
+)
+
+const backendDiagnosticsTimeout = 15 * time.Second
+
+type BackendDiagnosticStepName string
+
+const (
+	BackendDiagnosticStepDriver         BackendDiagnosticStepName = "DRIVER"
+	BackendDiagnosticStepDNS            BackendDiagnosticStepName = "DNS"
+	BackendDiagnosticStepTCP            BackendDiagnosticStepName = "TCP"
+	BackendDiagnosticStepTLS            BackendDiagnosticStepName = "TLS"
+	BackendDiagnosticStepAuthentication BackendDiagnosticStepName = "AUTHENTICATION"
+	BackendDiagnosticStepPermission     BackendDiagnosticStepName = "PERMISSION"
+)
+
+type BackendDiagnosticStatus string
+
+const (
+	BackendDiagnosticStatusPassed  BackendDiagnosticStatus = "PASSED"
+	BackendDiagnosticStatusFailed  BackendDiagnosticStatus = "FAILED"
+	BackendDiagnosticStatusSkipped BackendDiagnosticStatus = "SKIPPED"
+)
+
+// BackendDiagnostics is the outcome of connecting to a backend url without saving it.
+type BackendDiagnostics struct {
+	Type         string
+	Version      string
+	Capabilities driver.Capabilities
+	Steps        []*BackendDiagnosticStep
+}
+
+type BackendDiagnosticStep struct {
+	Name     BackendDiagnosticStepName
+	Status   BackendDiagnosticStatus
+	Message  string
+	Duration time.Duration
+}
+
+// Success reports whether none of the steps failed.
+func (d *BackendDiagnostics) Success() bool {
+	for _, step := range d.Steps {
+		if step.Status == BackendDiagnosticStatusFailed {
+			return false
+		}
+	}
+	return true
+}
+
+func (d *BackendDiagnostics) passed(name BackendDiagnosticStepName, start time.Time, message string) {
+	d.Steps = append(d.Steps, &BackendDiagnosticStep{
+		Name:     name,
+		Status:   BackendDiagnosticStatusPassed,
+		Message:  message,
+		Duration: time.Since(start),
+	})
+}
+
+func (d *BackendDiagnostics) failed(name BackendDiagnosticStepName, start time.Time, err error) {
+	d.Steps = append(d.Steps, &BackendDiagnosticStep{
+		Name:     name,
+		Status:   BackendDiagnosticStatusFailed,
+		Message:  err.Error(),
+		Duration: time.Since(start),
+	})
+}
+
+func (d *BackendDiagnostics) skipped(message string, names ...BackendDiagnosticStepName) {
+	for _, name := range names {
+		d.Steps = append(d.Steps, &BackendDiagnosticStep{
+			Name:    name,
+			Status:  BackendDiagnosticStatusSkipped,
+			Message: message,
+		})
+	}
+}
+
+// diagnoseBackend creates a backend for the url and runs read-only checks against it, the backend is closed
+// afterward and nothing is registered or saved.
+func diagnoseBackend(ctx context.Context, backendType string, rawURL string) *BackendDiagnostics {
+	diagnostics := &BackendDiagnostics{
+		Type:         backendType,
+		Capabilities: driver.GetCapabilities(backendType),
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, backendDiagnosticsTimeout)
+	defer cancel()
+
+	start := time.Now()
+	instance, err := driver.Create(ctx, backendType, rawURL)
+	if err != nil {
+		diagnostics.failed(BackendDiagnosticStepDriver, start, err)
+		diagnostics.skipped("the backend could not be created",
+			BackendDiagnosticStepDNS,
+			BackendDiagnosticStepTCP,
+			BackendDiagnosticStepTLS,
+			BackendDiagnosticStepAuthentication,
+			BackendDiagnosticStepPermission,
+		)
+		return diagnostics
+	}
+	defer func() {
+		if closeErr := instance.Close(ctx); closeErr != nil {
+			logrus.WithError(closeErr).
+				WithField("type", backendType).
+				Warn("failed to close backend after diagnostics")
+		}
+	}()
+	diagnostics.passed(BackendDiagnosticStepDriver, start, "")
+
+	remoteBackend, ok := instance.(driver.RemoteBackend)
+	if !ok {
+		diagnostics.skipped("the backend manages the local host",
+			BackendDiagnosticStepDNS,
+			BackendDiagnosticStepTCP,
+			BackendDiagnosticStepTLS,
+			BackendDiagnosticStepAuthentication,
+		)
+		if err := diagnoseVersion(ctx, diagnostics, instance); err != nil {
+			logrus.WithError(err).
+				WithField("type", backendType).
+				Debug("failed to detect backend version")
+		}
+		diagnosePermission(ctx, diagnostics, instance, false)
+		return diagnostics
+	}
+
+	if !diagnoseEndpoint(ctx, diagnostics, remoteBackend.Endpoint()) {
+		diagnostics.skipped("the host could not be reached",
+			BackendDiagnosticStepAuthentication,
+			BackendDiagnosticStepPermission,
+		)
+		return diagnostics
+	}
+
+	authenticated := false
+	if _, ok := instance.(driver.VersionBackend); ok {
+		start = time.Now()
+		err := diagnoseVersion(ctx, diagnostics, instance)
+		switch {
+		case err == nil || errors.Is(err, driver.ErrPermissionDenied):
+			diagnostics.passed(BackendDiagnosticStepAuthentication, start, "")
+			authenticated = true
+		default:
+			diagnostics.failed(BackendDiagnosticStepAuthentication, start, err)
+			diagnostics.skipped("the backend did not accept the credentials", BackendDiagnosticStepPermission)
+			return diagnostics
+		}
+	}
+
+	diagnosePermission(ctx, diagnostics, instance, !authenticated)
+	return diagnostics
+}
+
+// diagnoseEndpoint resolves, connects to and completes the TLS handshake with the endpoint,
+// it reports whether the host is reachable.
+func diagnoseEndpoint(ctx context.Context, diagnostics *BackendDiagnostics, endpoint driver.Endpoint) bool {
+	start := time.Now()
+	if net.ParseIP(endpoint.Host) != nil {
+		diagnostics.passed(BackendDiagnosticStepDNS, start, fmt.Sprintf("%s is an ip address", endpoint.Host))
+	} else {
+		addresses, err := net.DefaultResolver.LookupHost(ctx, endpoint.Host)
+		if err != nil {
+			diagnostics.failed(BackendDiagnosticStepDNS, start, err)
+			diagnostics.skipped("the host could not be resolved", BackendDiagnosticStepTCP, BackendDiagnosticStepTLS)
+			return false
+		}
+		diagnostics.passed(BackendDiagnosticStepDNS, start, fmt.Sprintf("%s resolved to %s", endpoint.Host, strings.Join(addresses, ", ")))
+	}
+
+	address := net.JoinHostPort(endpoint.Host, endpoint.Port)
+	start = time.Now()
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", address)
+	if err != nil {
+		diagnostics.failed(BackendDiagnosticStepTCP, start, err)
+		diagnostics.skipped("the connection could not be established", BackendDiagnosticStepTLS)
+		return false
+	}
+	defer conn.Close()
+	diagnostics.passed(BackendDiagnosticStepTCP, start, fmt.Sprintf("connected to %s", conn.RemoteAddr()))
+
+	if endpoint.TLSConfig == nil {
+		diagnostics.skipped("the backend does not use tls", BackendDiagnosticStepTLS)
+		return true
+	}
+
+	start = time.Now()
+	tlsConfig := endpoint.TLSConfig.Clone()
+	if tlsConfig.ServerName == "" {
+		tlsConfig.ServerName = endpoint.Host
+	}
+	tlsConn := tls.Client(conn, tlsConfig)
+	if err := tlsConn.HandshakeContext(ctx); err != nil {
+		diagnostics.failed(BackendDiagnosticStepTLS, start, err)
+		return false
+	}
+
+	message := tls.VersionName(tlsConn.ConnectionState().Version)
+	if tlsConfig.InsecureSkipVerify {
+		message += ", certificate verification is disabled"
+	}
+	diagnostics.passed(BackendDiagnosticStepTLS, start, message)
+	return true
+}
+
+// diagnoseVersion stores the version reported by the backend, backends that do not report it are not an error.
+func diagnoseVersion(ctx context.Context, diagnostics *BackendDiagnostics, instance driver.Backend) error {
+	versionBackend, ok := instance.(driver.VersionBackend)
+	if !ok {
+		return nil
+	}
+
+	version, err := versionBackend.Version(ctx)
+	if err != nil {
+		return err
+	}
+	diagnostics.Version = version
+	return nil
+}
+
+// diagnosePermission lists the interfaces of the backend, verifyAuthentication records the authentication step
+// from its outcome for backends that were not authenticated by an earlier call.
+func diagnosePermission(ctx context.Context, diagnostics *BackendDiagnostics, instance driver.Backend, verifyAuthentication bool) {
+	start := time.Now()
+	foreignServers, err := instance.FindForeignServers(ctx, nil)
+	if err == nil {
+		if verifyAuthentication {
+			diagnostics.passed(BackendDiagnosticStepAuthentication, start, "")
+		}
+		diagnostics.passed(BackendDiagnosticStepPermission, start, fmt.Sprintf("found %d unmanaged interfaces", len(foreignServers)))
+		return
+	}
+
+	if verifyAuthentication {
+		switch {
+		case errors.Is(err, driver.ErrUnauthorized):
+			diagnostics.failed(BackendDiagnosticStepAuthentication, start, err)
+			diagnostics.skipped("the backend did not accept the credentials", BackendDiagnosticStepPermission)
+			return
+		case errors.Is(err, driver.ErrPermissionDenied):
+			diagnostics.passed(BackendDiagnosticStepAuthentication, start, "")
+		default:
+			diagnostics.skipped("the credentials could not be verified", BackendDiagnosticStepAuthentication)
+		}
+	}
+
+	if errors.Is(err, fs.ErrPermission) {
+		err = fmt.Errorf("%w: %w", driver.ErrPermissionDenied, err)
+	}
+	diagnostics.failed(BackendDiagnosticStepPermission, start, err)
+}
@@ -0,0 +1,217 @@
+package manage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/UnAfraid/wg-ui/pkg/wireguard/driver"
+)
+
+type localDiagnosticsBackend struct {
+	driver.Backend
+	versionErr error
+	foreignErr error
+}
+
+func (b *localDiagnosticsBackend) Version(context.Context) (string, error) {
+	if b.versionErr != nil {
+		return "", b.versionErr
+	}
+	return "1.2.3", nil
+}
+
+func (b *localDiagnosticsBackend) FindForeignServers(context.Context, []string) ([]*driver.ForeignServer, error) {
+	if b.foreignErr != nil {
+		return nil, b.foreignErr
+	}
+	return []*driver.ForeignServer{{}}, nil
+}
+
+func (b *localDiagnosticsBackend) Close(context.Context) error {
+	return nil
+}
+
+type remoteDiagnosticsBackend struct {
+	localDiagnosticsBackend
+	endpoint driver.Endpoint
+}
+
+func (b *remoteDiagnosticsBackend) Endpoint() driver.Endpoint {
+	return b.endpoint
+}
+
+func registerDiagnosticsBackend(backend driver.Backend, createErr error) string {
+	scheme := fmt.Sprintf("diagnostics-%d", time.Now().UnixNano())
+	driver.Register(scheme, func(context.Context, string) (driver.Backend, error) {
+		return backend, createErr
+	}, true, driver.Capabilities{PeerStats: true})
+	return scheme
+}
+
+func diagnosticStatuses(diagnostics *BackendDiagnostics) map[BackendDiagnosticStepName]BackendDiagnosticStatus {
+	statuses := make(map[BackendDiagnosticStepName]BackendDiagnosticStatus, len(diagnostics.Steps))
+	for _, step := range diagnostics.Steps {
+		statuses[step.Name] = step.Status
+	}
+	return statuses
+}
+
+func tlsEndpoint(t *testing.T) driver.Endpoint {
+	t.Helper()
+
+	// the diagnostics close the connection after the handshake, which the server would log
+	testServer := httptest.NewUnstartedServer(http.NotFoundHandler())
+	testServer.Config.ErrorLog = log.New(io.Discard, "", 0)
+	testServer.StartTLS()
+	t.Cleanup(testServer.Close)
+
+	host, port, err := net.SplitHostPort(testServer.Listener.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return driver.Endpoint{
+		Host:      host,
+		Port:      port,
+		TLSConfig: testServer.Client().Transport.(*http.Transport).TLSClientConfig,
+	}
+}
+
+func closedEndpoint(t *testing.T) driver.Endpoint {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	host, port, _ := net.SplitHostPort(listener.Addr().String())
+	_ = listener.Close()
+	return driver.Endpoint{Host: host, Port: port}
+}
+
+func TestDiagnoseBackend(t *testing.T) {
+	passed, failed, skipped := BackendDiagnosticStatusPassed, BackendDiagnosticStatusFailed, BackendDiagnosticStatusSkipped
+
+	tests := []struct {
+		name      string
+		backend   func(t *testing.T) driver.Backend
+		createErr error
+		expected  map[BackendDiagnosticStepName]BackendDiagnosticStatus
+		version   string
+	}{
+		{
+			name: "remote",
+			backend: func(t *testing.T) driver.Backend {
+				return &remoteDiagnosticsBackend{endpoint: tlsEndpoint(t)}
+			},
+			expected: map[BackendDiagnosticStepName]BackendDiagnosticStatus{
+				BackendDiagnosticStepDriver:         passed,
+				BackendDiagnosticStepDNS:            passed,
+				BackendDiagnosticStepTCP:            passed,
+				BackendDiagnosticStepTLS:            passed,
+				BackendDiagnosticStepAuthentication: passed,
+				BackendDiagnosticStepPermission:     passed,
+			},
+			version: "1.2.3",
+		},
+		{
+			name: "rejected credentials",
+			backend: func(t *testing.T) driver.Backend {
+				return &remoteDiagnosticsBackend{
+					localDiagnosticsBackend: localDiagnosticsBackend{versionErr: driver.ErrUnauthorized},
+					endpoint:                tlsEndpoint(t),
+				}
+			},
+			expected: map[BackendDiagnosticStepName]BackendDiagnosticStatus{
+				BackendDiagnosticStepDriver:         passed,
+				BackendDiagnosticStepDNS:            passed,
+				BackendDiagnosticStepTCP:            passed,
+				BackendDiagnosticStepTLS:            passed,
+				BackendDiagnosticStepAuthentication: failed,
+				BackendDiagnosticStepPermission:     skipped,
+			},
+		},
+		{
+			name: "unreachable",
+			backend: func(t *testing.T) driver.Backend {
+				return &remoteDiagnosticsBackend{endpoint: closedEndpoint(t)}
+			},
+			expected: map[BackendDiagnosticStepName]BackendDiagnosticStatus{
+				BackendDiagnosticStepDriver:         passed,
+				BackendDiagnosticStepDNS:            passed,
+				BackendDiagnosticStepTCP:            failed,
+				BackendDiagnosticStepTLS:            skipped,
+				BackendDiagnosticStepAuthentication: skipped,
+				BackendDiagnosticStepPermission:     skipped,
+			},
+		},
+		{
+			name: "local without permission",
+			backend: func(t *testing.T) driver.Backend {
+				return &localDiagnosticsBackend{foreignErr: fs.ErrPermission}
+			},
+			expected: map[BackendDiagnosticStepName]BackendDiagnosticStatus{
+				BackendDiagnosticStepDriver:         passed,
+				BackendDiagnosticStepDNS:            skipped,
+				BackendDiagnosticStepTCP:            skipped,
+				BackendDiagnosticStepTLS:            skipped,
+				BackendDiagnosticStepAuthentication: skipped,
+				BackendDiagnosticStepPermission:     failed,
+			},
+			version: "1.2.3",
+		},
+		{
+			name:      "invalid url",
+			backend:   func(t *testing.T) driver.Backend { return nil },
+			createErr: errors.New("backend requires host"),
+			expected: map[BackendDiagnosticStepName]BackendDiagnosticStatus{
+				BackendDiagnosticStepDriver:         failed,
+				BackendDiagnosticStepDNS:            skipped,
+				BackendDiagnosticStepTCP:            skipped,
+				BackendDiagnosticStepTLS:            skipped,
+				BackendDiagnosticStepAuthentication: skipped,
+				BackendDiagnosticStepPermission:     skipped,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scheme := registerDiagnosticsBackend(tt.backend(t), tt.createErr)
+
+			diagnostics := diagnoseBackend(context.Background(), scheme, scheme+"://host")
+			if len(diagnostics.Steps) != len(tt.expected) {
+				t.Fatalf("expected %d steps, got %d", len(tt.expected), len(diagnostics.Steps))
+			}
+			for name, status := range diagnosticStatuses(diagnostics) {
+				if status != tt.expected[name] {
+					t.Errorf("expected step %s to be %s, got %s", name, tt.expected[name], status)
+				}
+			}
+
+			expectedSuccess := true
+			for _, status := range tt.expected {
+				if status == failed {
+					expectedSuccess = false
+				}
+			}
+			if diagnostics.Success() != expectedSuccess {
+				t.Errorf("expected success %t, got %t", expectedSuccess, diagnostics.Success())
+			}
+			if diagnostics.Version != tt.version {
+				t.Errorf("expected version %q, got %q", tt.version, diagnostics.Version)
+			}
+			if !diagnostics.Capabilities.PeerStats {
+				t.Errorf("expected the capabilities of the backend type")
+			}
+		})
+	}
+}
@@ -0,0 +1,59 @@
+package manage
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/UnAfraid/wg-ui/pkg/backend"
+	"github.com/UnAfraid/wg-ui/pkg/peer"
+	"github.com/UnAfraid/wg-ui/pkg/server"
+	"github.com/UnAfraid/wg-ui/pkg/wireguard/driver"
+)
+
+func backendCapabilities(b *backend.Backend) driver.Capabilities {
+	if b == nil {
+		return driver.Capabilities{}
+	}
+	return driver.GetCapabilities(b.Type())
+}
+
+// requireBackendCapabilities fails with driver.ErrCapabilityNotSupported when the backend does not declare
+// all the capabilities.
+func requireBackendCapabilities(b *backend.Backend, capabilities ...driver.Capability) error {
+	if err := backendCapabilities(b).Require(capabilities...); err != nil {
+		return fmt.Errorf("backend %s (%s): %w", b.Name, b.Type(), err)
+	}
+	return nil
+}
+
+// serverRequiredCapabilities lists the capabilities the settings of a server depend on.
+func serverRequiredCapabilities(srv *server.Server) []driver.Capability {
+	var capabilities []driver.Capability
+	if len(srv.DNS) > 0 {
+		capabilities = append(capabilities, driver.CapabilityDNS)
+	}
+	if srv.Firewall != nil {
+		capabilities = append(capabilities, driver.CapabilityFirewall)
+	}
+	return capabilities
+}
+
+// serverPeersRequiredCapabilities lists the capabilities the settings of a server and its peers depend on.
+func serverPeersRequiredCapabilities(srv *server.Server, peers []*peer.Peer) []driver.Capability {
+	capabilities := serverRequiredCapabilities(srv)
+	if slices.Contains(capabilities, driver.CapabilityFirewall) {
+		return capabilities
+	}
+
+	for _, p := range peers {
+		if peerRequiresFirewall(p.AllowedDestinations, p.ACL) {
+			return append(capabilities, driver.CapabilityFirewall)
+		}
+	}
+	return capabilities
+}
+
+// peerRequiresFirewall reports whether the destinations of a peer are restricted.
+func peerRequiresFirewall(allowedDestinations []string, acl []*peer.ACLRule) bool {
+	return len(allowedDestinations) > 0 || len(acl) > 0
+}
@@ -0,0 +1,53 @@
+package manage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/UnAfraid/wg-ui/pkg/backend"
+	"github.com/UnAfraid/wg-ui/pkg/peer"
+	"github.com/UnAfraid/wg-ui/pkg/server"
+	"github.com/UnAfraid/wg-ui/pkg/wireguard/driver"
+)
+
+func TestRequireBackendCapabilities(t *testing.T) {
+	scheme := fmt.Sprintf("capabilities-%d", time.Now().UnixNano())
+	driver.Register(scheme, func(context.Context, string) (driver.Backend, error) {
+		return nil, errors.New("not implemented")
+	}, true, driver.Capabilities{DNS: true})
+
+	b := &backend.Backend{Name: "test", Url: scheme + ":///"}
+	srv := &server.Server{DNS: []string{"1.1.1.1"}}
+
+	if err := requireBackendCapabilities(b, serverRequiredCapabilities(srv)...); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	srv.Firewall = &server.Firewall{IPForwarding: true}
+	err := requireBackendCapabilities(b, serverRequiredCapabilities(srv)...)
+	if !errors.Is(err, driver.ErrCapabilityNotSupported) {
+		t.Fatalf("expected %v, got %v", driver.ErrCapabilityNotSupported, err)
+	}
+}
+
+func TestServerPeersRequiredCapabilities(t *testing.T) {
+	srv := &server.Server{}
+	peers := []*peer.Peer{{}, {AllowedDestinations: []string{"10.0.0.0/24"}}}
+
+	capabilities := serverPeersRequiredCapabilities(srv, peers)
+	if len(capabilities) != 1 || capabilities[0] != driver.CapabilityFirewall {
+		t.Fatalf("expected the firewall capability for restricted peers, got %v", capabilities)
+	}
+
+	srv.Firewall = &server.Firewall{IPForwarding: true}
+	if capabilities := serverPeersRequiredCapabilities(srv, peers); len(capabilities) != 1 {
+		t.Fatalf("expected the firewall capability once, got %v", capabilities)
+	}
+
+	if capabilities := serverPeersRequiredCapabilities(&server.Server{}, peers[:1]); len(capabilities) != 0 {
+		t.Fatalf("expected no capabilities, got %v", capabilities)
+	}
+}
@@ -0,0 +1,269 @@
+package manage
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/netip"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/UnAfraid/wg-ui/pkg/internal/adapt"
+	"github.com/UnAfraid/wg-ui/pkg/peer"
+	"github.com/UnAfraid/wg-ui/pkg/server"
+	"github.com/UnAfraid/wg-ui/pkg/wireguard/driver"
+)
+
+func (s *service) reconcileServers(ctx context.Context) {
+	servers, err := s.serverService.FindServers(ctx, &server.FindOptions{})
+	if err != nil {
+		logrus.
+			WithError(err).
+			Error("failed to find servers")
+		return
+	}
+
+	for _, srv := range servers {
+		if err := s.reconcileServer(ctx, srv); err != nil {
+			logrus.
+				WithError(err).
+				WithField("name", srv.Name).
+				Warn("failed to check server for drift")
+		}
+	}
+}
+
+// reconcileServer compares the device of a running server with its stored configuration,
+// records the detected drift and, when the server allows it, reapplies the stored configuration.
+func (s *service) reconcileServer(ctx context.Context, srv *server.Server) error {
+	if !srv.Enabled || !srv.Running {
+		return s.clearServerDrift(ctx, srv)
+	}
+
+	differences, err := s.serverDriftDifferences(ctx, srv)
+	if err != nil {
+		return err
+	}
+
+	var drift *server.Drift
+	if len(differences) != 0 {
+		drift = &server.Drift{
+			DetectedAt:  time.Now(),
+			Differences: differences,
+		}
+
+		if srv.DriftMode == server.DriftModeAutoCorrect {
+			if err := s.reconfigureQueue.enqueue(srv.Id, "").wait(ctx); err != nil {
+				logrus.
+					WithError(err).
+					WithField("name", srv.Name).
+					Warn("failed to correct server drift")
+			} else {
+				drift.Corrected = true
+			}
+		}
+	}
+
+	return s.updateServerDrift(ctx, srv, drift)
+}
+
+// markServerDrifted records the drift of a server whose device could not be configured, the drift check corrects
+// it later when the server allows it. When the device can not be compared the failed configuration itself is recorded.
+func (s *service) markServerDrifted(ctx context.Context, serverId string, configureErr error) error {
+	return s.transactionScoper.InTransactionScope(ctx, func(ctx context.Context) error {
+		srv, err := s.findServer(ctx, serverId)
+		if err != nil {
+			return err
+		}
+
+		differences, err := s.serverDriftDifferences(ctx, srv)
+		if err != nil || len(differences) == 0 {
+			differences = []*server.DriftDifference{{
+				Field:    "device",
+				Expected: "configured",
+				Actual:   configureErr.Error(),
+			}}
+		}
+
+		return s.updateServerDrift(ctx, srv, &server.Drift{
+			DetectedAt:  time.Now(),
+			Differences: differences,
+		})
+	})
+}
+
+// serverDriftDifferences lists the differences between a running server and its device,
+// nothing is reported while the backend is disabled.
+func (s *service) serverDriftDifferences(ctx context.Context, srv *server.Server) ([]*server.DriftDifference, error) {
+	b, err := s.findBackend(ctx, srv.BackendId)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find backend: %w", err)
+	}
+	if !b.Enabled {
+		return nil, nil
+	}
+
+	peers, err := s.findDevicePeers(ctx, srv.Id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find peers: %w", err)
+	}
+
+	status, err := s.wireguardService.Status(ctx, b, srv.Name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get device status: %w", err)
+	}
+	if !status {
+		return []*server.DriftDifference{{
+			Field:    "running",
+			Expected: "true",
+			Actual:   "false",
+		}}, nil
+	}
+
+	device, err := s.wireguardService.Device(ctx, b, srv.Name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get device: %w", err)
+	}
+	return computeDrift(srv, peers, device), nil
+}
+
+func (s *service) updateServerDrift(ctx context.Context, srv *server.Server, drift *server.Drift) error {
+	if drift.Same(srv.Drift) {
+		return nil
+	}
+
+	if drift != nil {
+		logrus.
+			WithField("name", srv.Name).
+			WithField("differences", len(drift.Differences)).
+			WithField("corrected", drift.Corrected).
+			Warn("server drift detected")
+	}
+
+	updateOptions := &server.UpdateOptions{Drift: drift}
+	updateFieldMask := &server.UpdateFieldMask{Drift: true}
+	if _, err := s.serverService.UpdateServer(ctx, srv.Id, updateOptions, updateFieldMask, ""); err != nil {
+		return fmt.Errorf("failed to update server drift: %w", err)
+	}
+	return nil
+}
+
+func (s *service) clearServerDrift(ctx context.Context, srv *server.Server) error {
+	if srv.Drift == nil {
+		return nil
+	}
+
+	updateOptions := &server.UpdateOptions{Drift: nil}
+	updateFieldMask := &server.UpdateFieldMask{Drift: true}
+	if _, err := s.serverService.UpdateServer(ctx, srv.Id, updateOptions, updateFieldMask, ""); err != nil {
+		return fmt.Errorf("failed to clear server drift: %w", err)
+	}
+	return nil
+}
+
+// computeDrift lists the differences between the stored server and peers and the device reported by the backend.
+// Values the backend does not report, such as empty address lists, are not considered drift.
+func computeDrift(srv *server.Server, peers []*peer.Peer, device *driver.Device) []*server.DriftDifference {
+	if device == nil {
+		return []*server.DriftDifference{{
+			Field:    "running",
+			Expected: "true",
+			Actual:   "false",
+		}}
+	}
+
+	var differences []*server.DriftDifference
+	addDifference := func(field string, expected string, actual string) {
+		if expected != actual {
+			differences = append(differences, &server.DriftDifference{
+				Field:    field,
+				Expected: expected,
+				Actual:   actual,
+			})
+		}
+	}
+
+	if device.Wireguard.PublicKey != "" {
+		addDifference("publicKey", srv.PublicKey, device.Wireguard.PublicKey)
+	}
+	if srv.ListenPort != nil {
+		addDifference("listenPort", strconv.Itoa(*srv.ListenPort), strconv.Itoa(device.Wireguard.ListenPort))
+	}
+	addDifference("firewallMark", strconv.Itoa(adapt.Dereference(srv.FirewallMark)), strconv.Itoa(device.Wireguard.FirewallMark))
+	if srv.MTU != 0 && device.Interface.Mtu != 0 {
+		addDifference("mtu", strconv.Itoa(srv.MTU), strconv.Itoa(device.Interface.Mtu))
+	}
+	if len(device.Interface.Addresses) != 0 && !containsAddress(device.Interface.Addresses, srv.Address) {
+		addDifference("address", srv.Address, strings.Join(device.Interface.Addresses, ", "))
+	}
+
+	devicePeers := make(map[string]*driver.Peer, len(device.Wireguard.Peers))
+	for _, devicePeer := range device.Wireguard.Peers {
+		devicePeers[devicePeer.PublicKey] = devicePeer
+	}
+
+	for _, p := range peers {
+		devicePeer, ok := devicePeers[p.PublicKey]
+		if !ok {
+			addDifference(fmt.Sprintf("peer %s", p.Name), "present", "missing")
+			continue
+		}
+		delete(devicePeers, p.PublicKey)
+
+		expectedAllowedIPs := normalizePrefixes(p.AllowedIPs)
+		actualAllowedIPs := normalizePrefixes(adapt.Array(devicePeer.AllowedIPs, func(ipNet net.IPNet) string {
+			return ipNet.String()
+		}))
+		addDifference(fmt.Sprintf("peer %s allowedIPs", p.Name), strings.Join(expectedAllowedIPs, ", "), strings.Join(actualAllowedIPs, ", "))
+
+		addDifference(
+			fmt.Sprintf("peer %s persistentKeepalive", p.Name),
+			strconv.Itoa(p.PersistentKeepalive),
+			strconv.Itoa(int(devicePeer.PersistentKeepalive.Seconds())),
+		)
+	}
+
+	unexpectedPublicKeys := make([]string, 0, len(devicePeers))
+	for publicKey := range devicePeers {
+		unexpectedPublicKeys = append(unexpectedPublicKeys, publicKey)
+	}
+	slices.Sort(unexpectedPublicKeys)
+	for _, publicKey := range unexpectedPublicKeys {
+		addDifference(fmt.Sprintf("peer %s", publicKey), "absent", "present")
+	}
+
+	return differences
+}
+
+func containsAddress(addresses []string, address string) bool {
+	expected, err := netip.ParsePrefix(address)
+	if err != nil {
+		return slices.Contains(addresses, address)
+	}
+
+	for _, a := range addresses {
+		actual, err := netip.ParsePrefix(a)
+		if err == nil && actual.Addr() == expected.Addr() && actual.Bits() == expected.Bits() {
+			return true
+		}
+	}
+	return false
+}
+
+func normalizePrefixes(prefixes []string) []string {
+	normalized := make([]string, 0, len(prefixes))
+	for _, prefix := range prefixes {
+		parsed, err := netip.ParsePrefix(strings.TrimSpace(prefix))
+		if err != nil {
+			normalized = append(normalized, prefix)
+			continue
+		}
+		normalized = append(normalized, parsed.Masked().String())
+	}
+	slices.Sort(normalized)
+	return slices.Compact(normalized)
+}
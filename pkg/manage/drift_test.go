@@ -0,0 +1,103 @@
+package manage
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/UnAfraid/wg-ui/pkg/peer"
+	"github.com/UnAfraid/wg-ui/pkg/server"
+	"github.com/UnAfraid/wg-ui/pkg/wireguard/driver"
+)
+
+func mustParseIPNet(t *testing.T, cidr string) net.IPNet {
+	t.Helper()
+	_, ipNet, err := net.ParseCIDR(cidr)
+	if err != nil {
+		t.Fatalf("failed to parse cidr %s: %v", cidr, err)
+	}
+	return *ipNet
+}
+
+func TestComputeDriftWithoutDifferences(t *testing.T) {
+	listenPort := 51820
+	srv := &server.Server{
+		PublicKey:  "server-key",
+		ListenPort: &listenPort,
+		Address:    "10.0.0.1/24",
+		MTU:        1420,
+	}
+	peers := []*peer.Peer{
+		{Name: "alpha", PublicKey: "alpha-key", AllowedIPs: []string{"10.0.0.2/32"}, PersistentKeepalive: 25},
+	}
+	device := &driver.Device{
+		Interface: driver.Interface{
+			Addresses: []string{"10.0.0.1/24"},
+			Mtu:       1420,
+		},
+		Wireguard: driver.Wireguard{
+			PublicKey:  "server-key",
+			ListenPort: 51820,
+			Peers: []*driver.Peer{
+				{
+					PublicKey:           "alpha-key",
+					AllowedIPs:          []net.IPNet{mustParseIPNet(t, "10.0.0.2/32")},
+					PersistentKeepalive: 25 * time.Second,
+				},
+			},
+		},
+	}
+
+	if differences := computeDrift(srv, peers, device); len(differences) != 0 {
+		t.Fatalf("expected no drift, got %d differences, first: %+v", len(differences), differences[0])
+	}
+}
+
+func TestComputeDriftReportsPeerDifferences(t *testing.T) {
+	srv := &server.Server{
+		PublicKey: "server-key",
+		Address:   "10.0.0.1/24",
+	}
+	peers := []*peer.Peer{
+		{Name: "alpha", PublicKey: "alpha-key", AllowedIPs: []string{"10.0.0.2/32"}},
+		{Name: "bravo", PublicKey: "bravo-key", AllowedIPs: []string{"10.0.0.3/32"}},
+	}
+	device := &driver.Device{
+		Wireguard: driver.Wireguard{
+			PublicKey: "server-key",
+			Peers: []*driver.Peer{
+				{PublicKey: "alpha-key", AllowedIPs: []net.IPNet{mustParseIPNet(t, "10.0.0.9/32")}},
+				{PublicKey: "manual-key"},
+			},
+		},
+	}
+
+	differences := computeDrift(srv, peers, device)
+	expected := []server.DriftDifference{
+		{Field: "peer alpha allowedIPs", Expected: "10.0.0.2/32", Actual: "10.0.0.9/32"},
+		{Field: "peer bravo", Expected: "present", Actual: "missing"},
+		{Field: "peer manual-key", Expected: "absent", Actual: "present"},
+	}
+
+	if len(differences) != len(expected) {
+		t.Fatalf("expected %d differences, got %d", len(expected), len(differences))
+	}
+	for i, difference := range differences {
+		if *difference != expected[i] {
+			t.Fatalf("difference #%d: expected %+v, got %+v", i+1, expected[i], *difference)
+		}
+	}
+}
+
+func TestDriftSameIgnoresDetectionTime(t *testing.T) {
+	differences := []*server.DriftDifference{{Field: "mtu", Expected: "1420", Actual: "1500"}}
+	first := &server.Drift{DetectedAt: time.Now(), Differences: differences}
+	second := &server.Drift{DetectedAt: time.Now().Add(time.Minute), Differences: []*server.DriftDifference{{Field: "mtu", Expected: "1420", Actual: "1500"}}}
+
+	if !first.Same(second) {
+		t.Fatal("expected drifts with equal differences to be the same")
+	}
+	if first.Same(nil) {
+		t.Fatal("expected drift not to be the same as no drift")
+	}
+}
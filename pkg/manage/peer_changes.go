@@ -0,0 +1,30 @@
+package manage
+
+import (
+	"github.com/UnAfraid/wg-ui/pkg/peer"
+)
+
+// PeerChanges is a set of peer changes applied in a single transaction,
+// each affected server device is reconfigured once after all changes are stored.
+type PeerChanges struct {
+	Create []*PeerCreate
+	Update []*PeerUpdate
+	Delete []string
+}
+
+type PeerCreate struct {
+	ServerId string
+	Options  *peer.CreateOptions
+}
+
+type PeerUpdate struct {
+	PeerId    string
+	Options   *peer.UpdateOptions
+	FieldMask *peer.UpdateFieldMask
+}
+
+type PeerChangesResult struct {
+	Created []*peer.Peer
+	Updated []*peer.Peer
+	Deleted []*peer.Peer
+}
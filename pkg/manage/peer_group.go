@@ -0,0 +1,254 @@
+package manage
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/UnAfraid/wg-ui/pkg/dbx"
+	"github.com/UnAfraid/wg-ui/pkg/internal/adapt"
+	"github.com/UnAfraid/wg-ui/pkg/peer"
+	"github.com/UnAfraid/wg-ui/pkg/wireguard/driver"
+)
+
+// peerExpiryCheckInterval is how often the devices are checked for peers that expired since the last check.
+const peerExpiryCheckInterval = time.Minute
+
+type PeerGroupAction string
+
+const (
+	PeerGroupActionEnable  PeerGroupAction = "ENABLE"
+	PeerGroupActionDisable PeerGroupAction = "DISABLE"
+	PeerGroupActionDelete  PeerGroupAction = "DELETE"
+	// PeerGroupActionRegenerateConfigs reapplies the peers of the group to the devices of their servers.
+	PeerGroupActionRegenerateConfigs PeerGroupAction = "REGENERATE_CONFIGS"
+)
+
+func (a PeerGroupAction) Valid() bool {
+	switch a {
+	case PeerGroupActionEnable, PeerGroupActionDisable, PeerGroupActionDelete, PeerGroupActionRegenerateConfigs:
+		return true
+	}
+	return false
+}
+
+func (s *service) CreatePeerGroup(ctx context.Context, options *peer.GroupCreateOptions, userId string) (*peer.Group, error) {
+	return s.peerService.CreateGroup(ctx, options, userId)
+}
+
+// UpdatePeerGroup updates the group and reconfigures the devices of its peers when a device default changed.
+func (s *service) UpdatePeerGroup(ctx context.Context, groupId string, options *peer.GroupUpdateOptions, fieldMask *peer.GroupUpdateFieldMask, userId string) (*peer.Group, error) {
+	var serverIds []string
+	updatedGroup, err := dbx.InTransactionScopeWithResult(ctx, s.transactionScoper, func(ctx context.Context) (*peer.Group, error) {
+		peers, err := s.findGroupPeers(ctx, groupId)
+		if err != nil {
+			return nil, err
+		}
+
+		serverIds = peerServerIds(peers)
+		if fieldMask.ACL && len(options.ACL) != 0 {
+			for _, serverId := range serverIds {
+				if err := s.requireServerBackendCapabilities(ctx, serverId, driver.CapabilityFirewall); err != nil {
+					return nil, err
+				}
+			}
+		}
+
+		return s.peerService.UpdateGroup(ctx, groupId, options, fieldMask, userId)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if fieldMask.PersistentKeepalive || fieldMask.ACL || fieldMask.ExpiresAfter {
+		if err := s.configureServerDevices(ctx, serverIds, userId); err != nil {
+			return nil, err
+		}
+	}
+	return updatedGroup, nil
+}
+
+func (s *service) DeletePeerGroup(ctx context.Context, groupId string, userId string) (*peer.Group, error) {
+	return s.peerService.DeleteGroup(ctx, groupId, userId)
+}
+
+// ApplyPeerGroupAction applies the action to every peer of the group in a single transaction,
+// each affected server device is reconfigured once after it is committed.
+func (s *service) ApplyPeerGroupAction(ctx context.Context, groupId string, action PeerGroupAction, userId string) ([]*peer.Peer, error) {
+	if !action.Valid() {
+		return nil, fmt.Errorf("invalid peer group action: %s", action)
+	}
+
+	var serverIds []string
+	result, err := dbx.InTransactionScopeWithResult(ctx, s.transactionScoper, func(ctx context.Context) ([]*peer.Peer, error) {
+		peers, err := s.findGroupPeers(ctx, groupId)
+		if err != nil {
+			return nil, err
+		}
+		serverIds = peerServerIds(peers)
+
+		var result []*peer.Peer
+		for _, p := range peers {
+			switch action {
+			case PeerGroupActionEnable, PeerGroupActionDisable:
+				disabled := action == PeerGroupActionDisable
+				if p.Disabled != disabled {
+					updatedPeer, err := s.peerService.UpdatePeer(ctx, p.Id, &peer.UpdateOptions{
+						Disabled: disabled,
+					}, &peer.UpdateFieldMask{
+						Disabled: true,
+					}, userId)
+					if err != nil {
+						return nil, fmt.Errorf("failed to update peer %s: %w", p.Name, err)
+					}
+					p = updatedPeer
+				}
+			case PeerGroupActionDelete:
+				deletedPeer, err := s.peerService.DeletePeer(ctx, p.Id, userId)
+				if err != nil {
+					return nil, fmt.Errorf("failed to delete peer %s: %w", p.Name, err)
+				}
+				p = deletedPeer
+			}
+			result = append(result, p)
+		}
+		return result, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.configureServerDevices(ctx, serverIds, userId); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (s *service) findGroupPeers(ctx context.Context, groupId string) ([]*peer.Peer, error) {
+	group, err := s.peerService.FindGroup(ctx, &peer.GroupFindOneOptions{
+		IdOption: &peer.IdOption{
+			Id: groupId,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if group == nil {
+		return nil, peer.ErrGroupNotFound
+	}
+
+	return s.peerService.FindPeers(ctx, &peer.FindOptions{
+		Filter: &peer.Filter{
+			GroupIds: []string{group.Id},
+		},
+	})
+}
+
+// groupACL returns the acl rules a peer of the group inherits, none when the group is not set.
+func (s *service) groupACL(ctx context.Context, groupId string) ([]*peer.ACLRule, error) {
+	if groupId == "" {
+		return nil, nil
+	}
+
+	group, err := s.peerService.FindGroup(ctx, &peer.GroupFindOneOptions{
+		IdOption: &peer.IdOption{
+			Id: groupId,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if group == nil {
+		return nil, peer.ErrGroupNotFound
+	}
+	return group.ACL, nil
+}
+
+// hasPeerGroupDeviceHooks reports whether any peer group has hooks that depend on the stats updates.
+func (s *service) hasPeerGroupDeviceHooks(ctx context.Context) bool {
+	groups, err := s.peerService.FindGroups(ctx, &peer.GroupFindOptions{})
+	if err != nil {
+		logrus.
+			WithError(err).
+			Error("failed to find peer groups")
+		return false
+	}
+	return slices.ContainsFunc(groups, (*peer.Group).HasDeviceHooks)
+}
+
+// runPeerExpiry takes the peers off their devices once they expire, peers that expired while wg-ui was not running
+// are left out when the devices are configured on startup.
+func (s *service) runPeerExpiry() {
+	defer s.workers.Done()
+	ctx := context.Background()
+
+	lastCheck := time.Now()
+	for {
+		select {
+		case <-s.stopChan:
+			return
+		case <-time.After(peerExpiryCheckInterval):
+			now := time.Now()
+			s.expirePeers(ctx, lastCheck, now)
+			lastCheck = now
+		}
+	}
+}
+
+func (s *service) expirePeers(ctx context.Context, since time.Time, now time.Time) {
+	peers, err := s.peerService.FindPeers(ctx, &peer.FindOptions{
+		Filter: &peer.Filter{
+			Enabled: adapt.ToPointer(true),
+		},
+	})
+	if err != nil {
+		logrus.
+			WithError(err).
+			Error("failed to find peers")
+		return
+	}
+
+	groups, err := s.peerService.FindGroups(ctx, &peer.GroupFindOptions{})
+	if err != nil {
+		logrus.
+			WithError(err).
+			Error("failed to find peer groups")
+		return
+	}
+
+	var expiredPeers []*peer.Peer
+	for _, p := range peers {
+		var group *peer.Group
+		if i := slices.IndexFunc(groups, func(g *peer.Group) bool { return g.Id == p.GroupId }); i != -1 {
+			group = groups[i]
+		}
+
+		expiresAt := p.EffectiveExpiresAt(group)
+		if expiresAt != nil && expiresAt.After(since) && !expiresAt.After(now) {
+			expiredPeers = append(expiredPeers, p)
+		}
+	}
+
+	for _, serverId := range peerServerIds(expiredPeers) {
+		if err := s.reconfigureQueue.enqueue(serverId, "").wait(ctx); err != nil {
+			logrus.
+				WithError(err).
+				WithField("serverId", serverId).
+				Warn("failed to remove expired peers from device")
+		}
+	}
+}
+
+// peerServerIds returns the distinct servers of the peers, in the order they first appear.
+func peerServerIds(peers []*peer.Peer) []string {
+	var serverIds []string
+	for _, p := range peers {
+		if !slices.Contains(serverIds, p.ServerId) {
+			serverIds = append(serverIds, p.ServerId)
+		}
+	}
+	return serverIds
+}
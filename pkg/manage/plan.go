@@ -0,0 +1,277 @@
+package manage
+
+import (
+	"fmt"
+	"net/netip"
+	"slices"
+	"strconv"
+	"strings"
+
+	"golang.zx2c4.com/wireguard/wgctrl/wgtypes"
+
+	"github.com/UnAfraid/wg-ui/pkg/wireguard/driver"
+)
+
+// Plan describes the changes a mutation would make to the device of a server, without applying them.
+type Plan struct {
+	Changes           []*PlanChange
+	AddressesToAdd    []string
+	AddressesToRemove []string
+	RoutesToAdd       []string
+	RoutesToRemove    []string
+	// Restart reports whether the interface would be taken down or its address, MTU, listen port or key
+	// would change, either of which interrupts the established tunnels.
+	Restart bool
+}
+
+type PlanChange struct {
+	Field  string
+	Before string
+	After  string
+}
+
+type planField struct {
+	name  string
+	value string
+}
+
+// computePlan compares the device configuration before and after a change,
+// a nil configuration means the device is not running.
+func computePlan(before *driver.ConfigureOptions, after *driver.ConfigureOptions) *Plan {
+	plan := &Plan{}
+	if before == nil && after == nil {
+		return plan
+	}
+
+	beforeFields := flattenConfigureOptions(before)
+	afterFields := flattenConfigureOptions(after)
+
+	afterValues := make(map[string]string, len(afterFields))
+	for _, field := range afterFields {
+		afterValues[field.name] = field.value
+	}
+
+	seen := make(map[string]struct{}, len(beforeFields))
+	for _, field := range beforeFields {
+		seen[field.name] = struct{}{}
+		if after != nil && field.value != afterValues[field.name] {
+			plan.Changes = append(plan.Changes, &PlanChange{
+				Field:  field.name,
+				Before: field.value,
+				After:  afterValues[field.name],
+			})
+		}
+	}
+	for _, field := range afterFields {
+		if _, ok := seen[field.name]; !ok && field.value != "" {
+			plan.Changes = append(plan.Changes, &PlanChange{
+				Field:  field.name,
+				Before: "",
+				After:  field.value,
+			})
+		}
+	}
+
+	if (before == nil) != (after == nil) {
+		plan.Changes = slices.Insert(plan.Changes, 0, &PlanChange{
+			Field:  "running",
+			Before: strconv.FormatBool(before != nil),
+			After:  strconv.FormatBool(after != nil),
+		})
+	}
+
+	plan.AddressesToAdd, plan.AddressesToRemove = diffValues(planAddresses(before), planAddresses(after))
+	plan.RoutesToAdd, plan.RoutesToRemove = diffValues(planRoutes(before), planRoutes(after))
+
+	if before != nil {
+		plan.Restart = after == nil ||
+			len(plan.AddressesToAdd) != 0 ||
+			len(plan.AddressesToRemove) != 0 ||
+			before.InterfaceOptions.Mtu != after.InterfaceOptions.Mtu ||
+			!equalIntPointers(before.WireguardOptions.ListenPort, after.WireguardOptions.ListenPort) ||
+			before.WireguardOptions.PrivateKey != after.WireguardOptions.PrivateKey
+	}
+
+	return plan
+}
+
+// flattenConfigureOptions lists the configuration as named values, secrets are replaced by the derived public key
+// or a marker, so they never leave the server.
+func flattenConfigureOptions(options *driver.ConfigureOptions) []planField {
+	if options == nil {
+		return nil
+	}
+
+	fields := []planField{
+		{name: "name", value: options.InterfaceOptions.Name},
+		{name: "description", value: options.InterfaceOptions.Description},
+		{name: "address", value: options.InterfaceOptions.Address},
+		{name: "dns", value: strings.Join(options.InterfaceOptions.DNS, ", ")},
+		{name: "mtu", value: formatNonZero(options.InterfaceOptions.Mtu)},
+		{name: "hooks", value: formatHooks(options.InterfaceOptions.Hooks)},
+		{name: "publicKey", value: derivePublicKey(options.WireguardOptions.PrivateKey)},
+		{name: "listenPort", value: formatIntPointer(options.WireguardOptions.ListenPort)},
+		{name: "firewallMark", value: formatIntPointer(options.WireguardOptions.FirewallMark)},
+		{name: "firewall", value: formatFirewall(options.InterfaceOptions.Firewall)},
+	}
+
+	for _, p := range options.WireguardOptions.Peers {
+		if p == nil {
+			continue
+		}
+
+		label := p.Name
+		if label == "" {
+			label = p.PublicKey
+		}
+
+		var presharedKey string
+		if p.PresharedKey != "" {
+			presharedKey = "(set)"
+		}
+
+		fields = append(fields,
+			planField{name: fmt.Sprintf("peer %s", label), value: "present"},
+			planField{name: fmt.Sprintf("peer %s publicKey", label), value: p.PublicKey},
+			planField{name: fmt.Sprintf("peer %s description", label), value: p.Description},
+			planField{name: fmt.Sprintf("peer %s endpoint", label), value: p.Endpoint},
+			planField{name: fmt.Sprintf("peer %s allowedIPs", label), value: strings.Join(normalizePrefixes(p.AllowedIPs), ", ")},
+			planField{name: fmt.Sprintf("peer %s presharedKey", label), value: presharedKey},
+			planField{name: fmt.Sprintf("peer %s persistentKeepalive", label), value: formatNonZero(p.PersistentKeepalive)},
+			planField{name: fmt.Sprintf("peer %s allowedDestinations", label), value: strings.Join(normalizePrefixes(p.AllowedDestinations), ", ")},
+			planField{name: fmt.Sprintf("peer %s acl", label), value: formatACL(p.ACL)},
+		)
+	}
+
+	return fields
+}
+
+func planAddresses(options *driver.ConfigureOptions) []string {
+	if options == nil {
+		return nil
+	}
+
+	var addresses []string
+	for _, address := range strings.Split(options.InterfaceOptions.Address, ",") {
+		address = strings.TrimSpace(address)
+		if address == "" {
+			continue
+		}
+		if prefix, err := netip.ParsePrefix(address); err == nil {
+			address = prefix.String()
+		}
+		addresses = append(addresses, address)
+	}
+	return addresses
+}
+
+func planRoutes(options *driver.ConfigureOptions) []string {
+	if options == nil {
+		return nil
+	}
+
+	var allowedIPs []string
+	for _, p := range options.WireguardOptions.Peers {
+		if p != nil {
+			allowedIPs = append(allowedIPs, p.AllowedIPs...)
+		}
+	}
+	return normalizePrefixes(allowedIPs)
+}
+
+func diffValues(before []string, after []string) (added []string, removed []string) {
+	for _, value := range after {
+		if !slices.Contains(before, value) && !slices.Contains(added, value) {
+			added = append(added, value)
+		}
+	}
+	for _, value := range before {
+		if !slices.Contains(after, value) && !slices.Contains(removed, value) {
+			removed = append(removed, value)
+		}
+	}
+	slices.Sort(added)
+	slices.Sort(removed)
+	return added, removed
+}
+
+func derivePublicKey(privateKey string) string {
+	if privateKey == "" {
+		return ""
+	}
+
+	key, err := wgtypes.ParseKey(privateKey)
+	if err != nil {
+		return "(invalid)"
+	}
+	return key.PublicKey().String()
+}
+
+func formatHooks(hooks []*driver.HookOptions) string {
+	var commands []string
+	for _, hook := range hooks {
+		if hook != nil {
+			commands = append(commands, hook.Command)
+		}
+	}
+	return strings.Join(commands, "; ")
+}
+
+func formatFirewall(options *driver.FirewallOptions) string {
+	if options == nil {
+		return ""
+	}
+
+	var settings []string
+	if options.MasqueradeInterface != "" {
+		settings = append(settings, "masquerade "+options.MasqueradeInterface)
+	}
+	if options.IPForwarding {
+		settings = append(settings, "forwarding")
+	}
+	if options.PeerIsolation {
+		settings = append(settings, "peer isolation")
+	}
+	return strings.Join(settings, ", ")
+}
+
+func formatACL(rules []*driver.ACLRule) string {
+	formatted := make([]string, 0, len(rules))
+	for _, rule := range rules {
+		protocol := string(rule.Protocol)
+		if protocol == "" {
+			protocol = string(driver.ACLProtocolAny)
+		}
+
+		value := fmt.Sprintf("%s %s %s", rule.Action, protocol, strings.Join(normalizePrefixes([]string{rule.Destination}), ""))
+		if rule.FromPort != 0 {
+			value += ":" + strconv.Itoa(rule.FromPort)
+			if rule.ToPort != 0 && rule.ToPort != rule.FromPort {
+				value += "-" + strconv.Itoa(rule.ToPort)
+			}
+		}
+		formatted = append(formatted, value)
+	}
+	return strings.Join(formatted, ", ")
+}
+
+func formatNonZero(value int) string {
+	if value == 0 {
+		return ""
+	}
+	return strconv.Itoa(value)
+}
+
+func formatIntPointer(value *int) string {
+	if value == nil {
+		return ""
+	}
+	return strconv.Itoa(*value)
+}
+
+func equalIntPointers(a *int, b *int) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
@@ -0,0 +1,91 @@
+package manage
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/UnAfraid/wg-ui/pkg/peer"
+	"github.com/UnAfraid/wg-ui/pkg/server"
+)
+
+func TestComputePlanForPeerChange(t *testing.T) {
+	srv := &server.Server{
+		Name:    "wg0",
+		Enabled: true,
+		Running: true,
+		Address: "10.0.0.1/24",
+		MTU:     1420,
+	}
+	peers := []*peer.Peer{
+		{Id: "1", Name: "alpha", PublicKey: "alpha-key", AllowedIPs: []string{"10.0.0.2/32"}},
+	}
+	changedPeers := []*peer.Peer{
+		{Id: "1", Name: "alpha", PublicKey: "alpha-key", AllowedIPs: []string{"10.0.0.2/32", "192.168.1.0/24"}},
+		{Id: "2", Name: "bravo", PublicKey: "bravo-key", AllowedIPs: []string{"10.0.0.3/32"}},
+	}
+
+	plan := computePlan(runningConfigureOptions(srv, peers), runningConfigureOptions(srv, changedPeers))
+
+	if plan.Restart {
+		t.Fatalf("expected no restart for a peer change")
+	}
+	if len(plan.AddressesToAdd) != 0 || len(plan.AddressesToRemove) != 0 {
+		t.Fatalf("expected no address changes, got +%v -%v", plan.AddressesToAdd, plan.AddressesToRemove)
+	}
+	if expected := []string{"10.0.0.3/32", "192.168.1.0/24"}; !slices.Equal(plan.RoutesToAdd, expected) {
+		t.Fatalf("expected routes to add %v, got %v", expected, plan.RoutesToAdd)
+	}
+	if len(plan.RoutesToRemove) != 0 {
+		t.Fatalf("expected no routes to remove, got %v", plan.RoutesToRemove)
+	}
+
+	expected := []PlanChange{
+		{Field: "peer alpha allowedIPs", Before: "10.0.0.2/32", After: "10.0.0.2/32, 192.168.1.0/24"},
+		{Field: "peer bravo", Before: "", After: "present"},
+		{Field: "peer bravo publicKey", Before: "", After: "bravo-key"},
+		{Field: "peer bravo allowedIPs", Before: "", After: "10.0.0.3/32"},
+	}
+	if len(plan.Changes) != len(expected) {
+		t.Fatalf("expected %d changes, got %d: %+v", len(expected), len(plan.Changes), plan.Changes)
+	}
+	for i, change := range plan.Changes {
+		if *change != expected[i] {
+			t.Fatalf("change %d: expected %+v, got %+v", i, expected[i], *change)
+		}
+	}
+}
+
+func TestComputePlanRestartsOnInterfaceChange(t *testing.T) {
+	listenPort := 51820
+	srv := &server.Server{
+		Name:       "wg0",
+		Enabled:    true,
+		Running:    true,
+		Address:    "10.0.0.1/24",
+		ListenPort: &listenPort,
+	}
+	peers := []*peer.Peer{
+		{Name: "alpha", PublicKey: "alpha-key", AllowedIPs: []string{"10.0.0.2/32"}},
+	}
+	updatedServer := *srv
+	updatedServer.Address = "10.1.0.1/24"
+
+	plan := computePlan(runningConfigureOptions(srv, peers), runningConfigureOptions(&updatedServer, peers))
+	if !plan.Restart {
+		t.Fatalf("expected restart when the address changes")
+	}
+	if !slices.Equal(plan.AddressesToAdd, []string{"10.1.0.1/24"}) || !slices.Equal(plan.AddressesToRemove, []string{"10.0.0.1/24"}) {
+		t.Fatalf("unexpected address changes +%v -%v", plan.AddressesToAdd, plan.AddressesToRemove)
+	}
+
+	plan = computePlan(runningConfigureOptions(srv, peers), nil)
+	if !plan.Restart {
+		t.Fatalf("expected restart when the server is stopped")
+	}
+	if !slices.Equal(plan.RoutesToRemove, []string{"10.0.0.2/32"}) {
+		t.Fatalf("expected routes to remove [10.0.0.2/32], got %v", plan.RoutesToRemove)
+	}
+	if len(plan.Changes) != 1 || plan.Changes[0].Field != "running" || plan.Changes[0].After != "false" {
+		t.Fatalf("expected only the running change, got %+v", plan.Changes)
+	}
+}
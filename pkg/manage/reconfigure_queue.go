@@ -0,0 +1,150 @@
+package manage
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"time"
+)
+
+var errReconfigureQueueClosed = errors.New("device reconfiguration queue is closed")
+
+// reconfigureQueue coalesces device reconfigurations of the same server requested within delay into a single apply.
+// Applies of the same server never overlap, applies of different servers run concurrently.
+type reconfigureQueue struct {
+	delay      time.Duration
+	apply      reconfigureFunc
+	lock       sync.Mutex
+	pending    map[string]*reconfigureRequest
+	serverLock map[string]*sync.Mutex
+	closed     bool
+	abandoned  bool
+	waitGroup  sync.WaitGroup
+}
+
+// reconfigureFunc applies the device of a server, publicKeys lists the changed peers and is nil when the whole
+// device has to be applied.
+type reconfigureFunc func(ctx context.Context, serverId string, userId string, publicKeys []string) error
+
+type reconfigureRequest struct {
+	userId     string
+	full       bool
+	publicKeys []string
+	done       chan struct{}
+	err        error
+}
+
+func newReconfigureQueue(delay time.Duration, apply reconfigureFunc) *reconfigureQueue {
+	return &reconfigureQueue{
+		delay:      delay,
+		apply:      apply,
+		pending:    make(map[string]*reconfigureRequest),
+		serverLock: make(map[string]*sync.Mutex),
+	}
+}
+
+// enqueue schedules a reconfiguration of the server, joining an already scheduled one when possible.
+// Without public keys the whole device is applied, otherwise only the peers with the keys.
+func (q *reconfigureQueue) enqueue(serverId string, userId string, publicKeys ...string) *reconfigureRequest {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	if request, ok := q.pending[serverId]; ok {
+		request.userId = userId
+		request.addPublicKeys(publicKeys)
+		return request
+	}
+
+	request := &reconfigureRequest{
+		userId: userId,
+		done:   make(chan struct{}),
+	}
+	request.addPublicKeys(publicKeys)
+
+	if q.closed {
+		request.err = errReconfigureQueueClosed
+		close(request.done)
+		return request
+	}
+
+	q.pending[serverId] = request
+	q.waitGroup.Add(1)
+	time.AfterFunc(q.delay, func() {
+		q.flush(serverId)
+	})
+	return request
+}
+
+func (q *reconfigureQueue) flush(serverId string) {
+	defer q.waitGroup.Done()
+
+	q.lock.Lock()
+	request := q.pending[serverId]
+	delete(q.pending, serverId)
+	serverLock, ok := q.serverLock[serverId]
+	if !ok {
+		serverLock = &sync.Mutex{}
+		q.serverLock[serverId] = serverLock
+	}
+	userId := request.userId
+	var publicKeys []string
+	if !request.full {
+		publicKeys = request.publicKeys
+	}
+	abandoned := q.abandoned
+	q.lock.Unlock()
+
+	if abandoned {
+		request.err = errReconfigureQueueClosed
+		close(request.done)
+		return
+	}
+
+	serverLock.Lock()
+	defer serverLock.Unlock()
+
+	request.err = q.apply(context.Background(), serverId, userId, publicKeys)
+	close(request.done)
+}
+
+// close rejects new requests and waits for the scheduled ones to be applied.
+func (q *reconfigureQueue) close() {
+	q.lock.Lock()
+	q.closed = true
+	q.lock.Unlock()
+
+	q.waitGroup.Wait()
+}
+
+// abandon rejects new requests and fails the scheduled ones that were not applied yet.
+func (q *reconfigureQueue) abandon() {
+	q.lock.Lock()
+	q.closed = true
+	q.abandoned = true
+	q.lock.Unlock()
+
+	q.waitGroup.Wait()
+}
+
+func (r *reconfigureRequest) addPublicKeys(publicKeys []string) {
+	if len(publicKeys) == 0 {
+		r.full = true
+		return
+	}
+
+	for _, publicKey := range publicKeys {
+		if !slices.Contains(r.publicKeys, publicKey) {
+			r.publicKeys = append(r.publicKeys, publicKey)
+		}
+	}
+}
+
+func (r *reconfigureRequest) wait(ctx context.Context) error {
+	select {
+	case <-r.done:
+		return r.err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
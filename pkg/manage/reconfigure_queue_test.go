@@ -0,0 +1,113 @@
+package manage
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestReconfigureQueueCoalescesRequestsPerServer(t *testing.T) {
+	var applies atomic.Int32
+	var lastUserId atomic.Value
+	queue := newReconfigureQueue(50*time.Millisecond, func(_ context.Context, serverId string, userId string, _ []string) error {
+		applies.Add(1)
+		lastUserId.Store(userId)
+		return nil
+	})
+	defer queue.close()
+
+	var waitGroup sync.WaitGroup
+	for _, userId := range []string{"user-1", "user-2", "user-3"} {
+		request := queue.enqueue("server-1", userId)
+		waitGroup.Add(1)
+		go func() {
+			defer waitGroup.Done()
+			if err := request.wait(context.Background()); err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		}()
+	}
+	waitGroup.Wait()
+
+	if got := applies.Load(); got != 1 {
+		t.Fatalf("expected a single apply, got %d", got)
+	}
+	if got := lastUserId.Load(); got != "user-3" {
+		t.Fatalf("expected apply to use the last user id, got %v", got)
+	}
+}
+
+func TestReconfigureQueueReportsErrorToAllWaiters(t *testing.T) {
+	applyErr := errors.New("apply failed")
+	queue := newReconfigureQueue(10*time.Millisecond, func(context.Context, string, string, []string) error {
+		return applyErr
+	})
+	defer queue.close()
+
+	first := queue.enqueue("server-1", "")
+	second := queue.enqueue("server-1", "")
+	for _, request := range []*reconfigureRequest{first, second} {
+		if err := request.wait(context.Background()); !errors.Is(err, applyErr) {
+			t.Fatalf("expected apply error, got %v", err)
+		}
+	}
+}
+
+func TestReconfigureQueueRejectsRequestsAfterClose(t *testing.T) {
+	queue := newReconfigureQueue(time.Millisecond, func(context.Context, string, string, []string) error {
+		return nil
+	})
+	queue.close()
+
+	if err := queue.enqueue("server-1", "").wait(context.Background()); !errors.Is(err, errReconfigureQueueClosed) {
+		t.Fatalf("expected queue closed error, got %v", err)
+	}
+}
+
+func TestReconfigureQueueDropsScheduledRequestsWhenAbandoned(t *testing.T) {
+	var applies atomic.Int32
+	queue := newReconfigureQueue(50*time.Millisecond, func(context.Context, string, string, []string) error {
+		applies.Add(1)
+		return nil
+	})
+
+	request := queue.enqueue("server-1", "")
+	queue.abandon()
+
+	if err := request.wait(context.Background()); !errors.Is(err, errReconfigureQueueClosed) {
+		t.Fatalf("expected queue closed error, got %v", err)
+	}
+	if got := applies.Load(); got != 0 {
+		t.Fatalf("expected no apply, got %d", got)
+	}
+}
+
+func TestReconfigureQueueMergesChangedPeers(t *testing.T) {
+	applied := make(chan []string, 2)
+	queue := newReconfigureQueue(20*time.Millisecond, func(_ context.Context, _ string, _ string, publicKeys []string) error {
+		applied <- publicKeys
+		return nil
+	})
+	defer queue.close()
+
+	first := queue.enqueue("server-1", "", "alpha")
+	queue.enqueue("server-1", "", "bravo", "alpha")
+	if err := first.wait(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if publicKeys := <-applied; !slices.Equal(publicKeys, []string{"alpha", "bravo"}) {
+		t.Fatalf("expected the changed peers alpha and bravo, got %v", publicKeys)
+	}
+
+	queue.enqueue("server-1", "", "alpha")
+	if err := queue.enqueue("server-1", "").wait(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if publicKeys := <-applied; publicKeys != nil {
+		t.Fatalf("expected a full apply, got %v", publicKeys)
+	}
+}
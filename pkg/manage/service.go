@@ -5,7 +5,9 @@ import (
 	"errors"
 	"fmt"
 	"net"
+	"slices"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/sirupsen/logrus"
@@ -21,6 +23,9 @@ import (
 	"github.com/UnAfraid/wg-ui/pkg/wireguard/driver"
 )
 
+// ErrLeaderOnly is returned by a read only service for the data that is read from the devices.
+var ErrLeaderOnly = errors.New("live device data is only available on the cluster leader")
+
 type Service interface {
 	Authenticate(ctx context.Context, username string, password string) (*user.User, error)
 	CreateUser(ctx context.Context, options *user.CreateOptions) (*user.User, error)
@@ -28,20 +33,38 @@ type Service interface {
 	DeleteUser(ctx context.Context, userId string) (*user.User, error)
 	CreateBackend(ctx context.Context, options *backend.CreateOptions, userId string) (*backend.Backend, error)
 	UpdateBackend(ctx context.Context, backendId string, options *backend.UpdateOptions, fieldMask *backend.UpdateFieldMask, userId string) (*backend.Backend, error)
+	TestBackend(ctx context.Context, backendId string, rawURL string) (*BackendDiagnostics, error)
 	CreateServer(ctx context.Context, options *server.CreateOptions, userId string) (*server.Server, error)
 	UpdateServer(ctx context.Context, serverId string, options *server.UpdateOptions, fieldMask *server.UpdateFieldMask, userId string) (*server.Server, error)
 	DeleteServer(ctx context.Context, serverId string, userId string) (*server.Server, error)
+	PlanCreateServer(ctx context.Context, options *server.CreateOptions, userId string) (*server.Server, *Plan, error)
+	PlanUpdateServer(ctx context.Context, serverId string, options *server.UpdateOptions, fieldMask *server.UpdateFieldMask, userId string) (*server.Server, *Plan, error)
+	PlanDeleteServer(ctx context.Context, serverId string) (*server.Server, *Plan, error)
 	StartServer(ctx context.Context, serverId string, userId string) (*server.Server, error)
 	StopServer(ctx context.Context, serverId string, userId string) (*server.Server, error)
+	MoveServer(ctx context.Context, serverId string, targetBackendId string, userId string) (*server.Server, error)
 	ImportForeignServer(ctx context.Context, backendId string, name string, userId string) (*server.Server, error)
 	CreatePeer(ctx context.Context, serverId string, options *peer.CreateOptions, userId string) (*peer.Peer, error)
 	UpdatePeer(ctx context.Context, peerId string, options *peer.UpdateOptions, fieldMask *peer.UpdateFieldMask, userId string) (*peer.Peer, error)
 	DeletePeer(ctx context.Context, peerId string, userId string) (*peer.Peer, error)
+	PlanCreatePeer(ctx context.Context, serverId string, options *peer.CreateOptions, userId string) (*peer.Peer, *Plan, error)
+	PlanUpdatePeer(ctx context.Context, peerId string, options *peer.UpdateOptions, fieldMask *peer.UpdateFieldMask, userId string) (*peer.Peer, *Plan, error)
+	PlanDeletePeer(ctx context.Context, peerId string) (*peer.Peer, *Plan, error)
+	ImportPeers(ctx context.Context, serverId string, options *peer.ImportOptions, userId string) (*peer.ImportResult, error)
+	ApplyPeerChanges(ctx context.Context, changes *PeerChanges, userId string) (*PeerChangesResult, error)
+	CreatePeerGroup(ctx context.Context, options *peer.GroupCreateOptions, userId string) (*peer.Group, error)
+	UpdatePeerGroup(ctx context.Context, groupId string, options *peer.GroupUpdateOptions, fieldMask *peer.GroupUpdateFieldMask, userId string) (*peer.Group, error)
+	DeletePeerGroup(ctx context.Context, groupId string, userId string) (*peer.Group, error)
+	ApplyPeerGroupAction(ctx context.Context, groupId string, action PeerGroupAction, userId string) ([]*peer.Peer, error)
 	PeerStats(ctx context.Context, serverId string, peerPublicKey string) (*driver.PeerStats, error)
 	ForeignServers(ctx context.Context, backendId string) ([]*driver.ForeignServer, error)
 	ForeignServersAll(ctx context.Context) ([]*driver.ForeignServer, error)
 	DeleteBackend(ctx context.Context, backendId string, userId string) (*backend.Backend, error)
+	BackendHealth(backendId string) backend.Health
 	Close()
+	// Abandon stops the service like Close without applying the shutdown policies and hooks of the servers and
+	// drops the pending device reconfigurations, it is used once the devices belong to another cluster leader.
+	Abandon()
 }
 
 type service struct {
@@ -51,8 +74,12 @@ type service struct {
 	serverService     server.Service
 	peerService       peer.Service
 	wireguardService  wireguard.Service
+	reconfigureQueue  *reconfigureQueue
+	shutdownTimeout   time.Duration
 	stopChan          chan struct{}
-	stoppedChan       chan struct{}
+	stopOnce          sync.Once
+	workers           sync.WaitGroup
+	readOnly          bool
 }
 
 func NewService(
@@ -64,6 +91,9 @@ func NewService(
 	wireguardService wireguard.Service,
 	automaticStatsUpdateInterval time.Duration,
 	automaticStatsUpdateOnlyWithSubscribers bool,
+	deviceReconfigureDelay time.Duration,
+	driftCheckInterval time.Duration,
+	shutdownTimeout time.Duration,
 ) Service {
 	s := &service{
 		transactionScoper: transactionScoper,
@@ -72,25 +102,59 @@ func NewService(
 		serverService:     serverService,
 		peerService:       peerService,
 		wireguardService:  wireguardService,
+		shutdownTimeout:   shutdownTimeout,
 		stopChan:          make(chan struct{}),
-		stoppedChan:       make(chan struct{}),
 	}
+	s.reconfigureQueue = newReconfigureQueue(deviceReconfigureDelay, s.applyServerDevice)
 
 	s.cleanup(context.Background())
 	s.init()
 
 	if automaticStatsUpdateInterval.Seconds() > 0 {
+		s.workers.Add(1)
 		go s.run(automaticStatsUpdateInterval, automaticStatsUpdateOnlyWithSubscribers)
 	}
 
+	if driftCheckInterval.Seconds() > 0 {
+		s.workers.Add(1)
+		go s.runDriftCheck(driftCheckInterval)
+	}
+
+	s.workers.Add(1)
+	go s.runPeerExpiry()
+
+	return s
+}
+
+// NewReadOnlyService serves the stored data without starting, reconfiguring or watching the servers, cluster
+// followers use it to answer queries from a database snapshot. The wireguard service is only asked for
+// the backend health, the data read from the devices is rejected with ErrLeaderOnly.
+func NewReadOnlyService(
+	transactionScoper dbx.TransactionScoper,
+	userService user.Service,
+	backendService backend.Service,
+	serverService server.Service,
+	peerService peer.Service,
+	wireguardService wireguard.Service,
+) Service {
+	s := &service{
+		transactionScoper: transactionScoper,
+		userService:       userService,
+		backendService:    backendService,
+		serverService:     serverService,
+		peerService:       peerService,
+		wireguardService:  wireguardService,
+		stopChan:          make(chan struct{}),
+		readOnly:          true,
+	}
+	s.reconfigureQueue = newReconfigureQueue(0, s.applyServerDevice)
 	return s
 }
 
+// init starts the servers according to their startup policy.
 func (s *service) init() {
 	ctx := context.Background()
-	servers, err := s.serverService.FindServers(ctx, &server.FindOptions{
-		Enabled: adapt.ToPointerNilZero(true),
-	})
+	servers, err := s.serverService.FindServers(ctx, &server.FindOptions{})
 	if err != nil {
 		logrus.WithError(err).Error("failed to find servers")
 		return
@@ -136,16 +200,15 @@ func (s *service) init() {
 			continue
 		}
 
-		peers, err := s.peerService.FindPeers(ctx, &peer.FindOptions{
-			ServerId: adapt.ToPointer(srv.Id),
-		})
-		if err != nil {
-			logrus.WithError(err).WithField("name", srv.Name).Error("failed to find peers for server")
-			failed++
+		if !srv.ShouldStart() {
+			logrus.WithField("name", srv.Name).WithField("startupPolicy", srv.StartupPolicy).Debug("startup policy does not start the server, skipping initialization")
+			if err := s.syncServerRunning(ctx, b, srv); err != nil {
+				logrus.WithError(err).WithField("name", srv.Name).Warn("failed to update server running state")
+			}
 			continue
 		}
 
-		if _, err = s.configureDevice(ctx, srv, peers); err != nil {
+		if err := s.startServerOnInit(ctx, b, srv); err != nil {
 			logrus.WithError(err).WithField("name", srv.Name).Error("failed to configure wireguard device")
 			failed++
 			continue
@@ -165,6 +228,70 @@ func (s *service) init() {
 	}
 }
 
+// startServerOnInit configures the device of the server, the up hooks only run when the device was not left running.
+func (s *service) startServerOnInit(ctx context.Context, b *backend.Backend, srv *server.Server) error {
+	if !srv.Enabled {
+		enabledServer, err := s.serverService.UpdateServer(ctx, srv.Id, &server.UpdateOptions{
+			Enabled: true,
+		}, &server.UpdateFieldMask{
+			Enabled: true,
+		}, "")
+		if err != nil {
+			return fmt.Errorf("failed to enable server: %w", err)
+		}
+		srv = enabledServer
+	}
+
+	peers, err := s.findDevicePeers(ctx, srv.Id)
+	if err != nil {
+		return fmt.Errorf("failed to find peers for server: %w", err)
+	}
+
+	status, err := s.wireguardService.Status(ctx, b, srv.Name)
+	if err != nil {
+		return err
+	}
+
+	if !status {
+		s.runServerHooks(ctx, b, srv, server.HookActionPreUp)
+	}
+
+	device, err := s.configureDevice(ctx, srv, peers)
+	if err != nil {
+		return err
+	}
+
+	updatedServer, err := s.updateServer(ctx, srv, device, "")
+	if err != nil {
+		return err
+	}
+
+	if !status {
+		s.runServerHooks(ctx, b, updatedServer, server.HookActionPostUp)
+	}
+	return nil
+}
+
+// syncServerRunning marks a server that is not started on init as stopped when its device is down,
+// so it is not reported as drifted.
+func (s *service) syncServerRunning(ctx context.Context, b *backend.Backend, srv *server.Server) error {
+	if !srv.Running {
+		return nil
+	}
+
+	status, err := s.wireguardService.Status(ctx, b, srv.Name)
+	if err != nil || status {
+		return err
+	}
+
+	_, err = s.serverService.UpdateServer(ctx, srv.Id, &server.UpdateOptions{
+		Running: false,
+	}, &server.UpdateFieldMask{
+		Running: true,
+	}, "")
+	return err
+}
+
 func (s *service) getOrCreateDefaultBackend(ctx context.Context) (*backend.Backend, error) {
 	// Try to find existing linux backend
 	backends, err := s.backendService.FindBackends(ctx, &backend.FindOptions{})
@@ -189,7 +316,7 @@ func (s *service) getOrCreateDefaultBackend(ctx context.Context) (*backend.Backe
 }
 
 func (s *service) run(interval time.Duration, automaticStatsUpdateOnlyWithSubscribers bool) {
-	defer close(s.stoppedChan)
+	defer s.workers.Done()
 	ctx := context.Background()
 
 	for {
@@ -197,13 +324,39 @@ func (s *service) run(interval time.Duration, automaticStatsUpdateOnlyWithSubscr
 		case <-s.stopChan:
 			return
 		case <-time.After(interval):
-			if !automaticStatsUpdateOnlyWithSubscribers || s.serverService.HasSubscribers() {
+			if !automaticStatsUpdateOnlyWithSubscribers || s.serverService.HasSubscribers() || s.hasPeerDeviceHooks(ctx) || s.hasPeerGroupDeviceHooks(ctx) {
 				s.updateServersStats(ctx)
 			}
 		}
 	}
 }
 
+// hasPeerDeviceHooks reports whether any peer has hooks that depend on the stats updates.
+func (s *service) hasPeerDeviceHooks(ctx context.Context) bool {
+	peers, err := s.peerService.FindPeers(ctx, &peer.FindOptions{})
+	if err != nil {
+		logrus.
+			WithError(err).
+			Error("failed to find peers")
+		return false
+	}
+	return slices.ContainsFunc(peers, (*peer.Peer).HasDeviceHooks)
+}
+
+func (s *service) runDriftCheck(interval time.Duration) {
+	defer s.workers.Done()
+	ctx := context.Background()
+
+	for {
+		select {
+		case <-s.stopChan:
+			return
+		case <-time.After(interval):
+			s.reconcileServers(ctx)
+		}
+	}
+}
+
 func (s *service) Authenticate(ctx context.Context, username string, password string) (*user.User, error) {
 	return s.userService.Authenticate(ctx, username, password)
 }
@@ -289,6 +442,29 @@ func (s *service) UpdateBackend(ctx context.Context, backendId string, options *
 	return s.backendService.UpdateBackend(ctx, backendId, options, fieldMask, userId)
 }
 
+// TestBackend connects to the backend url and reports the outcome of each step, nothing is saved.
+// When backendId is set, a redacted password in the url is replaced with the one of the existing backend.
+func (s *service) TestBackend(ctx context.Context, backendId string, rawURL string) (*BackendDiagnostics, error) {
+	if backendId != "" {
+		existingBackend, err := s.findBackend(ctx, backendId)
+		if err != nil {
+			return nil, fmt.Errorf("failed to find backend: %w", err)
+		}
+
+		rawURL, err = backend.ReplaceRedactedURLPassword(rawURL, existingBackend.Url)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	parsedURL, err := backend.ParseURL(rawURL)
+	if err != nil {
+		return nil, err
+	}
+
+	return diagnoseBackend(ctx, parsedURL.Type, rawURL), nil
+}
+
 func (s *service) CreateServer(ctx context.Context, options *server.CreateOptions, userId string) (*server.Server, error) {
 	return dbx.InTransactionScopeWithResult(ctx, s.transactionScoper, func(ctx context.Context) (*server.Server, error) {
 		createdServer, err := s.serverService.CreateServer(ctx, options, userId)
@@ -296,12 +472,16 @@ func (s *service) CreateServer(ctx context.Context, options *server.CreateOption
 			return nil, err
 		}
 
-		if createdServer.Enabled {
-			b, err := s.findBackend(ctx, createdServer.BackendId)
-			if err != nil {
-				return nil, fmt.Errorf("failed to find backend: %w", err)
-			}
+		b, err := s.findBackend(ctx, createdServer.BackendId)
+		if err != nil {
+			return nil, fmt.Errorf("failed to find backend: %w", err)
+		}
+
+		if err := requireBackendCapabilities(b, serverRequiredCapabilities(createdServer)...); err != nil {
+			return nil, err
+		}
 
+		if createdServer.Enabled {
 			s.runServerHooks(ctx, b, createdServer, server.HookActionPreUp)
 
 			device, err := s.configureDevice(ctx, createdServer, nil)
@@ -334,6 +514,12 @@ func (s *service) UpdateServer(ctx context.Context, serverId string, options *se
 			return nil, fmt.Errorf("failed to find backend: %w", err)
 		}
 
+		if fieldMask.DNS || fieldMask.Firewall || fieldMask.BackendId {
+			if err := requireBackendCapabilities(b, serverRequiredCapabilities(updatedServer)...); err != nil {
+				return nil, err
+			}
+		}
+
 		if !updatedServer.Enabled {
 			status, err := s.wireguardService.Status(ctx, b, updatedServer.Name)
 			if err != nil {
@@ -361,9 +547,7 @@ func (s *service) UpdateServer(ctx context.Context, serverId string, options *se
 
 		// Reconfigure live interfaces for updates that affect runtime/config state.
 		if updatedServer.Running && serverUpdateRequiresReconfigure(fieldMask) {
-			peers, err := s.peerService.FindPeers(ctx, &peer.FindOptions{
-				ServerId: &updatedServer.Id,
-			})
+			peers, err := s.findDevicePeers(ctx, updatedServer.Id)
 			if err != nil {
 				return nil, fmt.Errorf("failed to find peers: %w", err)
 			}
@@ -389,7 +573,8 @@ func serverUpdateRequiresReconfigure(fieldMask *server.UpdateFieldMask) bool {
 		fieldMask.Address ||
 		fieldMask.DNS ||
 		fieldMask.MTU ||
-		fieldMask.Hooks
+		fieldMask.Hooks ||
+		fieldMask.Firewall
 }
 
 func (s *service) DeleteServer(ctx context.Context, serverId string, userId string) (*server.Server, error) {
@@ -439,6 +624,73 @@ func (s *service) DeleteServer(ctx context.Context, serverId string, userId stri
 	})
 }
 
+// PlanCreateServer computes the device changes of creating a server without persisting it or contacting the backend.
+func (s *service) PlanCreateServer(ctx context.Context, options *server.CreateOptions, userId string) (*server.Server, *Plan, error) {
+	srv, err := s.serverService.PreviewCreateServer(ctx, options, userId)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if !srv.Enabled {
+		return srv, computePlan(nil, nil), nil
+	}
+
+	if err := s.ensureDeviceConfigurable(ctx, srv); err != nil {
+		return nil, nil, err
+	}
+
+	after := configureOptions(srv, nil)
+	return srv, computePlan(nil, &after), nil
+}
+
+// PlanUpdateServer computes the device changes of updating a server without persisting it or contacting the backend,
+// the stored running state of the server is used in place of the device status.
+func (s *service) PlanUpdateServer(ctx context.Context, serverId string, options *server.UpdateOptions, fieldMask *server.UpdateFieldMask, userId string) (*server.Server, *Plan, error) {
+	currentServer, err := s.findServer(ctx, serverId)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	peers, err := s.findDevicePeers(ctx, currentServer.Id)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to find peers: %w", err)
+	}
+
+	updatedServer, err := s.serverService.PreviewUpdateServer(ctx, serverId, options, fieldMask, userId)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	before := runningConfigureOptions(currentServer, peers)
+	after := before
+	if !updatedServer.Enabled {
+		after = nil
+		updatedServer.Running = false
+	} else if currentServer.Running && serverUpdateRequiresReconfigure(fieldMask) {
+		if err := s.ensureDeviceConfigurable(ctx, updatedServer); err != nil {
+			return nil, nil, err
+		}
+		after = runningConfigureOptions(updatedServer, peers)
+	}
+
+	return updatedServer, computePlan(before, after), nil
+}
+
+// PlanDeleteServer computes the device changes of deleting a server without persisting it or contacting the backend.
+func (s *service) PlanDeleteServer(ctx context.Context, serverId string) (*server.Server, *Plan, error) {
+	srv, err := s.findServer(ctx, serverId)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	peers, err := s.findDevicePeers(ctx, srv.Id)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to find peers: %w", err)
+	}
+
+	return srv, computePlan(runningConfigureOptions(srv, peers), nil), nil
+}
+
 func (s *service) StartServer(ctx context.Context, serverId string, userId string) (*server.Server, error) {
 	return dbx.InTransactionScopeWithResult(ctx, s.transactionScoper, func(ctx context.Context) (*server.Server, error) {
 		srv, err := s.findServer(ctx, serverId)
@@ -451,9 +703,7 @@ func (s *service) StartServer(ctx context.Context, serverId string, userId strin
 			return nil, fmt.Errorf("failed to find backend: %w", err)
 		}
 
-		peers, err := s.peerService.FindPeers(ctx, &peer.FindOptions{
-			ServerId: &srv.Id,
-		})
+		peers, err := s.findDevicePeers(ctx, srv.Id)
 		if err != nil {
 			return nil, err
 		}
@@ -505,6 +755,120 @@ func (s *service) StopServer(ctx context.Context, serverId string, userId string
 	})
 }
 
+// MoveServer moves the server to another backend, a running server is brought down on the source backend and up on
+// the target backend with the same keys and peers, when that fails it is brought up on the source backend again.
+func (s *service) MoveServer(ctx context.Context, serverId string, targetBackendId string, userId string) (*server.Server, error) {
+	return dbx.InTransactionScopeWithResult(ctx, s.transactionScoper, func(ctx context.Context) (*server.Server, error) {
+		srv, err := s.findServer(ctx, serverId)
+		if err != nil {
+			return nil, err
+		}
+
+		sourceBackend, err := s.findBackend(ctx, srv.BackendId)
+		if err != nil {
+			return nil, fmt.Errorf("failed to find backend: %w", err)
+		}
+
+		targetBackend, err := s.findBackend(ctx, targetBackendId)
+		if err != nil {
+			return nil, fmt.Errorf("failed to find target backend: %w", err)
+		}
+
+		if targetBackend.Id == sourceBackend.Id {
+			return nil, server.ErrServerAlreadyOnBackend
+		}
+		if !targetBackend.Enabled {
+			return nil, fmt.Errorf("backend %s is disabled", targetBackend.Name)
+		}
+
+		peers, err := s.findDevicePeers(ctx, srv.Id)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := requireBackendCapabilities(targetBackend, serverPeersRequiredCapabilities(srv, peers)...); err != nil {
+			return nil, err
+		}
+
+		updateOptions := &server.UpdateOptions{
+			BackendId: targetBackend.Id,
+		}
+		updateFieldMask := &server.UpdateFieldMask{
+			BackendId: true,
+		}
+		if _, err := s.serverService.PreviewUpdateServer(ctx, srv.Id, updateOptions, updateFieldMask, userId); err != nil {
+			return nil, err
+		}
+
+		running := srv.Enabled && srv.Running
+		if running {
+			s.runServerHooks(ctx, sourceBackend, srv, server.HookActionPreDown)
+			if err := s.wireguardService.Down(ctx, sourceBackend, srv.Name); err != nil {
+				return nil, fmt.Errorf("failed to stop server on backend %s: %w", sourceBackend.Name, err)
+			}
+			s.runServerHooks(ctx, sourceBackend, srv, server.HookActionPostDown)
+		}
+
+		rollback := func(err error) error {
+			if !running {
+				return err
+			}
+			if rollbackErr := s.restoreMovedServer(ctx, srv, peers, sourceBackend, targetBackend); rollbackErr != nil {
+				return errors.Join(err, fmt.Errorf("failed to restore server on backend %s: %w", sourceBackend.Name, rollbackErr))
+			}
+			return err
+		}
+
+		movedServer, err := s.serverService.UpdateServer(ctx, srv.Id, updateOptions, updateFieldMask, userId)
+		if err != nil {
+			return nil, rollback(err)
+		}
+
+		if !running {
+			return movedServer, nil
+		}
+
+		s.runServerHooks(ctx, targetBackend, movedServer, server.HookActionPreUp)
+
+		device, err := s.configureDevice(ctx, movedServer, peers)
+		if err != nil {
+			return nil, rollback(fmt.Errorf("failed to start server on backend %s: %w", targetBackend.Name, err))
+		}
+
+		updatedServer, err := s.updateServer(ctx, movedServer, device, userId)
+		if err != nil {
+			return nil, rollback(err)
+		}
+
+		s.runServerHooks(ctx, targetBackend, updatedServer, server.HookActionPostUp)
+		return updatedServer, nil
+	})
+}
+
+// restoreMovedServer removes what was brought up on the target backend and brings the server up on the source
+// backend again, the backend of the server itself is restored by rolling back the transaction.
+func (s *service) restoreMovedServer(ctx context.Context, srv *server.Server, peers []*peer.Peer, sourceBackend *backend.Backend, targetBackend *backend.Backend) error {
+	if err := s.wireguardService.Down(ctx, targetBackend, srv.Name); err != nil {
+		logrus.
+			WithError(err).
+			WithField("server", srv.Name).
+			WithField("backend", targetBackend.Name).
+			Warn("failed to bring down server on target backend after failed move")
+	}
+
+	logrus.
+		WithField("server", srv.Name).
+		WithField("backend", sourceBackend.Name).
+		Info("restoring server on source backend after failed move")
+
+	s.runServerHooks(ctx, sourceBackend, srv, server.HookActionPreUp)
+	if _, err := s.wireguardService.Up(ctx, sourceBackend, configureOptions(srv, peers)); err != nil {
+		return err
+	}
+	s.runServerHooks(ctx, sourceBackend, srv, server.HookActionPostUp)
+	return nil
+}
+
 func (s *service) ImportForeignServer(ctx context.Context, backendId string, name string, userId string) (*server.Server, error) {
 	return dbx.InTransactionScopeWithResult(ctx, s.transactionScoper, func(ctx context.Context) (*server.Server, error) {
 		b, err := s.findBackend(ctx, backendId)
@@ -512,15 +876,17 @@ func (s *service) ImportForeignServer(ctx context.Context, backendId string, nam
 			return nil, fmt.Errorf("failed to find backend: %w", err)
 		}
 
+		if err := requireBackendCapabilities(b, driver.CapabilityForeignImport); err != nil {
+			return nil, err
+		}
+
 		servers, err := s.serverService.FindServers(ctx, &server.FindOptions{})
 		if err != nil {
 			return nil, fmt.Errorf("failed to find servers: %w", err)
 		}
 		managedServersByPublicKey := serverByPublicKey(servers, "")
 
-		knownInterfaces := adapt.Array(servers, func(server *server.Server) string {
-			return server.Name
-		})
+		knownInterfaces := backendInterfaceNames(servers, b.Id)
 
 		foreignInterfaces, err := s.wireguardService.FindForeignServers(ctx, b, knownInterfaces)
 		if err != nil {
@@ -725,64 +1091,297 @@ func truncateRunes(value string, max int) string {
 }
 
 func (s *service) CreatePeer(ctx context.Context, serverId string, options *peer.CreateOptions, userId string) (*peer.Peer, error) {
-	return dbx.InTransactionScopeWithResult(ctx, s.transactionScoper, func(ctx context.Context) (*peer.Peer, error) {
-		createdPeer, err := s.peerService.CreatePeer(ctx, serverId, options, userId)
-		if err != nil {
+	groupACL, err := s.groupACL(ctx, options.GroupId)
+	if err != nil {
+		return nil, err
+	}
+
+	if peerRequiresFirewall(options.AllowedDestinations, append(slices.Clone(options.ACL), groupACL...)) {
+		if err := s.requireServerBackendCapabilities(ctx, serverId, driver.CapabilityFirewall); err != nil {
 			return nil, err
 		}
-		return s.configurePeerDevice(ctx, createdPeer, userId)
-	})
+	}
+
+	createdPeer, err := s.peerService.CreatePeer(ctx, serverId, options, userId)
+	if err != nil {
+		return nil, err
+	}
+	return s.configurePeerDevice(ctx, createdPeer, userId, createdPeer.PublicKey)
 }
 
 func (s *service) UpdatePeer(ctx context.Context, peerId string, options *peer.UpdateOptions, fieldMask *peer.UpdateFieldMask, userId string) (*peer.Peer, error) {
-	return dbx.InTransactionScopeWithResult(ctx, s.transactionScoper, func(ctx context.Context) (*peer.Peer, error) {
-		updatedPeer, err := s.peerService.UpdatePeer(ctx, peerId, options, fieldMask, userId)
-		if err != nil {
-			return nil, err
+	currentPeer, err := s.findPeer(ctx, peerId)
+	if err != nil {
+		return nil, err
+	}
+
+	if fieldMask.AllowedDestinations || fieldMask.ACL || fieldMask.GroupId {
+		// like on create the acl inherited from the group counts too, the unchanged fields are the current ones
+		groupId, allowedDestinations, acl := currentPeer.GroupId, currentPeer.AllowedDestinations, currentPeer.ACL
+		if fieldMask.GroupId {
+			groupId = options.GroupId
+		}
+		if fieldMask.AllowedDestinations {
+			allowedDestinations = options.AllowedDestinations
+		}
+		if fieldMask.ACL {
+			acl = options.ACL
 		}
-		return s.configurePeerDevice(ctx, updatedPeer, userId)
-	})
-}
 
-func (s *service) DeletePeer(ctx context.Context, peerId string, userId string) (*peer.Peer, error) {
-	return dbx.InTransactionScopeWithResult(ctx, s.transactionScoper, func(ctx context.Context) (*peer.Peer, error) {
-		deletedPeer, err := s.peerService.DeletePeer(ctx, peerId, userId)
+		groupACL, err := s.groupACL(ctx, groupId)
 		if err != nil {
 			return nil, err
 		}
-		return s.configurePeerDevice(ctx, deletedPeer, userId)
-	})
-}
+		if peerRequiresFirewall(allowedDestinations, append(slices.Clone(acl), groupACL...)) {
+			if err := s.requireServerBackendCapabilities(ctx, currentPeer.ServerId, driver.CapabilityFirewall); err != nil {
+				return nil, err
+			}
+		}
+	}
 
-func (s *service) PeerStats(ctx context.Context, serverId string, peerPublicKey string) (*driver.PeerStats, error) {
-	srv, err := s.findServer(ctx, serverId)
+	updatedPeer, err := s.peerService.UpdatePeer(ctx, peerId, options, fieldMask, userId)
 	if err != nil {
 		return nil, err
 	}
 
-	b, err := s.findBackend(ctx, srv.BackendId)
-	if err != nil {
-		return nil, fmt.Errorf("failed to find backend: %w", err)
+	// The previous key is removed from the device when the public key changed.
+	publicKeys := []string{updatedPeer.PublicKey}
+	if currentPeer.PublicKey != updatedPeer.PublicKey {
+		publicKeys = append(publicKeys, currentPeer.PublicKey)
+	}
+	return s.configurePeerDevice(ctx, updatedPeer, userId, publicKeys...)
+}
+
+func (s *service) DeletePeer(ctx context.Context, peerId string, userId string) (*peer.Peer, error) {
+	deletedPeer, err := s.peerService.DeletePeer(ctx, peerId, userId)
+	if err != nil {
+		return nil, err
+	}
+	return s.configurePeerDevice(ctx, deletedPeer, userId, deletedPeer.PublicKey)
+}
+
+func (s *service) requireServerBackendCapabilities(ctx context.Context, serverId string, capabilities ...driver.Capability) error {
+	srv, err := s.findServer(ctx, serverId)
+	if err != nil {
+		return err
+	}
+
+	b, err := s.findBackend(ctx, srv.BackendId)
+	if err != nil {
+		return fmt.Errorf("failed to find backend: %w", err)
+	}
+
+	return requireBackendCapabilities(b, capabilities...)
+}
+
+// PlanCreatePeer computes the device changes of creating a peer without persisting it or contacting the backend.
+func (s *service) PlanCreatePeer(ctx context.Context, serverId string, options *peer.CreateOptions, userId string) (*peer.Peer, *Plan, error) {
+	p, err := s.peerService.PreviewCreatePeer(ctx, serverId, options, userId)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	plan, err := s.planPeerChange(ctx, p.ServerId, func(peers []*peer.Peer) []*peer.Peer {
+		return append(peers, p)
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return p, plan, nil
+}
+
+// PlanUpdatePeer computes the device changes of updating a peer without persisting it or contacting the backend.
+func (s *service) PlanUpdatePeer(ctx context.Context, peerId string, options *peer.UpdateOptions, fieldMask *peer.UpdateFieldMask, userId string) (*peer.Peer, *Plan, error) {
+	p, err := s.peerService.PreviewUpdatePeer(ctx, peerId, options, fieldMask, userId)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	plan, err := s.planPeerChange(ctx, p.ServerId, func(peers []*peer.Peer) []*peer.Peer {
+		return adapt.Array(peers, func(existingPeer *peer.Peer) *peer.Peer {
+			if existingPeer.Id == p.Id {
+				return p
+			}
+			return existingPeer
+		})
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return p, plan, nil
+}
+
+// PlanDeletePeer computes the device changes of deleting a peer without persisting it or contacting the backend.
+func (s *service) PlanDeletePeer(ctx context.Context, peerId string) (*peer.Peer, *Plan, error) {
+	p, err := s.findPeer(ctx, peerId)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	plan, err := s.planPeerChange(ctx, p.ServerId, func(peers []*peer.Peer) []*peer.Peer {
+		return slices.DeleteFunc(peers, func(existingPeer *peer.Peer) bool {
+			return existingPeer.Id == p.Id
+		})
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return p, plan, nil
+}
+
+func (s *service) planPeerChange(ctx context.Context, serverId string, changeFn func(peers []*peer.Peer) []*peer.Peer) (*Plan, error) {
+	srv, err := s.findServer(ctx, serverId)
+	if err != nil {
+		return nil, err
+	}
+
+	peers, err := s.peerService.FindPeers(ctx, &peer.FindOptions{
+		ServerId: &srv.Id,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to find peers: %w", err)
+	}
+
+	b, err := s.findBackend(ctx, srv.BackendId)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find backend: %w", err)
+	}
+
+	beforePeers, err := s.peerService.DevicePeers(ctx, peers)
+	if err != nil {
+		return nil, err
+	}
+
+	afterPeers, err := s.peerService.DevicePeers(ctx, changeFn(slices.Clone(peers)))
+	if err != nil {
+		return nil, err
+	}
+
+	before := runningConfigureOptions(srv, beforePeers)
+	after := runningConfigureOptions(srv, afterPeers)
+	plan := computePlan(before, after)
+	if len(plan.Changes) > 0 && !backendCapabilities(b).LivePeerUpdates {
+		plan.Restart = true
+	}
+	return plan, nil
+}
+
+func (s *service) ImportPeers(ctx context.Context, serverId string, options *peer.ImportOptions, userId string) (*peer.ImportResult, error) {
+	result, err := s.peerService.ImportPeers(ctx, serverId, options, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(result.Errors) != 0 || len(result.Peers) == 0 {
+		return result, nil
+	}
+
+	if err := s.configureServerDevices(ctx, []string{serverId}, userId); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (s *service) ApplyPeerChanges(ctx context.Context, changes *PeerChanges, userId string) (*PeerChangesResult, error) {
+	if changes == nil {
+		return nil, errors.New("peer changes are required")
+	}
+
+	var serverIds []string
+	result, err := dbx.InTransactionScopeWithResult(ctx, s.transactionScoper, func(ctx context.Context) (*PeerChangesResult, error) {
+		addServerId := func(serverId string) {
+			if !slices.Contains(serverIds, serverId) {
+				serverIds = append(serverIds, serverId)
+			}
+		}
+
+		result := &PeerChangesResult{}
+		for i, change := range changes.Create {
+			createdPeer, err := s.peerService.CreatePeer(ctx, change.ServerId, change.Options, userId)
+			if err != nil {
+				return nil, fmt.Errorf("failed to create peer #%d: %w", i+1, err)
+			}
+			result.Created = append(result.Created, createdPeer)
+			addServerId(createdPeer.ServerId)
+		}
+
+		for i, change := range changes.Update {
+			updatedPeer, err := s.peerService.UpdatePeer(ctx, change.PeerId, change.Options, change.FieldMask, userId)
+			if err != nil {
+				return nil, fmt.Errorf("failed to update peer #%d: %w", i+1, err)
+			}
+			result.Updated = append(result.Updated, updatedPeer)
+			addServerId(updatedPeer.ServerId)
+		}
+
+		for i, peerId := range changes.Delete {
+			deletedPeer, err := s.peerService.DeletePeer(ctx, peerId, userId)
+			if err != nil {
+				return nil, fmt.Errorf("failed to delete peer #%d: %w", i+1, err)
+			}
+			result.Deleted = append(result.Deleted, deletedPeer)
+			addServerId(deletedPeer.ServerId)
+		}
+
+		return result, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.configureServerDevices(ctx, serverIds, userId); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (s *service) PeerStats(ctx context.Context, serverId string, peerPublicKey string) (*driver.PeerStats, error) {
+	if s.readOnly {
+		return nil, ErrLeaderOnly
+	}
+
+	srv, err := s.findServer(ctx, serverId)
+	if err != nil {
+		return nil, err
+	}
+
+	b, err := s.findBackend(ctx, srv.BackendId)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find backend: %w", err)
+	}
+
+	if err := requireBackendCapabilities(b, driver.CapabilityPeerStats); err != nil {
+		return nil, err
 	}
 
 	return s.wireguardService.PeerStats(ctx, b, srv.Name, peerPublicKey)
 }
 
+func (s *service) BackendHealth(backendId string) backend.Health {
+	return s.wireguardService.BackendHealth(backendId)
+}
+
 func (s *service) ForeignServers(ctx context.Context, backendId string) ([]*driver.ForeignServer, error) {
+	if s.readOnly {
+		return nil, ErrLeaderOnly
+	}
+
 	b, err := s.findBackend(ctx, backendId)
 	if err != nil {
 		return nil, fmt.Errorf("failed to find backend: %w", err)
 	}
 
+	if err := requireBackendCapabilities(b, driver.CapabilityForeignImport); err != nil {
+		return nil, err
+	}
+
 	servers, err := s.serverService.FindServers(ctx, &server.FindOptions{})
 	if err != nil {
 		return nil, fmt.Errorf("failed to find servers: %w", err)
 	}
 	managedServersByPublicKey := serverByPublicKey(servers, "")
 
-	knownInterfaces := adapt.Array(servers, func(server *server.Server) string {
-		return server.Name
-	})
+	knownInterfaces := backendInterfaceNames(servers, b.Id)
 	foreignServers, err := s.wireguardService.FindForeignServers(ctx, b, knownInterfaces)
 	if err != nil {
 		return nil, err
@@ -791,6 +1390,10 @@ func (s *service) ForeignServers(ctx context.Context, backendId string) ([]*driv
 }
 
 func (s *service) ForeignServersAll(ctx context.Context) ([]*driver.ForeignServer, error) {
+	if s.readOnly {
+		return nil, ErrLeaderOnly
+	}
+
 	backends, err := s.backendService.FindBackends(ctx, &backend.FindOptions{
 		Enabled: adapt.ToPointer(true),
 	})
@@ -803,6 +1406,10 @@ func (s *service) ForeignServersAll(ctx context.Context) ([]*driver.ForeignServe
 	var errs []error
 
 	for _, b := range backends {
+		if !backendCapabilities(b).ForeignImport {
+			continue
+		}
+
 		foreignServers, err := s.ForeignServers(ctx, b.Id)
 		if err != nil {
 			errs = append(errs, fmt.Errorf("backend %s: %w", b.Name, err))
@@ -848,83 +1455,261 @@ func (s *service) DeleteBackend(ctx context.Context, backendId string, userId st
 }
 
 func (s *service) Close() {
-	close(s.stopChan)
-	<-s.stoppedChan
+	s.stopOnce.Do(func() {
+		close(s.stopChan)
+		s.workers.Wait()
+		s.reconfigureQueue.close()
+		if !s.readOnly {
+			s.shutdown()
+		}
+	})
+}
+
+func (s *service) Abandon() {
+	s.stopOnce.Do(func() {
+		close(s.stopChan)
+		s.workers.Wait()
+		s.reconfigureQueue.abandon()
+		logrus.Info("left the devices of the servers to the new cluster leader")
+	})
+}
+
+// shutdown applies the shutdown policy of the running servers, servers that are not done within the shutdown timeout
+// are left as they are. The stored running state is kept, so servers with the RESTORE startup policy start again.
+func (s *service) shutdown() {
+	ctx, cancel := context.WithTimeout(context.Background(), s.shutdownTimeout)
+	defer cancel()
+
+	servers, err := s.serverService.FindServers(ctx, &server.FindOptions{
+		Filter: &server.Filter{
+			Running: adapt.ToPointer(true),
+		},
+	})
+	if err != nil {
+		logrus.WithError(err).Error("failed to find servers")
+		return
+	}
+
+	var wg sync.WaitGroup
+	for _, srv := range servers {
+		if srv.ShutdownPolicy == "" || srv.ShutdownPolicy == server.ShutdownPolicyLeaveRunning {
+			continue
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := s.shutdownServer(ctx, srv); err != nil {
+				logrus.
+					WithError(err).
+					WithField("name", srv.Name).
+					WithField("shutdownPolicy", srv.ShutdownPolicy).
+					Error("failed to apply server shutdown policy")
+			}
+		}()
+	}
+
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-ctx.Done():
+		logrus.WithField("timeout", s.shutdownTimeout).Warn("server shutdown policies did not complete in time")
+	}
 }
 
-func (s *service) configurePeerDevice(ctx context.Context, p *peer.Peer, userId string) (*peer.Peer, error) {
-	srv, err := s.findServer(ctx, p.ServerId)
+func (s *service) shutdownServer(ctx context.Context, srv *server.Server) error {
+	b, err := s.findBackend(ctx, srv.BackendId)
+	if err != nil {
+		return fmt.Errorf("failed to find backend: %w", err)
+	}
+	if !b.Enabled {
+		return nil
+	}
+
+	s.runServerHooks(ctx, b, srv, server.HookActionPreDown)
+	if srv.ShutdownPolicy != server.ShutdownPolicyStop {
+		return nil
+	}
+
+	if err := s.wireguardService.Down(ctx, b, srv.Name); err != nil {
+		return err
+	}
+
+	s.runServerHooks(ctx, b, srv, server.HookActionPostDown)
+	return nil
+}
+
+// configurePeerDevice schedules a reconfiguration of the peer server device and waits for it,
+// changes to peers of the same server made within the reconfigure delay share a single reconfiguration.
+// The peer change is already committed at this point, on failure the server is marked as drifted.
+func (s *service) configurePeerDevice(ctx context.Context, p *peer.Peer, userId string, publicKeys ...string) (*peer.Peer, error) {
+	if err := s.reconfigureQueue.enqueue(p.ServerId, userId, publicKeys...).wait(ctx); err != nil {
+		return nil, fmt.Errorf("peer was saved but the device could not be configured, the server is marked as drifted: %w", err)
+	}
+	return p, nil
+}
+
+// configureServerDevices schedules a reconfiguration of the server devices and waits for them, like
+// configurePeerDevice the changes must be committed already.
+func (s *service) configureServerDevices(ctx context.Context, serverIds []string, userId string) error {
+	requests := make([]*reconfigureRequest, len(serverIds))
+	for i, serverId := range serverIds {
+		requests[i] = s.reconfigureQueue.enqueue(serverId, userId)
+	}
+
+	var errs []error
+	for i, request := range requests {
+		if err := request.wait(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("server %s: %w", serverIds[i], err))
+		}
+	}
+	if len(errs) != 0 {
+		return fmt.Errorf("peers were saved but the devices could not be configured, the servers are marked as drifted: %w", errors.Join(errs...))
+	}
+	return nil
+}
+
+// applyServerDevice applies the queued reconfiguration, the changes are committed already so a failure is recorded
+// as drift of the server instead of being rolled back.
+func (s *service) applyServerDevice(ctx context.Context, serverId string, userId string, publicKeys []string) error {
+	err := s.transactionScoper.InTransactionScope(ctx, func(ctx context.Context) error {
+		return s.configureServerDevicePeers(ctx, serverId, userId, publicKeys)
+	})
+	if err != nil {
+		if driftErr := s.markServerDrifted(ctx, serverId, err); driftErr != nil {
+			logrus.
+				WithError(driftErr).
+				WithField("serverId", serverId).
+				Warn("failed to mark server as drifted")
+		}
+	}
+	return err
+}
+
+// findDevicePeers returns the peers of the server as they are configured on its device.
+func (s *service) findDevicePeers(ctx context.Context, serverId string) ([]*peer.Peer, error) {
+	peers, err := s.peerService.FindPeers(ctx, &peer.FindOptions{
+		ServerId: &serverId,
+	})
 	if err != nil {
 		return nil, err
 	}
+	return s.peerService.DevicePeers(ctx, peers)
+}
+
+// configureServerDevicePeers applies the peers with the public keys of a running server to its device,
+// the whole device is applied when there are no keys or the backend can not change single peers.
+func (s *service) configureServerDevicePeers(ctx context.Context, serverId string, userId string, publicKeys []string) error {
+	srv, err := s.findServer(ctx, serverId)
+	if err != nil {
+		return err
+	}
 
 	b, err := s.findBackend(ctx, srv.BackendId)
 	if err != nil {
-		return nil, fmt.Errorf("failed to find backend: %w", err)
+		return fmt.Errorf("failed to find backend: %w", err)
 	}
 
 	status, err := s.wireguardService.Status(ctx, b, srv.Name)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
 	if !status {
-		return p, nil
+		return nil
 	}
 
-	peers, err := s.peerService.FindPeers(ctx, &peer.FindOptions{
-		ServerId: &srv.Id,
-	})
+	peers, err := s.findDevicePeers(ctx, srv.Id)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
-	device, err := s.configureDevice(ctx, srv, peers)
+	device, err := s.configureDevicePeers(ctx, srv, peers, publicKeys)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
 	if _, err := s.updateServer(ctx, srv, device, userId); err != nil {
-		return nil, err
+		return err
 	}
 
-	return p, nil
+	return nil
 }
 
 func (s *service) configureDevice(ctx context.Context, srv *server.Server, peers []*peer.Peer) (*driver.Device, error) {
+	return s.configureDevicePeers(ctx, srv, peers, nil)
+}
+
+func (s *service) configureDevicePeers(ctx context.Context, srv *server.Server, peers []*peer.Peer, publicKeys []string) (*driver.Device, error) {
+	if err := s.ensureDeviceConfigurable(ctx, srv); err != nil {
+		return nil, err
+	}
+
 	b, err := s.findBackend(ctx, srv.BackendId)
 	if err != nil {
 		return nil, fmt.Errorf("failed to find backend: %w", err)
 	}
 
+	options := configureOptions(srv, peers)
+	if options.ManagesFirewall() {
+		if err := requireBackendCapabilities(b, driver.CapabilityFirewall); err != nil {
+			return nil, err
+		}
+	}
+	if len(options.InterfaceOptions.DNS) > 0 && !backendCapabilities(b).DNS {
+		logrus.
+			WithField("server", srv.Name).
+			WithField("backend", b.Name).
+			Warn("backend does not support dns, the dns servers are not applied")
+	}
+
+	if len(publicKeys) > 0 {
+		device, err := s.wireguardService.UpdatePeers(ctx, b, options, publicKeys)
+		if !errors.Is(err, driver.ErrPeerUpdatesNotSupported) {
+			return device, err
+		}
+	}
+
+	return s.wireguardService.Up(ctx, b, options)
+}
+
+func (s *service) ensureDeviceConfigurable(ctx context.Context, srv *server.Server) error {
+	b, err := s.findBackend(ctx, srv.BackendId)
+	if err != nil {
+		return fmt.Errorf("failed to find backend: %w", err)
+	}
+
 	if !b.Enabled {
-		return nil, fmt.Errorf("backend %s is disabled", b.Name)
+		return fmt.Errorf("backend %s is disabled", b.Name)
 	}
 
-	if err := s.ensurePublicKeyUnique(ctx, srv.PublicKey, srv.Id); err != nil {
-		return nil, err
+	return s.ensurePublicKeyUnique(ctx, srv.PublicKey, srv.Id)
+}
+
+// runningConfigureOptions returns the device configuration of a server that is expected to be running, nil otherwise.
+func runningConfigureOptions(srv *server.Server, peers []*peer.Peer) *driver.ConfigureOptions {
+	if !srv.Enabled || !srv.Running {
+		return nil
 	}
+	options := configureOptions(srv, peers)
+	return &options
+}
 
-	return s.wireguardService.Up(ctx, b, driver.ConfigureOptions{
+func configureOptions(srv *server.Server, peers []*peer.Peer) driver.ConfigureOptions {
+	return driver.ConfigureOptions{
 		InterfaceOptions: driver.InterfaceOptions{
 			Name:        srv.Name,
 			Description: srv.Description,
 			Address:     srv.Address,
 			DNS:         srv.DNS,
 			Mtu:         srv.MTU,
-			Hooks: adapt.Array(srv.Hooks, func(hook *server.Hook) *driver.HookOptions {
-				if hook == nil {
-					return nil
-				}
-
-				return &driver.HookOptions{
-					Command:       hook.Command,
-					RunOnPreUp:    hook.RunOnPreUp,
-					RunOnPostUp:   hook.RunOnPostUp || hook.RunOnStart,
-					RunOnPreDown:  hook.RunOnPreDown,
-					RunOnPostDown: hook.RunOnPostDown || hook.RunOnStop,
-				}
-			}),
+			Hooks:       commandHookOptions(srv.Hooks),
+			Firewall:    firewallOptions(srv.Firewall),
 		},
 		WireguardOptions: driver.WireguardOptions{
 			PrivateKey:   srv.PrivateKey,
@@ -939,10 +1724,52 @@ func (s *service) configureDevice(ctx context.Context, srv *server.Server, peers
 					AllowedIPs:          peer.AllowedIPs,
 					PresharedKey:        peer.PresharedKey,
 					PersistentKeepalive: peer.PersistentKeepalive,
+					AllowedDestinations: peer.AllowedDestinations,
+					ACL:                 adapt.Array(peer.ACL, aclRuleOptions),
 				}
 			}),
 		},
-	})
+	}
+}
+
+func firewallOptions(firewall *server.Firewall) *driver.FirewallOptions {
+	if firewall == nil {
+		return nil
+	}
+	return &driver.FirewallOptions{
+		MasqueradeInterface: firewall.MasqueradeInterface,
+		IPForwarding:        firewall.IPForwarding,
+		PeerIsolation:       firewall.PeerIsolation,
+	}
+}
+
+func aclRuleOptions(rule *peer.ACLRule) *driver.ACLRule {
+	return &driver.ACLRule{
+		Destination: rule.Destination,
+		Protocol:    driver.ACLProtocol(strings.ToLower(string(rule.Protocol))),
+		FromPort:    rule.FromPort,
+		ToPort:      rule.ToPort,
+		Action:      driver.ACLAction(strings.ToLower(string(rule.Action))),
+	}
+}
+
+// commandHookOptions returns the raw command hooks of a server, typed hook actions are always run by wg-ui itself.
+func commandHookOptions(hooks []*server.Hook) []*driver.HookOptions {
+	var hookOptions []*driver.HookOptions
+	for _, hook := range hooks {
+		if hook == nil || hook.Action != nil {
+			continue
+		}
+
+		hookOptions = append(hookOptions, &driver.HookOptions{
+			Command:       hook.Command,
+			RunOnPreUp:    hook.RunOnPreUp,
+			RunOnPostUp:   hook.RunOnPostUp || hook.RunOnStart,
+			RunOnPreDown:  hook.RunOnPreDown,
+			RunOnPostDown: hook.RunOnPostDown || hook.RunOnStop,
+		})
+	}
+	return hookOptions
 }
 
 func (s *service) runServerHooks(ctx context.Context, b *backend.Backend, srv *server.Server, action server.HookAction) {
@@ -950,11 +1777,19 @@ func (s *service) runServerHooks(ctx context.Context, b *backend.Backend, srv *s
 		return
 	}
 
-	if backendDelegatesHookExecution(b) {
-		return
+	if backendCapabilities(b).HookExecution {
+		// Raw commands are part of the device configuration, only typed actions are left to run here.
+		delegated := *srv
+		delegated.Hooks = slices.DeleteFunc(slices.Clone(srv.Hooks), func(hook *server.Hook) bool {
+			return hook == nil || hook.Action == nil
+		})
+		if len(delegated.Hooks) == 0 {
+			return
+		}
+		srv = &delegated
 	}
 
-	if err := srv.RunHooks(ctx, action); err != nil {
+	if err := s.serverService.RunHooks(ctx, srv, action); err != nil {
 		logrus.
 			WithError(err).
 			WithField("server", srv.Name).
@@ -964,10 +1799,6 @@ func (s *service) runServerHooks(ctx context.Context, b *backend.Backend, srv *s
 	}
 }
 
-func backendDelegatesHookExecution(b *backend.Backend) bool {
-	return b != nil && strings.EqualFold(b.Type(), "exec")
-}
-
 func (s *service) updateServer(ctx context.Context, srv *server.Server, device *driver.Device, userId string) (*server.Server, error) {
 	b, err := s.findBackend(ctx, srv.BackendId)
 	if err != nil {
@@ -1125,6 +1956,18 @@ func (s *service) testBackendURL(ctx context.Context, rawURL string) error {
 	return nil
 }
 
+// backendInterfaceNames returns the interface names of the servers managed by the backend, interfaces with the same
+// name on other backends belong to different hosts.
+func backendInterfaceNames(servers []*server.Server, backendId string) []string {
+	var names []string
+	for _, srv := range servers {
+		if srv.BackendId == backendId {
+			names = append(names, srv.Name)
+		}
+	}
+	return names
+}
+
 func (s *service) findUserById(ctx context.Context, userId string) (*user.User, error) {
 	u, err := s.userService.FindUser(ctx, &user.FindOneOptions{
 		IdOption: &user.IdOption{
@@ -1326,7 +2169,7 @@ func (s *service) updateServersStats(ctx context.Context) {
 
 func (s *service) updateServerStats(ctx context.Context, srv *server.Server) error {
 	if !srv.Enabled || !srv.Running {
-		return nil
+		return s.updatePeersStats(ctx, nil, srv)
 	}
 
 	b, err := s.findBackend(ctx, srv.BackendId)
@@ -1354,5 +2197,78 @@ func (s *service) updateServerStats(ctx context.Context, srv *server.Server) err
 			return fmt.Errorf("failed to update server stats: %w", err)
 		}
 	}
+
+	return s.updatePeersStats(ctx, b, srv)
+}
+
+// updatePeersStats stores the device state of the server peers, the peer service runs the lifecycle hooks
+// of the peers whose state changed. A nil backend means the server is not running.
+func (s *service) updatePeersStats(ctx context.Context, b *backend.Backend, srv *server.Server) error {
+	if b != nil && !backendCapabilities(b).PeerStats {
+		return nil
+	}
+
+	peers, err := s.peerService.FindPeers(ctx, &peer.FindOptions{
+		ServerId: &srv.Id,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to find peers: %w", err)
+	}
+	if len(peers) == 0 {
+		return nil
+	}
+
+	devicePeers := make(map[string]*driver.Peer)
+	if b != nil {
+		device, err := s.wireguardService.Device(ctx, b, srv.Name)
+		if err != nil {
+			return fmt.Errorf("failed to get device: %w", err)
+		}
+		if device != nil {
+			for _, devicePeer := range device.Wireguard.Peers {
+				devicePeers[devicePeer.PublicKey] = devicePeer
+			}
+		}
+	}
+
+	now := time.Now()
+	for _, p := range peers {
+		newStats := peerDeviceStats(p.Stats, devicePeers[p.PublicKey], now)
+		if !newStats.DeviceStateChanged(p.Stats) {
+			continue
+		}
+
+		updateOptions := &peer.UpdateOptions{Stats: newStats}
+		updateFieldMask := &peer.UpdateFieldMask{Stats: true}
+		if _, err = s.peerService.UpdatePeer(ctx, p.Id, updateOptions, updateFieldMask, ""); err != nil {
+			return fmt.Errorf("failed to update peer %s stats: %w", p.Name, err)
+		}
+	}
 	return nil
 }
+
+// peerDeviceStats derives the stats of a peer from the device, the last known handshake and endpoint are kept
+// while the peer is not present on the device.
+func peerDeviceStats(previous peer.Stats, devicePeer *driver.Peer, now time.Time) peer.Stats {
+	if devicePeer == nil {
+		return peer.Stats{
+			LastHandshakeTime: previous.LastHandshakeTime,
+			Endpoint:          previous.Endpoint,
+			ReceiveBytes:      previous.ReceiveBytes,
+			TransmitBytes:     previous.TransmitBytes,
+		}
+	}
+
+	stats := peer.Stats{
+		LastHandshakeTime: devicePeer.Stats.LastHandshakeTime,
+		Endpoint:          devicePeer.Endpoint,
+		ReceiveBytes:      devicePeer.Stats.ReceiveBytes,
+		TransmitBytes:     devicePeer.Stats.TransmitBytes,
+		Applied:           true,
+	}
+	if stats.Endpoint == "" {
+		stats.Endpoint = previous.Endpoint
+	}
+	stats.Connected = stats.Online(now)
+	return stats
+}
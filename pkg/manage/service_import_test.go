@@ -3,6 +3,8 @@ package manage
 import (
 	"strings"
 	"testing"
+
+	"github.com/UnAfraid/wg-ui/pkg/server"
 )
 
 func TestImportedPeerNameUsesPreferredName(t *testing.T) {
@@ -54,3 +56,16 @@ func TestImportedPeerNameAppliesLengthLimitWithSuffix(t *testing.T) {
 		t.Fatalf("expected name length <= 30, got %d for %q", len([]rune(second)), second)
 	}
 }
+
+func TestBackendInterfaceNamesOnlyListsServersOfTheBackend(t *testing.T) {
+	servers := []*server.Server{
+		{Name: "wg0", BackendId: "router1"},
+		{Name: "wg1", BackendId: "router1"},
+		{Name: "wg0", BackendId: "router2"},
+	}
+
+	names := backendInterfaceNames(servers, "router2")
+	if len(names) != 1 || names[0] != "wg0" {
+		t.Fatalf("expected only the interfaces of router2, got %v", names)
+	}
+}
@@ -0,0 +1,745 @@
+package manage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	bboltdb "go.etcd.io/bbolt"
+	"golang.zx2c4.com/wireguard/wgctrl/wgtypes"
+
+	"github.com/UnAfraid/wg-ui/pkg/backend"
+	"github.com/UnAfraid/wg-ui/pkg/cluster"
+	"github.com/UnAfraid/wg-ui/pkg/datastore"
+	"github.com/UnAfraid/wg-ui/pkg/datastore/bbolt"
+	"github.com/UnAfraid/wg-ui/pkg/dbx"
+	"github.com/UnAfraid/wg-ui/pkg/hook"
+	"github.com/UnAfraid/wg-ui/pkg/peer"
+	"github.com/UnAfraid/wg-ui/pkg/server"
+	"github.com/UnAfraid/wg-ui/pkg/subscription"
+	"github.com/UnAfraid/wg-ui/pkg/user"
+	"github.com/UnAfraid/wg-ui/pkg/wireguard"
+	"github.com/UnAfraid/wg-ui/pkg/wireguard/driver"
+	"github.com/UnAfraid/wg-ui/pkg/wireguard/memory"
+)
+
+// newMemoryService returns a service on a temporary database, its backends are created on memory networks.
+func newMemoryService(t *testing.T) *service {
+	t.Helper()
+	s, _ := newMemoryServiceWithDB(t)
+	return s
+}
+
+func newMemoryServiceWithDB(t *testing.T) (*service, *bboltdb.DB) {
+	t.Helper()
+	memory.Register()
+
+	db, err := datastore.NewBBoltDB(filepath.Join(t.TempDir(), "wg-ui.db"), time.Second)
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	t.Cleanup(func() { _ = db.Close() })
+	return newMemoryServiceOnDB(t, db), db
+}
+
+// newMemoryServiceOnDB starts a service on the database like a restart of wg-ui would.
+func newMemoryServiceOnDB(t *testing.T, db *bboltdb.DB) *service {
+	t.Helper()
+
+	transactionScoper := dbx.NewBBoltTransactionScoper(db)
+	subscriptionImpl, err := subscription.NewSubscription(subscription.Options{}, nil, bbolt.NewEventJournalRepository(db))
+	if err != nil {
+		t.Fatalf("failed to create subscription: %v", err)
+	}
+	t.Cleanup(func() { _ = subscriptionImpl.Close() })
+
+	hookService := hook.NewService(bbolt.NewHookExecutionRepository(db), transactionScoper, time.Second, 1024, 10, hook.Policy{RawCommandsEnabled: true})
+	t.Cleanup(hookService.Close)
+	serverRepository := bbolt.NewServerRepository(db)
+	serverService := server.NewService(serverRepository, transactionScoper, hookService, subscriptionImpl)
+	peerService := peer.NewService(bbolt.NewPeerRepository(db), bbolt.NewPeerGroupRepository(db), transactionScoper, serverService, hookService, subscriptionImpl)
+	userService, err := user.NewService(bbolt.NewUserRepository(db), transactionScoper, subscriptionImpl, "", "")
+	if err != nil {
+		t.Fatalf("failed to create user service: %v", err)
+	}
+	backendService := backend.NewService(bbolt.NewBackendRepository(db), backend.NewServerCounter(serverRepository), transactionScoper, subscriptionImpl)
+
+	wireguardService := wireguard.NewService(wireguard.NewRegistry(wireguard.HealthPolicy{}))
+	t.Cleanup(func() { _ = wireguardService.Close(context.Background()) })
+
+	s := NewService(transactionScoper, userService, backendService, serverService, peerService, wireguardService, 0, false, 0, 0, time.Second).(*service)
+	t.Cleanup(s.Close)
+	return s
+}
+
+// createMemoryBackend creates a backend on its own memory network, the network is returned to inspect the devices.
+func createMemoryBackend(t *testing.T, s *service, name string) (*backend.Backend, *memory.Network) {
+	t.Helper()
+	return createMemoryBackendWithScheme(t, s, name, "memory")
+}
+
+// registerMemoryDriver registers the memory backend under a new scheme with the given capabilities, it stands in for
+// backends that lack some of the capabilities of the memory one. Without live peer updates the peer methods are hidden.
+func registerMemoryDriver(t *testing.T, capabilities driver.Capabilities) string {
+	t.Helper()
+
+	scheme := fmt.Sprintf("memory-%d", time.Now().UnixNano())
+	driver.Register(scheme, func(_ context.Context, rawURL string) (driver.Backend, error) {
+		instance, err := memory.NewMemoryBackend("memory" + strings.TrimPrefix(rawURL, scheme))
+		if err != nil || capabilities.LivePeerUpdates {
+			return instance, err
+		}
+		return withoutPeerUpdates{instance}, nil
+	}, true, capabilities)
+	return scheme
+}
+
+func createMemoryBackendWithScheme(t *testing.T, s *service, name string, scheme string) (*backend.Backend, *memory.Network) {
+	t.Helper()
+
+	networkName := strings.ToLower(strings.ReplaceAll(t.Name(), "/", "-")) + "-" + name
+	t.Cleanup(func() { memory.ResetNetwork(networkName) })
+
+	b, err := s.CreateBackend(context.Background(), &backend.CreateOptions{
+		Name:    name,
+		Url:     scheme + "://" + networkName,
+		Enabled: true,
+	}, "")
+	if err != nil {
+		t.Fatalf("CreateBackend returned error: %v", err)
+	}
+	return b, memory.GetNetwork(networkName)
+}
+
+func createMemoryServer(t *testing.T, s *service, backendId string) *server.Server {
+	t.Helper()
+
+	srv, err := s.CreateServer(context.Background(), &server.CreateOptions{
+		Name:      "wg0",
+		BackendId: backendId,
+		Enabled:   true,
+		Address:   "10.0.0.1/24",
+	}, "")
+	if err != nil {
+		t.Fatalf("CreateServer returned error: %v", err)
+	}
+	return srv
+}
+
+func createMemoryPeer(t *testing.T, s *service, serverId string, name string, allowedIP string) *peer.Peer {
+	t.Helper()
+
+	key, err := wgtypes.GeneratePrivateKey()
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+
+	p, err := s.CreatePeer(context.Background(), serverId, &peer.CreateOptions{
+		Name:       name,
+		PublicKey:  key.PublicKey().String(),
+		AllowedIPs: []string{allowedIP},
+	}, "")
+	if err != nil {
+		t.Fatalf("CreatePeer returned error: %v", err)
+	}
+	return p
+}
+
+func devicePeerKeys(t *testing.T, s *service, b *backend.Backend, name string) []string {
+	t.Helper()
+
+	device, err := s.wireguardService.Device(context.Background(), b, name)
+	if err != nil {
+		t.Fatalf("Device returned error: %v", err)
+	}
+
+	var keys []string
+	for _, p := range device.Wireguard.Peers {
+		keys = append(keys, p.PublicKey)
+	}
+	return keys
+}
+
+func TestServerAndPeersAreConfiguredOnTheDevice(t *testing.T) {
+	ctx := context.Background()
+	s := newMemoryService(t)
+	b, _ := createMemoryBackend(t, s, "memory")
+
+	srv := createMemoryServer(t, s, b.Id)
+	if !srv.Running {
+		t.Fatalf("expected the server to be running")
+	}
+
+	alpha := createMemoryPeer(t, s, srv.Id, "alpha", "10.0.0.2/32")
+	bravo := createMemoryPeer(t, s, srv.Id, "bravo", "10.0.0.3/32")
+
+	device, err := s.wireguardService.Device(ctx, b, srv.Name)
+	if err != nil {
+		t.Fatalf("Device returned error: %v", err)
+	}
+	if device.Wireguard.PublicKey != srv.PublicKey || device.Interface.Addresses[0] != srv.Address {
+		t.Fatalf("unexpected device: %+v", device)
+	}
+	if keys := devicePeerKeys(t, s, b, srv.Name); len(keys) != 2 || keys[0] != alpha.PublicKey || keys[1] != bravo.PublicKey {
+		t.Fatalf("expected both peers on the device, got %v", keys)
+	}
+
+	if _, err := s.DeletePeer(ctx, alpha.Id, ""); err != nil {
+		t.Fatalf("DeletePeer returned error: %v", err)
+	}
+	if keys := devicePeerKeys(t, s, b, srv.Name); len(keys) != 1 || keys[0] != bravo.PublicKey {
+		t.Fatalf("expected the deleted peer to be removed from the device, got %v", keys)
+	}
+}
+
+func TestMoveServer(t *testing.T) {
+	ctx := context.Background()
+	s := newMemoryService(t)
+	source, _ := createMemoryBackend(t, s, "source")
+	target, _ := createMemoryBackend(t, s, "target")
+
+	srv := createMemoryServer(t, s, source.Id)
+	p := createMemoryPeer(t, s, srv.Id, "alpha", "10.0.0.2/32")
+
+	movedServer, err := s.MoveServer(ctx, srv.Id, target.Id, "")
+	if err != nil {
+		t.Fatalf("MoveServer returned error: %v", err)
+	}
+	if movedServer.BackendId != target.Id || !movedServer.Running {
+		t.Fatalf("expected the server to run on the target backend, got %+v", movedServer)
+	}
+	if keys := devicePeerKeys(t, s, target, srv.Name); len(keys) != 1 || keys[0] != p.PublicKey {
+		t.Fatalf("expected the peers to be moved to the target device, got %v", keys)
+	}
+	if up, err := s.wireguardService.Status(ctx, source, srv.Name); err != nil || up {
+		t.Fatalf("expected the source device to be down, got %v, %v", up, err)
+	}
+
+	if _, err := s.MoveServer(ctx, srv.Id, target.Id, ""); !errors.Is(err, server.ErrServerAlreadyOnBackend) {
+		t.Fatalf("expected %v, got %v", server.ErrServerAlreadyOnBackend, err)
+	}
+}
+
+func TestMoveServerRestoresSourceWhenTargetFails(t *testing.T) {
+	ctx := context.Background()
+	s := newMemoryService(t)
+	source, _ := createMemoryBackend(t, s, "source")
+	target, targetNetwork := createMemoryBackend(t, s, "target")
+
+	srv := createMemoryServer(t, s, source.Id)
+	p := createMemoryPeer(t, s, srv.Id, "alpha", "10.0.0.2/32")
+
+	injected := errors.New("boom")
+	targetNetwork.InjectFault(memory.OperationUp, injected, 0)
+	if _, err := s.MoveServer(ctx, srv.Id, target.Id, ""); !errors.Is(err, injected) {
+		t.Fatalf("expected %v, got %v", injected, err)
+	}
+
+	restoredServer, err := s.findServer(ctx, srv.Id)
+	if err != nil {
+		t.Fatalf("findServer returned error: %v", err)
+	}
+	if restoredServer.BackendId != source.Id || !restoredServer.Running {
+		t.Fatalf("expected the server to stay on the running source backend, got %+v", restoredServer)
+	}
+	if keys := devicePeerKeys(t, s, source, srv.Name); len(keys) != 1 || keys[0] != p.PublicKey {
+		t.Fatalf("expected the source device to be restored with its peers, got %v", keys)
+	}
+	if up, err := s.wireguardService.Status(ctx, target, srv.Name); err != nil || up {
+		t.Fatalf("expected nothing to be left on the target backend, got %v, %v", up, err)
+	}
+}
+
+func TestFailedPeerDeviceConfigurationMarksServerDrifted(t *testing.T) {
+	ctx := context.Background()
+	s := newMemoryService(t)
+	b, network := createMemoryBackend(t, s, "memory")
+	srv := createMemoryServer(t, s, b.Id)
+
+	key, err := wgtypes.GeneratePrivateKey()
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+
+	injected := errors.New("boom")
+	network.InjectFault(memory.OperationUpsertPeer, injected, 1)
+	if _, err := s.CreatePeer(ctx, srv.Id, &peer.CreateOptions{
+		Name:       "alpha",
+		PublicKey:  key.PublicKey().String(),
+		AllowedIPs: []string{"10.0.0.2/32"},
+	}, ""); !errors.Is(err, injected) {
+		t.Fatalf("expected %v, got %v", injected, err)
+	}
+
+	driftedServer, err := s.findServer(ctx, srv.Id)
+	if err != nil {
+		t.Fatalf("findServer returned error: %v", err)
+	}
+	if driftedServer.Drift == nil || len(driftedServer.Drift.Differences) != 1 || driftedServer.Drift.Differences[0].Field != "peer alpha" {
+		t.Fatalf("expected the missing peer to be recorded as drift, got %+v", driftedServer.Drift)
+	}
+}
+
+func TestAbandonAfterLostLeadershipLeavesDevicesRunning(t *testing.T) {
+	ctx := context.Background()
+	s := newMemoryService(t)
+	b, network := createMemoryBackend(t, s, "memory")
+
+	hookFile := filepath.Join(t.TempDir(), "pre-down")
+	srv, err := s.CreateServer(ctx, &server.CreateOptions{
+		Name:           "wg0",
+		BackendId:      b.Id,
+		Enabled:        true,
+		Address:        "10.0.0.1/24",
+		ShutdownPolicy: server.ShutdownPolicyStop,
+		Hooks:          []*server.Hook{{Command: "touch " + hookFile, RunOnPreDown: true}},
+	}, "")
+	if err != nil {
+		t.Fatalf("CreateServer returned error: %v", err)
+	}
+
+	store := cluster.NewLeaseFile(filepath.Join(t.TempDir(), "lease.json"))
+	elector := cluster.NewElector(store, cluster.ElectorOptions{
+		NodeId:        "leader",
+		LeaseDuration: time.Minute,
+		RenewInterval: 10 * time.Millisecond,
+	})
+	electorCtx, cancel := context.WithCancel(ctx)
+	t.Cleanup(cancel)
+	go elector.Run(electorCtx)
+
+	select {
+	case <-elector.Elected():
+	case <-time.After(5 * time.Second):
+		t.Fatalf("expected the node to be elected")
+	}
+
+	// another node takes over the lease
+	lease, err := store.Read(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.CompareAndSwap(ctx, lease.Generation, &cluster.Lease{
+		NodeId:     "other",
+		Generation: lease.Generation + 1,
+		ExpiresAt:  time.Now().Add(time.Minute),
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case <-elector.Lost():
+	case <-time.After(5 * time.Second):
+		t.Fatalf("expected the node to lose the leadership")
+	}
+	s.Abandon()
+
+	if calls := network.Calls(memory.OperationDown); calls != 0 {
+		t.Fatalf("expected no Down call after the leadership was lost, got %d", calls)
+	}
+	if up, err := s.wireguardService.Status(ctx, b, srv.Name); err != nil || !up {
+		t.Fatalf("expected the device to be left running, got %v, %v", up, err)
+	}
+	if _, err := os.Stat(hookFile); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("expected the pre down hook not to run, got %v", err)
+	}
+}
+
+func TestReadOnlyServiceServesSnapshot(t *testing.T) {
+	ctx := context.Background()
+	s, db := newMemoryServiceWithDB(t)
+	b, network := createMemoryBackend(t, s, "memory")
+	srv := createMemoryServer(t, s, b.Id)
+	p := createMemoryPeer(t, s, srv.Id, "alpha", "10.0.0.2/32")
+
+	snapshotPath := filepath.Join(t.TempDir(), "snapshot.db")
+	if err := datastore.WriteSnapshot(db, snapshotPath); err != nil {
+		t.Fatalf("WriteSnapshot returned error: %v", err)
+	}
+	snapshot, err := datastore.NewReadOnlyBBoltDB(snapshotPath, time.Second)
+	if err != nil {
+		t.Fatalf("failed to open snapshot: %v", err)
+	}
+	t.Cleanup(func() { _ = snapshot.Close() })
+
+	transactionScoper := dbx.NewBBoltTransactionScoper(snapshot)
+	subscriptionImpl, err := subscription.NewSubscription(subscription.Options{}, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to create subscription: %v", err)
+	}
+	hookService := hook.NewService(bbolt.NewHookExecutionRepository(snapshot), transactionScoper, time.Second, 1024, 10, hook.Policy{})
+	t.Cleanup(hookService.Close)
+	serverRepository := bbolt.NewServerRepository(snapshot)
+	serverService := server.NewService(serverRepository, transactionScoper, hookService, subscriptionImpl)
+	peerService := peer.NewService(bbolt.NewPeerRepository(snapshot), bbolt.NewPeerGroupRepository(snapshot), transactionScoper, serverService, hookService, subscriptionImpl)
+	userService, err := user.NewService(bbolt.NewUserRepository(snapshot), transactionScoper, subscriptionImpl, "", "")
+	if err != nil {
+		t.Fatalf("failed to create user service: %v", err)
+	}
+	backendService := backend.NewService(bbolt.NewBackendRepository(snapshot), backend.NewServerCounter(serverRepository), transactionScoper, subscriptionImpl)
+
+	readOnly := NewReadOnlyService(transactionScoper, userService, backendService, serverService, peerService, s.wireguardService)
+
+	servers, err := serverService.FindServers(ctx, &server.FindOptions{})
+	if err != nil || len(servers) != 1 || servers[0].Id != srv.Id {
+		t.Fatalf("expected the server of the snapshot, got %v, %v", servers, err)
+	}
+	if _, err := readOnly.PeerStats(ctx, srv.Id, p.PublicKey); !errors.Is(err, ErrLeaderOnly) {
+		t.Fatalf("expected %v, got %v", ErrLeaderOnly, err)
+	}
+	if _, err := readOnly.ForeignServersAll(ctx); !errors.Is(err, ErrLeaderOnly) {
+		t.Fatalf("expected %v, got %v", ErrLeaderOnly, err)
+	}
+	if _, err := readOnly.DeletePeer(ctx, p.Id, ""); err == nil {
+		t.Fatalf("expected the read only service to reject changes")
+	}
+
+	readOnly.Close()
+	if calls := network.Calls(memory.OperationDown); calls != 0 {
+		t.Fatalf("expected closing the read only service to leave the devices, got %d Down calls", calls)
+	}
+	if keys := devicePeerKeys(t, s, b, srv.Name); len(keys) != 1 || keys[0] != p.PublicKey {
+		t.Fatalf("expected the device to be left unchanged, got %v", keys)
+	}
+}
+
+func TestApplyPeerChangesConfiguresDevicesAfterCommit(t *testing.T) {
+	ctx := context.Background()
+	s := newMemoryService(t)
+	b, network := createMemoryBackend(t, s, "memory")
+	srv := createMemoryServer(t, s, b.Id)
+
+	key, err := wgtypes.GeneratePrivateKey()
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	changes := &PeerChanges{
+		Create: []*PeerCreate{{
+			ServerId: srv.Id,
+			Options: &peer.CreateOptions{
+				Name:       "alpha",
+				PublicKey:  key.PublicKey().String(),
+				AllowedIPs: []string{"10.0.0.2/32"},
+			},
+		}},
+	}
+
+	injected := errors.New("boom")
+	network.InjectFault(memory.OperationUp, injected, 1)
+	if _, err := s.ApplyPeerChanges(ctx, changes, ""); !errors.Is(err, injected) {
+		t.Fatalf("expected %v, got %v", injected, err)
+	}
+
+	peers, err := s.peerService.FindPeers(ctx, &peer.FindOptions{ServerId: &srv.Id})
+	if err != nil {
+		t.Fatalf("FindPeers returned error: %v", err)
+	}
+	if len(peers) != 1 {
+		t.Fatalf("expected the committed peer to be kept, got %d peers", len(peers))
+	}
+
+	driftedServer, err := s.findServer(ctx, srv.Id)
+	if err != nil {
+		t.Fatalf("findServer returned error: %v", err)
+	}
+	if driftedServer.Drift == nil {
+		t.Fatalf("expected the failed reconfiguration to be recorded as drift")
+	}
+}
+
+func TestUpdatePeerChecksTheACLInheritedFromTheGroup(t *testing.T) {
+	ctx := context.Background()
+	s := newMemoryService(t)
+	scheme := registerMemoryDriver(t, driver.Capabilities{LivePeerUpdates: true})
+	b, _ := createMemoryBackendWithScheme(t, s, "nofirewall", scheme)
+	srv := createMemoryServer(t, s, b.Id)
+	p := createMemoryPeer(t, s, srv.Id, "alpha", "10.0.0.2/32")
+
+	restricted, err := s.CreatePeerGroup(ctx, &peer.GroupCreateOptions{
+		Name: "restricted",
+		ACL: []*peer.ACLRule{{
+			Destination: "192.168.1.0/24",
+			Protocol:    peer.ACLProtocolAny,
+			Action:      peer.ACLActionDeny,
+		}},
+	}, "")
+	if err != nil {
+		t.Fatalf("CreatePeerGroup returned error: %v", err)
+	}
+	open, err := s.CreatePeerGroup(ctx, &peer.GroupCreateOptions{Name: "open"}, "")
+	if err != nil {
+		t.Fatalf("CreatePeerGroup returned error: %v", err)
+	}
+
+	_, err = s.UpdatePeer(ctx, p.Id, &peer.UpdateOptions{GroupId: restricted.Id}, &peer.UpdateFieldMask{GroupId: true}, "")
+	if !errors.Is(err, driver.ErrCapabilityNotSupported) {
+		t.Fatalf("expected %v, got %v", driver.ErrCapabilityNotSupported, err)
+	}
+
+	updated, err := s.UpdatePeer(ctx, p.Id, &peer.UpdateOptions{GroupId: open.Id}, &peer.UpdateFieldMask{GroupId: true}, "")
+	if err != nil {
+		t.Fatalf("UpdatePeer returned error: %v", err)
+	}
+	if updated.GroupId != open.Id {
+		t.Fatalf("expected group %s, got %s", open.Id, updated.GroupId)
+	}
+}
+
+func TestPeerStatsUpdatesAreNotReplayed(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	s := newMemoryService(t)
+	b, _ := createMemoryBackend(t, s, "memory")
+	srv := createMemoryServer(t, s, b.Id)
+
+	live, err := s.peerService.Subscribe(ctx, nil)
+	if err != nil {
+		t.Fatalf("Subscribe returned error: %v", err)
+	}
+	p := createMemoryPeer(t, s, srv.Id, "alpha", "10.0.0.2/32")
+	created := <-live
+
+	if err := s.updateServerStats(ctx, srv); err != nil {
+		t.Fatalf("updateServerStats returned error: %v", err)
+	}
+	if event := <-live; event.Action != peer.ChangedActionStatsUpdated {
+		t.Fatalf("expected the stats update to be delivered live, got %s", event.Action)
+	}
+
+	resumed, err := s.peerService.Subscribe(ctx, &created.Cursor)
+	if err != nil {
+		t.Fatalf("Subscribe returned error: %v", err)
+	}
+	if _, err := s.UpdatePeer(ctx, p.Id, &peer.UpdateOptions{Description: "renamed"}, &peer.UpdateFieldMask{Description: true}, ""); err != nil {
+		t.Fatalf("UpdatePeer returned error: %v", err)
+	}
+	if event := <-resumed; event.Action != peer.ChangedActionUpdated {
+		t.Fatalf("expected the stats update to be left out of the replay, got %s", event.Action)
+	}
+}
+
+func TestFindPeersReturnsPeersMatchingNameAndDescriptionOnce(t *testing.T) {
+	ctx := context.Background()
+	s := newMemoryService(t)
+	b, _ := createMemoryBackend(t, s, "memory")
+	srv := createMemoryServer(t, s, b.Id)
+	p := createMemoryPeer(t, s, srv.Id, "alpha", "10.0.0.2/32")
+	if _, err := s.UpdatePeer(ctx, p.Id, &peer.UpdateOptions{Description: "alpha laptop"}, &peer.UpdateFieldMask{Description: true}, ""); err != nil {
+		t.Fatalf("UpdatePeer returned error: %v", err)
+	}
+
+	peers, err := s.peerService.FindPeers(ctx, &peer.FindOptions{Query: "alpha"})
+	if err != nil {
+		t.Fatalf("FindPeers returned error: %v", err)
+	}
+	if len(peers) != 1 {
+		t.Fatalf("expected the peer once, got %d peers", len(peers))
+	}
+}
+
+// waitForFile waits for a file touched by a hook, the hooks run in the background.
+func waitForFile(t *testing.T, name string) {
+	t.Helper()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		if _, err := os.Stat(name); err == nil {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("expected %s to be created by a hook", filepath.Base(name))
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestInitStartsServersByStartupPolicy(t *testing.T) {
+	ctx := context.Background()
+	s, db := newMemoryServiceWithDB(t)
+	b, _ := createMemoryBackend(t, s, "memory")
+	hookDir := t.TempDir()
+
+	tests := []struct {
+		name          string
+		enabled       bool
+		startupPolicy server.StartupPolicy
+		expectedUp    bool
+	}{
+		{name: "default-enabled", enabled: true, expectedUp: true},
+		{name: "default-disabled", enabled: false, expectedUp: false},
+		{name: "always", enabled: false, startupPolicy: server.StartupPolicyAlways, expectedUp: true},
+		{name: "never", enabled: true, startupPolicy: server.StartupPolicyNever, expectedUp: false},
+		{name: "restore", enabled: true, startupPolicy: server.StartupPolicyRestore, expectedUp: true},
+	}
+	for i, tt := range tests {
+		if _, err := s.CreateServer(ctx, &server.CreateOptions{
+			Name:          tt.name,
+			BackendId:     b.Id,
+			Enabled:       tt.enabled,
+			Address:       fmt.Sprintf("10.0.%d.1/24", i),
+			StartupPolicy: tt.startupPolicy,
+			Hooks:         []*server.Hook{{Command: "touch " + filepath.Join(hookDir, tt.name), RunOnPreUp: true}},
+		}, ""); err != nil {
+			t.Fatalf("CreateServer %s returned error: %v", tt.name, err)
+		}
+		if tt.enabled {
+			waitForFile(t, filepath.Join(hookDir, tt.name))
+		}
+	}
+	s.Close()
+
+	// the host restarted, no device is left running
+	memory.ResetNetwork(strings.ToLower(t.Name()) + "-memory")
+	for _, tt := range tests {
+		_ = os.Remove(filepath.Join(hookDir, tt.name))
+	}
+
+	restarted := newMemoryServiceOnDB(t, db)
+	for _, tt := range tests {
+		up, err := restarted.wireguardService.Status(ctx, b, tt.name)
+		if err != nil {
+			t.Fatalf("Status %s returned error: %v", tt.name, err)
+		}
+		if up != tt.expectedUp {
+			t.Fatalf("expected %s to be up %t, got %t", tt.name, tt.expectedUp, up)
+		}
+		if tt.expectedUp {
+			waitForFile(t, filepath.Join(hookDir, tt.name))
+		}
+	}
+	for _, tt := range tests {
+		if _, err := os.Stat(filepath.Join(hookDir, tt.name)); !tt.expectedUp && !errors.Is(err, os.ErrNotExist) {
+			t.Fatalf("expected the pre up hook of %s not to run, got %v", tt.name, err)
+		}
+	}
+}
+
+func TestCloseAppliesShutdownPolicies(t *testing.T) {
+	ctx := context.Background()
+	s := newMemoryService(t)
+	b, network := createMemoryBackend(t, s, "memory")
+	hookDir := t.TempDir()
+
+	tests := []struct {
+		name            string
+		shutdownPolicy  server.ShutdownPolicy
+		expectedUp      bool
+		expectedPreDown bool
+	}{
+		{name: "default", expectedUp: true},
+		{name: "leave-running", shutdownPolicy: server.ShutdownPolicyLeaveRunning, expectedUp: true},
+		{name: "stop", shutdownPolicy: server.ShutdownPolicyStop, expectedUp: false, expectedPreDown: true},
+		{name: "pre-down-hooks", shutdownPolicy: server.ShutdownPolicyPreDownHooks, expectedUp: true, expectedPreDown: true},
+	}
+	for i, tt := range tests {
+		if _, err := s.CreateServer(ctx, &server.CreateOptions{
+			Name:           tt.name,
+			BackendId:      b.Id,
+			Enabled:        true,
+			Address:        fmt.Sprintf("10.0.%d.1/24", i),
+			ShutdownPolicy: tt.shutdownPolicy,
+			Hooks: []*server.Hook{
+				{Command: "touch " + filepath.Join(hookDir, tt.name+"-pre-down"), RunOnPreDown: true},
+				{Command: "touch " + filepath.Join(hookDir, tt.name+"-post-down"), RunOnPostDown: true},
+			},
+		}, ""); err != nil {
+			t.Fatalf("CreateServer %s returned error: %v", tt.name, err)
+		}
+	}
+
+	s.Close()
+
+	if calls := network.Calls(memory.OperationDown); calls != 1 {
+		t.Fatalf("expected a single Down call, got %d", calls)
+	}
+	for _, tt := range tests {
+		up, err := s.wireguardService.Status(ctx, b, tt.name)
+		if err != nil {
+			t.Fatalf("Status %s returned error: %v", tt.name, err)
+		}
+		if up != tt.expectedUp {
+			t.Fatalf("expected %s to be up %t, got %t", tt.name, tt.expectedUp, up)
+		}
+		if tt.expectedPreDown {
+			waitForFile(t, filepath.Join(hookDir, tt.name+"-pre-down"))
+		}
+		if !tt.expectedUp {
+			waitForFile(t, filepath.Join(hookDir, tt.name+"-post-down"))
+		}
+	}
+	for _, tt := range tests {
+		if _, err := os.Stat(filepath.Join(hookDir, tt.name+"-pre-down")); !tt.expectedPreDown && !errors.Is(err, os.ErrNotExist) {
+			t.Fatalf("expected the pre down hook of %s not to run, got %v", tt.name, err)
+		}
+		if _, err := os.Stat(filepath.Join(hookDir, tt.name+"-post-down")); tt.expectedUp && !errors.Is(err, os.ErrNotExist) {
+			t.Fatalf("expected the post down hook of %s not to run, got %v", tt.name, err)
+		}
+	}
+}
+
+// withoutPeerUpdates hides the peer methods of the memory backend, like the backends that only configure whole devices.
+type withoutPeerUpdates struct {
+	driver.Backend
+}
+
+// changePeer creates, updates and deletes a peer of the server.
+func changePeer(t *testing.T, s *service, serverId string) {
+	t.Helper()
+
+	ctx := context.Background()
+	p := createMemoryPeer(t, s, serverId, "alpha", "10.0.0.2/32")
+	if _, err := s.UpdatePeer(ctx, p.Id, &peer.UpdateOptions{AllowedIPs: []string{"10.0.0.3/32"}}, &peer.UpdateFieldMask{AllowedIPs: true}, ""); err != nil {
+		t.Fatalf("UpdatePeer returned error: %v", err)
+	}
+	if _, err := s.DeletePeer(ctx, p.Id, ""); err != nil {
+		t.Fatalf("DeletePeer returned error: %v", err)
+	}
+}
+
+func TestPeerChangesUseLivePeerUpdates(t *testing.T) {
+	s := newMemoryService(t)
+	b, network := createMemoryBackend(t, s, "memory")
+	srv := createMemoryServer(t, s, b.Id)
+	upCalls := network.Calls(memory.OperationUp)
+
+	changePeer(t, s, srv.Id)
+
+	if calls := network.Calls(memory.OperationUpsertPeer); calls != 2 {
+		t.Fatalf("expected the created and updated peer to be upserted, got %d calls", calls)
+	}
+	if calls := network.Calls(memory.OperationRemovePeer); calls != 1 {
+		t.Fatalf("expected the deleted peer to be removed, got %d calls", calls)
+	}
+	if calls := network.Calls(memory.OperationUp) - upCalls; calls != 0 {
+		t.Fatalf("expected no full reconfiguration, got %d Up calls", calls)
+	}
+	if keys := devicePeerKeys(t, s, b, srv.Name); len(keys) != 0 {
+		t.Fatalf("expected no peers left on the device, got %v", keys)
+	}
+}
+
+func TestPeerChangesReconfigureDevicesWithoutLivePeerUpdates(t *testing.T) {
+	s := newMemoryService(t)
+	scheme := registerMemoryDriver(t, driver.Capabilities{PeerStats: true})
+	b, network := createMemoryBackendWithScheme(t, s, "memory", scheme)
+	srv := createMemoryServer(t, s, b.Id)
+	upCalls := network.Calls(memory.OperationUp)
+
+	changePeer(t, s, srv.Id)
+
+	if calls := network.Calls(memory.OperationUp) - upCalls; calls != 3 {
+		t.Fatalf("expected a full reconfiguration per change, got %d Up calls", calls)
+	}
+	if calls := network.Calls(memory.OperationUpsertPeer) + network.Calls(memory.OperationRemovePeer); calls != 0 {
+		t.Fatalf("expected no live peer update, got %d calls", calls)
+	}
+	if keys := devicePeerKeys(t, s, b, srv.Name); len(keys) != 0 {
+		t.Fatalf("expected no peers left on the device, got %v", keys)
+	}
+}
@@ -0,0 +1,46 @@
+package pagination
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Cursor points at a single item within a sorted result set.
+// Key is the value of the sort field and Id breaks ties between items sharing the same key.
+type Cursor struct {
+	Field string `json:"f"`
+	Key   string `json:"k"`
+	Id    string `json:"i"`
+}
+
+func (c Cursor) Encode() string {
+	bytes, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(bytes)
+}
+
+func DecodeCursor(value string) (*Cursor, error) {
+	bytes, err := base64.RawURLEncoding.DecodeString(strings.TrimSpace(value))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidCursor, err)
+	}
+
+	var cursor Cursor
+	if err := json.Unmarshal(bytes, &cursor); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidCursor, err)
+	}
+
+	if cursor.Field == "" || cursor.Id == "" {
+		return nil, ErrInvalidCursor
+	}
+
+	return &cursor, nil
+}
+
+func (c Cursor) compare(other Cursor) int {
+	if c.Key != other.Key {
+		return strings.Compare(c.Key, other.Key)
+	}
+	return strings.Compare(c.Id, other.Id)
+}
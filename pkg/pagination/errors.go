@@ -0,0 +1,11 @@
+package pagination
+
+import (
+	"errors"
+)
+
+var (
+	ErrInvalidCursor      = errors.New("invalid cursor")
+	ErrCursorSortMismatch = errors.New("cursor does not match sort field")
+	ErrInvalidLimit       = errors.New("limit must not be negative")
+)
@@ -0,0 +1,53 @@
+package pagination
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Options controls ordering and windowing of a FindAll call.
+// A zero Limit means no limit.
+type Options struct {
+	SortField  string
+	Descending bool
+	Limit      int
+	After      *Cursor
+}
+
+func (o *Options) Validate(sortFields ...string) error {
+	if o.Limit < 0 {
+		return ErrInvalidLimit
+	}
+
+	valid := false
+	for _, sortField := range sortFields {
+		if o.SortField == sortField {
+			valid = true
+			break
+		}
+	}
+	if !valid {
+		return fmt.Errorf("invalid sort field: %s", o.SortField)
+	}
+
+	if o.After != nil && o.After.Field != o.SortField {
+		return ErrCursorSortMismatch
+	}
+
+	return nil
+}
+
+// StringKey returns a case-insensitive sort key for value.
+func StringKey(value string) string {
+	return strings.ToLower(value)
+}
+
+// TimeKey returns a sort key for t which orders lexicographically the same way as chronologically.
+// The zero time sorts before every other time.
+func TimeKey(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return fmt.Sprintf("%020d", t.UnixNano())
+}
@@ -0,0 +1,58 @@
+package pagination
+
+import (
+	"slices"
+)
+
+// Window collects the items of a single page while scanning a data set.
+// It keeps at most Limit items in memory, so repositories can stream their storage through it
+// without materializing the whole result set.
+// With nil options it simply keeps every item in insertion order.
+type Window[T any] struct {
+	options *Options
+	cursor  func(T, string) Cursor
+	items   []T
+	cursors []Cursor
+}
+
+func NewWindow[T any](options *Options, cursor func(T, string) Cursor) *Window[T] {
+	return &Window[T]{
+		options: options,
+		cursor:  cursor,
+	}
+}
+
+func (w *Window[T]) Add(item T) {
+	if w.options == nil {
+		w.items = append(w.items, item)
+		return
+	}
+
+	cursor := w.cursor(item, w.options.SortField)
+	if w.options.After != nil && w.compare(cursor, *w.options.After) <= 0 {
+		return
+	}
+
+	index, _ := slices.BinarySearchFunc(w.cursors, cursor, w.compare)
+	if w.options.Limit > 0 && index >= w.options.Limit {
+		return
+	}
+
+	w.items = slices.Insert(w.items, index, item)
+	w.cursors = slices.Insert(w.cursors, index, cursor)
+	if w.options.Limit > 0 && len(w.items) > w.options.Limit {
+		w.items = w.items[:w.options.Limit]
+		w.cursors = w.cursors[:w.options.Limit]
+	}
+}
+
+func (w *Window[T]) Items() []T {
+	return w.items
+}
+
+func (w *Window[T]) compare(a Cursor, b Cursor) int {
+	if w.options.Descending {
+		return b.compare(a)
+	}
+	return a.compare(b)
+}
@@ -0,0 +1,102 @@
+package pagination
+
+import (
+	"slices"
+	"testing"
+)
+
+type testItem struct {
+	id   string
+	name string
+}
+
+func testItemCursor(item testItem, field string) Cursor {
+	return Cursor{
+		Field: field,
+		Key:   StringKey(item.name),
+		Id:    item.id,
+	}
+}
+
+func collectIds(items []testItem) []string {
+	ids := make([]string, 0, len(items))
+	for _, item := range items {
+		ids = append(ids, item.id)
+	}
+	return ids
+}
+
+var testItems = []testItem{
+	{id: "3", name: "charlie"},
+	{id: "1", name: "Alpha"},
+	{id: "5", name: "echo"},
+	{id: "2", name: "bravo"},
+	{id: "4", name: "delta"},
+}
+
+func TestWindowWithoutOptionsKeepsInsertionOrder(t *testing.T) {
+	window := NewWindow(nil, testItemCursor)
+	for _, item := range testItems {
+		window.Add(item)
+	}
+
+	ids := collectIds(window.Items())
+	expected := []string{"3", "1", "5", "2", "4"}
+	if !slices.Equal(ids, expected) {
+		t.Fatalf("expected %v, got %v", expected, ids)
+	}
+}
+
+func TestWindowSortsAndLimits(t *testing.T) {
+	window := NewWindow(&Options{SortField: "NAME", Limit: 3}, testItemCursor)
+	for _, item := range testItems {
+		window.Add(item)
+	}
+
+	ids := collectIds(window.Items())
+	expected := []string{"1", "2", "3"}
+	if !slices.Equal(ids, expected) {
+		t.Fatalf("expected %v, got %v", expected, ids)
+	}
+}
+
+func TestWindowDescendingAfterCursor(t *testing.T) {
+	after := testItemCursor(testItem{id: "4", name: "delta"}, "NAME")
+	window := NewWindow(&Options{SortField: "NAME", Descending: true, Limit: 2, After: &after}, testItemCursor)
+	for _, item := range testItems {
+		window.Add(item)
+	}
+
+	ids := collectIds(window.Items())
+	expected := []string{"3", "2"}
+	if !slices.Equal(ids, expected) {
+		t.Fatalf("expected %v, got %v", expected, ids)
+	}
+}
+
+func TestCursorRoundTrip(t *testing.T) {
+	cursor := Cursor{Field: "NAME", Key: "alpha", Id: "1"}
+	decoded, err := DecodeCursor(cursor.Encode())
+	if err != nil {
+		t.Fatalf("DecodeCursor returned error: %v", err)
+	}
+	if *decoded != cursor {
+		t.Fatalf("expected %+v, got %+v", cursor, *decoded)
+	}
+
+	if _, err := DecodeCursor("not a cursor"); err == nil {
+		t.Fatal("expected error for invalid cursor")
+	}
+}
+
+func TestOptionsValidateRejectsMismatchedCursor(t *testing.T) {
+	options := &Options{SortField: "NAME", After: &Cursor{Field: "CREATED_AT", Id: "1"}}
+	if err := options.Validate("NAME", "CREATED_AT"); err == nil {
+		t.Fatal("expected error for mismatched cursor")
+	}
+
+	options = &Options{SortField: "UNKNOWN"}
+	if err := options.Validate("NAME", "CREATED_AT"); err == nil {
+		t.Fatal("expected error for unknown sort field")
+	}
+}
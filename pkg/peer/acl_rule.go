@@ -0,0 +1,63 @@
+package peer
+
+import (
+	"fmt"
+	"net/netip"
+)
+
+type ACLAction string
+
+var (
+	ACLActionAllow ACLAction = "ALLOW"
+	ACLActionDeny  ACLAction = "DENY"
+)
+
+type ACLProtocol string
+
+var (
+	ACLProtocolAny  ACLProtocol = "ANY"
+	ACLProtocolTCP  ACLProtocol = "TCP"
+	ACLProtocolUDP  ACLProtocol = "UDP"
+	ACLProtocolICMP ACLProtocol = "ICMP"
+)
+
+// ACLRule allows or denies forwarding traffic from the peer to a destination network,
+// the first matching rule of the peer wins.
+type ACLRule struct {
+	Destination string
+	Protocol    ACLProtocol
+	// FromPort and ToPort limit tcp and udp rules to a destination port range, zero matches any port.
+	FromPort int
+	ToPort   int
+	Action   ACLAction
+}
+
+func (r *ACLRule) validate() error {
+	if _, err := netip.ParsePrefix(r.Destination); err != nil {
+		return fmt.Errorf("invalid destination: %w", err)
+	}
+
+	switch r.Action {
+	case ACLActionAllow, ACLActionDeny:
+	default:
+		return fmt.Errorf("invalid action: %s", r.Action)
+	}
+
+	switch r.Protocol {
+	case ACLProtocolTCP, ACLProtocolUDP:
+	case ACLProtocolAny, ACLProtocolICMP:
+		if r.FromPort != 0 || r.ToPort != 0 {
+			return fmt.Errorf("ports require the TCP or UDP protocol, got: %s", r.Protocol)
+		}
+	default:
+		return fmt.Errorf("invalid protocol: %s", r.Protocol)
+	}
+
+	if r.FromPort < 0 || r.FromPort > 65535 || r.ToPort < 0 || r.ToPort > 65535 {
+		return fmt.Errorf("invalid port range: %d-%d, expected values between 1 and 65535", r.FromPort, r.ToPort)
+	}
+	if r.ToPort != 0 && (r.FromPort == 0 || r.ToPort < r.FromPort) {
+		return fmt.Errorf("invalid port range: %d-%d", r.FromPort, r.ToPort)
+	}
+	return nil
+}
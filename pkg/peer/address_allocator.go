@@ -0,0 +1,62 @@
+package peer
+
+import (
+	"fmt"
+	"net/netip"
+	"slices"
+)
+
+// addressAllocator hands out single host addresses from the server subnet which are not used by the server or any peer.
+type addressAllocator struct {
+	prefix netip.Prefix
+	next   netip.Addr
+	used   []netip.Prefix
+}
+
+func newAddressAllocator(serverAddress string, peers []*Peer) (*addressAllocator, error) {
+	serverPrefix, err := netip.ParsePrefix(serverAddress)
+	if err != nil {
+		return nil, fmt.Errorf("invalid server address: %s - %w", serverAddress, err)
+	}
+
+	prefix := serverPrefix.Masked()
+	used := []netip.Prefix{
+		netip.PrefixFrom(serverPrefix.Addr(), serverPrefix.Addr().BitLen()),
+	}
+	for _, p := range peers {
+		for _, allowedIP := range p.AllowedIPs {
+			allowedPrefix, err := netip.ParsePrefix(allowedIP)
+			if err != nil || !prefix.Overlaps(allowedPrefix) {
+				continue
+			}
+			used = append(used, allowedPrefix.Masked())
+		}
+	}
+
+	return &addressAllocator{
+		prefix: prefix,
+		next:   prefix.Addr().Next(),
+		used:   used,
+	}, nil
+}
+
+func (a *addressAllocator) allocate() (string, error) {
+	for addr := a.next; addr.IsValid() && a.prefix.Contains(addr); addr = addr.Next() {
+		if addr.Is4() && !a.prefix.Contains(addr.Next()) {
+			// broadcast address
+			break
+		}
+
+		if slices.ContainsFunc(a.used, func(used netip.Prefix) bool {
+			return used.Contains(addr)
+		}) {
+			continue
+		}
+
+		hostPrefix := netip.PrefixFrom(addr, addr.BitLen())
+		a.used = append(a.used, hostPrefix)
+		a.next = addr.Next()
+		return hostPrefix.String(), nil
+	}
+	return "", ErrNoFreeAddress
+}
@@ -1,12 +1,22 @@
 package peer
 
+import (
+	"time"
+)
+
 type CreateOptions struct {
 	Name                string
 	Description         string
+	Tags                []string
+	GroupId             string
 	PublicKey           string
 	Endpoint            string
 	AllowedIPs          []string
 	PresharedKey        string
 	PersistentKeepalive int
+	AllowedDestinations []string
+	ACL                 []*ACLRule
 	Hooks               []*Hook
+	Disabled            bool
+	ExpiresAt           *time.Time
 }
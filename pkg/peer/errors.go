@@ -19,4 +19,14 @@ var (
 	ErrCreatePeerOptionsRequired   = errors.New("create peer options are required")
 	ErrUpdatePeerOptionsRequired   = errors.New("update peer options are required")
 	ErrUpdatePeerFieldMaskRequired = errors.New("update peer field mask are required")
+	ErrImportPeersOptionsRequired  = errors.New("import peers options are required")
+	ErrUnsupportedFileFormat       = errors.New("unsupported file format")
+	ErrNoFreeAddress               = errors.New("no free address left in server subnet")
+	ErrGroupNotFound               = errors.New("peer group not found")
+	ErrGroupIdAlreadyExists        = errors.New("peer group id already exists")
+	ErrGroupNameAlreadyInUse       = errors.New("peer group name already in use")
+	ErrGroupHasPeers               = errors.New("peer group has peers and cannot be deleted")
+	ErrCreateGroupOptionsRequired  = errors.New("create peer group options are required")
+	ErrUpdateGroupOptionsRequired  = errors.New("update peer group options are required")
+	ErrGroupFieldMaskRequired      = errors.New("update peer group field mask are required")
 )
@@ -0,0 +1,215 @@
+package peer
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+const (
+	fileColumnName                = "name"
+	fileColumnDescription         = "description"
+	fileColumnPublicKey           = "publicKey"
+	fileColumnPresharedKey        = "presharedKey"
+	fileColumnEndpoint            = "endpoint"
+	fileColumnAllowedIPs          = "allowedIPs"
+	fileColumnPersistentKeepalive = "persistentKeepalive"
+)
+
+var fileColumns = []string{
+	fileColumnName,
+	fileColumnDescription,
+	fileColumnPublicKey,
+	fileColumnPresharedKey,
+	fileColumnEndpoint,
+	fileColumnAllowedIPs,
+	fileColumnPersistentKeepalive,
+}
+
+type fileRecord struct {
+	Name                string   `json:"name"`
+	Description         string   `json:"description,omitempty"`
+	PublicKey           string   `json:"publicKey,omitempty"`
+	PresharedKey        string   `json:"presharedKey,omitempty"`
+	Endpoint            string   `json:"endpoint,omitempty"`
+	AllowedIPs          []string `json:"allowedIPs,omitempty"`
+	PersistentKeepalive int      `json:"persistentKeepalive,omitempty"`
+}
+
+func (r *fileRecord) toCreateOptions() *CreateOptions {
+	return &CreateOptions{
+		Name:                strings.TrimSpace(r.Name),
+		Description:         r.Description,
+		PublicKey:           strings.TrimSpace(r.PublicKey),
+		Endpoint:            strings.TrimSpace(r.Endpoint),
+		AllowedIPs:          r.AllowedIPs,
+		PresharedKey:        strings.TrimSpace(r.PresharedKey),
+		PersistentKeepalive: r.PersistentKeepalive,
+	}
+}
+
+// DecodeFile reads the peers of a CSV or JSON peer file, rows are numbered starting from 1 without the CSV header.
+// Rows that cannot be decoded are reported as row errors, other errors mean the file as a whole is unreadable.
+func DecodeFile(format FileFormat, reader io.Reader) ([]*CreateOptions, []*RowError, error) {
+	switch format {
+	case FileFormatCSV:
+		return decodeCSV(reader)
+	case FileFormatJSON:
+		return decodeJSON(reader)
+	default:
+		return nil, nil, fmt.Errorf("%w: %s", ErrUnsupportedFileFormat, format)
+	}
+}
+
+// EncodeFile writes peers in a format accepted by DecodeFile.
+func EncodeFile(format FileFormat, writer io.Writer, peers []*Peer) error {
+	records := make([]*fileRecord, 0, len(peers))
+	for _, p := range peers {
+		records = append(records, &fileRecord{
+			Name:                p.Name,
+			Description:         p.Description,
+			PublicKey:           p.PublicKey,
+			PresharedKey:        p.PresharedKey,
+			Endpoint:            p.Endpoint,
+			AllowedIPs:          p.AllowedIPs,
+			PersistentKeepalive: p.PersistentKeepalive,
+		})
+	}
+
+	switch format {
+	case FileFormatCSV:
+		return encodeCSV(writer, records)
+	case FileFormatJSON:
+		encoder := json.NewEncoder(writer)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(records)
+	default:
+		return fmt.Errorf("%w: %s", ErrUnsupportedFileFormat, format)
+	}
+}
+
+func decodeJSON(reader io.Reader) ([]*CreateOptions, []*RowError, error) {
+	var rawRecords []json.RawMessage
+	if err := json.NewDecoder(reader).Decode(&rawRecords); err != nil {
+		return nil, nil, fmt.Errorf("failed to decode json: %w", err)
+	}
+
+	var options []*CreateOptions
+	var rowErrors []*RowError
+	for i, rawRecord := range rawRecords {
+		var record fileRecord
+		if err := json.Unmarshal(rawRecord, &record); err != nil {
+			rowErrors = append(rowErrors, &RowError{Row: i + 1, Err: err})
+			continue
+		}
+		options = append(options, record.toCreateOptions())
+	}
+	return options, rowErrors, nil
+}
+
+func decodeCSV(reader io.Reader) ([]*CreateOptions, []*RowError, error) {
+	csvReader := csv.NewReader(reader)
+	csvReader.FieldsPerRecord = -1
+	csvReader.TrimLeadingSpace = true
+
+	header, err := csvReader.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, nil, nil
+		}
+		return nil, nil, fmt.Errorf("failed to read csv header: %w", err)
+	}
+
+	columnIndex := make(map[string]int, len(header))
+	for i, column := range header {
+		for _, knownColumn := range fileColumns {
+			if strings.EqualFold(strings.TrimSpace(column), knownColumn) {
+				columnIndex[knownColumn] = i
+			}
+		}
+	}
+	if _, ok := columnIndex[fileColumnName]; !ok {
+		return nil, nil, fmt.Errorf("csv header is missing the %s column", fileColumnName)
+	}
+
+	var options []*CreateOptions
+	var rowErrors []*RowError
+	for row := 1; ; row++ {
+		fields, err := csvReader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			var parseErr *csv.ParseError
+			if !errors.As(err, &parseErr) {
+				return nil, nil, fmt.Errorf("failed to read csv: %w", err)
+			}
+			rowErrors = append(rowErrors, &RowError{Row: row, Err: err})
+			continue
+		}
+
+		field := func(column string) string {
+			index, ok := columnIndex[column]
+			if !ok || index >= len(fields) {
+				return ""
+			}
+			return strings.TrimSpace(fields[index])
+		}
+
+		record := fileRecord{
+			Name:         field(fileColumnName),
+			Description:  field(fileColumnDescription),
+			PublicKey:    field(fileColumnPublicKey),
+			PresharedKey: field(fileColumnPresharedKey),
+			Endpoint:     field(fileColumnEndpoint),
+			AllowedIPs: strings.FieldsFunc(field(fileColumnAllowedIPs), func(r rune) bool {
+				return r == ',' || r == ';' || r == ' '
+			}),
+		}
+
+		if rawPersistentKeepalive := field(fileColumnPersistentKeepalive); rawPersistentKeepalive != "" {
+			persistentKeepalive, err := strconv.Atoi(rawPersistentKeepalive)
+			if err != nil {
+				rowErrors = append(rowErrors, &RowError{Row: row, Err: fmt.Errorf("invalid persistent keep alive: %w", err)})
+				continue
+			}
+			record.PersistentKeepalive = persistentKeepalive
+		}
+
+		options = append(options, record.toCreateOptions())
+	}
+	return options, rowErrors, nil
+}
+
+func encodeCSV(writer io.Writer, records []*fileRecord) error {
+	csvWriter := csv.NewWriter(writer)
+	if err := csvWriter.Write(fileColumns); err != nil {
+		return err
+	}
+
+	for _, record := range records {
+		persistentKeepalive := ""
+		if record.PersistentKeepalive != 0 {
+			persistentKeepalive = strconv.Itoa(record.PersistentKeepalive)
+		}
+
+		if err := csvWriter.Write([]string{
+			record.Name,
+			record.Description,
+			record.PublicKey,
+			record.PresharedKey,
+			record.Endpoint,
+			strings.Join(record.AllowedIPs, ","),
+			persistentKeepalive,
+		}); err != nil {
+			return err
+		}
+	}
+
+	csvWriter.Flush()
+	return csvWriter.Error()
+}
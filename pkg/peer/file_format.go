@@ -0,0 +1,8 @@
+package peer
+
+type FileFormat string
+
+const (
+	FileFormatCSV  FileFormat = "CSV"
+	FileFormatJSON FileFormat = "JSON"
+)
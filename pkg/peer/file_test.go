@@ -0,0 +1,101 @@
+package peer
+
+import (
+	"bytes"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestDecodeCSVReportsRowErrors(t *testing.T) {
+	input := strings.Join([]string{
+		"Name,publicKey,allowedIPs,persistentKeepalive",
+		`alpha,key-a,"10.0.0.2/32,fd00::2/128",25`,
+		"bravo,key-b,10.0.0.3/32,never",
+		"charlie,key-c,,",
+	}, "\n")
+
+	options, rowErrors, err := DecodeFile(FileFormatCSV, strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("DecodeFile returned error: %v", err)
+	}
+
+	if len(options) != 2 {
+		t.Fatalf("expected 2 decoded rows, got %d", len(options))
+	}
+	if !slices.Equal(options[0].AllowedIPs, []string{"10.0.0.2/32", "fd00::2/128"}) {
+		t.Fatalf("unexpected allowed ips: %v", options[0].AllowedIPs)
+	}
+	if options[0].PersistentKeepalive != 25 {
+		t.Fatalf("expected persistent keepalive 25, got %d", options[0].PersistentKeepalive)
+	}
+
+	if len(rowErrors) != 1 || rowErrors[0].Row != 2 {
+		t.Fatalf("expected a single error on row 2, got %v", rowErrors)
+	}
+}
+
+func TestDecodeCSVRequiresNameColumn(t *testing.T) {
+	if _, _, err := DecodeFile(FileFormatCSV, strings.NewReader("publicKey\nkey-a\n")); err == nil {
+		t.Fatal("expected error for missing name column")
+	}
+}
+
+func TestEncodeDecodeRoundTrip(t *testing.T) {
+	peers := []*Peer{
+		{
+			Name:                "alpha",
+			Description:         "first, peer",
+			PublicKey:           "key-a",
+			AllowedIPs:          []string{"10.0.0.2/32"},
+			PersistentKeepalive: 25,
+		},
+	}
+
+	for _, format := range []FileFormat{FileFormatCSV, FileFormatJSON} {
+		var buffer bytes.Buffer
+		if err := EncodeFile(format, &buffer, peers); err != nil {
+			t.Fatalf("%s: EncodeFile returned error: %v", format, err)
+		}
+
+		options, rowErrors, err := DecodeFile(format, &buffer)
+		if err != nil || len(rowErrors) != 0 {
+			t.Fatalf("%s: DecodeFile returned error: %v %v", format, err, rowErrors)
+		}
+		if len(options) != 1 {
+			t.Fatalf("%s: expected 1 row, got %d", format, len(options))
+		}
+
+		decoded := options[0]
+		if decoded.Name != "alpha" || decoded.Description != "first, peer" || decoded.PublicKey != "key-a" || decoded.PersistentKeepalive != 25 {
+			t.Fatalf("%s: unexpected decoded row: %+v", format, decoded)
+		}
+		if !slices.Equal(decoded.AllowedIPs, []string{"10.0.0.2/32"}) {
+			t.Fatalf("%s: unexpected allowed ips: %v", format, decoded.AllowedIPs)
+		}
+	}
+}
+
+func TestAddressAllocatorSkipsUsedAddresses(t *testing.T) {
+	allocator, err := newAddressAllocator("10.0.0.1/29", []*Peer{
+		{AllowedIPs: []string{"10.0.0.2/32", "192.168.0.0/24"}},
+		{AllowedIPs: []string{"10.0.0.4/31"}},
+	})
+	if err != nil {
+		t.Fatalf("newAddressAllocator returned error: %v", err)
+	}
+
+	var allocated []string
+	for {
+		address, err := allocator.allocate()
+		if err != nil {
+			break
+		}
+		allocated = append(allocated, address)
+	}
+
+	expected := []string{"10.0.0.3/32", "10.0.0.6/32"}
+	if !slices.Equal(allocated, expected) {
+		t.Fatalf("expected %v, got %v", expected, allocated)
+	}
+}
@@ -0,0 +1,44 @@
+package peer
+
+import (
+	"slices"
+	"time"
+
+	"github.com/UnAfraid/wg-ui/pkg/internal/tag"
+)
+
+// Filter narrows down the peers selected by FindOptions, every set field must match.
+// BackendIds is resolved to server ids by the service before reaching the repository.
+type Filter struct {
+	ServerIds  []string
+	BackendIds []string
+	GroupIds   []string
+	Online     *bool
+	Enabled    *bool
+	// Tags selects the peers having all the tags.
+	Tags []string
+}
+
+func (f *Filter) Matches(p *Peer) bool {
+	if len(f.ServerIds) != 0 && !slices.Contains(f.ServerIds, p.ServerId) {
+		return false
+	}
+
+	if len(f.GroupIds) != 0 && !slices.Contains(f.GroupIds, p.GroupId) {
+		return false
+	}
+
+	if f.Online != nil && p.Online(time.Now()) != *f.Online {
+		return false
+	}
+
+	if f.Enabled != nil && p.Disabled == *f.Enabled {
+		return false
+	}
+
+	if len(f.Tags) != 0 && !tag.ContainsAll(p.Tags, f.Tags) {
+		return false
+	}
+
+	return true
+}
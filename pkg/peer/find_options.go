@@ -1,5 +1,9 @@
 package peer
 
+import (
+	"github.com/UnAfraid/wg-ui/pkg/pagination"
+)
+
 type FindOptions struct {
 	Ids          []string
 	ServerId     *string
@@ -7,4 +11,13 @@ type FindOptions struct {
 	CreateUserId *string
 	UpdateUserId *string
 	Query        string
+	Filter       *Filter
+	Pagination   *pagination.Options
+}
+
+func (options *FindOptions) Validate() error {
+	if options.Pagination != nil {
+		return options.Pagination.Validate(SortFieldName, SortFieldCreatedAt, SortFieldLastHandshake)
+	}
+	return nil
 }
@@ -0,0 +1,187 @@
+package peer
+
+import (
+	"fmt"
+	"net"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/UnAfraid/wg-ui/pkg/pagination"
+)
+
+// Group shares defaults between its peers, the values set on a peer take precedence over the group ones.
+type Group struct {
+	Id          string
+	Name        string
+	Description string
+	// PersistentKeepalive is used by the peers without a persistent keepalive of their own.
+	PersistentKeepalive int
+	// DNS is meant for the client configurations of the peers, it is not applied to the device.
+	DNS []string
+	// ACL rules are evaluated after the rules of the peer.
+	ACL []*ACLRule
+	// Hooks run in addition to the hooks of the peer.
+	Hooks []*Hook
+	// ExpiresAfter takes the peers without an expiry of their own off the device once passed since they were created,
+	// zero never expires them.
+	ExpiresAfter time.Duration
+	CreateUserId string
+	UpdateUserId string
+	DeleteUserId string
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+	DeletedAt    *time.Time
+}
+
+func (g *Group) validate(fieldMask *GroupUpdateFieldMask) error {
+	if fieldMask == nil || fieldMask.Name {
+		if len(strings.TrimSpace(g.Name)) == 0 {
+			return ErrNameRequired
+		}
+
+		if len(g.Name) < 3 {
+			return fmt.Errorf("name must be at least 3 characters long")
+		}
+		if len(g.Name) > 30 {
+			return fmt.Errorf("name must not be longer than 30 characters")
+		}
+	}
+
+	if fieldMask == nil || fieldMask.Description {
+		if len(g.Description) > 255 {
+			return fmt.Errorf("description must not be longer than 255 characters")
+		}
+	}
+
+	if fieldMask == nil || fieldMask.PersistentKeepalive {
+		if g.PersistentKeepalive < 0 || g.PersistentKeepalive > 65535 {
+			return fmt.Errorf("invalid persistent keep alive: %d, expected value between 1 and 65535", g.PersistentKeepalive)
+		}
+	}
+
+	if fieldMask == nil || fieldMask.DNS {
+		for i, dns := range g.DNS {
+			if net.ParseIP(dns) == nil {
+				return fmt.Errorf("invalid dns ip address %d: %s", i+1, dns)
+			}
+		}
+	}
+
+	if fieldMask == nil || fieldMask.ACL {
+		if err := validateACL(g.ACL); err != nil {
+			return err
+		}
+	}
+
+	if fieldMask == nil || fieldMask.Hooks {
+		if err := validateHooks(g.Hooks); err != nil {
+			return err
+		}
+	}
+
+	if fieldMask == nil || fieldMask.ExpiresAfter {
+		if g.ExpiresAfter < 0 {
+			return fmt.Errorf("invalid expiry: %s, expected a positive duration", g.ExpiresAfter)
+		}
+	}
+
+	return nil
+}
+
+func (g *Group) update(options *GroupUpdateOptions, fieldMask *GroupUpdateFieldMask) {
+	if fieldMask.Name {
+		g.Name = options.Name
+	}
+
+	if fieldMask.Description {
+		g.Description = options.Description
+	}
+
+	if fieldMask.PersistentKeepalive {
+		g.PersistentKeepalive = options.PersistentKeepalive
+	}
+
+	if fieldMask.DNS {
+		g.DNS = options.DNS
+	}
+
+	if fieldMask.ACL {
+		g.ACL = options.ACL
+	}
+
+	if fieldMask.Hooks {
+		g.Hooks = options.Hooks
+	}
+
+	if fieldMask.ExpiresAfter {
+		g.ExpiresAfter = options.ExpiresAfter
+	}
+
+	if fieldMask.UpdateUserId {
+		g.UpdateUserId = options.UpdateUserId
+	}
+}
+
+// HasDeviceHooks reports whether any hook of the group follows the device state of its peers.
+func (g *Group) HasDeviceHooks() bool {
+	return slices.ContainsFunc(g.Hooks, func(h *Hook) bool {
+		return h != nil && h.followsDevice()
+	})
+}
+
+func (g *Group) Cursor(sortField string) pagination.Cursor {
+	var key string
+	switch sortField {
+	case SortFieldCreatedAt:
+		key = pagination.TimeKey(g.CreatedAt)
+	default:
+		key = pagination.StringKey(g.Name)
+	}
+
+	return pagination.Cursor{
+		Field: sortField,
+		Key:   key,
+		Id:    g.Id,
+	}
+}
+
+// EffectiveExpiresAt returns when the peer expires, nil when it never does.
+func (p *Peer) EffectiveExpiresAt(group *Group) *time.Time {
+	if p.ExpiresAt != nil {
+		return p.ExpiresAt
+	}
+	if group == nil || group.ExpiresAfter == 0 {
+		return nil
+	}
+	expiresAt := p.CreatedAt.Add(group.ExpiresAfter)
+	return &expiresAt
+}
+
+// Active reports whether the peer belongs on the device, it is not when disabled or expired.
+func (p *Peer) Active(group *Group, now time.Time) bool {
+	if p.Disabled {
+		return false
+	}
+	expiresAt := p.EffectiveExpiresAt(group)
+	return expiresAt == nil || now.Before(*expiresAt)
+}
+
+// withGroup returns a copy of the peer with the defaults of the group filled in.
+func (p *Peer) withGroup(group *Group) *Peer {
+	if group == nil {
+		return p
+	}
+
+	effectivePeer := *p
+	if effectivePeer.PersistentKeepalive == 0 {
+		effectivePeer.PersistentKeepalive = group.PersistentKeepalive
+	}
+	if len(group.ACL) != 0 {
+		effectivePeer.ACL = append(slices.Clone(p.ACL), group.ACL...)
+	}
+	if len(group.Hooks) != 0 {
+		effectivePeer.Hooks = append(slices.Clone(p.Hooks), group.Hooks...)
+	}
+	return &effectivePeer
+}
@@ -0,0 +1,15 @@
+package peer
+
+import (
+	"time"
+)
+
+type GroupCreateOptions struct {
+	Name                string
+	Description         string
+	PersistentKeepalive int
+	DNS                 []string
+	ACL                 []*ACLRule
+	Hooks               []*Hook
+	ExpiresAfter        time.Duration
+}
@@ -0,0 +1,12 @@
+package peer
+
+type GroupFindOneOptions struct {
+	IdOption *IdOption
+}
+
+func (options *GroupFindOneOptions) Validate() error {
+	if options.IdOption == nil {
+		return ErrOneOptionRequired
+	}
+	return options.IdOption.Validate()
+}
@@ -0,0 +1,18 @@
+package peer
+
+import (
+	"github.com/UnAfraid/wg-ui/pkg/pagination"
+)
+
+type GroupFindOptions struct {
+	Ids        []string
+	Query      string
+	Pagination *pagination.Options
+}
+
+func (options *GroupFindOptions) Validate() error {
+	if options.Pagination != nil {
+		return options.Pagination.Validate(SortFieldName, SortFieldCreatedAt)
+	}
+	return nil
+}
@@ -0,0 +1,13 @@
+package peer
+
+import (
+	"context"
+)
+
+type GroupRepository interface {
+	FindOne(ctx context.Context, options *GroupFindOneOptions) (*Group, error)
+	FindAll(ctx context.Context, options *GroupFindOptions) ([]*Group, error)
+	Create(ctx context.Context, group *Group) (*Group, error)
+	Update(ctx context.Context, group *Group, fieldMask *GroupUpdateFieldMask) (*Group, error)
+	Delete(ctx context.Context, groupId string, deleteUserId string) (*Group, error)
+}
@@ -0,0 +1,77 @@
+package peer
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPeerActive(t *testing.T) {
+	createdAt := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	expiresAt := createdAt.Add(time.Hour)
+	group := &Group{ExpiresAfter: 24 * time.Hour}
+
+	tests := []struct {
+		name     string
+		peer     *Peer
+		group    *Group
+		now      time.Time
+		expected bool
+	}{
+		{name: "no expiry", peer: &Peer{CreatedAt: createdAt}, now: createdAt.Add(365 * 24 * time.Hour), expected: true},
+		{name: "disabled", peer: &Peer{CreatedAt: createdAt, Disabled: true}, now: createdAt, expected: false},
+		{name: "before own expiry", peer: &Peer{CreatedAt: createdAt, ExpiresAt: &expiresAt}, group: group, now: expiresAt.Add(-time.Second), expected: true},
+		{name: "own expiry takes precedence", peer: &Peer{CreatedAt: createdAt, ExpiresAt: &expiresAt}, group: group, now: expiresAt, expected: false},
+		{name: "before group expiry", peer: &Peer{CreatedAt: createdAt}, group: group, now: createdAt.Add(23 * time.Hour), expected: true},
+		{name: "after group expiry", peer: &Peer{CreatedAt: createdAt}, group: group, now: createdAt.Add(24 * time.Hour), expected: false},
+		{name: "group without expiry", peer: &Peer{CreatedAt: createdAt}, group: &Group{}, now: createdAt.Add(365 * 24 * time.Hour), expected: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if active := tt.peer.Active(tt.group, tt.now); active != tt.expected {
+				t.Fatalf("expected %v, got %v", tt.expected, active)
+			}
+		})
+	}
+}
+
+func TestPeerWithGroup(t *testing.T) {
+	peerRule := &ACLRule{Destination: "10.0.0.0/8", Protocol: ACLProtocolAny, Action: ACLActionAllow}
+	groupRule := &ACLRule{Destination: "0.0.0.0/0", Protocol: ACLProtocolAny, Action: ACLActionDeny}
+	group := &Group{
+		PersistentKeepalive: 25,
+		ACL:                 []*ACLRule{groupRule},
+		Hooks:               []*Hook{{Command: "true", RunOnCreate: true}},
+	}
+
+	p := &Peer{ACL: []*ACLRule{peerRule}}
+	effectivePeer := p.withGroup(group)
+	if effectivePeer.PersistentKeepalive != 25 {
+		t.Fatalf("expected the group keepalive, got %d", effectivePeer.PersistentKeepalive)
+	}
+	if len(effectivePeer.ACL) != 2 || effectivePeer.ACL[0] != peerRule || effectivePeer.ACL[1] != groupRule {
+		t.Fatalf("expected the peer rules followed by the group rules, got %v", effectivePeer.ACL)
+	}
+	if len(effectivePeer.Hooks) != 1 {
+		t.Fatalf("expected the group hook, got %d hooks", len(effectivePeer.Hooks))
+	}
+	if len(p.ACL) != 1 || p.PersistentKeepalive != 0 {
+		t.Fatalf("expected the peer to be left unchanged")
+	}
+
+	p.PersistentKeepalive = 10
+	if keepalive := p.withGroup(group).PersistentKeepalive; keepalive != 10 {
+		t.Fatalf("expected the peer keepalive, got %d", keepalive)
+	}
+}
+
+func TestFilterMatchesTags(t *testing.T) {
+	p := &Peer{Tags: []string{"office", "env=prod"}}
+
+	if !(&Filter{Tags: []string{"ENV=prod", "Office"}}).Matches(p) {
+		t.Fatalf("expected tags to match case-insensitively")
+	}
+	if (&Filter{Tags: []string{"office", "laptop"}}).Matches(p) {
+		t.Fatalf("expected a missing tag to not match")
+	}
+}
@@ -0,0 +1,12 @@
+package peer
+
+type GroupUpdateFieldMask struct {
+	Name                bool
+	Description         bool
+	PersistentKeepalive bool
+	DNS                 bool
+	ACL                 bool
+	Hooks               bool
+	ExpiresAfter        bool
+	UpdateUserId        bool
+}
@@ -0,0 +1,16 @@
+package peer
+
+import (
+	"time"
+)
+
+type GroupUpdateOptions struct {
+	Name                string
+	Description         string
+	PersistentKeepalive int
+	DNS                 []string
+	ACL                 []*ACLRule
+	Hooks               []*Hook
+	ExpiresAfter        time.Duration
+	UpdateUserId        string
+}
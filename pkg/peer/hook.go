@@ -1,10 +1,29 @@
 package peer
 
+import (
+	"time"
+
+	"github.com/UnAfraid/wg-ui/pkg/hook"
+)
+
 type Hook struct {
+	// Command is a raw executable path, it is ignored when a typed action is set.
 	Command     string
+	Action      *hook.Action
 	RunOnCreate bool
 	RunOnUpdate bool
 	RunOnDelete bool
+	// RunOnConnect, RunOnDisconnect, RunOnEndpointChange, RunOnApply and RunOnRemove follow the device state
+	// as seen by the stats updates, not the stored peer.
+	RunOnConnect        bool
+	RunOnDisconnect     bool
+	RunOnEndpointChange bool
+	RunOnApply          bool
+	RunOnRemove         bool
+	// Timeout of a single attempt, the configured default is used when zero.
+	Timeout     time.Duration
+	MaxAttempts int
+	RetryDelay  time.Duration
 }
 
 func (h *Hook) shouldExecute(action HookAction) bool {
@@ -15,6 +34,28 @@ func (h *Hook) shouldExecute(action HookAction) bool {
 		return h.RunOnUpdate
 	case HookActionDelete:
 		return h.RunOnDelete
+	case HookActionConnected:
+		return h.RunOnConnect
+	case HookActionDisconnected:
+		return h.RunOnDisconnect
+	case HookActionEndpointChanged:
+		return h.RunOnEndpointChange
+	case HookActionApplied:
+		return h.RunOnApply
+	case HookActionRemoved:
+		return h.RunOnRemove
 	}
 	return false
 }
+
+// followsDevice reports whether the hook runs on any device lifecycle event.
+func (h *Hook) followsDevice() bool {
+	return h.RunOnConnect || h.RunOnDisconnect || h.RunOnEndpointChange || h.RunOnApply || h.RunOnRemove
+}
+
+func (h *Hook) retryPolicy() hook.RetryPolicy {
+	return hook.RetryPolicy{
+		MaxAttempts: h.MaxAttempts,
+		Delay:       h.RetryDelay,
+	}
+}
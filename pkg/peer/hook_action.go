@@ -6,4 +6,13 @@ var (
 	HookActionCreate HookAction = "CREATE"
 	HookActionUpdate HookAction = "UPDATE"
 	HookActionDelete HookAction = "DELETE"
+	// HookActionConnected fires on the first handshake after the peer was offline.
+	HookActionConnected HookAction = "CONNECTED"
+	// HookActionDisconnected fires when the last handshake of a connected peer times out.
+	HookActionDisconnected    HookAction = "DISCONNECTED"
+	HookActionEndpointChanged HookAction = "ENDPOINT_CHANGED"
+	// HookActionApplied fires when the peer shows up on the device of its server.
+	HookActionApplied HookAction = "APPLIED"
+	// HookActionRemoved fires when the peer is no longer present on the device of its server.
+	HookActionRemoved HookAction = "REMOVED"
 )
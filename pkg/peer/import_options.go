@@ -0,0 +1,7 @@
+package peer
+
+type ImportOptions struct {
+	Peers             []*CreateOptions
+	GenerateKeys      bool
+	GenerateAddresses bool
+}
@@ -0,0 +1,29 @@
+package peer
+
+import (
+	"fmt"
+)
+
+type ImportResult struct {
+	Peers  []*ImportedPeer
+	Errors []*RowError
+}
+
+// ImportedPeer is a peer created by an import, PrivateKey is only set when the key pair was generated during the import.
+type ImportedPeer struct {
+	Peer       *Peer
+	PrivateKey string
+}
+
+type RowError struct {
+	Row int
+	Err error
+}
+
+func (e *RowError) Error() string {
+	return fmt.Sprintf("row %d: %s", e.Row, e.Err)
+}
+
+func (e *RowError) Unwrap() error {
+	return e.Err
+}
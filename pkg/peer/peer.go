@@ -1,15 +1,18 @@
 package peer
 
 import (
-	"errors"
 	"fmt"
 	"net"
 	"net/netip"
 	"os/exec"
+	"strconv"
 	"strings"
 	"time"
 
 	"golang.zx2c4.com/wireguard/wgctrl/wgtypes"
+
+	"github.com/UnAfraid/wg-ui/pkg/hook"
+	"github.com/UnAfraid/wg-ui/pkg/internal/tag"
 )
 
 type Peer struct {
@@ -17,12 +20,20 @@ type Peer struct {
 	ServerId            string
 	Name                string
 	Description         string
+	Tags                []string
+	GroupId             string
 	PublicKey           string
 	Endpoint            string
 	AllowedIPs          []string
 	PresharedKey        string
 	PersistentKeepalive int
+	// AllowedDestinations restricts where traffic from the peer is forwarded to, empty allows any destination.
+	AllowedDestinations []string
+	ACL                 []*ACLRule
 	Hooks               []*Hook
+	Disabled            bool
+	ExpiresAt           *time.Time
+	Stats               Stats
 	CreateUserId        string
 	UpdateUserId        string
 	DeleteUserId        string
@@ -51,6 +62,12 @@ func (p *Peer) validate(fieldMask *UpdateFieldMask) error {
 		}
 	}
 
+	if fieldMask == nil || fieldMask.Tags {
+		if err := tag.Validate(p.Tags); err != nil {
+			return err
+		}
+	}
+
 	if fieldMask == nil || fieldMask.PublicKey {
 		if len(strings.TrimSpace(p.PublicKey)) == 0 {
 			return ErrPublicKeyRequired
@@ -92,22 +109,60 @@ func (p *Peer) validate(fieldMask *UpdateFieldMask) error {
 		}
 	}
 
+	if fieldMask == nil || fieldMask.AllowedDestinations {
+		for i, destination := range p.AllowedDestinations {
+			if _, err := netip.ParsePrefix(destination); err != nil {
+				return fmt.Errorf("invalid allowed destination: %d - %w", i+1, err)
+			}
+		}
+	}
+
+	if fieldMask == nil || fieldMask.ACL {
+		if err := validateACL(p.ACL); err != nil {
+			return err
+		}
+	}
+
 	if fieldMask == nil || fieldMask.Hooks {
-		for i, hook := range p.Hooks {
-			if _, err := exec.LookPath(hook.Command); err != nil {
-				return fmt.Errorf("invalid peer hook #%d command: %s - %w", i+1, hook.Command, err)
+		if err := validateHooks(p.Hooks); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func validateACL(rules []*ACLRule) error {
+	for i, rule := range rules {
+		if err := rule.validate(); err != nil {
+			return fmt.Errorf("invalid acl rule #%d: %w", i+1, err)
+		}
+	}
+	return nil
+}
+
+func validateHooks(hooks []*Hook) error {
+	for i, hook := range hooks {
+		if hook.Action != nil {
+			if err := hook.Action.Validate(); err != nil {
+				return fmt.Errorf("invalid peer hook #%d action: %w", i+1, err)
 			}
+		} else if _, err := exec.LookPath(hook.Command); err != nil {
+			return fmt.Errorf("invalid peer hook #%d command: %s - %w", i+1, hook.Command, err)
+		}
 
-			if i != len(p.Hooks)-1 {
-				for _, nextHook := range p.Hooks[i+1:] {
-					if strings.EqualFold(hook.Command, nextHook.Command) {
-						return fmt.Errorf("hook command: %s already exists", hook.Command)
-					}
+		if hook.Timeout < 0 || hook.MaxAttempts < 0 || hook.RetryDelay < 0 {
+			return fmt.Errorf("invalid peer hook #%d: timeout, attempts and retry delay must not be negative", i+1)
+		}
+
+		if hook.Action == nil && i != len(hooks)-1 {
+			for _, nextHook := range hooks[i+1:] {
+				if strings.EqualFold(hook.Command, nextHook.Command) {
+					return fmt.Errorf("hook command: %s already exists", hook.Command)
 				}
 			}
 		}
 	}
-
 	return nil
 }
 
@@ -120,6 +175,14 @@ func (p *Peer) update(options *UpdateOptions, fieldMask *UpdateFieldMask) {
 		p.Description = options.Description
 	}
 
+	if fieldMask.Tags {
+		p.Tags = options.Tags
+	}
+
+	if fieldMask.GroupId {
+		p.GroupId = options.GroupId
+	}
+
 	if fieldMask.PublicKey {
 		p.PublicKey = options.PublicKey
 	}
@@ -140,10 +203,30 @@ func (p *Peer) update(options *UpdateOptions, fieldMask *UpdateFieldMask) {
 		p.PersistentKeepalive = options.PersistentKeepalive
 	}
 
+	if fieldMask.AllowedDestinations {
+		p.AllowedDestinations = options.AllowedDestinations
+	}
+
+	if fieldMask.ACL {
+		p.ACL = options.ACL
+	}
+
 	if fieldMask.Hooks {
 		p.Hooks = options.Hooks
 	}
 
+	if fieldMask.Disabled {
+		p.Disabled = options.Disabled
+	}
+
+	if fieldMask.ExpiresAt {
+		p.ExpiresAt = options.ExpiresAt
+	}
+
+	if fieldMask.Stats {
+		p.Stats = options.Stats
+	}
+
 	if fieldMask.CreateUserId {
 		p.CreateUserId = options.CreateUserId
 	}
@@ -153,25 +236,68 @@ func (p *Peer) update(options *UpdateOptions, fieldMask *UpdateFieldMask) {
 	}
 }
 
-func (p *Peer) runHooks(action HookAction) error {
-	var errs []error
-	for i, hook := range p.Hooks {
-		if !hook.shouldExecute(action) {
+func (p *Peer) hookRunOptions(interfaceName string, action HookAction) []*hook.RunOptions {
+	var runOptions []*hook.RunOptions
+	for _, h := range p.Hooks {
+		if !h.shouldExecute(action) {
 			continue
 		}
 
-		cmd := exec.Command(hook.Command, "PEER", p.PublicKey, string(action))
-		cmd.Env = []string{
-			fmt.Sprintf("WG_PEER_NAME=%s", p.Name),
-			fmt.Sprintf("WG_PEER_DESCRIPTION=%s", strings.ReplaceAll(p.Description, "\n", "\\n")),
-			fmt.Sprintf("WG_PEER_PUBLICKEY=%s", p.PublicKey),
-			fmt.Sprintf("WG_PEER_ENDPOINT=%s", p.Endpoint),
-			fmt.Sprintf("WG_PEER_ALLOWED_IPS=%s", strings.Join(p.AllowedIPs, ", ")),
-			fmt.Sprintf("WG_PEER_PERSISTENT_KEEP_ALIVE=%d", p.PersistentKeepalive),
-		}
-		if err := cmd.Run(); err != nil {
-			errs = append(errs, fmt.Errorf("failed to execute hook #%d %s - %w", i+1, hook.Command, err))
+		runOptions = append(runOptions, &hook.RunOptions{
+			ServerId:    p.ServerId,
+			PeerId:      p.Id,
+			Action:      string(action),
+			Command:     h.Command,
+			Path:        h.Command,
+			Args:        []string{"PEER", p.PublicKey, string(action)},
+			TypedAction: h.Action,
+			Variables: map[string]string{
+				"Interface":           interfaceName,
+				"Name":                p.Name,
+				"Description":         p.Description,
+				"PublicKey":           p.PublicKey,
+				"Endpoint":            p.Endpoint,
+				"AllowedIPs":          strings.Join(p.AllowedIPs, ","),
+				"PersistentKeepalive": strconv.Itoa(p.PersistentKeepalive),
+				"Action":              string(action),
+				"LastHandshake":       formatHandshakeTime(p.Stats.LastHandshakeTime),
+				"CurrentEndpoint":     p.Stats.Endpoint,
+				"ReceiveBytes":        strconv.FormatInt(p.Stats.ReceiveBytes, 10),
+				"TransmitBytes":       strconv.FormatInt(p.Stats.TransmitBytes, 10),
+			},
+			Env: []string{
+				fmt.Sprintf("WG_SERVER_NAME=%s", interfaceName),
+				fmt.Sprintf("WG_PEER_NAME=%s", p.Name),
+				fmt.Sprintf("WG_PEER_DESCRIPTION=%s", strings.ReplaceAll(p.Description, "\n", "\\n")),
+				fmt.Sprintf("WG_PEER_PUBLICKEY=%s", p.PublicKey),
+				fmt.Sprintf("WG_PEER_ENDPOINT=%s", p.Endpoint),
+				fmt.Sprintf("WG_PEER_ALLOWED_IPS=%s", strings.Join(p.AllowedIPs, ", ")),
+				fmt.Sprintf("WG_PEER_PERSISTENT_KEEP_ALIVE=%d", p.PersistentKeepalive),
+				fmt.Sprintf("WG_PEER_LAST_HANDSHAKE=%s", formatHandshakeTime(p.Stats.LastHandshakeTime)),
+				fmt.Sprintf("WG_PEER_CURRENT_ENDPOINT=%s", p.Stats.Endpoint),
+				fmt.Sprintf("WG_PEER_RX_BYTES=%d", p.Stats.ReceiveBytes),
+				fmt.Sprintf("WG_PEER_TX_BYTES=%d", p.Stats.TransmitBytes),
+			},
+			Timeout: h.Timeout,
+			Retry:   h.retryPolicy(),
+		})
+	}
+	return runOptions
+}
+
+// HasDeviceHooks reports whether any hook of the peer follows its device state.
+func (p *Peer) HasDeviceHooks() bool {
+	for _, h := range p.Hooks {
+		if h != nil && h.followsDevice() {
+			return true
 		}
 	}
-	return errors.Join(errs...)
+	return false
+}
+
+func formatHandshakeTime(lastHandshakeTime time.Time) string {
+	if lastHandshakeTime.IsZero() {
+		return ""
+	}
+	return lastHandshakeTime.UTC().Format(time.RFC3339)
 }
@@ -3,15 +3,19 @@ package peer
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"path"
+	"slices"
 	"strings"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/sirupsen/logrus"
+	"golang.zx2c4.com/wireguard/wgctrl/wgtypes"
 
 	"github.com/UnAfraid/wg-ui/pkg/dbx"
+	"github.com/UnAfraid/wg-ui/pkg/hook"
 	"github.com/UnAfraid/wg-ui/pkg/server"
 	"github.com/UnAfraid/wg-ui/pkg/subscription"
 )
@@ -26,27 +30,45 @@ type Service interface {
 	CreatePeer(ctx context.Context, serverId string, options *CreateOptions, userId string) (*Peer, error)
 	UpdatePeer(ctx context.Context, peerId string, options *UpdateOptions, fieldMask *UpdateFieldMask, userId string) (*Peer, error)
 	DeletePeer(ctx context.Context, peerId string, userId string) (*Peer, error)
-	Subscribe(ctx context.Context) (<-chan *ChangedEvent, error)
+	ImportPeers(ctx context.Context, serverId string, options *ImportOptions, userId string) (*ImportResult, error)
+	PreviewCreatePeer(ctx context.Context, serverId string, options *CreateOptions, userId string) (*Peer, error)
+	PreviewUpdatePeer(ctx context.Context, peerId string, options *UpdateOptions, fieldMask *UpdateFieldMask, userId string) (*Peer, error)
+	// DevicePeers returns the peers as they are configured on the device, disabled and expired peers are left out
+	// and the defaults of their group are filled in.
+	DevicePeers(ctx context.Context, peers []*Peer) ([]*Peer, error)
+	FindGroup(ctx context.Context, options *GroupFindOneOptions) (*Group, error)
+	FindGroups(ctx context.Context, options *GroupFindOptions) ([]*Group, error)
+	CreateGroup(ctx context.Context, options *GroupCreateOptions, userId string) (*Group, error)
+	UpdateGroup(ctx context.Context, groupId string, options *GroupUpdateOptions, fieldMask *GroupUpdateFieldMask, userId string) (*Group, error)
+	DeleteGroup(ctx context.Context, groupId string, userId string) (*Group, error)
+	// Subscribe delivers the changed events, when since is set the journaled events following it are delivered first.
+	Subscribe(ctx context.Context, since *subscription.Cursor) (<-chan *ChangedEvent, error)
 	HasSubscribers() bool
 }
 
 type service struct {
 	peerRepository    Repository
+	groupRepository   GroupRepository
 	transactionScoper dbx.TransactionScoper
 	serverService     server.Service
+	hookService       hook.Service
 	subscription      subscription.Subscription
 }
 
 func NewService(
 	peerRepository Repository,
+	groupRepository GroupRepository,
 	transactionScoper dbx.TransactionScoper,
 	serverService server.Service,
+	hookService hook.Service,
 	subscription subscription.Subscription,
 ) Service {
 	return &service{
 		peerRepository:    peerRepository,
+		groupRepository:   groupRepository,
 		transactionScoper: transactionScoper,
 		serverService:     serverService,
+		hookService:       hookService,
 		subscription:      subscription,
 	}
 }
@@ -59,36 +81,31 @@ func (s *service) FindPeer(ctx context.Context, options *FindOneOptions) (*Peer,
 }
 
 func (s *service) FindPeers(ctx context.Context, options *FindOptions) ([]*Peer, error) {
-	return s.peerRepository.FindAll(ctx, options)
-}
+	if err := options.Validate(); err != nil {
+		return nil, err
+	}
 
-func (s *service) CreatePeer(ctx context.Context, serverId string, options *CreateOptions, userId string) (*Peer, error) {
-	return dbx.InTransactionScopeWithResult(ctx, s.transactionScoper, func(ctx context.Context) (*Peer, error) {
-		srv, err := s.findServerById(ctx, serverId)
+	if options.Filter != nil && len(options.Filter.BackendIds) != 0 {
+		filter, err := s.resolveBackendFilter(ctx, options.Filter)
 		if err != nil {
 			return nil, err
 		}
-
-		existingPeers, err := s.findPeersByServerId(ctx, serverId)
-		if err != nil {
-			return nil, err
+		if filter == nil {
+			return nil, nil
 		}
 
-		for _, peer := range existingPeers {
-			if strings.EqualFold(peer.Name, options.Name) {
-				return nil, ErrPeerNameAlreadyInUse
-			}
-			if strings.EqualFold(peer.PublicKey, options.PublicKey) {
-				return nil, ErrPublicKeyAlreadyExists
-			}
-		}
+		scopedOptions := *options
+		scopedOptions.Filter = filter
+		options = &scopedOptions
+	}
 
-		peer, err := processCreatePeer(srv, options, userId)
-		if err != nil {
-			return nil, err
-		}
+	return s.peerRepository.FindAll(ctx, options)
+}
 
-		if err := peer.validate(nil); err != nil {
+func (s *service) CreatePeer(ctx context.Context, serverId string, options *CreateOptions, userId string) (*Peer, error) {
+	return dbx.InTransactionScopeWithResult(ctx, s.transactionScoper, func(ctx context.Context) (*Peer, error) {
+		peer, err := s.prepareCreatePeer(ctx, serverId, options, userId)
+		if err != nil {
 			return nil, err
 		}
 
@@ -97,7 +114,7 @@ func (s *service) CreatePeer(ctx context.Context, serverId string, options *Crea
 			return nil, err
 		}
 
-		if err := createdPeer.runHooks(HookActionCreate); err != nil {
+		if err := s.runHooks(ctx, createdPeer, HookActionCreate); err != nil {
 			logrus.
 				WithError(err).
 				WithField("peer", peer.Name).
@@ -114,22 +131,18 @@ func (s *service) CreatePeer(ctx context.Context, serverId string, options *Crea
 
 func (s *service) UpdatePeer(ctx context.Context, peerId string, options *UpdateOptions, fieldMask *UpdateFieldMask, userId string) (*Peer, error) {
 	return dbx.InTransactionScopeWithResult(ctx, s.transactionScoper, func(ctx context.Context) (*Peer, error) {
-		peer, err := s.findPeerById(ctx, peerId)
-		if err != nil {
-			return nil, err
-		}
-
-		existingPeers, err := s.findPeersByServerId(ctx, peer.ServerId)
+		peer, err := s.prepareUpdatePeer(ctx, peerId, options, fieldMask, userId)
 		if err != nil {
 			return nil, err
 		}
 
-		if err = processUpdatePeer(existingPeers, peer, options, fieldMask, userId); err != nil {
-			return nil, err
-		}
-
-		if err := peer.validate(fieldMask); err != nil {
-			return nil, err
+		var previousStats Stats
+		if fieldMask.Stats {
+			existingPeer, err := s.findPeerById(ctx, peerId)
+			if err != nil {
+				return nil, err
+			}
+			previousStats = existingPeer.Stats
 		}
 
 		updatedPeer, err := s.peerRepository.Update(ctx, peer, fieldMask)
@@ -137,14 +150,26 @@ func (s *service) UpdatePeer(ctx context.Context, peerId string, options *Update
 			return nil, err
 		}
 
-		if err := updatedPeer.runHooks(HookActionUpdate); err != nil {
+		action := ChangedActionUpdated
+		if fieldMask.Stats {
+			action = ChangedActionStatsUpdated
+			for _, hookAction := range updatedPeer.Stats.hookActions(previousStats) {
+				if err := s.runHooks(ctx, updatedPeer, hookAction); err != nil {
+					logrus.
+						WithError(err).
+						WithField("peer", peer.Name).
+						WithField("action", hookAction).
+						Warn("failed to run hooks on peer device change")
+				}
+			}
+		} else if err := s.runHooks(ctx, updatedPeer, HookActionUpdate); err != nil {
 			logrus.
 				WithError(err).
 				WithField("peer", peer.Name).
 				Warn("failed to run hooks on peer update")
 		}
 
-		if err = s.notify(ChangedActionUpdated, updatedPeer); err != nil {
+		if err = s.notify(action, updatedPeer); err != nil {
 			logrus.WithError(err).Warn("failed to notify peer updated event")
 		}
 
@@ -164,7 +189,7 @@ func (s *service) DeletePeer(ctx context.Context, peerId string, userId string)
 			return nil, err
 		}
 
-		if err := deletedPeer.runHooks(HookActionDelete); err != nil {
+		if err := s.runHooks(ctx, deletedPeer, HookActionDelete); err != nil {
 			logrus.
 				WithError(err).
 				WithField("peer", peer.Name).
@@ -179,6 +204,300 @@ func (s *service) DeletePeer(ctx context.Context, peerId string, userId string)
 	})
 }
 
+func (s *service) ImportPeers(ctx context.Context, serverId string, options *ImportOptions, userId string) (*ImportResult, error) {
+	if options == nil {
+		return nil, ErrImportPeersOptionsRequired
+	}
+
+	return dbx.InTransactionScopeWithResult(ctx, s.transactionScoper, func(ctx context.Context) (*ImportResult, error) {
+		srv, err := s.findServerById(ctx, serverId)
+		if err != nil {
+			return nil, err
+		}
+
+		existingPeers, err := s.findPeersByServerId(ctx, serverId)
+		if err != nil {
+			return nil, err
+		}
+
+		var allocator *addressAllocator
+		if options.GenerateAddresses {
+			allocator, err = newAddressAllocator(srv.Address, existingPeers)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		result := &ImportResult{}
+		for i, createOptions := range options.Peers {
+			importedPeer, err := processImportPeer(srv, createOptions, options, allocator, userId)
+			if err == nil {
+				err = validatePeerUnique(existingPeers, importedPeer.Peer.Name, importedPeer.Peer.PublicKey)
+			}
+			if err == nil {
+				err = importedPeer.Peer.validate(nil)
+			}
+			if err != nil {
+				result.Errors = append(result.Errors, &RowError{Row: i + 1, Err: err})
+				continue
+			}
+
+			existingPeers = append(existingPeers, importedPeer.Peer)
+			result.Peers = append(result.Peers, importedPeer)
+		}
+
+		if len(result.Errors) != 0 {
+			result.Peers = nil
+			return result, nil
+		}
+
+		for _, importedPeer := range result.Peers {
+			createdPeer, err := s.peerRepository.Create(ctx, importedPeer.Peer)
+			if err != nil {
+				return nil, err
+			}
+			importedPeer.Peer = createdPeer
+
+			if err := s.runHooks(ctx, createdPeer, HookActionCreate); err != nil {
+				logrus.
+					WithError(err).
+					WithField("peer", createdPeer.Name).
+					Warn("failed to run hooks on peer import")
+			}
+
+			if err = s.notify(ChangedActionCreated, createdPeer); err != nil {
+				logrus.WithError(err).Warn("failed to notify peer created event")
+			}
+		}
+
+		return result, nil
+	})
+}
+
+// PreviewCreatePeer validates the create options and returns the peer that would be created, without persisting it.
+func (s *service) PreviewCreatePeer(ctx context.Context, serverId string, options *CreateOptions, userId string) (*Peer, error) {
+	return s.prepareCreatePeer(ctx, serverId, options, userId)
+}
+
+// PreviewUpdatePeer validates the update options and returns the peer as it would be after the update, without persisting it.
+func (s *service) PreviewUpdatePeer(ctx context.Context, peerId string, options *UpdateOptions, fieldMask *UpdateFieldMask, userId string) (*Peer, error) {
+	return s.prepareUpdatePeer(ctx, peerId, options, fieldMask, userId)
+}
+
+func (s *service) DevicePeers(ctx context.Context, peers []*Peer) ([]*Peer, error) {
+	groups, err := s.findPeersGroups(ctx, peers)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	var devicePeers []*Peer
+	for _, p := range peers {
+		group := groups[p.GroupId]
+		if !p.Active(group, now) {
+			continue
+		}
+		devicePeers = append(devicePeers, p.withGroup(group))
+	}
+	return devicePeers, nil
+}
+
+func (s *service) FindGroup(ctx context.Context, options *GroupFindOneOptions) (*Group, error) {
+	if err := options.Validate(); err != nil {
+		return nil, err
+	}
+	return s.groupRepository.FindOne(ctx, options)
+}
+
+func (s *service) FindGroups(ctx context.Context, options *GroupFindOptions) ([]*Group, error) {
+	if err := options.Validate(); err != nil {
+		return nil, err
+	}
+	return s.groupRepository.FindAll(ctx, options)
+}
+
+func (s *service) CreateGroup(ctx context.Context, options *GroupCreateOptions, userId string) (*Group, error) {
+	if options == nil {
+		return nil, ErrCreateGroupOptionsRequired
+	}
+
+	return dbx.InTransactionScopeWithResult(ctx, s.transactionScoper, func(ctx context.Context) (*Group, error) {
+		if err := s.validateGroupNameUnique(ctx, options.Name, ""); err != nil {
+			return nil, err
+		}
+
+		id, err := newId()
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate new id: %w", err)
+		}
+
+		now := time.Now()
+		group := &Group{
+			Id:                  id,
+			Name:                options.Name,
+			Description:         options.Description,
+			PersistentKeepalive: options.PersistentKeepalive,
+			DNS:                 options.DNS,
+			ACL:                 options.ACL,
+			Hooks:               options.Hooks,
+			ExpiresAfter:        options.ExpiresAfter,
+			CreateUserId:        userId,
+			CreatedAt:           now,
+			UpdatedAt:           now,
+		}
+
+		if err := group.validate(nil); err != nil {
+			return nil, err
+		}
+
+		if err := s.checkHooks(group.Hooks); err != nil {
+			return nil, err
+		}
+
+		return s.groupRepository.Create(ctx, group)
+	})
+}
+
+func (s *service) UpdateGroup(ctx context.Context, groupId string, options *GroupUpdateOptions, fieldMask *GroupUpdateFieldMask, userId string) (*Group, error) {
+	if options == nil {
+		return nil, ErrUpdateGroupOptionsRequired
+	}
+
+	if fieldMask == nil {
+		return nil, ErrGroupFieldMaskRequired
+	}
+
+	return dbx.InTransactionScopeWithResult(ctx, s.transactionScoper, func(ctx context.Context) (*Group, error) {
+		group, err := s.findGroupById(ctx, groupId)
+		if err != nil {
+			return nil, err
+		}
+
+		if fieldMask.Name {
+			if err := s.validateGroupNameUnique(ctx, options.Name, group.Id); err != nil {
+				return nil, err
+			}
+		}
+
+		if userId != "" {
+			options.UpdateUserId = userId
+			fieldMask.UpdateUserId = true
+		}
+		group.update(options, fieldMask)
+		group.UpdatedAt = time.Now()
+
+		if err := group.validate(fieldMask); err != nil {
+			return nil, err
+		}
+
+		if fieldMask.Hooks {
+			if err := s.checkHooks(group.Hooks); err != nil {
+				return nil, err
+			}
+		}
+
+		return s.groupRepository.Update(ctx, group, fieldMask)
+	})
+}
+
+func (s *service) DeleteGroup(ctx context.Context, groupId string, userId string) (*Group, error) {
+	return dbx.InTransactionScopeWithResult(ctx, s.transactionScoper, func(ctx context.Context) (*Group, error) {
+		group, err := s.findGroupById(ctx, groupId)
+		if err != nil {
+			return nil, err
+		}
+
+		peers, err := s.peerRepository.FindAll(ctx, &FindOptions{
+			Filter: &Filter{
+				GroupIds: []string{group.Id},
+			},
+		})
+		if err != nil {
+			return nil, err
+		}
+		if len(peers) != 0 {
+			return nil, ErrGroupHasPeers
+		}
+
+		return s.groupRepository.Delete(ctx, group.Id, userId)
+	})
+}
+
+func (s *service) prepareCreatePeer(ctx context.Context, serverId string, options *CreateOptions, userId string) (*Peer, error) {
+	if options == nil {
+		return nil, ErrCreatePeerOptionsRequired
+	}
+
+	srv, err := s.findServerById(ctx, serverId)
+	if err != nil {
+		return nil, err
+	}
+
+	existingPeers, err := s.findPeersByServerId(ctx, serverId)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := validatePeerUnique(existingPeers, options.Name, options.PublicKey); err != nil {
+		return nil, err
+	}
+
+	peer, err := processCreatePeer(srv, options, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := peer.validate(nil); err != nil {
+		return nil, err
+	}
+
+	if peer.GroupId != "" {
+		if _, err := s.findGroupById(ctx, peer.GroupId); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.checkHooks(peer.Hooks); err != nil {
+		return nil, err
+	}
+
+	return peer, nil
+}
+
+func (s *service) prepareUpdatePeer(ctx context.Context, peerId string, options *UpdateOptions, fieldMask *UpdateFieldMask, userId string) (*Peer, error) {
+	peer, err := s.findPeerById(ctx, peerId)
+	if err != nil {
+		return nil, err
+	}
+
+	existingPeers, err := s.findPeersByServerId(ctx, peer.ServerId)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = processUpdatePeer(existingPeers, peer, options, fieldMask, userId); err != nil {
+		return nil, err
+	}
+
+	if err := peer.validate(fieldMask); err != nil {
+		return nil, err
+	}
+
+	if fieldMask.GroupId && peer.GroupId != "" {
+		if _, err := s.findGroupById(ctx, peer.GroupId); err != nil {
+			return nil, err
+		}
+	}
+
+	if fieldMask.Hooks {
+		if err := s.checkHooks(peer.Hooks); err != nil {
+			return nil, err
+		}
+	}
+
+	return peer, nil
+}
+
 func (s *service) findServerById(ctx context.Context, serverId string) (*server.Server, error) {
 	srv, err := s.serverService.FindServer(ctx, &server.FindOneOptions{
 		IdOption: &server.IdOption{
@@ -209,12 +528,107 @@ func (s *service) findPeerById(ctx context.Context, peerId string) (*Peer, error
 	return peer, nil
 }
 
+func (s *service) findGroupById(ctx context.Context, groupId string) (*Group, error) {
+	group, err := s.groupRepository.FindOne(ctx, &GroupFindOneOptions{
+		IdOption: &IdOption{
+			Id: groupId,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if group == nil {
+		return nil, ErrGroupNotFound
+	}
+	return group, nil
+}
+
+// findPeerGroup returns the group of the peer, nil when the peer is not in a group.
+func (s *service) findPeerGroup(ctx context.Context, peer *Peer) (*Group, error) {
+	if peer.GroupId == "" {
+		return nil, nil
+	}
+	return s.groupRepository.FindOne(ctx, &GroupFindOneOptions{
+		IdOption: &IdOption{
+			Id: peer.GroupId,
+		},
+	})
+}
+
+// findPeersGroups returns the groups of the peers by their id.
+func (s *service) findPeersGroups(ctx context.Context, peers []*Peer) (map[string]*Group, error) {
+	var groupIds []string
+	for _, p := range peers {
+		if p.GroupId != "" && !slices.Contains(groupIds, p.GroupId) {
+			groupIds = append(groupIds, p.GroupId)
+		}
+	}
+	if len(groupIds) == 0 {
+		return nil, nil
+	}
+
+	groups, err := s.groupRepository.FindAll(ctx, &GroupFindOptions{
+		Ids: groupIds,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to find peer groups: %w", err)
+	}
+
+	groupsById := make(map[string]*Group, len(groups))
+	for _, group := range groups {
+		groupsById[group.Id] = group
+	}
+	return groupsById, nil
+}
+
+func (s *service) validateGroupNameUnique(ctx context.Context, name string, groupId string) error {
+	groups, err := s.groupRepository.FindAll(ctx, &GroupFindOptions{})
+	if err != nil {
+		return err
+	}
+
+	for _, group := range groups {
+		if strings.EqualFold(group.Name, name) && group.Id != groupId {
+			return ErrGroupNameAlreadyInUse
+		}
+	}
+	return nil
+}
+
 func (s *service) findPeersByServerId(ctx context.Context, serverId string) ([]*Peer, error) {
 	return s.peerRepository.FindAll(ctx, &FindOptions{
 		ServerId: &serverId,
 	})
 }
 
+// resolveBackendFilter replaces the backend ids of the filter with the ids of the servers on those backends,
+// it returns nil when no server can match.
+func (s *service) resolveBackendFilter(ctx context.Context, filter *Filter) (*Filter, error) {
+	servers, err := s.serverService.FindServers(ctx, &server.FindOptions{
+		Filter: &server.Filter{
+			BackendIds: filter.BackendIds,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var serverIds []string
+	for _, srv := range servers {
+		if len(filter.ServerIds) == 0 || slices.Contains(filter.ServerIds, srv.Id) {
+			serverIds = append(serverIds, srv.Id)
+		}
+	}
+	if len(serverIds) == 0 {
+		return nil, nil
+	}
+
+	scopedFilter := *filter
+	scopedFilter.ServerIds = serverIds
+	scopedFilter.BackendIds = nil
+	return &scopedFilter, nil
+}
+
 func newId() (string, error) {
 	id, err := uuid.NewRandom()
 	if err != nil {
@@ -240,12 +654,18 @@ func processCreatePeer(server *server.Server, options *CreateOptions, userId str
 		ServerId:            server.Id,
 		Name:                options.Name,
 		Description:         options.Description,
+		Tags:                options.Tags,
+		GroupId:             options.GroupId,
 		PublicKey:           options.PublicKey,
 		Endpoint:            options.Endpoint,
 		AllowedIPs:          options.AllowedIPs,
 		PresharedKey:        options.PresharedKey,
 		PersistentKeepalive: options.PersistentKeepalive,
+		AllowedDestinations: options.AllowedDestinations,
+		ACL:                 options.ACL,
 		Hooks:               options.Hooks,
+		Disabled:            options.Disabled,
+		ExpiresAt:           options.ExpiresAt,
 		CreateUserId:        userId,
 		CreatedAt:           now,
 		UpdatedAt:           now,
@@ -253,6 +673,53 @@ func processCreatePeer(server *server.Server, options *CreateOptions, userId str
 	}, nil
 }
 
+func processImportPeer(server *server.Server, options *CreateOptions, importOptions *ImportOptions, allocator *addressAllocator, userId string) (*ImportedPeer, error) {
+	if options == nil {
+		return nil, ErrCreatePeerOptionsRequired
+	}
+
+	createOptions := *options
+	var privateKey string
+	if importOptions.GenerateKeys && strings.TrimSpace(createOptions.PublicKey) == "" {
+		key, err := wgtypes.GeneratePrivateKey()
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate private key: %w", err)
+		}
+		privateKey = key.String()
+		createOptions.PublicKey = key.PublicKey().String()
+	}
+
+	if allocator != nil && len(createOptions.AllowedIPs) == 0 {
+		address, err := allocator.allocate()
+		if err != nil {
+			return nil, err
+		}
+		createOptions.AllowedIPs = []string{address}
+	}
+
+	peer, err := processCreatePeer(server, &createOptions, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ImportedPeer{
+		Peer:       peer,
+		PrivateKey: privateKey,
+	}, nil
+}
+
+func validatePeerUnique(existingPeers []*Peer, name string, publicKey string) error {
+	for _, peer := range existingPeers {
+		if strings.EqualFold(peer.Name, name) {
+			return ErrPeerNameAlreadyInUse
+		}
+		if strings.EqualFold(peer.PublicKey, publicKey) {
+			return ErrPublicKeyAlreadyExists
+		}
+	}
+	return nil
+}
+
 func processUpdatePeer(existingPeers []*Peer, peer *Peer, options *UpdateOptions, fieldMask *UpdateFieldMask, userId string) error {
 	if options == nil {
 		return ErrUpdatePeerOptionsRequired
@@ -287,20 +754,61 @@ func processUpdatePeer(existingPeers []*Peer, peer *Peer, options *UpdateOptions
 	return nil
 }
 
+// checkHooks rejects hooks that are not allowed by the hook policy, such as raw commands when they are disabled.
+func (s *service) checkHooks(hooks []*Hook) error {
+	for i, h := range hooks {
+		if err := s.hookService.CheckHook(h.Action); err != nil {
+			return fmt.Errorf("invalid peer hook #%d: %w", i+1, err)
+		}
+	}
+	return nil
+}
+
+func (s *service) runHooks(ctx context.Context, peer *Peer, action HookAction) error {
+	group, err := s.findPeerGroup(ctx, peer)
+	if err != nil {
+		return err
+	}
+
+	peer = peer.withGroup(group)
+	if len(peer.Hooks) == 0 {
+		return nil
+	}
+
+	srv, err := s.findServerById(ctx, peer.ServerId)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, runOptions := range peer.hookRunOptions(srv.Name, action) {
+		if err := s.hookService.Run(ctx, runOptions); err != nil {
+			errs = append(errs, fmt.Errorf("failed to queue hook %s - %w", runOptions.Command, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
 func (s *service) notify(action string, peer *Peer) error {
 	bytes, err := json.Marshal(ChangedEvent{Action: action, Peer: peer})
 	if err != nil {
 		return err
 	}
 
-	if err := s.subscription.Notify(bytes, path.Join(subscriptionPath, peer.Id)); err != nil {
+	notify := s.subscription.Notify
+	if action == ChangedActionStatsUpdated {
+		// the stats change on every poll, subscribers resuming after a cursor read the current ones instead
+		notify = s.subscription.NotifyEphemeral
+	}
+
+	if err := notify(bytes, path.Join(subscriptionPath, peer.Id)); err != nil {
 		return fmt.Errorf("failed to notify peer changed event: %w", err)
 	}
 	return nil
 }
 
-func (s *service) Subscribe(ctx context.Context) (<-chan *ChangedEvent, error) {
-	bytesChannel, err := s.subscription.Subscribe(ctx, path.Join(subscriptionPath, "*"))
+func (s *service) Subscribe(ctx context.Context, since *subscription.Cursor) (<-chan *ChangedEvent, error) {
+	events, err := s.subscription.Subscribe(ctx, path.Join(subscriptionPath, "*"), since)
 	if err != nil {
 		return nil, err
 	}
@@ -309,10 +817,10 @@ func (s *service) Subscribe(ctx context.Context) (<-chan *ChangedEvent, error) {
 	go func() {
 		defer close(observerChan)
 
-		for bytes := range bytesChannel {
-			var changedEvent *ChangedEvent
-			if err := json.Unmarshal(bytes, &changedEvent); err != nil {
-				logrus.WithError(err).Warn("failed to decode peer changed event")
+		for event := range events {
+			changedEvent, _, err := DecodeChangedEvent(event)
+			if err != nil {
+				logrus.WithError(err).Warn("failed to decode changed event")
 				return
 			}
 			observerChan <- changedEvent
@@ -0,0 +1,29 @@
+package peer
+
+import (
+	"github.com/UnAfraid/wg-ui/pkg/pagination"
+)
+
+const (
+	SortFieldName          = "NAME"
+	SortFieldCreatedAt     = "CREATED_AT"
+	SortFieldLastHandshake = "LAST_HANDSHAKE"
+)
+
+func (p *Peer) Cursor(sortField string) pagination.Cursor {
+	var key string
+	switch sortField {
+	case SortFieldCreatedAt:
+		key = pagination.TimeKey(p.CreatedAt)
+	case SortFieldLastHandshake:
+		key = pagination.TimeKey(p.Stats.LastHandshakeTime)
+	default:
+		key = pagination.StringKey(p.Name)
+	}
+
+	return pagination.Cursor{
+		Field: sortField,
+		Key:   key,
+		Id:    p.Id,
+	}
+}
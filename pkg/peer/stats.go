@@ -0,0 +1,60 @@
+package peer
+
+import (
+	"time"
+)
+
+// onlineHandshakeTimeout is how long after the last handshake a peer is still considered online,
+// WireGuard rejects a session 180 seconds after it was established.
+const onlineHandshakeTimeout = 3 * time.Minute
+
+type Stats struct {
+	LastHandshakeTime time.Time
+	// Endpoint is the address the peer last connected from, it is kept while the peer is offline.
+	Endpoint      string
+	ReceiveBytes  int64
+	TransmitBytes int64
+	// Connected and Applied are the device state seen by the last stats update, peer hooks fire on their transitions.
+	Connected bool
+	Applied   bool
+}
+
+func (s Stats) Online(now time.Time) bool {
+	if s.LastHandshakeTime.IsZero() {
+		return false
+	}
+	return now.Sub(s.LastHandshakeTime) < onlineHandshakeTimeout
+}
+
+// DeviceStateChanged reports whether the device state differs from the stored one, transfer counters are ignored.
+func (s Stats) DeviceStateChanged(previous Stats) bool {
+	return !s.LastHandshakeTime.Equal(previous.LastHandshakeTime) ||
+		s.Endpoint != previous.Endpoint ||
+		s.Connected != previous.Connected ||
+		s.Applied != previous.Applied
+}
+
+// hookActions lists the device lifecycle events between the previous and the current stats, in the order they happened.
+func (s Stats) hookActions(previous Stats) []HookAction {
+	var actions []HookAction
+	if s.Applied && !previous.Applied {
+		actions = append(actions, HookActionApplied)
+	}
+	if s.Connected && !previous.Connected {
+		actions = append(actions, HookActionConnected)
+	}
+	if s.Endpoint != "" && previous.Endpoint != "" && s.Endpoint != previous.Endpoint {
+		actions = append(actions, HookActionEndpointChanged)
+	}
+	if !s.Connected && previous.Connected {
+		actions = append(actions, HookActionDisconnected)
+	}
+	if !s.Applied && previous.Applied {
+		actions = append(actions, HookActionRemoved)
+	}
+	return actions
+}
+
+func (p *Peer) Online(now time.Time) bool {
+	return p.Stats.Online(now)
+}
@@ -0,0 +1,54 @@
+package peer
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestStatsHookActions(t *testing.T) {
+	handshake := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	offline := Stats{LastHandshakeTime: handshake, Endpoint: "192.0.2.1:51820", Applied: true}
+	online := Stats{LastHandshakeTime: handshake.Add(5 * time.Minute), Endpoint: "192.0.2.1:51820", Applied: true, Connected: true}
+	roamed := online
+	roamed.Endpoint = "198.51.100.7:40000"
+
+	tests := []struct {
+		name     string
+		previous Stats
+		current  Stats
+		expected []HookAction
+	}{
+		{name: "applied and connected", previous: Stats{}, current: online, expected: []HookAction{HookActionApplied, HookActionConnected}},
+		{name: "connected", previous: offline, current: online, expected: []HookAction{HookActionConnected}},
+		{name: "endpoint changed", previous: online, current: roamed, expected: []HookAction{HookActionEndpointChanged}},
+		{name: "disconnected", previous: online, current: offline, expected: []HookAction{HookActionDisconnected}},
+		{name: "removed", previous: online, current: Stats{LastHandshakeTime: online.LastHandshakeTime, Endpoint: online.Endpoint}, expected: []HookAction{HookActionDisconnected, HookActionRemoved}},
+		{name: "first endpoint", previous: Stats{Applied: true}, current: online, expected: []HookAction{HookActionConnected}},
+		{name: "unchanged", previous: online, current: online},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if actions := tt.current.hookActions(tt.previous); !slices.Equal(actions, tt.expected) {
+				t.Fatalf("expected %v, got %v", tt.expected, actions)
+			}
+		})
+	}
+}
+
+func TestStatsDeviceStateChangedIgnoresTransfer(t *testing.T) {
+	stats := Stats{LastHandshakeTime: time.Now(), Applied: true, Connected: true, ReceiveBytes: 1}
+	updated := stats
+	updated.ReceiveBytes = 1024
+	updated.TransmitBytes = 2048
+
+	if updated.DeviceStateChanged(stats) {
+		t.Fatalf("expected transfer counters to be ignored")
+	}
+
+	updated.Connected = false
+	if !updated.DeviceStateChanged(stats) {
+		t.Fatalf("expected a connection change to be reported")
+	}
+}
@@ -3,12 +3,19 @@ package peer
 type UpdateFieldMask struct {
 	Name                bool
 	Description         bool
+	Tags                bool
+	GroupId             bool
 	PublicKey           bool
 	Endpoint            bool
 	AllowedIPs          bool
 	PresharedKey        bool
 	PersistentKeepalive bool
+	AllowedDestinations bool
+	ACL                 bool
 	Hooks               bool
+	Disabled            bool
+	ExpiresAt           bool
+	Stats               bool
 	CreateUserId        bool
 	UpdateUserId        bool
 }
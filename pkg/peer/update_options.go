@@ -1,15 +1,25 @@
 package peer
 
+import (
+	"time"
+)
+
 type UpdateOptions struct {
 	Name                string
 	Description         string
-	Enabled             bool
+	Tags                []string
+	GroupId             string
 	PublicKey           string
 	Endpoint            string
 	AllowedIPs          []string
 	PresharedKey        string
 	PersistentKeepalive int
+	AllowedDestinations []string
+	ACL                 []*ACLRule
 	Hooks               []*Hook
+	Disabled            bool
+	ExpiresAt           *time.Time
+	Stats               Stats
 	CreateUserId        string
 	UpdateUserId        string
 }
@@ -1,5 +1,13 @@
 package server
 
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+
+	"github.com/UnAfraid/wg-ui/pkg/subscription"
+)
+
 const (
 	ChangedActionCreated               = "CREATED"
 	ChangedActionUpdated               = "UPDATED"
@@ -7,9 +15,26 @@ const (
 	ChangedActionInterfaceStatsUpdated = "INTERFACE_STATS_UPDATED"
 	ChangedActionStarted               = "STARTED"
 	ChangedActionStopped               = "STOPPED"
+	ChangedActionDriftDetected         = "DRIFT_DETECTED"
 )
 
 type ChangedEvent struct {
 	Action string  `json:"action"`
 	Server *Server `json:"server"`
+	// Cursor of the event in the journal, it is not part of the notification.
+	Cursor subscription.Cursor `json:"-"`
+}
+
+// DecodeChangedEvent decodes an event of the server channels, it reports false for the events of other channels.
+func DecodeChangedEvent(event *subscription.Event) (*ChangedEvent, bool, error) {
+	if !subscription.MatchChannel(path.Join(subscriptionPath, "*"), event.Channel) {
+		return nil, false, nil
+	}
+
+	var changedEvent *ChangedEvent
+	if err := json.Unmarshal(event.Bytes, &changedEvent); err != nil {
+		return nil, false, fmt.Errorf("failed to decode server changed event: %w", err)
+	}
+	changedEvent.Cursor = event.Cursor()
+	return changedEvent, true, nil
 }
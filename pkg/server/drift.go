@@ -0,0 +1,30 @@
+package server
+
+import (
+	"slices"
+	"time"
+)
+
+// Drift is a difference between the stored configuration of a server and the actual state of its device.
+type Drift struct {
+	DetectedAt  time.Time
+	Corrected   bool
+	Differences []*DriftDifference
+}
+
+type DriftDifference struct {
+	Field    string
+	Expected string
+	Actual   string
+}
+
+// Same reports whether both drifts describe the same differences, ignoring when they were detected.
+func (d *Drift) Same(other *Drift) bool {
+	if d == nil || other == nil {
+		return d == other
+	}
+
+	return d.Corrected == other.Corrected && slices.EqualFunc(d.Differences, other.Differences, func(a *DriftDifference, b *DriftDifference) bool {
+		return *a == *b
+	})
+}
@@ -0,0 +1,18 @@
+package server
+
+type DriftMode string
+
+const (
+	// DriftModeAlert only records the detected drift, it is the default for servers without a mode.
+	DriftModeAlert DriftMode = "ALERT"
+	// DriftModeAutoCorrect reapplies the stored configuration to the device when drift is detected.
+	DriftModeAutoCorrect DriftMode = "AUTO_CORRECT"
+)
+
+func (m DriftMode) Valid() bool {
+	switch m {
+	case "", DriftModeAlert, DriftModeAutoCorrect:
+		return true
+	}
+	return false
+}
@@ -12,6 +12,7 @@ var (
 	ErrOnlyOneOptionAllowed          = errors.New("only one option is allowed")
 	ErrServerNotFound                = errors.New("server not found")
 	ErrServerIdAlreadyExists         = errors.New("server id already exists")
+	ErrServerAlreadyOnBackend        = errors.New("server already uses this backend")
 	ErrServerNameAlreadyInUse        = errors.New("name is already in use")
 	ErrInvalidMtu                    = errors.New("invalid MTU must be between 1280 and 1500")
 	ErrCreateServerOptionsRequired   = errors.New("create server options are required")
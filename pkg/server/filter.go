@@ -0,0 +1,36 @@
+package server
+
+import (
+	"slices"
+
+	"github.com/UnAfraid/wg-ui/pkg/internal/tag"
+)
+
+// Filter narrows down the servers selected by FindOptions, every set field must match.
+type Filter struct {
+	BackendIds []string
+	Enabled    *bool
+	Running    *bool
+	// Tags selects the servers having all the tags.
+	Tags []string
+}
+
+func (f *Filter) Matches(s *Server) bool {
+	if len(f.BackendIds) != 0 && !slices.Contains(f.BackendIds, s.BackendId) {
+		return false
+	}
+
+	if f.Enabled != nil && s.Enabled != *f.Enabled {
+		return false
+	}
+
+	if f.Running != nil && s.Running != *f.Running {
+		return false
+	}
+
+	if len(f.Tags) != 0 && !tag.ContainsAll(s.Tags, f.Tags) {
+		return false
+	}
+
+	return true
+}
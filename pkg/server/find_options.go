@@ -1,5 +1,9 @@
 package server
 
+import (
+	"github.com/UnAfraid/wg-ui/pkg/pagination"
+)
+
 type FindOptions struct {
 	Ids          []string
 	Query        string
@@ -7,4 +11,13 @@ type FindOptions struct {
 	Enabled      *bool
 	CreateUserId *string
 	UpdateUserId *string
+	Filter       *Filter
+	Pagination   *pagination.Options
+}
+
+func (options *FindOptions) Validate() error {
+	if options.Pagination != nil {
+		return options.Pagination.Validate(SortFieldName, SortFieldCreatedAt)
+	}
+	return nil
 }
@@ -0,0 +1,25 @@
+package server
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Firewall holds the NAT and forwarding settings the backend manages for the server.
+type Firewall struct {
+	// MasqueradeInterface is the egress interface traffic from the server networks is masqueraded on, empty disables NAT.
+	MasqueradeInterface string
+	IPForwarding        bool
+	// PeerIsolation drops traffic between peers of the server.
+	PeerIsolation bool
+}
+
+func (f *Firewall) validate() error {
+	if f.MasqueradeInterface == "" {
+		return nil
+	}
+	if len(f.MasqueradeInterface) > 15 || strings.ContainsAny(f.MasqueradeInterface, " /\t\n") {
+		return fmt.Errorf("invalid masquerade interface: %s", f.MasqueradeInterface)
+	}
+	return nil
+}
@@ -1,11 +1,23 @@
 package server
 
+import (
+	"time"
+
+	"github.com/UnAfraid/wg-ui/pkg/hook"
+)
+
 type Hook struct {
+	// Command is a raw shell command, it is ignored when a typed action is set.
 	Command       string
+	Action        *hook.Action
 	RunOnPreUp    bool
 	RunOnPostUp   bool
 	RunOnPreDown  bool
 	RunOnPostDown bool
+	// Timeout of a single attempt, the configured default is used when zero.
+	Timeout     time.Duration
+	MaxAttempts int
+	RetryDelay  time.Duration
 
 	// Legacy fields kept for backward compatibility with existing stored data.
 	RunOnCreate bool
@@ -28,3 +40,10 @@ func (h *Hook) shouldExecute(action HookAction) bool {
 	}
 	return false
 }
+
+func (h *Hook) retryPolicy() hook.RetryPolicy {
+	return hook.RetryPolicy{
+		MaxAttempts: h.MaxAttempts,
+		Delay:       h.RetryDelay,
+	}
+}
@@ -2,6 +2,8 @@ package server
 
 type NameOption struct {
 	Name string
+	// BackendId limits the match to the servers of the backend, interface names only have to be unique per host.
+	BackendId string
 }
 
 func (option *NameOption) Validate() error {
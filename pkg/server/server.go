@@ -1,46 +1,51 @@
 package server
 
 import (
-	"context"
-	"errors"
 	"fmt"
 	"math"
 	"net"
-	"os"
-	"os/exec"
 	"regexp"
+	"strconv"
 	"strings"
 	"time"
 
 	"golang.zx2c4.com/wireguard/wgctrl/wgtypes"
 
+	"github.com/UnAfraid/wg-ui/pkg/hook"
 	"github.com/UnAfraid/wg-ui/pkg/internal/adapt"
+	"github.com/UnAfraid/wg-ui/pkg/internal/tag"
 )
 
 var namePattern = regexp.MustCompile("[a-zA-Z0-9.-_]{1,16}")
 
 type Server struct {
-	Id           string
-	Name         string
-	Description  string
-	BackendId    string
-	Enabled      bool
-	Running      bool
-	PublicKey    string
-	PrivateKey   string
-	ListenPort   *int
-	FirewallMark *int
-	Address      string
-	DNS          []string
-	MTU          int
-	Stats        Stats
-	Hooks        []*Hook
-	CreateUserId string
-	UpdateUserId string
-	DeleteUserId string
-	CreatedAt    time.Time
-	UpdatedAt    time.Time
-	DeletedAt    *time.Time
+	Id             string
+	Name           string
+	Description    string
+	Tags           []string
+	BackendId      string
+	Enabled        bool
+	Running        bool
+	PublicKey      string
+	PrivateKey     string
+	ListenPort     *int
+	FirewallMark   *int
+	Address        string
+	DNS            []string
+	MTU            int
+	Stats          Stats
+	Hooks          []*Hook
+	DriftMode      DriftMode
+	StartupPolicy  StartupPolicy
+	ShutdownPolicy ShutdownPolicy
+	Drift          *Drift
+	Firewall       *Firewall
+	CreateUserId   string
+	UpdateUserId   string
+	DeleteUserId   string
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+	DeletedAt      *time.Time
 }
 
 func (s *Server) validate(fieldMask *UpdateFieldMask) error {
@@ -60,6 +65,12 @@ func (s *Server) validate(fieldMask *UpdateFieldMask) error {
 		}
 	}
 
+	if fieldMask == nil || fieldMask.Tags {
+		if err := tag.Validate(s.Tags); err != nil {
+			return err
+		}
+	}
+
 	if fieldMask == nil || fieldMask.PrivateKey {
 		if len(strings.TrimSpace(s.PrivateKey)) == 0 {
 			if _, err := wgtypes.GeneratePrivateKey(); err != nil {
@@ -101,14 +112,46 @@ func (s *Server) validate(fieldMask *UpdateFieldMask) error {
 		}
 	}
 
+	if fieldMask == nil || fieldMask.DriftMode {
+		if !s.DriftMode.Valid() {
+			return fmt.Errorf("invalid drift mode: %s", s.DriftMode)
+		}
+	}
+
+	if fieldMask == nil || fieldMask.StartupPolicy {
+		if !s.StartupPolicy.Valid() {
+			return fmt.Errorf("invalid startup policy: %s", s.StartupPolicy)
+		}
+	}
+
+	if fieldMask == nil || fieldMask.ShutdownPolicy {
+		if !s.ShutdownPolicy.Valid() {
+			return fmt.Errorf("invalid shutdown policy: %s", s.ShutdownPolicy)
+		}
+	}
+
+	if fieldMask == nil || fieldMask.Firewall {
+		if s.Firewall != nil {
+			if err := s.Firewall.validate(); err != nil {
+				return err
+			}
+		}
+	}
+
 	if fieldMask == nil || fieldMask.Hooks {
 		for i, hook := range s.Hooks {
-			command := strings.TrimSpace(hook.Command)
-			if command == "" {
-				return fmt.Errorf("invalid server hook #%d command: command is required", i+1)
-			}
-			if strings.Contains(command, "\n") {
-				return fmt.Errorf("invalid server hook #%d command: multiline commands are not supported", i+1)
+			if hook.Action != nil {
+				if err := hook.Action.Validate(); err != nil {
+					return fmt.Errorf("invalid server hook #%d action: %w", i+1, err)
+				}
+			} else {
+				command := strings.TrimSpace(hook.Command)
+				if command == "" {
+					return fmt.Errorf("invalid server hook #%d command: command is required", i+1)
+				}
+				if strings.Contains(command, "\n") {
+					return fmt.Errorf("invalid server hook #%d command: multiline commands are not supported", i+1)
+				}
 			}
 
 			if !(hook.RunOnPreUp ||
@@ -119,6 +162,9 @@ func (s *Server) validate(fieldMask *UpdateFieldMask) error {
 				hook.RunOnStop) {
 				return fmt.Errorf("invalid server hook #%d: no lifecycle events selected", i+1)
 			}
+			if hook.Timeout < 0 || hook.MaxAttempts < 0 || hook.RetryDelay < 0 {
+				return fmt.Errorf("invalid server hook #%d: timeout, attempts and retry delay must not be negative", i+1)
+			}
 		}
 	}
 
@@ -129,6 +175,10 @@ func (s *Server) update(options *UpdateOptions, fieldMask *UpdateFieldMask) erro
 		s.Description = options.Description
 	}
 
+	if fieldMask.Tags {
+		s.Tags = options.Tags
+	}
+
 	if fieldMask.BackendId {
 		s.BackendId = options.BackendId
 	}
@@ -178,6 +228,26 @@ func (s *Server) update(options *UpdateOptions, fieldMask *UpdateFieldMask) erro
 		s.Hooks = options.Hooks
 	}
 
+	if fieldMask.DriftMode {
+		s.DriftMode = options.DriftMode
+	}
+
+	if fieldMask.StartupPolicy {
+		s.StartupPolicy = options.StartupPolicy
+	}
+
+	if fieldMask.ShutdownPolicy {
+		s.ShutdownPolicy = options.ShutdownPolicy
+	}
+
+	if fieldMask.Firewall {
+		s.Firewall = options.Firewall
+	}
+
+	if fieldMask.Drift {
+		s.Drift = options.Drift
+	}
+
 	if fieldMask.CreateUserId {
 		s.CreateUserId = options.CreateUserId
 	}
@@ -189,35 +259,48 @@ func (s *Server) update(options *UpdateOptions, fieldMask *UpdateFieldMask) erro
 	return nil
 }
 
-func (s *Server) runHooks(ctx context.Context, action HookAction) error {
-	var errs []error
-	for i, hook := range s.Hooks {
-		if !hook.shouldExecute(action) {
+func (s *Server) hookRunOptions(action HookAction) []*hook.RunOptions {
+	var runOptions []*hook.RunOptions
+	for _, h := range s.Hooks {
+		if !h.shouldExecute(action) {
 			continue
 		}
 
-		command := interpolateHookCommand(hook.Command, s.Name)
-		cmd := exec.CommandContext(ctx, "sh", "-c", command)
-		cmd.Env = append(os.Environ(),
-			fmt.Sprintf("WG_SERVER_NAME=%s", s.Name),
-			fmt.Sprintf("WG_SERVER_DESCRIPTION=%s", strings.ReplaceAll(s.Description, "\n", "\\n")),
-			fmt.Sprintf("WG_SERVER_PUBLICKEY=%s", s.PublicKey),
-			fmt.Sprintf("WG_SERVER_LISTEN_PORT=%d", adapt.Dereference(s.ListenPort)),
-			fmt.Sprintf("WG_SERVER_FIREWALL_MARK=%d", adapt.Dereference(s.FirewallMark)),
-			fmt.Sprintf("WG_SERVER_ADDRESS=%s", s.Address),
-			fmt.Sprintf("WG_SERVER_DNS=%s", strings.Join(s.DNS, ",")),
-			fmt.Sprintf("WG_SERVER_MTU=%d", s.MTU),
-			fmt.Sprintf("WG_SERVER_HOOK_ACTION=%s", string(action)),
-		)
-		if err := cmd.Run(); err != nil {
-			errs = append(errs, fmt.Errorf("failed to execute hook #%d %q - %w", i+1, hook.Command, err))
-		}
-	}
-	return errors.Join(errs...)
-}
-
-func (s *Server) RunHooks(ctx context.Context, action HookAction) error {
-	return s.runHooks(ctx, action)
+		runOptions = append(runOptions, &hook.RunOptions{
+			ServerId:    s.Id,
+			Action:      string(action),
+			Command:     h.Command,
+			Path:        "sh",
+			Args:        []string{"-c", interpolateHookCommand(h.Command, s.Name)},
+			TypedAction: h.Action,
+			Variables: map[string]string{
+				"Interface":    s.Name,
+				"Description":  s.Description,
+				"PublicKey":    s.PublicKey,
+				"ListenPort":   strconv.Itoa(adapt.Dereference(s.ListenPort)),
+				"FirewallMark": strconv.Itoa(adapt.Dereference(s.FirewallMark)),
+				"Address":      s.Address,
+				"DNS":          strings.Join(s.DNS, ","),
+				"MTU":          strconv.Itoa(s.MTU),
+				"Action":       string(action),
+			},
+			Env: []string{
+				fmt.Sprintf("WG_SERVER_NAME=%s", s.Name),
+				fmt.Sprintf("WG_SERVER_DESCRIPTION=%s", strings.ReplaceAll(s.Description, "\n", "\\n")),
+				fmt.Sprintf("WG_SERVER_PUBLICKEY=%s", s.PublicKey),
+				fmt.Sprintf("WG_SERVER_LISTEN_PORT=%d", adapt.Dereference(s.ListenPort)),
+				fmt.Sprintf("WG_SERVER_FIREWALL_MARK=%d", adapt.Dereference(s.FirewallMark)),
+				fmt.Sprintf("WG_SERVER_ADDRESS=%s", s.Address),
+				fmt.Sprintf("WG_SERVER_DNS=%s", strings.Join(s.DNS, ",")),
+				fmt.Sprintf("WG_SERVER_MTU=%d", s.MTU),
+				fmt.Sprintf("WG_SERVER_HOOK_ACTION=%s", string(action)),
+			},
+			InheritEnvironment: true,
+			Timeout:            h.Timeout,
+			Retry:              h.retryPolicy(),
+		})
+	}
+	return runOptions
 }
 
 func interpolateHookCommand(command string, interfaceName string) string {
@@ -14,6 +14,7 @@ import (
 	"golang.zx2c4.com/wireguard/wgctrl/wgtypes"
 
 	"github.com/UnAfraid/wg-ui/pkg/dbx"
+	"github.com/UnAfraid/wg-ui/pkg/hook"
 	"github.com/UnAfraid/wg-ui/pkg/subscription"
 )
 
@@ -27,20 +28,26 @@ type Service interface {
 	CreateServer(ctx context.Context, options *CreateOptions, userId string) (*Server, error)
 	UpdateServer(ctx context.Context, serverId string, options *UpdateOptions, fieldMask *UpdateFieldMask, userId string) (*Server, error)
 	DeleteServer(ctx context.Context, serverId string, userId string) (*Server, error)
-	Subscribe(ctx context.Context) (<-chan *ChangedEvent, error)
+	PreviewCreateServer(ctx context.Context, options *CreateOptions, userId string) (*Server, error)
+	PreviewUpdateServer(ctx context.Context, serverId string, options *UpdateOptions, fieldMask *UpdateFieldMask, userId string) (*Server, error)
+	RunHooks(ctx context.Context, server *Server, action HookAction) error
+	// Subscribe delivers the changed events, when since is set the journaled events following it are delivered first.
+	Subscribe(ctx context.Context, since *subscription.Cursor) (<-chan *ChangedEvent, error)
 	HasSubscribers() bool
 }
 
 type service struct {
 	serverRepository  Repository
 	transactionScoper dbx.TransactionScoper
+	hookService       hook.Service
 	subscription      subscription.Subscription
 }
 
-func NewService(serverRepository Repository, transactionScoper dbx.TransactionScoper, subscription subscription.Subscription) Service {
+func NewService(serverRepository Repository, transactionScoper dbx.TransactionScoper, hookService hook.Service, subscription subscription.Subscription) Service {
 	return &service{
 		serverRepository:  serverRepository,
 		transactionScoper: transactionScoper,
+		hookService:       hookService,
 		subscription:      subscription,
 	}
 }
@@ -53,23 +60,18 @@ func (s *service) FindServer(ctx context.Context, options *FindOneOptions) (*Ser
 }
 
 func (s *service) FindServers(ctx context.Context, options *FindOptions) ([]*Server, error) {
+	if err := options.Validate(); err != nil {
+		return nil, err
+	}
 	return s.serverRepository.FindAll(ctx, options)
 }
 
 func (s *service) CreateServer(ctx context.Context, options *CreateOptions, userId string) (*Server, error) {
-	server, err := processCreateServer(options, userId)
+	server, err := s.prepareCreateServer(ctx, options, userId)
 	if err != nil {
 		return nil, err
 	}
 
-	if err := server.validate(nil); err != nil {
-		return nil, err
-	}
-
-	if err := s.validateServerName(ctx, options.Name); err != nil {
-		return nil, err
-	}
-
 	return dbx.InTransactionScopeWithResult(ctx, s.transactionScoper, func(ctx context.Context) (*Server, error) {
 		createdServer, err := s.serverRepository.Create(ctx, server)
 		if err != nil {
@@ -86,19 +88,11 @@ func (s *service) CreateServer(ctx context.Context, options *CreateOptions, user
 
 func (s *service) UpdateServer(ctx context.Context, serverId string, options *UpdateOptions, fieldMask *UpdateFieldMask, userId string) (*Server, error) {
 	return dbx.InTransactionScopeWithResult(ctx, s.transactionScoper, func(ctx context.Context) (*Server, error) {
-		server, err := s.findServerById(ctx, serverId)
+		server, err := s.prepareUpdateServer(ctx, serverId, options, fieldMask, userId)
 		if err != nil {
 			return nil, err
 		}
 
-		if err := processUpdateServer(server, options, fieldMask, userId); err != nil {
-			return nil, err
-		}
-
-		if err := server.validate(fieldMask); err != nil {
-			return nil, err
-		}
-
 		updatedServer, err := s.serverRepository.Update(ctx, server, fieldMask)
 		if err != nil {
 			return nil, err
@@ -113,6 +107,8 @@ func (s *service) UpdateServer(ctx context.Context, serverId string, options *Up
 			}
 		} else if fieldMask.Stats {
 			action = ChangedActionInterfaceStatsUpdated
+		} else if fieldMask.Drift && updatedServer.Drift != nil {
+			action = ChangedActionDriftDetected
 		}
 
 		if err = s.notify(action, updatedServer); err != nil {
@@ -135,6 +131,10 @@ func (s *service) DeleteServer(ctx context.Context, serverId string, userId stri
 			return nil, err
 		}
 
+		if err := s.hookService.DeleteExecutions(ctx, &hook.FindOptions{ServerId: &server.Id}); err != nil {
+			return nil, fmt.Errorf("failed to delete hook executions: %w", err)
+		}
+
 		if err = s.notify(ChangedActionDeleted, deletedServer); err != nil {
 			logrus.WithError(err).Warn("failed to notify server deleted event")
 		}
@@ -143,6 +143,88 @@ func (s *service) DeleteServer(ctx context.Context, serverId string, userId stri
 	})
 }
 
+// PreviewCreateServer validates the create options and returns the server that would be created, without persisting it.
+func (s *service) PreviewCreateServer(ctx context.Context, options *CreateOptions, userId string) (*Server, error) {
+	return s.prepareCreateServer(ctx, options, userId)
+}
+
+// PreviewUpdateServer validates the update options and returns the server as it would be after the update, without persisting it.
+func (s *service) PreviewUpdateServer(ctx context.Context, serverId string, options *UpdateOptions, fieldMask *UpdateFieldMask, userId string) (*Server, error) {
+	return s.prepareUpdateServer(ctx, serverId, options, fieldMask, userId)
+}
+
+func (s *service) prepareCreateServer(ctx context.Context, options *CreateOptions, userId string) (*Server, error) {
+	server, err := processCreateServer(options, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := server.validate(nil); err != nil {
+		return nil, err
+	}
+
+	if err := s.checkHooks(server.Hooks); err != nil {
+		return nil, err
+	}
+
+	if err := s.validateServerName(ctx, options.Name, options.BackendId); err != nil {
+		return nil, err
+	}
+
+	return server, nil
+}
+
+func (s *service) prepareUpdateServer(ctx context.Context, serverId string, options *UpdateOptions, fieldMask *UpdateFieldMask, userId string) (*Server, error) {
+	server, err := s.findServerById(ctx, serverId)
+	if err != nil {
+		return nil, err
+	}
+
+	originalBackendId := server.BackendId
+	if err := processUpdateServer(server, options, fieldMask, userId); err != nil {
+		return nil, err
+	}
+
+	if fieldMask.BackendId && server.BackendId != originalBackendId {
+		if err := s.validateServerName(ctx, server.Name, server.BackendId); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := server.validate(fieldMask); err != nil {
+		return nil, err
+	}
+
+	if fieldMask.Hooks {
+		if err := s.checkHooks(server.Hooks); err != nil {
+			return nil, err
+		}
+	}
+
+	return server, nil
+}
+
+// checkHooks rejects hooks that are not allowed by the hook policy, such as raw commands when they are disabled.
+func (s *service) checkHooks(hooks []*Hook) error {
+	for i, h := range hooks {
+		if err := s.hookService.CheckHook(h.Action); err != nil {
+			return fmt.Errorf("invalid server hook #%d: %w", i+1, err)
+		}
+	}
+	return nil
+}
+
+// RunHooks queues the server hooks registered for the action, they run and are recorded in the background.
+func (s *service) RunHooks(ctx context.Context, server *Server, action HookAction) error {
+	var errs []error
+	for _, runOptions := range server.hookRunOptions(action) {
+		if err := s.hookService.Run(ctx, runOptions); err != nil {
+			errs = append(errs, fmt.Errorf("failed to queue hook %q - %w", runOptions.Command, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
 func (s *service) findServerById(ctx context.Context, serverId string) (*Server, error) {
 	server, err := s.serverRepository.FindOne(ctx, &FindOneOptions{
 		IdOption: &IdOption{
@@ -158,10 +240,13 @@ func (s *service) findServerById(ctx context.Context, serverId string) (*Server,
 	return server, nil
 }
 
-func (s *service) validateServerName(ctx context.Context, name string) error {
+// validateServerName checks that the name is not used by another server of the backend,
+// an empty backendId checks the servers of all backends.
+func (s *service) validateServerName(ctx context.Context, name string, backendId string) error {
 	existingServer, err := s.serverRepository.FindOne(ctx, &FindOneOptions{
 		NameOption: &NameOption{
-			Name: name,
+			Name:      name,
+			BackendId: backendId,
 		},
 	})
 	if err != nil {
@@ -214,24 +299,29 @@ func processCreateServer(options *CreateOptions, userId string) (*Server, error)
 	now := time.Now()
 
 	return &Server{
-		Id:           id,
-		Name:         options.Name,
-		Description:  options.Description,
-		BackendId:    options.BackendId,
-		Enabled:      options.Enabled,
-		Running:      options.Running,
-		PublicKey:    publicKey,
-		PrivateKey:   options.PrivateKey,
-		ListenPort:   options.ListenPort,
-		FirewallMark: options.FirewallMark,
-		Address:      options.Address,
-		DNS:          options.DNS,
-		MTU:          options.MTU,
-		Hooks:        options.Hooks,
-		CreateUserId: userId,
-		CreatedAt:    now,
-		UpdatedAt:    now,
-		DeletedAt:    nil,
+		Id:             id,
+		Name:           options.Name,
+		Description:    options.Description,
+		Tags:           options.Tags,
+		BackendId:      options.BackendId,
+		Enabled:        options.Enabled,
+		Running:        options.Running,
+		PublicKey:      publicKey,
+		PrivateKey:     options.PrivateKey,
+		ListenPort:     options.ListenPort,
+		FirewallMark:   options.FirewallMark,
+		Address:        options.Address,
+		DNS:            options.DNS,
+		MTU:            options.MTU,
+		Hooks:          options.Hooks,
+		DriftMode:      options.DriftMode,
+		StartupPolicy:  options.StartupPolicy,
+		ShutdownPolicy: options.ShutdownPolicy,
+		Firewall:       options.Firewall,
+		CreateUserId:   userId,
+		CreatedAt:      now,
+		UpdatedAt:      now,
+		DeletedAt:      nil,
 	}, nil
 }
 
@@ -274,14 +364,20 @@ func (s *service) notify(action string, server *Server) error {
 		return err
 	}
 
-	if err := s.subscription.Notify(bytes, path.Join(subscriptionPath, server.Id)); err != nil {
+	notify := s.subscription.Notify
+	if action == ChangedActionInterfaceStatsUpdated {
+		// the stats change on every poll, subscribers resuming after a cursor read the current ones instead
+		notify = s.subscription.NotifyEphemeral
+	}
+
+	if err := notify(bytes, path.Join(subscriptionPath, server.Id)); err != nil {
 		return fmt.Errorf("failed to notify server changed event: %w", err)
 	}
 	return nil
 }
 
-func (s *service) Subscribe(ctx context.Context) (<-chan *ChangedEvent, error) {
-	bytesChannel, err := s.subscription.Subscribe(ctx, path.Join(subscriptionPath, "*"))
+func (s *service) Subscribe(ctx context.Context, since *subscription.Cursor) (<-chan *ChangedEvent, error) {
+	events, err := s.subscription.Subscribe(ctx, path.Join(subscriptionPath, "*"), since)
 	if err != nil {
 		return nil, err
 	}
@@ -290,10 +386,10 @@ func (s *service) Subscribe(ctx context.Context) (<-chan *ChangedEvent, error) {
 	go func() {
 		defer close(observerChan)
 
-		for bytes := range bytesChannel {
-			var changedEvent *ChangedEvent
-			if err := json.Unmarshal(bytes, &changedEvent); err != nil {
-				logrus.WithError(err).Warn("failed to decode server changed event")
+		for event := range events {
+			changedEvent, _, err := DecodeChangedEvent(event)
+			if err != nil {
+				logrus.WithError(err).Warn("failed to decode changed event")
 				return
 			}
 			observerChan <- changedEvent
@@ -0,0 +1,20 @@
+package server
+
+type ShutdownPolicy string
+
+const (
+	// ShutdownPolicyLeaveRunning leaves the device as it is, it is the default for servers without a policy.
+	ShutdownPolicyLeaveRunning ShutdownPolicy = "LEAVE_RUNNING"
+	// ShutdownPolicyStop runs the down hooks and brings the device down.
+	ShutdownPolicyStop ShutdownPolicy = "STOP"
+	// ShutdownPolicyPreDownHooks only runs the pre down hooks, the device is left running.
+	ShutdownPolicyPreDownHooks ShutdownPolicy = "PRE_DOWN_HOOKS"
+)
+
+func (p ShutdownPolicy) Valid() bool {
+	switch p {
+	case "", ShutdownPolicyLeaveRunning, ShutdownPolicyStop, ShutdownPolicyPreDownHooks:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,26 @@
+package server
+
+import (
+	"github.com/UnAfraid/wg-ui/pkg/pagination"
+)
+
+const (
+	SortFieldName      = "NAME"
+	SortFieldCreatedAt = "CREATED_AT"
+)
+
+func (s *Server) Cursor(sortField string) pagination.Cursor {
+	var key string
+	switch sortField {
+	case SortFieldCreatedAt:
+		key = pagination.TimeKey(s.CreatedAt)
+	default:
+		key = pagination.StringKey(s.Name)
+	}
+
+	return pagination.Cursor{
+		Field: sortField,
+		Key:   key,
+		Id:    s.Id,
+	}
+}
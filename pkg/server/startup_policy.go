@@ -0,0 +1,36 @@
+package server
+
+type StartupPolicy string
+
+const (
+	// StartupPolicyStartIfEnabled starts the server when it is enabled, it is the default for servers without a policy.
+	StartupPolicyStartIfEnabled StartupPolicy = "START_IF_ENABLED"
+	// StartupPolicyAlways starts the server, enabling it when it is disabled.
+	StartupPolicyAlways StartupPolicy = "ALWAYS"
+	// StartupPolicyNever leaves the server stopped until it is started manually.
+	StartupPolicyNever StartupPolicy = "NEVER"
+	// StartupPolicyRestore starts the server only when it was running before the service stopped.
+	StartupPolicyRestore StartupPolicy = "RESTORE"
+)
+
+func (p StartupPolicy) Valid() bool {
+	switch p {
+	case "", StartupPolicyStartIfEnabled, StartupPolicyAlways, StartupPolicyNever, StartupPolicyRestore:
+		return true
+	}
+	return false
+}
+
+// ShouldStart reports whether the server is started when the service starts.
+func (s *Server) ShouldStart() bool {
+	switch s.StartupPolicy {
+	case StartupPolicyAlways:
+		return true
+	case StartupPolicyNever:
+		return false
+	case StartupPolicyRestore:
+		return s.Enabled && s.Running
+	default:
+		return s.Enabled
+	}
+}
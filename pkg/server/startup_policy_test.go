@@ -0,0 +1,28 @@
+package server
+
+import "testing"
+
+func TestServerShouldStart(t *testing.T) {
+	tests := []struct {
+		policy   StartupPolicy
+		enabled  bool
+		running  bool
+		expected bool
+	}{
+		{policy: "", enabled: true, running: false, expected: true},
+		{policy: "", enabled: false, running: false, expected: false},
+		{policy: StartupPolicyStartIfEnabled, enabled: true, running: false, expected: true},
+		{policy: StartupPolicyAlways, enabled: false, running: false, expected: true},
+		{policy: StartupPolicyNever, enabled: true, running: true, expected: false},
+		{policy: StartupPolicyRestore, enabled: true, running: true, expected: true},
+		{policy: StartupPolicyRestore, enabled: true, running: false, expected: false},
+		{policy: StartupPolicyRestore, enabled: false, running: true, expected: false},
+	}
+
+	for _, tt := range tests {
+		srv := &Server{StartupPolicy: tt.policy, Enabled: tt.enabled, Running: tt.running}
+		if actual := srv.ShouldStart(); actual != tt.expected {
+			t.Errorf("expected policy %q with enabled %t and running %t to start %t, got %t", tt.policy, tt.enabled, tt.running, tt.expected, actual)
+		}
+	}
+}
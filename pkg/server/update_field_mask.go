@@ -1,18 +1,24 @@
 package server
 
 type UpdateFieldMask struct {
-	Description  bool
-	BackendId    bool
-	Enabled      bool
-	Running      bool
-	PrivateKey   bool
-	ListenPort   bool
-	FirewallMark bool
-	Address      bool
-	DNS          bool
-	MTU          bool
-	Stats        bool
-	Hooks        bool
-	CreateUserId bool
-	UpdateUserId bool
+	Description    bool
+	Tags           bool
+	BackendId      bool
+	Enabled        bool
+	Running        bool
+	PrivateKey     bool
+	ListenPort     bool
+	FirewallMark   bool
+	Address        bool
+	DNS            bool
+	MTU            bool
+	Stats          bool
+	Hooks          bool
+	DriftMode      bool
+	StartupPolicy  bool
+	ShutdownPolicy bool
+	Drift          bool
+	Firewall       bool
+	CreateUserId   bool
+	UpdateUserId   bool
 }
@@ -1,18 +1,24 @@
 package server
 
 type UpdateOptions struct {
-	Description  string
-	BackendId    string
-	Enabled      bool
-	Running      bool
-	PrivateKey   string
-	ListenPort   *int
-	FirewallMark *int
-	Address      string
-	DNS          []string
-	MTU          int
-	Stats        Stats
-	Hooks        []*Hook
-	CreateUserId string
-	UpdateUserId string
+	Description    string
+	Tags           []string
+	BackendId      string
+	Enabled        bool
+	Running        bool
+	PrivateKey     string
+	ListenPort     *int
+	FirewallMark   *int
+	Address        string
+	DNS            []string
+	MTU            int
+	Stats          Stats
+	Hooks          []*Hook
+	DriftMode      DriftMode
+	StartupPolicy  StartupPolicy
+	ShutdownPolicy ShutdownPolicy
+	Drift          *Drift
+	Firewall       *Firewall
+	CreateUserId   string
+	UpdateUserId   string
 }
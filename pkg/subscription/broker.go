@@ -0,0 +1,274 @@
+package subscription
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"sync"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+type subscriber struct {
+	key    channelKey
+	queue  chan *Event
+	lock   sync.Mutex
+	closed bool
+	// replaying is set until the journaled events are delivered, a live event dropped meanwhile would leave a gap
+	// the subscriber cannot notice, so it is disconnected instead whatever the overflow policy and resumes with
+	// the cursor of the last event it received.
+	replaying bool
+}
+
+// offer queues the event without blocking, it reports false when the queue is full.
+func (s *subscriber) offer(event *Event) bool {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.closed {
+		return true
+	}
+
+	select {
+	case s.queue <- event:
+		return true
+	default:
+		return false
+	}
+}
+
+func (s *subscriber) isReplaying() bool {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	return s.replaying
+}
+
+func (s *subscriber) replayed() {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.replaying = false
+}
+
+func (s *subscriber) close() {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if !s.closed {
+		s.closed = true
+		close(s.queue)
+	}
+}
+
+type broker struct {
+	options     Options
+	transport   Transport
+	journal     *journal
+	subscribers map[channelKey]*subscriber
+	// lock guards the subscribers and the journal, events are journaled and queued in sequence order
+	lock sync.Mutex
+}
+
+// NewSubscription delivers notifications through a buffered queue per subscriber, so a slow subscriber never blocks
+// the publishers. Notifications are also exchanged with other instances through the transport, a nil transport
+// keeps them within this instance. Delivered events are journaled, in the repository too when one is set,
+// so subscriptions can resume after the cursor of the last event they received.
+func NewSubscription(options Options, transport Transport, repository JournalRepository) (Subscription, error) {
+	if err := options.Validate(); err != nil {
+		return nil, err
+	}
+
+	j, err := newJournal(repository, options.journalSize(), options.persistedJournalSize())
+	if err != nil {
+		return nil, err
+	}
+
+	s := &broker{
+		options:     options,
+		transport:   transport,
+		journal:     j,
+		subscribers: make(map[channelKey]*subscriber),
+	}
+
+	if transport != nil {
+		if err := transport.Subscribe(s.dispatch); err != nil {
+			j.close()
+			return nil, fmt.Errorf("failed to subscribe to transport: %w", err)
+		}
+	}
+	return s, nil
+}
+
+func (s *broker) Notify(bytes []byte, channel string) error {
+	return s.notify(bytes, channel, false)
+}
+
+func (s *broker) NotifyEphemeral(bytes []byte, channel string) error {
+	return s.notify(bytes, channel, true)
+}
+
+func (s *broker) notify(bytes []byte, channel string, ephemeral bool) error {
+	channel = joinPath(channel)
+	s.dispatch(channel, bytes, ephemeral)
+
+	if s.transport != nil {
+		if err := s.transport.Publish(channel, bytes, ephemeral); err != nil {
+			return fmt.Errorf("failed to publish notification: %w", err)
+		}
+	}
+	return nil
+}
+
+func (s *broker) Subscribe(ctx context.Context, channel string, since *Cursor) (<-chan *Event, error) {
+	uuidValue, err := uuid.NewRandom()
+	if err != nil {
+		return nil, err
+	}
+
+	sub := &subscriber{
+		key:       newChannelKey(uuidValue.String(), joinPath(channel)),
+		queue:     make(chan *Event, s.options.queueSize()),
+		replaying: since != nil,
+	}
+
+	// the subscriber queues the events following the snapshot, so the journal is read without holding the lock
+	s.lock.Lock()
+	var snapshot journalSnapshot
+	if since != nil {
+		snapshot = s.journal.snapshot()
+	}
+	s.subscribers[sub.key] = sub
+	s.lock.Unlock()
+
+	var replay []*Event
+	if since != nil {
+		replay, err = s.journal.since(ctx, snapshot, *since, sub.key.channel)
+		if err != nil {
+			s.unsubscribe(sub)
+			return nil, err
+		}
+	}
+
+	go func() {
+		<-ctx.Done()
+		s.unsubscribe(sub)
+	}()
+
+	if len(replay) == 0 {
+		sub.replayed()
+		return sub.queue, nil
+	}
+
+	events := make(chan *Event)
+	go func() {
+		defer close(events)
+
+		for _, event := range replay {
+			select {
+			case events <- event:
+			case <-ctx.Done():
+				return
+			}
+		}
+		sub.replayed()
+
+		for event := range sub.queue {
+			if !event.Ephemeral && event.Sequence <= snapshot.sequence {
+				continue
+			}
+
+			select {
+			case events <- event:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return events, nil
+}
+
+func (s *broker) HasSubscribers(channel string) bool {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if len(channel) == 0 {
+		return len(s.subscribers) != 0
+	}
+	channel = joinPath(channel)
+
+	for k := range s.subscribers {
+		if matchChannel(k.channel, channel) {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *broker) Close() error {
+	var err error
+	if s.transport != nil {
+		err = s.transport.Close()
+	}
+	s.journal.close()
+	return err
+}
+
+func (s *broker) dispatch(channel string, bytes []byte, ephemeral bool) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	var event *Event
+	if ephemeral {
+		event = s.journal.ephemeral(channel, bytes)
+	} else {
+		event = s.journal.append(channel, bytes)
+	}
+	for k, sub := range s.subscribers {
+		if !matchChannel(k.channel, channel) || sub.offer(event) {
+			continue
+		}
+
+		if s.options.OverflowPolicy == OverflowPolicyDisconnect || sub.isReplaying() {
+			logrus.
+				WithField("channel", channel).
+				WithField("subscription", sub.key.channel).
+				WithField("replaying", sub.isReplaying()).
+				Warn("subscriber queue is full, disconnecting subscriber")
+			delete(s.subscribers, k)
+			sub.close()
+			continue
+		}
+
+		logrus.
+			WithField("channel", channel).
+			WithField("subscription", sub.key.channel).
+			Debug("subscriber queue is full, dropping notification")
+	}
+}
+
+func (s *broker) unsubscribe(sub *subscriber) {
+	s.lock.Lock()
+	delete(s.subscribers, sub.key)
+	s.lock.Unlock()
+
+	sub.close()
+}
+
+// MatchChannel reports whether the channel of an event matches the pattern given to Subscribe.
+func MatchChannel(pattern string, channel string) bool {
+	return matchChannel(joinPath(pattern), channel)
+}
+
+func matchChannel(pattern string, channel string) bool {
+	match, err := filepath.Match(pattern, channel)
+	if err != nil {
+		logrus.
+			WithError(err).
+			WithField("a", pattern).
+			WithField("b", channel).
+			Warn("failed to match glob pattern")
+	}
+	return match
+}
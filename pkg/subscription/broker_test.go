@@ -0,0 +1,162 @@
+package subscription
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func newTestSubscription(t *testing.T, options Options, transport Transport) Subscription {
+	t.Helper()
+
+	s, err := NewSubscription(options, transport, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		_ = s.Close()
+	})
+	return s
+}
+
+func receive(t *testing.T, ch <-chan *Event) *Event {
+	t.Helper()
+
+	select {
+	case event := <-ch:
+		return event
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for notification")
+		return nil
+	}
+}
+
+func TestNotifyDoesNotBlockOnSlowSubscriber(t *testing.T) {
+	s := newTestSubscription(t, Options{QueueSize: 2, OverflowPolicy: OverflowPolicyDrop}, nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	slow, err := s.Subscribe(ctx, "server/*", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fast, err := s.Subscribe(ctx, "server/*", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := range 10 {
+			if err := s.Notify([]byte{byte(i)}, "server/id"); err != nil {
+				t.Error(err)
+			}
+			<-fast
+		}
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("notify blocked on the slow subscriber")
+	}
+
+	if first, second := receive(t, slow), receive(t, slow); first.Bytes[0] != 0 || second.Bytes[0] != 1 {
+		t.Fatalf("expected the queued notifications to be kept, got %v and %v", first, second)
+	}
+	select {
+	case event := <-slow:
+		t.Fatalf("expected the notifications over the queue size to be dropped, got %v", event.Bytes)
+	default:
+	}
+}
+
+func TestNotifyDisconnectsSlowSubscriber(t *testing.T) {
+	s := newTestSubscription(t, Options{QueueSize: 1, OverflowPolicy: OverflowPolicyDisconnect}, nil)
+
+	slow, err := s.Subscribe(context.Background(), "peer/*", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for range 2 {
+		if err := s.Notify([]byte("event"), "peer/id"); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	receive(t, slow)
+	if _, ok := <-slow; ok {
+		t.Fatal("expected the subscription to be closed")
+	}
+	if s.HasSubscribers("peer/*") {
+		t.Fatal("expected the disconnected subscriber to be removed")
+	}
+}
+
+func TestNotifyDisconnectsSubscriberOverflowingDuringReplay(t *testing.T) {
+	s := newTestSubscription(t, Options{QueueSize: 1, OverflowPolicy: OverflowPolicyDrop}, nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	live, err := s.Subscribe(ctx, "peer/*", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	notifyAll(t, s, "peer/id", "1", "2")
+	cursor := receive(t, live).Cursor()
+
+	resumed, err := s.Subscribe(ctx, "peer/*", &cursor)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the replayed event is not received yet, the second live event does not fit in the queue
+	notifyAll(t, s, "peer/id", "3", "4")
+
+	for _, expected := range []string{"2", "3"} {
+		if event := receive(t, resumed); string(event.Bytes) != expected {
+			t.Fatalf("expected %q, got %q", expected, event.Bytes)
+		}
+	}
+	if event := receive(t, resumed); event != nil {
+		t.Fatalf("expected the subscription to be closed instead of dropping events, got %q", event.Bytes)
+	}
+}
+
+func TestSubscribeEndsWithContext(t *testing.T) {
+	s := newTestSubscription(t, Options{}, nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	ch, err := s.Subscribe(ctx, "user/*", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !s.HasSubscribers("user/*") {
+		t.Fatal("expected a subscriber")
+	}
+
+	cancel()
+	select {
+	case _, ok := <-ch:
+		if ok {
+			t.Fatal("expected no notification")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected the subscription to be closed")
+	}
+	if s.HasSubscribers("user/*") {
+		t.Fatal("expected the subscriber to be removed")
+	}
+}
+
+func TestNewSubscriptionRejectsInvalidOptions(t *testing.T) {
+	if _, err := NewSubscription(Options{OverflowPolicy: "block"}, nil, nil); err == nil {
+		t.Fatal("expected an invalid overflow policy to be rejected")
+	}
+	if _, err := NewSubscription(Options{QueueSize: -1}, nil, nil); err == nil {
+		t.Fatal("expected a negative queue size to be rejected")
+	}
+}
@@ -0,0 +1,42 @@
+package subscription
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Cursor is the position of an event in the journal. Every journal picks a new epoch when it starts, it tells apart
+// the sequences handed out by other instances and by earlier runs, which may overlap with the ones of this journal.
+type Cursor struct {
+	Epoch    string
+	Sequence uint64
+}
+
+// EncodeCursor returns the opaque value of the cursor, subscriptions resume after it.
+func EncodeCursor(cursor Cursor) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(cursor.Epoch + ":" + strconv.FormatUint(cursor.Sequence, 10)))
+}
+
+func DecodeCursor(value string) (Cursor, error) {
+	bytes, err := base64.RawURLEncoding.DecodeString(strings.TrimSpace(value))
+	if err != nil {
+		return Cursor{}, fmt.Errorf("%w: %w", ErrInvalidCursor, err)
+	}
+
+	epoch, rawSequence, found := strings.Cut(string(bytes), ":")
+	if !found {
+		// cursors handed out before the epochs were added hold the sequence only
+		epoch, rawSequence = "", epoch
+	}
+
+	sequence, err := strconv.ParseUint(rawSequence, 10, 64)
+	if err != nil {
+		return Cursor{}, fmt.Errorf("%w: %w", ErrInvalidCursor, err)
+	}
+	return Cursor{
+		Epoch:    epoch,
+		Sequence: sequence,
+	}, nil
+}
@@ -0,0 +1,10 @@
+package subscription
+
+import (
+	"errors"
+)
+
+var (
+	ErrInvalidCursor = errors.New("invalid event cursor")
+	ErrCursorExpired = errors.New("event cursor is older than the event journal")
+)
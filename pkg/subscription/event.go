@@ -0,0 +1,25 @@
+package subscription
+
+import (
+	"time"
+)
+
+// Event is a notification delivered to the subscribers, the sequence increases with every notification
+// delivered by this instance and the epoch identifies the journal that handed it out.
+// Ephemeral events are not journaled, they carry the sequence of the last journaled event.
+type Event struct {
+	Epoch     string    `json:"epoch,omitempty"`
+	Sequence  uint64    `json:"sequence"`
+	Channel   string    `json:"channel"`
+	Bytes     []byte    `json:"bytes"`
+	CreatedAt time.Time `json:"createdAt"`
+	Ephemeral bool      `json:"-"`
+}
+
+// Cursor returns the position of the event, subscriptions resuming after it receive the events that follow it.
+func (e *Event) Cursor() Cursor {
+	return Cursor{
+		Epoch:    e.Epoch,
+		Sequence: e.Sequence,
+	}
+}
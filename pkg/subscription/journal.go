@@ -0,0 +1,230 @@
+package subscription
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+// JournalRepository persists the journal, so events can be replayed after a restart.
+type JournalRepository interface {
+	// Append stores the events and removes the oldest ones above the limit.
+	Append(ctx context.Context, events []*Event, limit int) error
+	// FindAfter returns up to limit events following the sequence, oldest first.
+	FindAfter(ctx context.Context, sequence uint64, limit int) ([]*Event, error)
+	// FindLast returns up to limit of the newest events, oldest first.
+	FindLast(ctx context.Context, limit int) ([]*Event, error)
+}
+
+// journal keeps the latest events in memory and writes them to the repository in the background,
+// notifications are sent from within database transactions and cannot wait for a write of their own.
+// Everything but the pending events is guarded by the lock of the broker.
+// The sequence continues from the last persisted event, while the epoch is new on every start, so the sequences
+// handed out by another instance or by a run that stopped before persisting its events are not mistaken for these.
+type journal struct {
+	repository    JournalRepository
+	memorySize    int
+	persistedSize int
+	epoch         string
+	sequence      uint64
+	events        []*Event
+
+	pendingLock   sync.Mutex
+	pending       []*Event
+	pendingSignal chan struct{}
+	stopChan      chan struct{}
+	done          chan struct{}
+}
+
+func newJournal(repository JournalRepository, memorySize int, persistedSize int) (*journal, error) {
+	j := &journal{
+		repository:    repository,
+		memorySize:    memorySize,
+		persistedSize: persistedSize,
+		epoch:         uuid.NewString(),
+		pendingSignal: make(chan struct{}, 1),
+		stopChan:      make(chan struct{}),
+		done:          make(chan struct{}),
+	}
+
+	if repository == nil {
+		close(j.done)
+		return j, nil
+	}
+
+	events, err := repository.FindLast(context.Background(), memorySize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load event journal: %w", err)
+	}
+	if len(events) != 0 {
+		j.events = events
+		j.sequence = events[len(events)-1].Sequence
+	}
+
+	go j.run()
+	return j, nil
+}
+
+func (j *journal) append(channel string, bytes []byte) *Event {
+	j.sequence++
+	event := &Event{
+		Epoch:     j.epoch,
+		Sequence:  j.sequence,
+		Channel:   channel,
+		Bytes:     bytes,
+		CreatedAt: time.Now(),
+	}
+
+	j.events = append(j.events, event)
+	if len(j.events) > j.memorySize {
+		j.events = j.events[len(j.events)-j.memorySize:]
+	}
+
+	if j.repository != nil {
+		j.pendingLock.Lock()
+		j.pending = append(j.pending, event)
+		j.pendingLock.Unlock()
+
+		select {
+		case j.pendingSignal <- struct{}{}:
+		default:
+		}
+	}
+	return event
+}
+
+// ephemeral returns an event that is not journaled, resuming after its cursor resumes after the last journaled event.
+func (j *journal) ephemeral(channel string, bytes []byte) *Event {
+	return &Event{
+		Epoch:     j.epoch,
+		Sequence:  j.sequence,
+		Channel:   channel,
+		Bytes:     bytes,
+		CreatedAt: time.Now(),
+		Ephemeral: true,
+	}
+}
+
+// journalSnapshot is the journal at the moment a subscription starts, it is taken under the lock of the broker and
+// read after releasing it, so notifications do not wait for the repository.
+type journalSnapshot struct {
+	sequence uint64
+	events   []*Event
+}
+
+// snapshot returns the current events, append replaces the slice instead of changing the events it holds.
+func (j *journal) snapshot() journalSnapshot {
+	return journalSnapshot{
+		sequence: j.sequence,
+		events:   j.events,
+	}
+}
+
+// since returns the events of the snapshot following the cursor that match the channel pattern, oldest first.
+func (j *journal) since(ctx context.Context, snapshot journalSnapshot, cursor Cursor, pattern string) ([]*Event, error) {
+	if cursor.Epoch != j.epoch {
+		if err := j.checkEpoch(ctx, cursor); err != nil {
+			return nil, err
+		}
+	}
+
+	sequence := cursor.Sequence
+	if sequence > snapshot.sequence {
+		return nil, ErrInvalidCursor
+	}
+	if sequence == snapshot.sequence {
+		return nil, nil
+	}
+
+	events := snapshot.events
+	if len(events) == 0 || events[0].Sequence > sequence+1 {
+		if j.repository == nil {
+			return nil, ErrCursorExpired
+		}
+
+		memoryStart := snapshot.sequence + 1
+		if len(events) != 0 {
+			memoryStart = events[0].Sequence
+		}
+
+		persisted, err := j.repository.FindAfter(ctx, sequence, int(memoryStart-sequence-1))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read event journal: %w", err)
+		}
+		if len(persisted) == 0 || persisted[0].Sequence != sequence+1 || persisted[len(persisted)-1].Sequence != memoryStart-1 {
+			return nil, ErrCursorExpired
+		}
+		events = append(persisted, events...)
+	} else {
+		events = events[sequence+1-events[0].Sequence:]
+	}
+
+	var matched []*Event
+	for _, event := range events {
+		if matchChannel(pattern, event.Channel) {
+			matched = append(matched, event)
+		}
+	}
+	return matched, nil
+}
+
+// checkEpoch accepts the cursor of another epoch only when the repository holds its event, the events following it
+// in the repository are the ones that followed it when it was delivered.
+func (j *journal) checkEpoch(ctx context.Context, cursor Cursor) error {
+	if j.repository == nil || cursor.Sequence == 0 {
+		return ErrCursorExpired
+	}
+
+	events, err := j.repository.FindAfter(ctx, cursor.Sequence-1, 1)
+	if err != nil {
+		return fmt.Errorf("failed to read event journal: %w", err)
+	}
+	if len(events) == 0 || events[0].Sequence != cursor.Sequence || events[0].Epoch != cursor.Epoch {
+		return ErrCursorExpired
+	}
+	return nil
+}
+
+func (j *journal) run() {
+	defer close(j.done)
+
+	for {
+		select {
+		case <-j.pendingSignal:
+			j.flush()
+		case <-j.stopChan:
+			j.flush()
+			return
+		}
+	}
+}
+
+func (j *journal) flush() {
+	j.pendingLock.Lock()
+	events := j.pending
+	j.pending = nil
+	j.pendingLock.Unlock()
+
+	if len(events) == 0 {
+		return
+	}
+
+	if err := j.repository.Append(context.Background(), events, j.persistedSize); err != nil {
+		logrus.
+			WithError(err).
+			WithField("events", len(events)).
+			Warn("failed to persist event journal")
+	}
+}
+
+// close writes the pending events to the repository.
+func (j *journal) close() {
+	if j.repository != nil {
+		close(j.stopChan)
+	}
+	<-j.done
+}
@@ -0,0 +1,317 @@
+package subscription
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+type memoryJournalRepository struct {
+	lock   sync.Mutex
+	events []*Event
+}
+
+func (r *memoryJournalRepository) Append(_ context.Context, events []*Event, limit int) error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.events = append(r.events, events...)
+	if len(r.events) > limit {
+		r.events = r.events[len(r.events)-limit:]
+	}
+	return nil
+}
+
+func (r *memoryJournalRepository) FindAfter(_ context.Context, sequence uint64, limit int) ([]*Event, error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	var events []*Event
+	for _, event := range r.events {
+		if event.Sequence > sequence && len(events) < limit {
+			events = append(events, event)
+		}
+	}
+	return events, nil
+}
+
+func (r *memoryJournalRepository) FindLast(_ context.Context, limit int) ([]*Event, error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if len(r.events) > limit {
+		return r.events[len(r.events)-limit:], nil
+	}
+	return r.events, nil
+}
+
+func notifyAll(t *testing.T, s Subscription, channel string, payloads ...string) {
+	t.Helper()
+	for _, payload := range payloads {
+		if err := s.Notify([]byte(payload), channel); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestSubscribeReplaysEventsAfterCursor(t *testing.T) {
+	s := newTestSubscription(t, Options{}, nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	live, err := s.Subscribe(ctx, "server/*", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	notifyAll(t, s, "server/id", "first")
+	notifyAll(t, s, "peer/id", "unrelated")
+	notifyAll(t, s, "server/id", "second")
+
+	first := receive(t, live)
+	cursor, err := DecodeCursor(EncodeCursor(first.Cursor()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resumed, err := s.Subscribe(ctx, "server/*", &cursor)
+	if err != nil {
+		t.Fatal(err)
+	}
+	notifyAll(t, s, "server/id", "third")
+
+	for _, expected := range []string{"second", "third"} {
+		if event := receive(t, resumed); string(event.Bytes) != expected {
+			t.Fatalf("expected %q, got %q", expected, event.Bytes)
+		}
+	}
+}
+
+func TestSubscribeRejectsUnknownCursor(t *testing.T) {
+	s := newTestSubscription(t, Options{JournalSize: 2}, nil)
+	notifyAll(t, s, "user/id", "1", "2", "3", "4")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	events, err := s.Subscribe(ctx, "user/*", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	notifyAll(t, s, "user/id", "5")
+	epoch := receive(t, events).Epoch
+
+	expired := Cursor{Epoch: epoch, Sequence: 1}
+	if _, err := s.Subscribe(ctx, "user/*", &expired); !errors.Is(err, ErrCursorExpired) {
+		t.Fatalf("expected %v, got %v", ErrCursorExpired, err)
+	}
+
+	future := Cursor{Epoch: epoch, Sequence: 10}
+	if _, err := s.Subscribe(ctx, "user/*", &future); !errors.Is(err, ErrInvalidCursor) {
+		t.Fatalf("expected %v, got %v", ErrInvalidCursor, err)
+	}
+
+	if _, err := DecodeCursor("not a cursor"); !errors.Is(err, ErrInvalidCursor) {
+		t.Fatalf("expected %v, got %v", ErrInvalidCursor, err)
+	}
+}
+
+func TestSubscribeResumesFromRepositoryAfterRestart(t *testing.T) {
+	repository := &memoryJournalRepository{}
+
+	s, err := NewSubscription(Options{JournalSize: 2}, nil, repository)
+	if err != nil {
+		t.Fatal(err)
+	}
+	notifyAll(t, s, "peer/id", "1", "2", "3", "4", "5")
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	restarted, err := NewSubscription(Options{JournalSize: 2}, nil, repository)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		_ = restarted.Close()
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	cursor := repository.events[0].Cursor()
+	events, err := restarted.Subscribe(ctx, "peer/*", &cursor)
+	if err != nil {
+		t.Fatal(err)
+	}
+	notifyAll(t, restarted, "peer/id", "6")
+
+	for _, expected := range []string{"2", "3", "4", "5", "6"} {
+		if event := receive(t, events); string(event.Bytes) != expected {
+			t.Fatalf("expected %q, got %q", expected, event.Bytes)
+		}
+	}
+}
+
+func TestSubscribeRejectsCursorOfAnotherInstance(t *testing.T) {
+	first, err := NewSubscription(Options{}, nil, &memoryJournalRepository{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		_ = first.Close()
+	})
+	second, err := NewSubscription(Options{}, nil, &memoryJournalRepository{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		_ = second.Close()
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	events, err := first.Subscribe(ctx, "peer/*", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	notifyAll(t, first, "peer/id", "1")
+	notifyAll(t, second, "peer/id", "1", "2")
+
+	// both instances handed out the sequence, the cursor of the first one does not resume on the second one
+	cursor := receive(t, events).Cursor()
+	if _, err := second.Subscribe(ctx, "peer/*", &cursor); !errors.Is(err, ErrCursorExpired) {
+		t.Fatalf("expected %v, got %v", ErrCursorExpired, err)
+	}
+}
+
+func TestDecodeCursorAcceptsSequenceOnlyCursors(t *testing.T) {
+	cursor, err := DecodeCursor(base64.RawURLEncoding.EncodeToString([]byte("42")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cursor != (Cursor{Sequence: 42}) {
+		t.Fatalf("unexpected cursor %+v", cursor)
+	}
+}
+
+type blockingJournalRepository struct {
+	*memoryJournalRepository
+	reading chan struct{}
+	release chan struct{}
+	once    sync.Once
+}
+
+func (r *blockingJournalRepository) FindAfter(ctx context.Context, sequence uint64, limit int) ([]*Event, error) {
+	r.once.Do(func() {
+		close(r.reading)
+	})
+	<-r.release
+	return r.memoryJournalRepository.FindAfter(ctx, sequence, limit)
+}
+
+func TestSubscribeReadsRepositoryWithoutBlockingNotifications(t *testing.T) {
+	repository := &memoryJournalRepository{}
+
+	s, err := NewSubscription(Options{JournalSize: 2}, nil, repository)
+	if err != nil {
+		t.Fatal(err)
+	}
+	notifyAll(t, s, "peer/id", "1", "2", "3", "4", "5")
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	blocking := &blockingJournalRepository{
+		memoryJournalRepository: repository,
+		reading:                 make(chan struct{}),
+		release:                 make(chan struct{}),
+	}
+	restarted, err := NewSubscription(Options{JournalSize: 2}, nil, blocking)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		_ = restarted.Close()
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	type subscribed struct {
+		events <-chan *Event
+		err    error
+	}
+	result := make(chan subscribed, 1)
+	cursor := repository.events[0].Cursor()
+	go func() {
+		events, err := restarted.Subscribe(ctx, "peer/*", &cursor)
+		result <- subscribed{events: events, err: err}
+	}()
+	<-blocking.reading
+
+	notified := make(chan struct{})
+	go func() {
+		defer close(notified)
+		notifyAll(t, restarted, "peer/id", "6")
+	}()
+	select {
+	case <-notified:
+	case <-time.After(5 * time.Second):
+		t.Fatal("notification waited for the journal read of a subscription")
+	}
+	close(blocking.release)
+
+	subscription := <-result
+	if subscription.err != nil {
+		t.Fatal(subscription.err)
+	}
+	for _, expected := range []string{"2", "3", "4", "5", "6"} {
+		if event := receive(t, subscription.events); string(event.Bytes) != expected {
+			t.Fatalf("expected %q, got %q", expected, event.Bytes)
+		}
+	}
+	select {
+	case event := <-subscription.events:
+		t.Fatalf("unexpected event %q", event.Bytes)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestNotifyEphemeralIsNotReplayed(t *testing.T) {
+	repository := &memoryJournalRepository{}
+	s, err := NewSubscription(Options{}, nil, repository)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	live, err := s.Subscribe(ctx, "health/*", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	notifyAll(t, s, "health/id", "journaled")
+	if err := s.NotifyEphemeral([]byte("flap"), "health/id"); err != nil {
+		t.Fatal(err)
+	}
+
+	journaled := receive(t, live)
+	if event := receive(t, live); string(event.Bytes) != "flap" || !event.Ephemeral {
+		t.Fatalf("expected the ephemeral event to be delivered live, got %+v", event)
+	} else if event.Cursor() != journaled.Cursor() {
+		t.Fatalf("expected the ephemeral event to resume after %+v, got %+v", journaled.Cursor(), event.Cursor())
+	}
+
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if len(repository.events) != 1 || string(repository.events[0].Bytes) != "journaled" {
+		t.Fatalf("expected only the journaled event to be persisted, got %d events", len(repository.events))
+	}
+}
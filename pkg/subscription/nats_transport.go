@@ -0,0 +1,78 @@
+package subscription
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	defaultNatsSubjectPrefix = "wg-ui"
+	natsEphemeralHeader      = "Wg-Ui-Ephemeral"
+)
+
+type natsTransport struct {
+	conn          *nats.Conn
+	subjectPrefix string
+}
+
+// NewNatsTransport exchanges notifications over a NATS server, instances sharing the subject prefix see the
+// notifications of each other.
+func NewNatsTransport(url string, subjectPrefix string) (Transport, error) {
+	if subjectPrefix == "" {
+		subjectPrefix = defaultNatsSubjectPrefix
+	}
+
+	conn, err := nats.Connect(
+		url,
+		nats.Name("wg-ui"),
+		// the notifications of this instance are delivered to its subscribers without the transport
+		nats.NoEcho(),
+		nats.MaxReconnects(-1),
+		nats.ReconnectWait(2*time.Second),
+		nats.DisconnectErrHandler(func(_ *nats.Conn, err error) {
+			logrus.
+				WithError(err).
+				Warn("disconnected from nats server")
+		}),
+		nats.ReconnectHandler(func(conn *nats.Conn) {
+			logrus.
+				WithField("url", conn.ConnectedUrlRedacted()).
+				Info("reconnected to nats server")
+		}),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to nats server: %w", err)
+	}
+
+	return &natsTransport{
+		conn:          conn,
+		subjectPrefix: subjectPrefix,
+	}, nil
+}
+
+func (t *natsTransport) Publish(channel string, bytes []byte, ephemeral bool) error {
+	msg := nats.NewMsg(t.subjectPrefix + "." + channel)
+	msg.Data = bytes
+	if ephemeral {
+		msg.Header.Set(natsEphemeralHeader, "true")
+	}
+	return t.conn.PublishMsg(msg)
+}
+
+func (t *natsTransport) Subscribe(handler func(channel string, bytes []byte, ephemeral bool)) error {
+	if _, err := t.conn.Subscribe(t.subjectPrefix+".>", func(msg *nats.Msg) {
+		handler(strings.TrimPrefix(msg.Subject, t.subjectPrefix+"."), msg.Data, msg.Header.Get(natsEphemeralHeader) == "true")
+	}); err != nil {
+		return err
+	}
+	return t.conn.Flush()
+}
+
+// Close delivers the pending notifications before closing the connection.
+func (t *natsTransport) Close() error {
+	return t.conn.Drain()
+}
@@ -0,0 +1,99 @@
+package subscription
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/nats-io/nats-server/v2/server"
+)
+
+func runNatsServer(t *testing.T) string {
+	t.Helper()
+
+	natsServer, err := server.NewServer(&server.Options{
+		Host:   "127.0.0.1",
+		Port:   server.RANDOM_PORT,
+		NoLog:  true,
+		NoSigs: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	go natsServer.Start()
+	t.Cleanup(natsServer.Shutdown)
+
+	if !natsServer.ReadyForConnections(5 * time.Second) {
+		t.Fatal("nats server did not start")
+	}
+	return natsServer.ClientURL()
+}
+
+func newNatsSubscription(t *testing.T, url string, subject string) Subscription {
+	t.Helper()
+
+	transport, err := NewNatsTransport(url, subject)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return newTestSubscription(t, Options{}, transport)
+}
+
+func TestNatsTransportDeliversToOtherInstances(t *testing.T) {
+	url := runNatsServer(t)
+	first := newNatsSubscription(t, url, "wg-ui")
+	second := newNatsSubscription(t, url, "wg-ui")
+	other := newNatsSubscription(t, url, "other")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	local, err := first.Subscribe(ctx, "server/*", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	remote, err := second.Subscribe(ctx, "server/*", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	unrelated, err := other.Subscribe(ctx, "server/*", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := first.Notify([]byte("changed"), "server/id"); err != nil {
+		t.Fatal(err)
+	}
+
+	if event := receive(t, local); string(event.Bytes) != "changed" {
+		t.Fatalf("expected the local notification, got %q", event.Bytes)
+	}
+	if event := receive(t, remote); string(event.Bytes) != "changed" {
+		t.Fatalf("expected the remote notification, got %q", event.Bytes)
+	}
+
+	// a second notification proves the first one was not echoed back to its own instance
+	if err := second.Notify([]byte("again"), "server/id"); err != nil {
+		t.Fatal(err)
+	}
+	if event := receive(t, local); string(event.Bytes) != "again" {
+		t.Fatalf("expected a single delivery per notification, got %q", event.Bytes)
+	}
+	if event := receive(t, remote); string(event.Bytes) != "again" {
+		t.Fatalf("expected the local notification, got %q", event.Bytes)
+	}
+
+	if err := first.NotifyEphemeral([]byte("flap"), "server/id"); err != nil {
+		t.Fatal(err)
+	}
+	receive(t, local)
+	if event := receive(t, remote); string(event.Bytes) != "flap" || !event.Ephemeral {
+		t.Fatalf("expected the remote notification to stay ephemeral, got %+v", event)
+	}
+
+	select {
+	case event := <-unrelated:
+		t.Fatalf("expected instances with another subject to be isolated, got %q", event.Bytes)
+	default:
+	}
+}
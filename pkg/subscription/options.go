@@ -0,0 +1,69 @@
+package subscription
+
+import (
+	"fmt"
+)
+
+// OverflowPolicy decides what happens to a subscriber whose queue is full.
+type OverflowPolicy string
+
+const (
+	// OverflowPolicyDrop drops the messages that do not fit in the queue of the subscriber, subscribers still
+	// receiving the events missed since their cursor are disconnected instead.
+	OverflowPolicyDrop OverflowPolicy = "drop"
+	// OverflowPolicyDisconnect closes the subscription, the client has to subscribe again.
+	OverflowPolicyDisconnect OverflowPolicy = "disconnect"
+)
+
+const (
+	defaultQueueSize            = 64
+	defaultJournalSize          = 1000
+	defaultPersistedJournalSize = 10000
+)
+
+type Options struct {
+	// QueueSize is the number of messages buffered for each subscriber.
+	QueueSize      int
+	OverflowPolicy OverflowPolicy
+	// JournalSize is the number of events kept in memory for subscriptions resuming after a cursor.
+	JournalSize int
+	// PersistedJournalSize is the number of events kept in the journal repository.
+	PersistedJournalSize int
+}
+
+func (o Options) Validate() error {
+	if o.QueueSize < 0 {
+		return fmt.Errorf("invalid subscription queue size: %d", o.QueueSize)
+	}
+
+	if o.JournalSize < 0 || o.PersistedJournalSize < 0 {
+		return fmt.Errorf("invalid subscription journal size: %d, %d", o.JournalSize, o.PersistedJournalSize)
+	}
+
+	switch o.OverflowPolicy {
+	case "", OverflowPolicyDrop, OverflowPolicyDisconnect:
+		return nil
+	}
+	return fmt.Errorf("invalid subscription overflow policy: %s", o.OverflowPolicy)
+}
+
+func (o Options) queueSize() int {
+	if o.QueueSize == 0 {
+		return defaultQueueSize
+	}
+	return o.QueueSize
+}
+
+func (o Options) journalSize() int {
+	if o.JournalSize == 0 {
+		return defaultJournalSize
+	}
+	return o.JournalSize
+}
+
+func (o Options) persistedJournalSize() int {
+	if o.PersistedJournalSize == 0 {
+		return defaultPersistedJournalSize
+	}
+	return o.PersistedJournalSize
+}
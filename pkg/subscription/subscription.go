@@ -6,6 +6,12 @@ import (
 
 type Subscription interface {
 	Notify(bytes []byte, channel string) error
-	Subscribe(ctx context.Context, channel string) (<-chan []byte, error)
+	// NotifyEphemeral delivers the notification to the current subscribers only, it is not journaled, so
+	// subscriptions resuming after a cursor never receive it.
+	NotifyEphemeral(bytes []byte, channel string) error
+	// Subscribe delivers the events of the channels matching the pattern, when since is set the journaled events
+	// following it are delivered first.
+	Subscribe(ctx context.Context, channel string, since *Cursor) (<-chan *Event, error)
 	HasSubscribers(channel string) bool
+	Close() error
 }
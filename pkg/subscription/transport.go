@@ -0,0 +1,10 @@
+package subscription
+
+// Transport carries notifications to the other wg-ui instances, Subscribe delivers the messages published by the
+// other instances only, notifications of this instance are delivered to its subscribers directly.
+// Ephemeral notifications are not journaled by the other instances either.
+type Transport interface {
+	Publish(channel string, bytes []byte, ephemeral bool) error
+	Subscribe(handler func(channel string, bytes []byte, ephemeral bool)) error
+	Close() error
+}
@@ -1,5 +1,13 @@
 package user
 
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+
+	"github.com/UnAfraid/wg-ui/pkg/subscription"
+)
+
 const (
 	ChangedActionCreated = "CREATED"
 	ChangedActionUpdated = "UPDATED"
@@ -9,4 +17,20 @@ const (
 type ChangedEvent struct {
 	Action string `json:"action"`
 	User   *User  `json:"user"`
+	// Cursor of the event in the journal, it is not part of the notification.
+	Cursor subscription.Cursor `json:"-"`
+}
+
+// DecodeChangedEvent decodes an event of the user channels, it reports false for the events of other channels.
+func DecodeChangedEvent(event *subscription.Event) (*ChangedEvent, bool, error) {
+	if !subscription.MatchChannel(path.Join(subscriptionPath, "*"), event.Channel) {
+		return nil, false, nil
+	}
+
+	var changedEvent *ChangedEvent
+	if err := json.Unmarshal(event.Bytes, &changedEvent); err != nil {
+		return nil, false, fmt.Errorf("failed to decode user changed event: %w", err)
+	}
+	changedEvent.Cursor = event.Cursor()
+	return changedEvent, true, nil
 }
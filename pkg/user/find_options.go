@@ -1,6 +1,18 @@
 package user
 
+import (
+	"github.com/UnAfraid/wg-ui/pkg/pagination"
+)
+
 type FindOptions struct {
-	Ids   []string
-	Query string
+	Ids        []string
+	Query      string
+	Pagination *pagination.Options
+}
+
+func (options *FindOptions) Validate() error {
+	if options.Pagination != nil {
+		return options.Pagination.Validate(SortFieldEmail, SortFieldCreatedAt)
+	}
+	return nil
 }
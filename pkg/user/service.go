@@ -28,7 +28,8 @@ type Service interface {
 	CreateUser(ctx context.Context, options *CreateOptions) (*User, error)
 	UpdateUser(ctx context.Context, userId string, options *UpdateOptions, fieldMask *UpdateFieldMask) (*User, error)
 	DeleteUser(ctx context.Context, userId string) (*User, error)
-	Subscribe(ctx context.Context) (<-chan *ChangedEvent, error)
+	// Subscribe delivers the changed events, when since is set the journaled events following it are delivered first.
+	Subscribe(ctx context.Context, since *subscription.Cursor) (<-chan *ChangedEvent, error)
 	HasSubscribers() bool
 }
 
@@ -86,6 +87,9 @@ func (s *service) FindUser(ctx context.Context, options *FindOneOptions) (*User,
 }
 
 func (s *service) FindUsers(ctx context.Context, options *FindOptions) ([]*User, error) {
+	if err := options.Validate(); err != nil {
+		return nil, err
+	}
 	return s.userRepository.FindAll(ctx, options)
 }
 
@@ -286,8 +290,8 @@ func (s *service) notify(action string, user *User) error {
 	return nil
 }
 
-func (s *service) Subscribe(ctx context.Context) (<-chan *ChangedEvent, error) {
-	bytesChannel, err := s.subscription.Subscribe(ctx, path.Join(subscriptionPath, "*"))
+func (s *service) Subscribe(ctx context.Context, since *subscription.Cursor) (<-chan *ChangedEvent, error) {
+	events, err := s.subscription.Subscribe(ctx, path.Join(subscriptionPath, "*"), since)
 	if err != nil {
 		return nil, err
 	}
@@ -296,10 +300,10 @@ func (s *service) Subscribe(ctx context.Context) (<-chan *ChangedEvent, error) {
 	go func() {
 		defer close(observerChan)
 
-		for bytes := range bytesChannel {
-			var changedEvent *ChangedEvent
-			if err := json.Unmarshal(bytes, &changedEvent); err != nil {
-				logrus.WithError(err).Warn("failed to decode user changed event")
+		for event := range events {
+			changedEvent, _, err := DecodeChangedEvent(event)
+			if err != nil {
+				logrus.WithError(err).Warn("failed to decode changed event")
 				return
 			}
 			observerChan <- changedEvent
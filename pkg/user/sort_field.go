@@ -0,0 +1,26 @@
+package user
+
+import (
+	"github.com/UnAfraid/wg-ui/pkg/pagination"
+)
+
+const (
+	SortFieldEmail     = "EMAIL"
+	SortFieldCreatedAt = "CREATED_AT"
+)
+
+func (u *User) Cursor(sortField string) pagination.Cursor {
+	var key string
+	switch sortField {
+	case SortFieldCreatedAt:
+		key = pagination.TimeKey(u.CreatedAt)
+	default:
+		key = pagination.StringKey(u.Email)
+	}
+
+	return pagination.Cursor{
+		Field: sortField,
+		Key:   key,
+		Id:    u.Id,
+	}
+}
@@ -0,0 +1,288 @@
+package agent
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/UnAfraid/wg-ui/pkg/wireguard/driver"
+)
+
+const defaultAgentPort = "4581"
+
+type parsedURL struct {
+	baseURL            string
+	host               string
+	port               string
+	token              string
+	caFile             string
+	certFile           string
+	keyFile            string
+	insecureSkipVerify bool
+}
+
+type agentBackend struct {
+	baseURL  string
+	token    string
+	endpoint driver.Endpoint
+	client   *http.Client
+}
+
+// Register registers the agent backend type, the capabilities are the ones the agent can proxy,
+// operations the backend of the agent does not support fail with the matching driver error.
+func Register() {
+	driver.Register("agent", func(_ context.Context, rawURL string) (driver.Backend, error) {
+		return NewAgentBackend(rawURL)
+	}, true, driver.Capabilities{
+		Firewall:        true,
+		LivePeerUpdates: true,
+		ForeignImport:   true,
+		PeerStats:       true,
+	}, driver.RemoteTarget(defaultAgentPort))
+}
+
+func NewAgentBackend(rawURL string) (driver.Backend, error) {
+	parsed, err := parseURL(rawURL)
+	if err != nil {
+		return nil, err
+	}
+
+	tlsConfig := &tls.Config{
+		InsecureSkipVerify: parsed.insecureSkipVerify, //nolint:gosec // explicitly user-controlled
+		MinVersion:         tls.VersionTLS12,
+	}
+	if parsed.caFile != "" {
+		rootCAs, err := loadCertPool(parsed.caFile)
+		if err != nil {
+			return nil, err
+		}
+		tlsConfig.RootCAs = rootCAs
+	}
+	if parsed.certFile != "" {
+		certificate, err := tls.LoadX509KeyPair(parsed.certFile, parsed.keyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load agent client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{certificate}
+	}
+
+	return &agentBackend{
+		baseURL: parsed.baseURL,
+		token:   parsed.token,
+		endpoint: driver.Endpoint{
+			Host:      parsed.host,
+			Port:      parsed.port,
+			TLSConfig: tlsConfig,
+		},
+		client: &http.Client{
+			Transport: &http.Transport{
+				Proxy:           http.ProxyFromEnvironment,
+				TLSClientConfig: tlsConfig,
+			},
+			Timeout: 30 * time.Second,
+		},
+	}, nil
+}
+
+func (b *agentBackend) Device(ctx context.Context, name string) (*driver.Device, error) {
+	var device *driver.Device
+	if err := b.call(ctx, pathDevice, nameRequest{Name: name}, &device); err != nil {
+		return nil, err
+	}
+	return device, nil
+}
+
+func (b *agentBackend) Up(ctx context.Context, options driver.ConfigureOptions) (*driver.Device, error) {
+	if err := options.Validate(); err != nil {
+		return nil, err
+	}
+
+	var device *driver.Device
+	if err := b.call(ctx, pathUp, options, &device); err != nil {
+		return nil, err
+	}
+	return device, nil
+}
+
+func (b *agentBackend) Down(ctx context.Context, name string) error {
+	return b.call(ctx, pathDown, nameRequest{Name: name}, nil)
+}
+
+func (b *agentBackend) Status(ctx context.Context, name string) (bool, error) {
+	var response statusResponse
+	if err := b.call(ctx, pathStatus, nameRequest{Name: name}, &response); err != nil {
+		return false, err
+	}
+	return response.Up, nil
+}
+
+func (b *agentBackend) Stats(ctx context.Context, name string) (*driver.InterfaceStats, error) {
+	var stats *driver.InterfaceStats
+	if err := b.call(ctx, pathStats, nameRequest{Name: name}, &stats); err != nil {
+		return nil, err
+	}
+	return stats, nil
+}
+
+func (b *agentBackend) PeerStats(ctx context.Context, name string, peerPublicKey string) (*driver.PeerStats, error) {
+	var stats *driver.PeerStats
+	if err := b.call(ctx, pathPeerStats, peerRequest{Name: name, PublicKey: peerPublicKey}, &stats); err != nil {
+		return nil, err
+	}
+	return stats, nil
+}
+
+func (b *agentBackend) FindForeignServers(ctx context.Context, knownInterfaces []string) ([]*driver.ForeignServer, error) {
+	var servers []*driver.ForeignServer
+	if err := b.call(ctx, pathForeignServers, foreignServersRequest{KnownInterfaces: knownInterfaces}, &servers); err != nil {
+		return nil, err
+	}
+	return servers, nil
+}
+
+func (b *agentBackend) UpsertPeer(ctx context.Context, name string, peer *driver.PeerOptions) error {
+	return b.call(ctx, pathUpsertPeer, peerRequest{Name: name, PublicKey: peer.PublicKey, Peer: peer}, nil)
+}
+
+func (b *agentBackend) RemovePeer(ctx context.Context, name string, publicKey string) error {
+	return b.call(ctx, pathRemovePeer, peerRequest{Name: name, PublicKey: publicKey}, nil)
+}
+
+func (b *agentBackend) ApplyFirewall(ctx context.Context, options driver.ConfigureOptions) error {
+	return b.call(ctx, pathApplyFirewall, options, nil)
+}
+
+func (b *agentBackend) RemoveFirewall(ctx context.Context, name string) error {
+	return b.call(ctx, pathRemoveFirewall, nameRequest{Name: name}, nil)
+}
+
+func (b *agentBackend) Endpoint() driver.Endpoint {
+	return b.endpoint
+}
+
+func (b *agentBackend) Close(_ context.Context) error {
+	b.client.CloseIdleConnections()
+	return nil
+}
+
+func (b *agentBackend) call(ctx context.Context, path string, payload any, result any) error {
+	bodyBytes, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, b.baseURL+path, bytes.NewReader(bodyBytes))
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Accept", "application/json")
+	request.Header.Set("Content-Type", "application/json")
+	if b.token != "" {
+		request.Header.Set("Authorization", authorizationScheme+b.token)
+	}
+
+	response, err := b.client.Do(request)
+	if err != nil {
+		return fmt.Errorf("agent request failed: %w", err)
+	}
+	defer response.Body.Close()
+
+	responseBody, err := io.ReadAll(response.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read agent response: %w", err)
+	}
+
+	if response.StatusCode < http.StatusOK || response.StatusCode >= http.StatusMultipleChoices {
+		var errResponse errorResponse
+		if err := json.Unmarshal(responseBody, &errResponse); err != nil || errResponse.Error == "" {
+			message := strings.TrimSpace(string(responseBody))
+			if message == "" {
+				message = response.Status
+			}
+			return fmt.Errorf("agent %s failed: %s", path, message)
+		}
+		return errResponse.err()
+	}
+
+	if result == nil {
+		return nil
+	}
+	if err := json.Unmarshal(responseBody, result); err != nil {
+		return fmt.Errorf("failed to decode agent response: %w", err)
+	}
+	return nil
+}
+
+func parseURL(rawURL string) (*parsedURL, error) {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid agent backend url: %w", err)
+	}
+
+	if !strings.EqualFold(parsed.Scheme, "agent") {
+		return nil, fmt.Errorf("invalid backend type: %s", parsed.Scheme)
+	}
+
+	host := strings.TrimSpace(parsed.Hostname())
+	if host == "" {
+		return nil, errors.New("agent backend requires host")
+	}
+
+	port := strings.TrimSpace(parsed.Port())
+	if port == "" {
+		port = defaultAgentPort
+	}
+
+	var token string
+	if parsed.User != nil {
+		token, _ = parsed.User.Password()
+	}
+
+	query := parsed.Query()
+	insecureSkipVerify, err := queryBool(query, "insecureSkipVerify", false)
+	if err != nil {
+		return nil, err
+	}
+
+	certFile := strings.TrimSpace(query.Get("cert"))
+	keyFile := strings.TrimSpace(query.Get("key"))
+	if (certFile == "") != (keyFile == "") {
+		return nil, errors.New("agent backend requires both cert and key for client certificates")
+	}
+	if token == "" && certFile == "" {
+		return nil, errors.New("agent backend requires a token or a client certificate")
+	}
+
+	return &parsedURL{
+		baseURL:            fmt.Sprintf("https://%s", net.JoinHostPort(host, port)),
+		host:               host,
+		port:               port,
+		token:              token,
+		caFile:             strings.TrimSpace(query.Get("ca")),
+		certFile:           certFile,
+		keyFile:            keyFile,
+		insecureSkipVerify: insecureSkipVerify,
+	}, nil
+}
+
+func queryBool(values url.Values, key string, defaultValue bool) (bool, error) {
+	raw := strings.TrimSpace(values.Get(key))
+	if raw == "" {
+		return defaultValue, nil
+	}
+	parsed, err := strconv.ParseBool(raw)
+	if err != nil {
+		return false, fmt.Errorf("invalid %s value: %s", key, raw)
+	}
+	return parsed, nil
+}
@@ -0,0 +1,85 @@
+package agent
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/UnAfraid/wg-ui/pkg/wireguard/driver"
+)
+
+const (
+	pathDevice         = "/v1/device"
+	pathUp             = "/v1/up"
+	pathDown           = "/v1/down"
+	pathStatus         = "/v1/status"
+	pathStats          = "/v1/stats"
+	pathPeerStats      = "/v1/peer-stats"
+	pathForeignServers = "/v1/foreign-servers"
+	pathUpsertPeer     = "/v1/peers/upsert"
+	pathRemovePeer     = "/v1/peers/remove"
+	pathApplyFirewall  = "/v1/firewall/apply"
+	pathRemoveFirewall = "/v1/firewall/remove"
+)
+
+const (
+	errorCodeUnauthorized = "unauthorized"
+	errorCodeBadRequest   = "bad_request"
+	errorCodeInternal     = "internal"
+	errorCodePeerUpdates  = "peer_updates_not_supported"
+	errorCodeFirewall     = "firewall_not_supported"
+	errorCodeCapability   = "capability_not_supported"
+)
+
+const (
+	authorizationScheme = "Bearer "
+	maxRequestBodySize  = 4 << 20
+)
+
+type nameRequest struct {
+	Name string `json:"name"`
+}
+
+type peerRequest struct {
+	Name      string              `json:"name"`
+	PublicKey string              `json:"publicKey"`
+	Peer      *driver.PeerOptions `json:"peer,omitempty"`
+}
+
+type foreignServersRequest struct {
+	KnownInterfaces []string `json:"knownInterfaces"`
+}
+
+type statusResponse struct {
+	Up bool `json:"up"`
+}
+
+type emptyResponse struct{}
+
+type errorResponse struct {
+	Code  string `json:"code"`
+	Error string `json:"error"`
+}
+
+// errorCodes maps the driver errors that callers match with errors.Is to their wire representation.
+var errorCodes = map[string]error{
+	errorCodeUnauthorized: driver.ErrUnauthorized,
+	errorCodePeerUpdates:  driver.ErrPeerUpdatesNotSupported,
+	errorCodeFirewall:     driver.ErrFirewallNotSupported,
+	errorCodeCapability:   driver.ErrCapabilityNotSupported,
+}
+
+func errorCode(err error) string {
+	for code, target := range errorCodes {
+		if errors.Is(err, target) {
+			return code
+		}
+	}
+	return errorCodeInternal
+}
+
+func (r errorResponse) err() error {
+	if target, ok := errorCodes[r.Code]; ok {
+		return fmt.Errorf("%w: %s", target, r.Error)
+	}
+	return fmt.Errorf("agent error: %s", r.Error)
+}
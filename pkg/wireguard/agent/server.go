@@ -0,0 +1,189 @@
+package agent
+
+import (
+	"context"
+	"crypto/subtle"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/UnAfraid/wg-ui/pkg/wireguard/driver"
+)
+
+type handler struct {
+	backend driver.Backend
+	token   string
+}
+
+// NewHandler exposes the operations of a local backend to remote wg-ui instances, requests must carry the token
+// as a bearer token unless it is empty, in which case authentication is left to the TLS client certificates.
+func NewHandler(backend driver.Backend, token string) http.Handler {
+	h := &handler{
+		backend: backend,
+		token:   token,
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("POST "+pathDevice, handle(h, func(ctx context.Context, request nameRequest) (*driver.Device, error) {
+		return h.backend.Device(ctx, request.Name)
+	}))
+	mux.Handle("POST "+pathUp, handle(h, func(ctx context.Context, options driver.ConfigureOptions) (*driver.Device, error) {
+		return h.backend.Up(ctx, options)
+	}))
+	mux.Handle("POST "+pathDown, handle(h, func(ctx context.Context, request nameRequest) (emptyResponse, error) {
+		return emptyResponse{}, h.backend.Down(ctx, request.Name)
+	}))
+	mux.Handle("POST "+pathStatus, handle(h, func(ctx context.Context, request nameRequest) (statusResponse, error) {
+		up, err := h.backend.Status(ctx, request.Name)
+		return statusResponse{Up: up}, err
+	}))
+	mux.Handle("POST "+pathStats, handle(h, func(ctx context.Context, request nameRequest) (*driver.InterfaceStats, error) {
+		return h.backend.Stats(ctx, request.Name)
+	}))
+	mux.Handle("POST "+pathPeerStats, handle(h, func(ctx context.Context, request peerRequest) (*driver.PeerStats, error) {
+		return h.backend.PeerStats(ctx, request.Name, request.PublicKey)
+	}))
+	mux.Handle("POST "+pathForeignServers, handle(h, func(ctx context.Context, request foreignServersRequest) ([]*driver.ForeignServer, error) {
+		return h.backend.FindForeignServers(ctx, request.KnownInterfaces)
+	}))
+	mux.Handle("POST "+pathUpsertPeer, handle(h, func(ctx context.Context, request peerRequest) (emptyResponse, error) {
+		peerBackend, ok := h.backend.(driver.PeerBackend)
+		if !ok {
+			return emptyResponse{}, driver.ErrPeerUpdatesNotSupported
+		}
+		if request.Peer == nil {
+			return emptyResponse{}, errors.New("peer is required")
+		}
+		return emptyResponse{}, peerBackend.UpsertPeer(ctx, request.Name, request.Peer)
+	}))
+	mux.Handle("POST "+pathRemovePeer, handle(h, func(ctx context.Context, request peerRequest) (emptyResponse, error) {
+		peerBackend, ok := h.backend.(driver.PeerBackend)
+		if !ok {
+			return emptyResponse{}, driver.ErrPeerUpdatesNotSupported
+		}
+		return emptyResponse{}, peerBackend.RemovePeer(ctx, request.Name, request.PublicKey)
+	}))
+	mux.Handle("POST "+pathApplyFirewall, handle(h, func(ctx context.Context, options driver.ConfigureOptions) (emptyResponse, error) {
+		firewallBackend, ok := h.backend.(driver.FirewallBackend)
+		if !ok {
+			// Without firewall settings there is nothing to remove, the controller only fails on configurations that need it.
+			if !options.ManagesFirewall() {
+				return emptyResponse{}, nil
+			}
+			return emptyResponse{}, driver.ErrFirewallNotSupported
+		}
+		return emptyResponse{}, firewallBackend.ApplyFirewall(ctx, options)
+	}))
+	mux.Handle("POST "+pathRemoveFirewall, handle(h, func(ctx context.Context, request nameRequest) (emptyResponse, error) {
+		firewallBackend, ok := h.backend.(driver.FirewallBackend)
+		if !ok {
+			return emptyResponse{}, nil
+		}
+		return emptyResponse{}, firewallBackend.RemoveFirewall(ctx, request.Name)
+	}))
+	return h.authenticate(mux)
+}
+
+func (h *handler) authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if h.token != "" {
+			token, ok := strings.CutPrefix(r.Header.Get("Authorization"), authorizationScheme)
+			if !ok || subtle.ConstantTimeCompare([]byte(token), []byte(h.token)) != 1 {
+				writeError(w, http.StatusUnauthorized, errorResponse{
+					Code:  errorCodeUnauthorized,
+					Error: "invalid agent token",
+				})
+				return
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func handle[Request any, Response any](h *handler, operation func(ctx context.Context, request Request) (Response, error)) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var request Request
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxRequestBodySize)).Decode(&request); err != nil {
+			writeError(w, http.StatusBadRequest, errorResponse{
+				Code:  errorCodeBadRequest,
+				Error: fmt.Sprintf("invalid request: %s", err),
+			})
+			return
+		}
+
+		response, err := operation(r.Context(), request)
+		if err != nil {
+			logrus.
+				WithError(err).
+				WithField("path", r.URL.Path).
+				Warn("agent operation failed")
+
+			writeError(w, http.StatusInternalServerError, errorResponse{
+				Code:  errorCode(err),
+				Error: err.Error(),
+			})
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(response); err != nil {
+			logrus.
+				WithError(err).
+				WithField("path", r.URL.Path).
+				Warn("failed to write agent response")
+		}
+	})
+}
+
+func writeError(w http.ResponseWriter, statusCode int, response errorResponse) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		logrus.
+			WithError(err).
+			Warn("failed to write agent error response")
+	}
+}
+
+// ServerTLSConfig loads the certificate of the agent, when a client CA is given the clients are required
+// to present a certificate signed by it.
+func ServerTLSConfig(certFile string, keyFile string, clientCAFile string) (*tls.Config, error) {
+	certificate, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load agent certificate: %w", err)
+	}
+
+	tlsConfig := &tls.Config{
+		Certificates: []tls.Certificate{certificate},
+		MinVersion:   tls.VersionTLS12,
+	}
+	if clientCAFile != "" {
+		clientCAs, err := loadCertPool(clientCAFile)
+		if err != nil {
+			return nil, err
+		}
+		tlsConfig.ClientCAs = clientCAs
+		tlsConfig.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+	return tlsConfig, nil
+}
+
+func loadCertPool(path string) (*x509.CertPool, error) {
+	pemBytes, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read certificate authority: %w", err)
+	}
+
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(pemBytes) {
+		return nil, fmt.Errorf("no certificates found in %s", path)
+	}
+	return pool, nil
+}
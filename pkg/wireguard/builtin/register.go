@@ -1,16 +1,27 @@
 package builtin
 
 import (
+	"github.com/UnAfraid/wg-ui/pkg/wireguard/agent"
 	"github.com/UnAfraid/wg-ui/pkg/wireguard/exec"
 	"github.com/UnAfraid/wg-ui/pkg/wireguard/linux"
+	"github.com/UnAfraid/wg-ui/pkg/wireguard/memory"
 	"github.com/UnAfraid/wg-ui/pkg/wireguard/networkmanager"
+	"github.com/UnAfraid/wg-ui/pkg/wireguard/opnsense"
 	"github.com/UnAfraid/wg-ui/pkg/wireguard/routeros"
 )
 
-// RegisterAll registers all built-in wireguard backend implementations.
-func RegisterAll() {
+// RegisterAll registers all built-in wireguard backend implementations, the simulated memory backend
+// only when it is enabled.
+func RegisterAll(memoryBackendEnabled bool) {
+	agent.Register()
 	exec.Register()
+	exec.RegisterSSH()
 	linux.Register()
 	networkmanager.Register()
+	opnsense.Register()
 	routeros.Register()
+
+	if memoryBackendEnabled {
+		memory.Register()
+	}
 }
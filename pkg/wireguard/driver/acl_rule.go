@@ -0,0 +1,64 @@
+package driver
+
+import (
+	"fmt"
+	"net/netip"
+	"strings"
+)
+
+type ACLAction string
+
+const (
+	ACLActionAllow ACLAction = "allow"
+	ACLActionDeny  ACLAction = "deny"
+)
+
+type ACLProtocol string
+
+const (
+	ACLProtocolAny  ACLProtocol = "any"
+	ACLProtocolTCP  ACLProtocol = "tcp"
+	ACLProtocolUDP  ACLProtocol = "udp"
+	ACLProtocolICMP ACLProtocol = "icmp"
+)
+
+// ACLRule allows or denies forwarding traffic from a peer to a destination, the rules of a peer are
+// evaluated in order and the first match wins.
+type ACLRule struct {
+	Destination string
+	Protocol    ACLProtocol
+	// FromPort and ToPort are the destination port range of tcp and udp rules, zero matches any port.
+	FromPort int
+	ToPort   int
+	Action   ACLAction
+}
+
+func (r *ACLRule) Validate() error {
+	if _, err := netip.ParsePrefix(strings.TrimSpace(r.Destination)); err != nil {
+		return fmt.Errorf("invalid acl destination: %s - %w", r.Destination, err)
+	}
+
+	switch r.Action {
+	case ACLActionAllow, ACLActionDeny:
+	default:
+		return fmt.Errorf("invalid acl action: %s", r.Action)
+	}
+
+	switch r.Protocol {
+	case ACLProtocolTCP, ACLProtocolUDP:
+	case "", ACLProtocolAny, ACLProtocolICMP:
+		if r.FromPort != 0 || r.ToPort != 0 {
+			return fmt.Errorf("acl ports require the tcp or udp protocol, got: %s", r.Protocol)
+		}
+	default:
+		return fmt.Errorf("invalid acl protocol: %s", r.Protocol)
+	}
+
+	if r.FromPort < 0 || r.FromPort > 65535 || r.ToPort < 0 || r.ToPort > 65535 {
+		return fmt.Errorf("invalid acl port range: %d-%d, expected values between 1 and 65535", r.FromPort, r.ToPort)
+	}
+	if r.ToPort != 0 && (r.FromPort == 0 || r.ToPort < r.FromPort) {
+		return fmt.Errorf("invalid acl port range: %d-%d", r.FromPort, r.ToPort)
+	}
+	return nil
+}
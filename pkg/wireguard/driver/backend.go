@@ -12,3 +12,33 @@ type Backend interface {
 	FindForeignServers(ctx context.Context, knownInterfaces []string) ([]*ForeignServer, error)
 	Close(ctx context.Context) error
 }
+
+// PeerBackend is an optional Backend capability for changing a single peer of a running interface
+// without reconciling the others.
+type PeerBackend interface {
+	// UpsertPeer adds the peer or replaces its settings, including the allowed ips.
+	UpsertPeer(ctx context.Context, name string, peer *PeerOptions) error
+	// RemovePeer removes the peer, it is a no-op when the peer does not exist.
+	RemovePeer(ctx context.Context, name string, publicKey string) error
+}
+
+// FirewallBackend is an optional Backend capability for enforcing the NAT, forwarding and peer access control
+// rules of a configuration.
+type FirewallBackend interface {
+	// ApplyFirewall replaces the firewall rules of the interface, it removes them when the options do not manage the firewall.
+	ApplyFirewall(ctx context.Context, options ConfigureOptions) error
+	// RemoveFirewall removes the firewall rules of the interface.
+	RemoveFirewall(ctx context.Context, name string) error
+}
+
+// RemoteBackend is an optional Backend capability of backends that manage a host over the network,
+// the endpoint is used to diagnose connection problems.
+type RemoteBackend interface {
+	// Endpoint returns the address the backend connects to.
+	Endpoint() Endpoint
+}
+
+// VersionBackend is an optional Backend capability for reporting the version of the software managing the host.
+type VersionBackend interface {
+	Version(ctx context.Context) (string, error)
+}
@@ -0,0 +1,73 @@
+package driver
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrCapabilityNotSupported is returned when an operation requires a capability the backend does not declare.
+var ErrCapabilityNotSupported = errors.New("capability is not supported by the backend")
+
+type Capability string
+
+const (
+	CapabilityHookExecution   Capability = "hook execution"
+	CapabilityDNS             Capability = "dns"
+	CapabilityFirewall        Capability = "firewall"
+	CapabilityLivePeerUpdates Capability = "live peer updates"
+	CapabilityForeignImport   Capability = "foreign import"
+	CapabilityPeerStats       Capability = "peer stats"
+)
+
+// Capabilities describes the optional features of a backend type.
+type Capabilities struct {
+	// HookExecution reports that the backend runs the raw command hooks of the interface itself.
+	HookExecution bool
+	// DNS reports that the DNS servers of the interface are applied to the host.
+	DNS bool
+	// Firewall reports that the backend implements FirewallBackend.
+	Firewall bool
+	// LivePeerUpdates reports that peer changes are applied without restarting the interface.
+	LivePeerUpdates bool
+	ForeignImport   bool
+	PeerStats       bool
+}
+
+// Supports reports whether the capability is declared.
+func (c Capabilities) Supports(capability Capability) bool {
+	switch capability {
+	case CapabilityHookExecution:
+		return c.HookExecution
+	case CapabilityDNS:
+		return c.DNS
+	case CapabilityFirewall:
+		return c.Firewall
+	case CapabilityLivePeerUpdates:
+		return c.LivePeerUpdates
+	case CapabilityForeignImport:
+		return c.ForeignImport
+	case CapabilityPeerStats:
+		return c.PeerStats
+	default:
+		return false
+	}
+}
+
+// Require returns ErrCapabilityNotSupported for the first capability that is not declared.
+func (c Capabilities) Require(capabilities ...Capability) error {
+	for _, capability := range capabilities {
+		if !c.Supports(capability) {
+			return fmt.Errorf("%w: %s", ErrCapabilityNotSupported, capability)
+		}
+	}
+	return nil
+}
+
+// GetCapabilities returns the declared capabilities of a backend type, none for unknown types.
+func GetCapabilities(scheme string) Capabilities {
+	registration, ok := Get(scheme)
+	if !ok {
+		return Capabilities{}
+	}
+	return registration.Capabilities
+}
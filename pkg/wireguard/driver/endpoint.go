@@ -0,0 +1,11 @@
+package driver
+
+import "crypto/tls"
+
+// Endpoint describes the network address of a remote backend.
+type Endpoint struct {
+	Host string
+	Port string
+	// TLSConfig is the configuration the connection is secured with, nil when TLS is not used.
+	TLSConfig *tls.Config
+}
@@ -6,4 +6,14 @@ var (
 	// ErrConnectionStale signals that backend runtime connection state is no longer usable
 	// and the backend instance should be recreated and retried once.
 	ErrConnectionStale = errors.New("wireguard backend connection is stale")
+	// ErrPeerUpdatesNotSupported is returned for single peer changes on backends that do not implement PeerBackend,
+	// the whole configuration has to be applied with Up instead.
+	ErrPeerUpdatesNotSupported = errors.New("single peer updates are not supported by this backend")
+	// ErrFirewallNotSupported is returned for configurations with firewall settings on backends that do not
+	// implement FirewallBackend.
+	ErrFirewallNotSupported = errors.New("firewall management is not supported by this backend")
+	// ErrUnauthorized is returned when a remote backend rejects the configured credentials.
+	ErrUnauthorized = errors.New("backend rejected the credentials")
+	// ErrPermissionDenied is returned when the configured credentials are not allowed to perform an operation.
+	ErrPermissionDenied = errors.New("backend denied permission")
 )
@@ -0,0 +1,52 @@
+package driver
+
+import (
+	"fmt"
+	"net/netip"
+	"strings"
+)
+
+// FirewallOptions are the NAT and forwarding settings a backend manages next to the interface,
+// nil interface firewall options leave the firewall untouched.
+type FirewallOptions struct {
+	// MasqueradeInterface is the egress interface traffic from the server networks is masqueraded on, empty disables NAT.
+	MasqueradeInterface string
+	// IPForwarding enables forwarding for the address families of the server.
+	IPForwarding bool
+	// PeerIsolation drops traffic between peers of the same server.
+	PeerIsolation bool
+}
+
+func (o *FirewallOptions) Validate() error {
+	if o == nil {
+		return nil
+	}
+	if o.MasqueradeInterface != "" {
+		if len(o.MasqueradeInterface) > 15 || strings.ContainsAny(o.MasqueradeInterface, " /\t\n") {
+			return fmt.Errorf("invalid masquerade interface: %s", o.MasqueradeInterface)
+		}
+	}
+	return nil
+}
+
+// ManagesFirewall reports whether the options require firewall rules, either server wide or for a peer.
+func (o ConfigureOptions) ManagesFirewall() bool {
+	if o.InterfaceOptions.Firewall != nil {
+		return true
+	}
+	for _, peer := range o.WireguardOptions.Peers {
+		if peer != nil && (len(peer.AllowedDestinations) > 0 || len(peer.ACL) > 0) {
+			return true
+		}
+	}
+	return false
+}
+
+func validateDestinations(destinations []string) error {
+	for _, destination := range destinations {
+		if _, err := netip.ParsePrefix(strings.TrimSpace(destination)); err != nil {
+			return fmt.Errorf("invalid allowed destination: %s - %w", destination, err)
+		}
+	}
+	return nil
+}
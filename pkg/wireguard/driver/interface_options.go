@@ -1,6 +1,9 @@
 package driver
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+)
 
 type InterfaceOptions struct {
 	Name        string
@@ -9,6 +12,7 @@ type InterfaceOptions struct {
 	DNS         []string
 	Mtu         int
 	Hooks       []*HookOptions
+	Firewall    *FirewallOptions
 }
 
 func (o InterfaceOptions) Validate() error {
@@ -23,5 +27,8 @@ func (o InterfaceOptions) Validate() error {
 			return err
 		}
 	}
+	if err := o.Firewall.Validate(); err != nil {
+		return fmt.Errorf("firewall: %w", err)
+	}
 	return nil
 }
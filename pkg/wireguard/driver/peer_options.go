@@ -1,6 +1,9 @@
 package driver
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+)
 
 type PeerOptions struct {
 	Name                string
@@ -10,6 +13,10 @@ type PeerOptions struct {
 	AllowedIPs          []string
 	PresharedKey        string
 	PersistentKeepalive int
+	// AllowedDestinations restricts where traffic from the peer is forwarded to, empty allows any destination.
+	AllowedDestinations []string
+	// ACL is evaluated before the allowed destinations.
+	ACL []*ACLRule
 }
 
 func (o *PeerOptions) Validate() error {
@@ -21,5 +28,15 @@ func (o *PeerOptions) Validate() error {
 		return errors.New("allowed ips are required")
 	}
 
+	if err := validateDestinations(o.AllowedDestinations); err != nil {
+		return err
+	}
+
+	for i, rule := range o.ACL {
+		if err := rule.Validate(); err != nil {
+			return fmt.Errorf("invalid acl rule #%d: %w", i+1, err)
+		}
+	}
+
 	return nil
 }
@@ -10,10 +10,27 @@ import (
 // Factory is a function that creates a new Backend instance
 type Factory func(ctx context.Context, rawURL string) (Backend, error)
 
-// Registration holds factory and support info for a backend type
+// Registration holds factory, support and capability info for a backend type
 type Registration struct {
-	Factory   Factory
-	Supported bool
+	Factory      Factory
+	Supported    bool
+	Capabilities Capabilities
+	// Remote reports that backends of the type manage the host addressed by the url,
+	// several backends of the type can be created as long as they target different hosts.
+	Remote bool
+	// DefaultPort is the port of remote backend urls that do not specify one.
+	DefaultPort string
+}
+
+// RegisterOption configures optional registration settings of a backend type.
+type RegisterOption func(registration *Registration)
+
+// RemoteTarget marks the backend type as managing the host addressed by the url.
+func RemoteTarget(defaultPort string) RegisterOption {
+	return func(registration *Registration) {
+		registration.Remote = true
+		registration.DefaultPort = defaultPort
+	}
 }
 
 var (
@@ -21,15 +38,21 @@ var (
 	registryMap = make(map[string]*Registration)
 )
 
-// Register registers a backend type with its factory and platform support status.
-func Register(scheme string, factory Factory, supported bool) {
+// Register registers a backend type with its factory, platform support status and capabilities.
+func Register(scheme string, factory Factory, supported bool, capabilities Capabilities, options ...RegisterOption) {
+	registration := &Registration{
+		Factory:      factory,
+		Supported:    supported,
+		Capabilities: capabilities,
+	}
+	for _, option := range options {
+		option(registration)
+	}
+
 	registryMu.Lock()
 	defer registryMu.Unlock()
 
-	registryMap[scheme] = &Registration{
-		Factory:   factory,
-		Supported: supported,
-	}
+	registryMap[scheme] = registration
 }
 
 // Get returns the registration for a backend type
@@ -53,6 +76,18 @@ func IsSupported(scheme string) bool {
 	return reg.Supported
 }
 
+// IsRemote checks if backends of the type manage the host addressed by their url
+func IsRemote(scheme string) bool {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	reg, ok := registryMap[scheme]
+	if !ok {
+		return false
+	}
+	return reg.Remote
+}
+
 // ListTypes returns all registered backend type names in sorted order
 func ListTypes() []string {
 	registryMu.RLock()
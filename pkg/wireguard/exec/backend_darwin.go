@@ -26,7 +26,7 @@ import (
 func Register() {
 	driver.Register("exec", func(_ context.Context, rawURL string) (driver.Backend, error) {
 		return NewExecBackend(rawURL)
-	}, isExecBackendAvailable())
+	}, isExecBackendAvailable(), execCapabilities(true))
 }
 
 func isExecBackendAvailable() bool {
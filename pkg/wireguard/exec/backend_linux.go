@@ -3,11 +3,10 @@
 package exec
 
 import (
+	"bytes"
 	"context"
-	"encoding/json"
 	"errors"
 	"fmt"
-	"net/netip"
 	"os"
 	osexec "os/exec"
 	"path/filepath"
@@ -15,7 +14,6 @@ import (
 	"strconv"
 	"strings"
 
-	"github.com/sirupsen/logrus"
 	"github.com/vishvananda/netlink"
 
 	"github.com/UnAfraid/wg-ui/pkg/backend"
@@ -26,7 +24,13 @@ import (
 func Register() {
 	driver.Register("exec", func(_ context.Context, rawURL string) (driver.Backend, error) {
 		return NewExecBackend(rawURL)
-	}, isExecBackendAvailable())
+	}, isExecBackendAvailable(), execCapabilities(isResolvconfAvailable()))
+}
+
+// isResolvconfAvailable reports whether wg-quick can apply the DNS servers of an interface.
+func isResolvconfAvailable() bool {
+	_, err := osexec.LookPath("resolvconf")
+	return err == nil
 }
 
 func isExecBackendAvailable() bool {
@@ -39,13 +43,7 @@ func isExecBackendAvailable() bool {
 }
 
 type execBackend struct {
-	configDir      string
-	useSudo        bool
-	sudoPath       string
-	wgPath         string
-	wgQuickPath    string
-	ipPath         string
-	resolvconfPath string
+	wgQuick
 }
 
 func NewExecBackend(rawURL string) (driver.Backend, error) {
@@ -80,17 +78,13 @@ func NewExecBackend(rawURL string) (driver.Backend, error) {
 		useSudo = parsedUseSudo
 	}
 
-	wgPath, err := osexec.LookPath("wg")
-	if err != nil {
-		return nil, fmt.Errorf("failed to find wg command: %w", err)
-	}
-	wgQuickPath, err := osexec.LookPath("wg-quick")
-	if err != nil {
-		return nil, fmt.Errorf("failed to find wg-quick command: %w", err)
-	}
-	ipPath, err := osexec.LookPath("ip")
-	if err != nil {
-		return nil, fmt.Errorf("failed to find ip command: %w", err)
+	binaryPaths := make(map[string]string)
+	for _, binary := range []string{"wg", "wg-quick", "ip"} {
+		binaryPath, err := osexec.LookPath(binary)
+		if err != nil {
+			return nil, fmt.Errorf("failed to find %s command: %w", binary, err)
+		}
+		binaryPaths[binary] = binaryPath
 	}
 	resolvconfPath, _ := osexec.LookPath("resolvconf")
 
@@ -103,24 +97,26 @@ func NewExecBackend(rawURL string) (driver.Backend, error) {
 	}
 
 	return &execBackend{
-		configDir:      configDir,
-		useSudo:        useSudo,
-		sudoPath:       sudoPath,
-		wgPath:         wgPath,
-		wgQuickPath:    wgQuickPath,
-		ipPath:         ipPath,
-		resolvconfPath: resolvconfPath,
+		wgQuick: wgQuick{
+			configDir: configDir,
+			runner: &localRunner{
+				useSudo:        useSudo,
+				sudoPath:       sudoPath,
+				binaryPaths:    binaryPaths,
+				resolvconfPath: resolvconfPath,
+			},
+		},
 	}, nil
 }
 
 func (b *execBackend) Device(ctx context.Context, name string) (*driver.Device, error) {
 	device, err := b.deviceFromDump(ctx, name)
 	if err != nil {
-		exists, existsErr := b.interfaceExists(name)
-		if existsErr != nil {
-			return nil, existsErr
+		link, linkErr := findInterface(name)
+		if linkErr != nil {
+			return nil, linkErr
 		}
-		if exists {
+		if link != nil {
 			return nil, err
 		}
 		return b.deviceFromConfigFile(ctx, name)
@@ -151,70 +147,10 @@ func (b *execBackend) Device(ctx context.Context, name string) (*driver.Device,
 }
 
 func (b *execBackend) Up(ctx context.Context, options driver.ConfigureOptions) (*driver.Device, error) {
-	if err := options.Validate(); err != nil {
-		return nil, err
-	}
-
-	name := options.InterfaceOptions.Name
-	configPath, err := b.writeConfig(ctx, options)
-	if err != nil {
-		return nil, err
-	}
-
-	exists, err := b.interfaceExists(name)
-	if err != nil {
+	if err := b.up(ctx, options); err != nil {
 		return nil, err
 	}
-
-	if !exists {
-		if _, err := b.runWGQuick(ctx, "up", configPath); err != nil {
-			return nil, fmt.Errorf("failed to bring interface up: %w", err)
-		}
-	} else {
-		if err := b.syncWithoutRestart(ctx, options, configPath); err != nil {
-			logrus.WithError(err).
-				WithField("interface", name).
-				Warn("live reconfiguration failed, restarting interface")
-
-			if downErr := b.down(ctx, name, configPath); downErr != nil {
-				logrus.WithError(downErr).
-					WithField("interface", name).
-					Warn("failed to stop interface during reconfiguration fallback")
-			}
-
-			if _, err := b.runWGQuick(ctx, "up", configPath); err != nil {
-				return nil, fmt.Errorf("failed to restart interface after live reconfiguration failure: %w", err)
-			}
-		}
-	}
-
-	return b.Device(ctx, name)
-}
-
-func (b *execBackend) Down(ctx context.Context, name string) error {
-	configPath := b.configFilePath(name)
-	return b.down(ctx, name, configPath)
-}
-
-func (b *execBackend) down(ctx context.Context, name string, configPath string) error {
-	exists, err := b.interfaceExists(name)
-	if err != nil {
-		return err
-	}
-	if !exists {
-		return nil
-	}
-
-	if _, err := b.runWGQuick(ctx, "down", configPath); err != nil {
-		if _, fallbackErr := b.runWGQuick(ctx, "down", name); fallbackErr != nil {
-			return err
-		}
-	}
-	return nil
-}
-
-func (b *execBackend) Status(_ context.Context, name string) (bool, error) {
-	return b.interfaceExists(name)
+	return b.Device(ctx, options.InterfaceOptions.Name)
 }
 
 func (b *execBackend) Stats(_ context.Context, name string) (*driver.InterfaceStats, error) {
@@ -229,21 +165,6 @@ func (b *execBackend) Stats(_ context.Context, name string) (*driver.InterfaceSt
 	return linkStatisticsToBackendInterfaceStats(link.Attrs().Statistics), nil
 }
 
-func (b *execBackend) PeerStats(ctx context.Context, name string, peerPublicKey string) (*driver.PeerStats, error) {
-	device, err := b.deviceFromDump(ctx, name)
-	if err != nil {
-		return nil, err
-	}
-
-	for _, p := range device.Wireguard.Peers {
-		if p.PublicKey == peerPublicKey {
-			return &p.Stats, nil
-		}
-	}
-
-	return nil, nil
-}
-
 func (b *execBackend) FindForeignServers(ctx context.Context, knownInterfaces []string) ([]*driver.ForeignServer, error) {
 	knownInterfaceSet := make(map[string]struct{}, len(knownInterfaces))
 	for _, name := range knownInterfaces {
@@ -303,75 +224,86 @@ func (b *execBackend) Close(_ context.Context) error {
 	return nil
 }
 
-func (b *execBackend) syncWithoutRestart(ctx context.Context, options driver.ConfigureOptions, configPath string) error {
-	name := options.InterfaceOptions.Name
-
-	strippedConfig, err := b.runWGQuick(ctx, "strip", configPath)
-	if err != nil {
-		return fmt.Errorf("failed to strip config for syncconf: %w", err)
-	}
-
-	tmpPath, err := writeTempFile(strippedConfig)
-	if err != nil {
-		return err
-	}
-	defer os.Remove(tmpPath)
+// localRunner runs the commands on this host, the files are reached through commands as well when sudo is used.
+type localRunner struct {
+	useSudo        bool
+	sudoPath       string
+	binaryPaths    map[string]string
+	resolvconfPath string
+}
 
-	if _, err := b.runWG(ctx, "syncconf", name, tmpPath); err != nil {
-		return fmt.Errorf("failed to apply syncconf: %w", err)
+func (r *localRunner) run(ctx context.Context, input []byte, binary string, args ...string) ([]byte, error) {
+	if binaryPath, ok := r.binaryPaths[binary]; ok {
+		binary = binaryPath
 	}
 
-	if err := b.reconcileAddress(ctx, name, options.InterfaceOptions.Address); err != nil {
-		return err
+	cmdName := binary
+	cmdArgs := args
+	if r.useSudo {
+		cmdName = r.sudoPath
+		cmdArgs = append([]string{"-n", binary}, args...)
 	}
 
-	if err := b.reconcileMTU(ctx, name, options.InterfaceOptions.Mtu); err != nil {
-		return err
+	cmd := osexec.CommandContext(ctx, cmdName, cmdArgs...)
+	if input != nil {
+		cmd.Stdin = bytes.NewReader(input)
 	}
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		trimmed := strings.TrimSpace(string(output))
+		if r.useSudo {
+			lower := strings.ToLower(trimmed)
+			if strings.Contains(lower, "a password is required") || strings.Contains(lower, "no tty present") {
+				return nil, fmt.Errorf("passwordless sudo is required for command %s %s", filepath.Base(binary), strings.Join(args, " "))
+			}
+		}
 
-	if err := b.reconcileRoutes(ctx, name, options.WireguardOptions.Peers); err != nil {
-		return err
+		if trimmed == "" {
+			return nil, fmt.Errorf("command failed: %s %s: %w", filepath.Base(binary), strings.Join(args, " "), err)
+		}
+		return nil, fmt.Errorf("command failed: %s %s: %w: %s", filepath.Base(binary), strings.Join(args, " "), err, trimmed)
 	}
 
-	return nil
+	return output, nil
 }
 
-func (b *execBackend) writeConfig(ctx context.Context, options driver.ConfigureOptions) (string, error) {
-	name := options.InterfaceOptions.Name
-	configPath := b.configFilePath(name)
-
-	configOptions := options
-	if len(configOptions.InterfaceOptions.DNS) > 0 && b.resolvconfPath == "" {
-		logrus.WithField("interface", name).
-			Warn("resolvconf command not found; omitting DNS configuration for wg-quick")
-		configOptions.InterfaceOptions.DNS = nil
+func (r *localRunner) interfaceExists(_ context.Context, name string) (bool, error) {
+	link, err := findInterface(name)
+	if err != nil {
+		return false, err
 	}
+	return link != nil, nil
+}
 
-	configContent := renderConfig(configOptions)
+func (r *localRunner) resolvconfAvailable(_ context.Context) bool {
+	return r.resolvconfPath != ""
+}
 
-	if b.useSudo {
-		tmpPath, err := writeTempFile([]byte(configContent))
+func (r *localRunner) writeFile(ctx context.Context, path string, content []byte) error {
+	dir := filepath.Dir(path)
+	if r.useSudo {
+		tmpPath, err := writeTempFile(content)
 		if err != nil {
-			return "", err
+			return err
 		}
 		defer os.Remove(tmpPath)
 
-		if _, err := b.runCommand(ctx, "install", "-d", "-m", "0755", b.configDir); err != nil {
-			return "", fmt.Errorf("failed to prepare config directory %s: %w", b.configDir, err)
+		if _, err := r.run(ctx, nil, "install", "-d", "-m", "0755", dir); err != nil {
+			return fmt.Errorf("failed to prepare config directory %s: %w", dir, err)
 		}
-		if _, err := b.runCommand(ctx, "install", "-m", "0600", tmpPath, configPath); err != nil {
-			return "", fmt.Errorf("failed to install config file %s: %w", configPath, err)
+		if _, err := r.run(ctx, nil, "install", "-m", "0600", tmpPath, path); err != nil {
+			return fmt.Errorf("failed to install config file %s: %w", path, err)
 		}
-		return configPath, nil
+		return nil
 	}
 
-	if err := os.MkdirAll(b.configDir, 0o755); err != nil {
-		return "", fmt.Errorf("failed to create config directory %s: %w", b.configDir, err)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create config directory %s: %w", dir, err)
 	}
 
-	tmpFile, err := os.CreateTemp(b.configDir, fmt.Sprintf(".%s-*.conf", name))
+	tmpFile, err := os.CreateTemp(dir, fmt.Sprintf(".%s-*", filepath.Base(path)))
 	if err != nil {
-		return "", fmt.Errorf("failed to create temporary config file: %w", err)
+		return fmt.Errorf("failed to create temporary config file: %w", err)
 	}
 	tmpPath := tmpFile.Name()
 
@@ -380,133 +312,49 @@ func (b *execBackend) writeConfig(ctx context.Context, options driver.ConfigureO
 		_ = os.Remove(tmpPath)
 	}
 
-	if _, err := tmpFile.WriteString(configContent); err != nil {
+	if _, err := tmpFile.Write(content); err != nil {
 		cleanup()
-		return "", fmt.Errorf("failed to write temporary config file: %w", err)
+		return fmt.Errorf("failed to write temporary config file: %w", err)
 	}
 	if err := tmpFile.Chmod(0o600); err != nil {
 		cleanup()
-		return "", fmt.Errorf("failed to chmod temporary config file: %w", err)
+		return fmt.Errorf("failed to chmod temporary config file: %w", err)
 	}
 	if err := tmpFile.Close(); err != nil {
 		cleanup()
-		return "", fmt.Errorf("failed to close temporary config file: %w", err)
+		return fmt.Errorf("failed to close temporary config file: %w", err)
 	}
 
-	if err := os.Rename(tmpPath, configPath); err != nil {
+	if err := os.Rename(tmpPath, path); err != nil {
 		cleanup()
-		return "", fmt.Errorf("failed to move config into place: %w", err)
+		return fmt.Errorf("failed to move config into place: %w", err)
 	}
-
-	return configPath, nil
+	return nil
 }
 
-func (b *execBackend) deviceFromConfigFile(ctx context.Context, name string) (*driver.Device, error) {
-	configPath := b.configFilePath(name)
-	configContent, err := b.readConfigFile(ctx, configPath)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read config file for interface %s: %w", name, err)
+func (r *localRunner) readFile(ctx context.Context, path string) ([]byte, error) {
+	if r.useSudo {
+		return catFile(ctx, r, path)
 	}
 
-	parsedConfig, err := parseConfigDevice(name, string(configContent))
-	if err != nil {
-		return nil, fmt.Errorf("failed to parse config file for interface %s: %w", name, err)
-	}
-
-	return parsedConfig.Device, nil
-}
-
-func (b *execBackend) findForeignServersFromConfigFiles(
-	ctx context.Context,
-	knownInterfaceSet map[string]struct{},
-	existingForeignNames map[string]struct{},
-) ([]*driver.ForeignServer, error) {
-	configFiles, err := b.listConfigFiles(ctx)
+	output, err := os.ReadFile(path)
 	if err != nil {
-		return nil, err
-	}
-
-	var foreignServers []*driver.ForeignServer
-	for _, configPath := range configFiles {
-		base := filepath.Base(configPath)
-		name := strings.TrimSuffix(base, ".conf")
-		if strings.TrimSpace(name) == "" || name == base {
-			continue
-		}
-		if _, ok := knownInterfaceSet[name]; ok {
-			continue
-		}
-		if _, ok := existingForeignNames[name]; ok {
-			continue
-		}
-
-		configContent, err := b.readConfigFile(ctx, configPath)
-		if err != nil {
-			logrus.WithError(err).
-				WithField("path", configPath).
-				Warn("failed to read foreign server config file")
-			continue
-		}
-
-		parsedConfig, err := parseConfigDevice(name, string(configContent))
-		if err != nil {
-			logrus.WithError(err).
-				WithField("path", configPath).
-				Warn("failed to parse foreign server config file")
-			continue
-		}
-
-		foreignServers = append(foreignServers, &driver.ForeignServer{
-			Interface: &driver.ForeignInterface{
-				Name:      parsedConfig.Device.Interface.Name,
-				Addresses: parsedConfig.Device.Interface.Addresses,
-				Mtu:       parsedConfig.Device.Interface.Mtu,
-				State:     "down",
-			},
-			Hooks:        parsedConfig.Hooks,
-			Name:         parsedConfig.Device.Wireguard.Name,
-			Description:  parsedConfig.Description,
-			Type:         "wireguard",
-			PublicKey:    parsedConfig.Device.Wireguard.PublicKey,
-			ListenPort:   parsedConfig.Device.Wireguard.ListenPort,
-			FirewallMark: parsedConfig.Device.Wireguard.FirewallMark,
-			Peers:        parsedConfig.Device.Wireguard.Peers,
-		})
+		return nil, fmt.Errorf("failed to read config file %s: %w", path, err)
 	}
-
-	return foreignServers, nil
+	return output, nil
 }
 
-func (b *execBackend) listConfigFiles(ctx context.Context) ([]string, error) {
-	if b.useSudo {
-		output, err := b.runCommand(ctx, "find", b.configDir, "-maxdepth", "1", "-type", "f", "-name", "*.conf", "-print")
-		if err != nil {
-			lower := strings.ToLower(err.Error())
-			if strings.Contains(lower, "no such file or directory") {
-				return nil, nil
-			}
-			return nil, fmt.Errorf("failed to list config files with sudo in %s: %w", b.configDir, err)
-		}
-
-		trimmed := strings.TrimSpace(string(output))
-		if trimmed == "" {
-			return nil, nil
-		}
-
-		files := strings.Split(trimmed, "\n")
-		for i, file := range files {
-			files[i] = strings.TrimSpace(file)
-		}
-		sort.Strings(files)
-		return files, nil
+func (r *localRunner) listConfigFiles(ctx context.Context, dir string) ([]string, error) {
+	if r.useSudo {
+		return findConfigFiles(ctx, r, dir)
 	}
 
-	entries, err := os.ReadDir(b.configDir)
+	entries, err := os.ReadDir(dir)
 	if err != nil {
 		if errors.Is(err, os.ErrNotExist) {
 			return nil, nil
 		}
-		return nil, fmt.Errorf("failed to list config files in %s: %w", b.configDir, err)
+		return nil, fmt.Errorf("failed to list config files in %s: %w", dir, err)
 	}
 
 	var files []string
@@ -514,276 +362,12 @@ func (b *execBackend) listConfigFiles(ctx context.Context) ([]string, error) {
 		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".conf") {
 			continue
 		}
-		files = append(files, filepath.Join(b.configDir, entry.Name()))
+		files = append(files, filepath.Join(dir, entry.Name()))
 	}
 	sort.Strings(files)
 	return files, nil
 }
 
-func (b *execBackend) readConfigFile(ctx context.Context, path string) ([]byte, error) {
-	if b.useSudo {
-		output, err := b.runCommand(ctx, "cat", path)
-		if err != nil {
-			return nil, fmt.Errorf("failed to read config file %s with sudo: %w", path, err)
-		}
-		return output, nil
-	}
-
-	output, err := os.ReadFile(path)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read config file %s: %w", path, err)
-	}
-	return output, nil
-}
-
-func (b *execBackend) reconcileAddress(ctx context.Context, name string, desiredAddress string) error {
-	state, err := b.getInterfaceState(ctx, name)
-	if err != nil {
-		return err
-	}
-
-	desired, err := normalizeCIDR(desiredAddress)
-	if err != nil {
-		return fmt.Errorf("failed to parse desired address %q: %w", desiredAddress, err)
-	}
-
-	current := make(map[string]struct{})
-	for _, info := range state.AddrInfo {
-		if info.Scope == "link" {
-			continue
-		}
-		if info.Family != "inet" && info.Family != "inet6" {
-			continue
-		}
-		if info.Local == "" || info.PrefixLen <= 0 {
-			continue
-		}
-
-		addr, err := normalizeCIDR(fmt.Sprintf("%s/%d", info.Local, info.PrefixLen))
-		if err != nil {
-			continue
-		}
-		current[addr] = struct{}{}
-	}
-
-	if _, ok := current[desired]; !ok {
-		if _, err := b.runIP(ctx, "address", "add", desired, "dev", name); err != nil {
-			return fmt.Errorf("failed to add interface address %s: %w", desired, err)
-		}
-	}
-
-	for addr := range current {
-		if addr == desired {
-			continue
-		}
-		if _, err := b.runIP(ctx, "address", "del", addr, "dev", name); err != nil {
-			return fmt.Errorf("failed to remove interface address %s: %w", addr, err)
-		}
-	}
-
-	return nil
-}
-
-func (b *execBackend) reconcileMTU(ctx context.Context, name string, desiredMTU int) error {
-	if desiredMTU <= 0 {
-		return nil
-	}
-
-	state, err := b.getInterfaceState(ctx, name)
-	if err != nil {
-		return err
-	}
-
-	if state.Mtu == desiredMTU {
-		return nil
-	}
-
-	if _, err := b.runIP(ctx, "link", "set", "dev", name, "mtu", strconv.Itoa(desiredMTU)); err != nil {
-		return fmt.Errorf("failed to set mtu to %d: %w", desiredMTU, err)
-	}
-	return nil
-}
-
-func (b *execBackend) reconcileRoutes(ctx context.Context, name string, peers []*driver.PeerOptions) error {
-	desiredIPv4 := make(map[string]struct{})
-	desiredIPv6 := make(map[string]struct{})
-
-	for _, p := range peers {
-		for _, allowedIP := range p.AllowedIPs {
-			prefix, err := normalizeCIDR(allowedIP)
-			if err != nil {
-				return fmt.Errorf("failed to parse allowed ip %q: %w", allowedIP, err)
-			}
-
-			parsed, err := netip.ParsePrefix(prefix)
-			if err != nil {
-				return fmt.Errorf("failed to parse normalized allowed ip %q: %w", prefix, err)
-			}
-
-			if parsed.Addr().Is4() {
-				desiredIPv4[prefix] = struct{}{}
-			} else {
-				desiredIPv6[prefix] = struct{}{}
-			}
-		}
-	}
-
-	for prefix := range desiredIPv4 {
-		if _, err := b.runIP(ctx, "-4", "route", "replace", prefix, "dev", name); err != nil {
-			return fmt.Errorf("failed to add or replace ipv4 route %s: %w", prefix, err)
-		}
-	}
-
-	for prefix := range desiredIPv6 {
-		if _, err := b.runIP(ctx, "-6", "route", "replace", prefix, "dev", name); err != nil {
-			return fmt.Errorf("failed to add or replace ipv6 route %s: %w", prefix, err)
-		}
-	}
-
-	currentIPv4, err := b.listRoutes(ctx, name, false)
-	if err != nil {
-		return err
-	}
-	currentIPv6, err := b.listRoutes(ctx, name, true)
-	if err != nil {
-		return err
-	}
-
-	for prefix := range currentIPv4 {
-		if _, ok := desiredIPv4[prefix]; ok {
-			continue
-		}
-		if _, err := b.runIP(ctx, "-4", "route", "del", prefix, "dev", name); err != nil {
-			return fmt.Errorf("failed to remove ipv4 route %s: %w", prefix, err)
-		}
-	}
-
-	for prefix := range currentIPv6 {
-		if _, ok := desiredIPv6[prefix]; ok {
-			continue
-		}
-		if _, err := b.runIP(ctx, "-6", "route", "del", prefix, "dev", name); err != nil {
-			return fmt.Errorf("failed to remove ipv6 route %s: %w", prefix, err)
-		}
-	}
-
-	return nil
-}
-
-func (b *execBackend) listRoutes(ctx context.Context, name string, ipv6 bool) (map[string]struct{}, error) {
-	familyFlag := "-4"
-	defaultPrefix := "0.0.0.0/0"
-	if ipv6 {
-		familyFlag = "-6"
-		defaultPrefix = "::/0"
-	}
-
-	output, err := b.runIP(ctx, "-j", familyFlag, "route", "show", "dev", name)
-	if err != nil {
-		return nil, fmt.Errorf("failed to list routes for %s: %w", name, err)
-	}
-
-	var routes []ipRouteState
-	if err := json.Unmarshal(output, &routes); err != nil {
-		return nil, fmt.Errorf("failed to decode route list: %w", err)
-	}
-
-	result := make(map[string]struct{})
-	for _, route := range routes {
-		dst := strings.TrimSpace(route.Dst)
-		if dst == "" {
-			continue
-		}
-		if dst == "default" {
-			dst = defaultPrefix
-		}
-		prefix, err := normalizeCIDR(dst)
-		if err != nil {
-			continue
-		}
-		result[prefix] = struct{}{}
-	}
-
-	return result, nil
-}
-
-func (b *execBackend) getInterfaceState(ctx context.Context, name string) (*ipInterfaceState, error) {
-	output, err := b.runIP(ctx, "-j", "address", "show", "dev", name)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get interface state for %s: %w", name, err)
-	}
-
-	var states []ipInterfaceState
-	if err := json.Unmarshal(output, &states); err != nil {
-		return nil, fmt.Errorf("failed to decode interface state for %s: %w", name, err)
-	}
-	if len(states) == 0 {
-		return nil, fmt.Errorf("interface state for %s not found", name)
-	}
-
-	return &states[0], nil
-}
-
-func (b *execBackend) deviceFromDump(ctx context.Context, name string) (*driver.Device, error) {
-	output, err := b.runWG(ctx, "show", name, "dump")
-	if err != nil {
-		return nil, fmt.Errorf("failed to read device dump for %s: %w", name, err)
-	}
-	return parseDeviceDump(name, string(output))
-}
-
-func (b *execBackend) interfaceExists(name string) (bool, error) {
-	link, err := findInterface(name)
-	if err != nil {
-		return false, err
-	}
-	return link != nil, nil
-}
-
-func (b *execBackend) configFilePath(name string) string {
-	return filepath.Join(b.configDir, fmt.Sprintf("%s.conf", name))
-}
-
-func (b *execBackend) runWG(ctx context.Context, args ...string) ([]byte, error) {
-	return b.runCommand(ctx, b.wgPath, args...)
-}
-
-func (b *execBackend) runWGQuick(ctx context.Context, args ...string) ([]byte, error) {
-	return b.runCommand(ctx, b.wgQuickPath, args...)
-}
-
-func (b *execBackend) runIP(ctx context.Context, args ...string) ([]byte, error) {
-	return b.runCommand(ctx, b.ipPath, args...)
-}
-
-func (b *execBackend) runCommand(ctx context.Context, binary string, args ...string) ([]byte, error) {
-	cmdName := binary
-	cmdArgs := args
-	if b.useSudo {
-		cmdName = b.sudoPath
-		cmdArgs = append([]string{"-n", binary}, args...)
-	}
-
-	cmd := osexec.CommandContext(ctx, cmdName, cmdArgs...)
-	output, err := cmd.CombinedOutput()
-	if err != nil {
-		trimmed := strings.TrimSpace(string(output))
-		if b.useSudo {
-			lower := strings.ToLower(trimmed)
-			if strings.Contains(lower, "a password is required") || strings.Contains(lower, "no tty present") {
-				return nil, fmt.Errorf("passwordless sudo is required for command %s %s", filepath.Base(binary), strings.Join(args, " "))
-			}
-		}
-
-		if trimmed == "" {
-			return nil, fmt.Errorf("command failed: %s %s: %w", filepath.Base(binary), strings.Join(args, " "), err)
-		}
-		return nil, fmt.Errorf("command failed: %s %s: %w: %s", filepath.Base(binary), strings.Join(args, " "), err, trimmed)
-	}
-
-	return output, nil
-}
-
 func findInterface(name string) (netlink.Link, error) {
 	link, err := netlink.LinkByName(name)
 	if err != nil {
@@ -827,19 +411,3 @@ func linkStatisticsToBackendInterfaceStats(statistics *netlink.LinkStatistics) *
 		TxBytes: statistics.TxBytes,
 	}
 }
-
-type ipInterfaceState struct {
-	Mtu      int             `json:"mtu"`
-	AddrInfo []ipAddressInfo `json:"addr_info"`
-}
-
-type ipAddressInfo struct {
-	Family    string `json:"family"`
-	Local     string `json:"local"`
-	PrefixLen int    `json:"prefixlen"`
-	Scope     string `json:"scope"`
-}
-
-type ipRouteState struct {
-	Dst string `json:"dst"`
-}
@@ -172,14 +172,14 @@ func TestRunCommandSudoPrefix(t *testing.T) {
 		t.Fatalf("failed to create fake sudo command: %v", err)
 	}
 
-	backend := &execBackend{
+	runner := &localRunner{
 		useSudo:  true,
 		sudoPath: fakeSudoPath,
 	}
 
-	output, err := backend.runCommand(context.Background(), "wg", "show")
+	output, err := runner.run(context.Background(), nil, "wg", "show")
 	if err != nil {
-		t.Fatalf("runCommand returned error: %v", err)
+		t.Fatalf("run returned error: %v", err)
 	}
 
 	if strings.TrimSpace(string(output)) != "-n\nwg\nshow" {
@@ -273,8 +273,9 @@ PersistentKeepalive = 20
 
 func TestWriteConfigOmitsDNSWithoutResolvconf(t *testing.T) {
 	tmpDir := t.TempDir()
-	backend := &execBackend{
+	backend := &wgQuick{
 		configDir: tmpDir,
+		runner:    &localRunner{},
 	}
 
 	configPath, err := backend.writeConfig(context.Background(), driver.ConfigureOptions{
@@ -303,9 +304,11 @@ func TestWriteConfigOmitsDNSWithoutResolvconf(t *testing.T) {
 
 func TestWriteConfigIncludesDNSWithResolvconf(t *testing.T) {
 	tmpDir := t.TempDir()
-	backend := &execBackend{
-		configDir:      tmpDir,
-		resolvconfPath: "/usr/bin/resolvconf",
+	backend := &wgQuick{
+		configDir: tmpDir,
+		runner: &localRunner{
+			resolvconfPath: "/usr/bin/resolvconf",
+		},
 	}
 
 	configPath, err := backend.writeConfig(context.Background(), driver.ConfigureOptions{
@@ -351,8 +354,14 @@ ListenPort = 51820
 	}
 
 	execBackend := &execBackend{
-		configDir: tmpDir,
-		wgPath:    "/command/that/does/not/exist",
+		wgQuick: wgQuick{
+			configDir: tmpDir,
+			runner: &localRunner{
+				binaryPaths: map[string]string{
+					"wg": "/command/that/does/not/exist",
+				},
+			},
+		},
 	}
 
 	device, err := execBackend.Device(context.Background(), name)
@@ -12,7 +12,7 @@ import (
 func Register() {
 	driver.Register("exec", func(_ context.Context, rawURL string) (driver.Backend, error) {
 		return NewExecBackend(rawURL)
-	}, false)
+	}, false, execCapabilities(false))
 }
 
 func NewExecBackend(_ string) (driver.Backend, error) {
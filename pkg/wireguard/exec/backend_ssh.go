@@ -0,0 +1,342 @@
+package exec
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/UnAfraid/wg-ui/pkg/wireguard/driver"
+)
+
+const defaultSSHPort = "22"
+
+// RegisterSSH registers the ssh backend, it runs the exec backend commands on a remote linux host.
+func RegisterSSH() {
+	driver.Register("ssh", func(_ context.Context, rawURL string) (driver.Backend, error) {
+		return NewSSHBackend(rawURL)
+	}, true, execCapabilities(true), driver.RemoteTarget(defaultSSHPort))
+}
+
+type sshBackend struct {
+	wgQuick
+	endpoint driver.Endpoint
+	client   *sshClient
+}
+
+func NewSSHBackend(rawURL string) (driver.Backend, error) {
+	parsed, err := parseSSHURL(rawURL)
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := newSSHClient(parsed)
+	if err != nil {
+		return nil, err
+	}
+
+	host, port, err := net.SplitHostPort(parsed.address)
+	if err != nil {
+		return nil, fmt.Errorf("invalid ssh backend address: %w", err)
+	}
+
+	return &sshBackend{
+		wgQuick: wgQuick{
+			configDir: parsed.configDir,
+			runner: &sshRunner{
+				client:  client,
+				useSudo: parsed.useSudo,
+			},
+		},
+		endpoint: driver.Endpoint{
+			Host: host,
+			Port: port,
+		},
+		client: client,
+	}, nil
+}
+
+func (b *sshBackend) Device(ctx context.Context, name string) (*driver.Device, error) {
+	device, err := b.deviceFromDump(ctx, name)
+	if err != nil {
+		link, linkErr := findLink(ctx, b.runIP, name)
+		if linkErr != nil {
+			return nil, linkErr
+		}
+		if link != nil {
+			return nil, err
+		}
+		return b.deviceFromConfigFile(ctx, name)
+	}
+
+	state, err := ipRoute2(b.runIP).getInterfaceState(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+
+	device.Interface = driver.Interface{
+		Name:      state.Name,
+		Addresses: interfaceStateAddresses(state),
+		Mtu:       state.Mtu,
+	}
+	return device, nil
+}
+
+func (b *sshBackend) Up(ctx context.Context, options driver.ConfigureOptions) (*driver.Device, error) {
+	if err := b.up(ctx, options); err != nil {
+		return nil, err
+	}
+	return b.Device(ctx, options.InterfaceOptions.Name)
+}
+
+func (b *sshBackend) Stats(ctx context.Context, name string) (*driver.InterfaceStats, error) {
+	link, err := findLink(ctx, b.runIP, name)
+	if err != nil {
+		return nil, err
+	}
+	if link == nil {
+		return nil, nil
+	}
+
+	return &driver.InterfaceStats{
+		RxBytes: link.Stats64.Rx.Bytes,
+		TxBytes: link.Stats64.Tx.Bytes,
+	}, nil
+}
+
+func (b *sshBackend) FindForeignServers(ctx context.Context, knownInterfaces []string) ([]*driver.ForeignServer, error) {
+	knownInterfaceSet := make(map[string]struct{}, len(knownInterfaces))
+	for _, name := range knownInterfaces {
+		knownInterfaceSet[name] = struct{}{}
+	}
+
+	links, err := ipRoute2(b.runIP).listWireguardLinks(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	existingForeignNames := make(map[string]struct{})
+	var foreignServers []*driver.ForeignServer
+	for _, link := range links {
+		if _, ok := knownInterfaceSet[link.Name]; ok {
+			continue
+		}
+
+		state, err := ipRoute2(b.runIP).getInterfaceState(ctx, link.Name)
+		if err != nil {
+			return nil, err
+		}
+
+		device, err := b.deviceFromDump(ctx, link.Name)
+		if err != nil {
+			return nil, err
+		}
+
+		foreignServers = append(foreignServers, &driver.ForeignServer{
+			Interface: &driver.ForeignInterface{
+				Name:      link.Name,
+				Addresses: interfaceStateAddresses(state),
+				Mtu:       link.Mtu,
+				State:     strings.ToLower(link.OperState),
+			},
+			Name:         device.Wireguard.Name,
+			Type:         "wireguard",
+			PublicKey:    device.Wireguard.PublicKey,
+			ListenPort:   device.Wireguard.ListenPort,
+			FirewallMark: device.Wireguard.FirewallMark,
+			Peers:        device.Wireguard.Peers,
+		})
+		existingForeignNames[link.Name] = struct{}{}
+	}
+
+	fileForeignServers, err := b.findForeignServersFromConfigFiles(ctx, knownInterfaceSet, existingForeignNames)
+	if err != nil {
+		return nil, err
+	}
+	foreignServers = append(foreignServers, fileForeignServers...)
+
+	return foreignServers, nil
+}
+
+func (b *sshBackend) Endpoint() driver.Endpoint {
+	return b.endpoint
+}
+
+// Version returns the version of the wireguard tools installed on the host.
+func (b *sshBackend) Version(ctx context.Context) (string, error) {
+	output, err := b.runner.run(ctx, nil, "wg", "--version")
+	if err != nil {
+		return "", err
+	}
+	version, _, _ := strings.Cut(strings.TrimSpace(string(output)), " - ")
+	return version, nil
+}
+
+func (b *sshBackend) Close(_ context.Context) error {
+	return b.client.close()
+}
+
+// sshRunner runs the commands on the remote host, the files are reached through commands as well.
+type sshRunner struct {
+	client  *sshClient
+	useSudo bool
+}
+
+func (r *sshRunner) run(ctx context.Context, input []byte, binary string, args ...string) ([]byte, error) {
+	command := shellQuote(binary, args...)
+	if r.useSudo {
+		command = "sudo -n " + command
+	}
+
+	output, err := r.client.run(ctx, command, input)
+	if err != nil {
+		trimmed := strings.TrimSpace(string(output))
+		if r.useSudo {
+			lower := strings.ToLower(trimmed)
+			if strings.Contains(lower, "a password is required") || strings.Contains(lower, "no tty present") {
+				return nil, fmt.Errorf("%w: passwordless sudo is required for command %s %s", driver.ErrPermissionDenied, binary, strings.Join(args, " "))
+			}
+		}
+
+		if trimmed == "" {
+			return nil, fmt.Errorf("command failed: %s %s: %w", binary, strings.Join(args, " "), err)
+		}
+		return nil, fmt.Errorf("command failed: %s %s: %w: %s", binary, strings.Join(args, " "), err, trimmed)
+	}
+
+	return output, nil
+}
+
+func (r *sshRunner) interfaceExists(ctx context.Context, name string) (bool, error) {
+	link, err := findLink(ctx, func(ctx context.Context, args ...string) ([]byte, error) {
+		return r.run(ctx, nil, "ip", args...)
+	}, name)
+	if err != nil {
+		return false, err
+	}
+	return link != nil, nil
+}
+
+func (r *sshRunner) resolvconfAvailable(ctx context.Context) bool {
+	_, err := r.run(ctx, nil, "sh", "-c", "command -v resolvconf")
+	return err == nil
+}
+
+// writeFile streams the content to the remote host, it is written to a temporary file first so that
+// wg-quick never reads a partially written config.
+func (r *sshRunner) writeFile(ctx context.Context, filePath string, content []byte) error {
+	const script = `umask 077 && mkdir -p "$1" && cat > "$2.tmp" && mv "$2.tmp" "$2"`
+	_, err := r.run(ctx, content, "sh", "-c", script, "sh", path.Dir(filePath), filePath)
+	return err
+}
+
+func (r *sshRunner) readFile(ctx context.Context, filePath string) ([]byte, error) {
+	return catFile(ctx, r, filePath)
+}
+
+func (r *sshRunner) listConfigFiles(ctx context.Context, dir string) ([]string, error) {
+	return findConfigFiles(ctx, r, dir)
+}
+
+// findLink returns the wireguard link with the name, nil when there is none.
+func findLink(ctx context.Context, ip ipRoute2, name string) (*ipLinkState, error) {
+	links, err := ip.listWireguardLinks(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for i := range links {
+		if links[i].Name == name {
+			return &links[i], nil
+		}
+	}
+	return nil, nil
+}
+
+func interfaceStateAddresses(state *ipInterfaceState) []string {
+	var addresses []string
+	for _, info := range state.AddrInfo {
+		if info.Local == "" || info.PrefixLen <= 0 {
+			continue
+		}
+		addresses = append(addresses, fmt.Sprintf("%s/%d", info.Local, info.PrefixLen))
+	}
+	return addresses
+}
+
+// shellQuote renders the command for the remote shell, every argument is single quoted.
+func shellQuote(binary string, args ...string) string {
+	quoted := make([]string, 0, len(args)+1)
+	for _, arg := range append([]string{binary}, args...) {
+		quoted = append(quoted, "'"+strings.ReplaceAll(arg, "'", `'\''`)+"'")
+	}
+	return strings.Join(quoted, " ")
+}
+
+type parsedSSHURL struct {
+	address            string
+	user               string
+	password           string
+	keyFile            string
+	knownHostsFile     string
+	hostKeyFingerprint string
+	configDir          string
+	useSudo            bool
+}
+
+func parseSSHURL(rawURL string) (*parsedSSHURL, error) {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid ssh backend url: %w", err)
+	}
+
+	if !strings.EqualFold(parsed.Scheme, "ssh") {
+		return nil, fmt.Errorf("invalid backend type: %s", parsed.Scheme)
+	}
+
+	host := strings.TrimSpace(parsed.Hostname())
+	if host == "" {
+		return nil, errors.New("ssh backend requires host")
+	}
+	port := strings.TrimSpace(parsed.Port())
+	if port == "" {
+		port = defaultSSHPort
+	}
+
+	if parsed.User == nil || strings.TrimSpace(parsed.User.Username()) == "" {
+		return nil, errors.New("ssh backend requires user")
+	}
+	password, _ := parsed.User.Password()
+
+	configDir := strings.TrimSpace(parsed.Path)
+	if configDir == "" || configDir == "/" {
+		configDir = defaultConfigDir
+	}
+	if !path.IsAbs(configDir) {
+		return nil, fmt.Errorf("ssh backend path must be absolute: %q", configDir)
+	}
+
+	query := parsed.Query()
+	useSudo := false
+	if rawUseSudo := strings.TrimSpace(query.Get("sudo")); rawUseSudo != "" {
+		useSudo, err = strconv.ParseBool(rawUseSudo)
+		if err != nil {
+			return nil, fmt.Errorf("invalid sudo option %q: %w", rawUseSudo, err)
+		}
+	}
+
+	// The base64 fingerprint may contain a "+", which is decoded as a space when the url is not escaped.
+	return &parsedSSHURL{
+		address:            net.JoinHostPort(host, port),
+		user:               strings.TrimSpace(parsed.User.Username()),
+		password:           password,
+		keyFile:            strings.TrimSpace(query.Get("key")),
+		knownHostsFile:     strings.TrimSpace(query.Get("knownHosts")),
+		hostKeyFingerprint: strings.ReplaceAll(strings.TrimSpace(query.Get("hostKey")), " ", "+"),
+		configDir:          path.Clean(configDir),
+		useSudo:            useSudo,
+	}, nil
+}
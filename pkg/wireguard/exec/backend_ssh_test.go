@@ -0,0 +1,304 @@
+package exec
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/pem"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+
+	"github.com/UnAfraid/wg-ui/pkg/wireguard/driver"
+)
+
+type sshCommand struct {
+	command string
+	input   string
+}
+
+// testSSHServer accepts the client key and answers exec requests with the handler output.
+type testSSHServer struct {
+	address     string
+	hostKey     ssh.PublicKey
+	connections atomic.Int32
+
+	mu       sync.Mutex
+	commands []sshCommand
+}
+
+func newTestSSHServer(t *testing.T, clientKey ssh.PublicKey, handler func(command string) (string, uint32)) *testSSHServer {
+	t.Helper()
+
+	_, hostPrivateKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate host key: %v", err)
+	}
+	hostSigner, err := ssh.NewSignerFromKey(hostPrivateKey)
+	if err != nil {
+		t.Fatalf("failed to create host signer: %v", err)
+	}
+
+	config := &ssh.ServerConfig{
+		PublicKeyCallback: func(_ ssh.ConnMetadata, key ssh.PublicKey) (*ssh.Permissions, error) {
+			if string(key.Marshal()) != string(clientKey.Marshal()) {
+				return nil, io.ErrUnexpectedEOF
+			}
+			return nil, nil
+		},
+	}
+	config.AddHostKey(hostSigner)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	t.Cleanup(func() { _ = listener.Close() })
+
+	server := &testSSHServer{
+		address: listener.Addr().String(),
+		hostKey: hostSigner.PublicKey(),
+	}
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			server.connections.Add(1)
+			go server.serve(conn, config, handler)
+		}
+	}()
+
+	return server
+}
+
+func (s *testSSHServer) serve(conn net.Conn, config *ssh.ServerConfig, handler func(command string) (string, uint32)) {
+	_, channels, requests, err := ssh.NewServerConn(conn, config)
+	if err != nil {
+		return
+	}
+	go ssh.DiscardRequests(requests)
+
+	for newChannel := range channels {
+		channel, channelRequests, err := newChannel.Accept()
+		if err != nil {
+			return
+		}
+
+		go func() {
+			defer channel.Close()
+			for request := range channelRequests {
+				if request.Type != "exec" {
+					_ = request.Reply(false, nil)
+					continue
+				}
+				_ = request.Reply(true, nil)
+
+				command := string(request.Payload[4:])
+				input, _ := io.ReadAll(channel)
+
+				s.mu.Lock()
+				s.commands = append(s.commands, sshCommand{command: command, input: string(input)})
+				s.mu.Unlock()
+
+				output, status := handler(command)
+				_, _ = channel.Write([]byte(output))
+				_, _ = channel.SendRequest("exit-status", false, binary.BigEndian.AppendUint32(nil, status))
+				return
+			}
+		}()
+	}
+}
+
+func (s *testSSHServer) recordedCommands() []sshCommand {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]sshCommand(nil), s.commands...)
+}
+
+func writeTestSSHKey(t *testing.T) (string, ssh.PublicKey) {
+	t.Helper()
+
+	_, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate client key: %v", err)
+	}
+	block, err := ssh.MarshalPrivateKey(privateKey, "")
+	if err != nil {
+		t.Fatalf("failed to marshal client key: %v", err)
+	}
+	signer, err := ssh.NewSignerFromKey(privateKey)
+	if err != nil {
+		t.Fatalf("failed to create client signer: %v", err)
+	}
+
+	keyFile := filepath.Join(t.TempDir(), "id_ed25519")
+	if err := os.WriteFile(keyFile, pem.EncodeToMemory(block), 0o600); err != nil {
+		t.Fatalf("failed to write client key: %v", err)
+	}
+	return keyFile, signer.PublicKey()
+}
+
+func writeTestKnownHosts(t *testing.T, address string, key ssh.PublicKey) string {
+	t.Helper()
+
+	knownHostsFile := filepath.Join(t.TempDir(), "known_hosts")
+	line := knownhosts.Line([]string{knownhosts.Normalize(address)}, key)
+	if err := os.WriteFile(knownHostsFile, []byte(line+"\n"), 0o600); err != nil {
+		t.Fatalf("failed to write known hosts: %v", err)
+	}
+	return knownHostsFile
+}
+
+func TestParseSSHURL(t *testing.T) {
+	parsed, err := parseSSHURL("ssh://root@vps.example.com?key=/root/.ssh/id_ed25519&sudo=true")
+	if err != nil {
+		t.Fatalf("parseSSHURL returned error: %v", err)
+	}
+	if parsed.address != "vps.example.com:22" {
+		t.Fatalf("unexpected address: %q", parsed.address)
+	}
+	if parsed.configDir != defaultConfigDir {
+		t.Fatalf("unexpected config dir: %q", parsed.configDir)
+	}
+	if parsed.keyFile != "/root/.ssh/id_ed25519" || !parsed.useSudo {
+		t.Fatalf("unexpected options: %+v", parsed)
+	}
+
+	if _, err := parseSSHURL("ssh://vps.example.com/etc/wireguard"); err == nil {
+		t.Fatalf("expected missing user to fail")
+	}
+	if _, err := parseSSHURL("ssh://root@vps.example.com/etc/wireguard?sudo=maybe"); err == nil {
+		t.Fatalf("expected invalid sudo option to fail")
+	}
+}
+
+func TestShellQuote(t *testing.T) {
+	if got := shellQuote("sh", "-c", "echo 'hi'"); got != `'sh' '-c' 'echo '\''hi'\'''` {
+		t.Fatalf("unexpected quoted command: %s", got)
+	}
+}
+
+func TestSSHBackendRunsCommandsOverSSH(t *testing.T) {
+	keyFile, clientKey := writeTestSSHKey(t)
+	server := newTestSSHServer(t, clientKey, func(command string) (string, uint32) {
+		switch {
+		case strings.Contains(command, "'link' 'show' 'type' 'wireguard'"):
+			return `[{"ifname":"wg0","mtu":1420,"operstate":"UNKNOWN","stats64":{"rx":{"bytes":10},"tx":{"bytes":20}}}]`, 0
+		case strings.Contains(command, "'wg' 'show' 'wg1' 'dump'"):
+			return "private-key\tpublic-key\t51820\toff\n", 0
+		case strings.Contains(command, "'address' 'show' 'dev' 'wg1'"):
+			return `[{"ifname":"wg1","mtu":1420,"addr_info":[{"family":"inet","local":"10.0.1.1","prefixlen":24,"scope":"global"}]}]`, 0
+		case strings.HasPrefix(command, "sudo -n 'sh' '-c' 'command -v resolvconf'"):
+			return "", 1
+		default:
+			return "", 0
+		}
+	})
+	knownHostsFile := writeTestKnownHosts(t, server.address, server.hostKey)
+
+	backend, err := NewSSHBackend("ssh://root@" + server.address + "/etc/wireguard?sudo=true&key=" + keyFile + "&knownHosts=" + knownHostsFile)
+	if err != nil {
+		t.Fatalf("NewSSHBackend returned error: %v", err)
+	}
+	t.Cleanup(func() { _ = backend.Close(context.Background()) })
+
+	ctx := context.Background()
+	up, err := backend.Status(ctx, "wg0")
+	if err != nil || !up {
+		t.Fatalf("expected wg0 to be up, got %v, %v", up, err)
+	}
+
+	stats, err := backend.Stats(ctx, "wg0")
+	if err != nil {
+		t.Fatalf("Stats returned error: %v", err)
+	}
+	if stats.RxBytes != 10 || stats.TxBytes != 20 {
+		t.Fatalf("unexpected stats: %+v", stats)
+	}
+
+	device, err := backend.Up(ctx, driver.ConfigureOptions{
+		InterfaceOptions: driver.InterfaceOptions{
+			Name:    "wg1",
+			Address: "10.0.1.1/24",
+			DNS:     []string{"1.1.1.1"},
+		},
+		WireguardOptions: driver.WireguardOptions{
+			PrivateKey: "private-key",
+		},
+	})
+	if err != nil {
+		t.Fatalf("Up returned error: %v", err)
+	}
+	if device.Interface.Name != "wg1" || len(device.Interface.Addresses) != 1 || device.Interface.Addresses[0] != "10.0.1.1/24" {
+		t.Fatalf("unexpected device: %+v", device.Interface)
+	}
+
+	var configWrite, wgQuickUp *sshCommand
+	commands := server.recordedCommands()
+	for i, command := range commands {
+		if !strings.HasPrefix(command.command, "sudo -n ") {
+			t.Fatalf("expected command to run with sudo: %s", command.command)
+		}
+		switch {
+		case strings.Contains(command.command, "'/etc/wireguard/wg1.conf'") && strings.Contains(command.command, "'sh' '-c' 'umask 077"):
+			configWrite = &commands[i]
+		case strings.Contains(command.command, "'wg-quick' 'up' '/etc/wireguard/wg1.conf'"):
+			wgQuickUp = &commands[i]
+		}
+	}
+	if configWrite == nil || !strings.Contains(configWrite.input, "PrivateKey = private-key") {
+		t.Fatalf("expected the config to be streamed to the remote host, got %+v", commands)
+	}
+	if strings.Contains(configWrite.input, "DNS =") {
+		t.Fatalf("expected DNS to be omitted without resolvconf, got:\n%s", configWrite.input)
+	}
+	if wgQuickUp == nil {
+		t.Fatalf("expected wg-quick up to be run, got %+v", commands)
+	}
+
+	if connections := server.connections.Load(); connections != 1 {
+		t.Fatalf("expected the ssh connection to be reused, got %d connections", connections)
+	}
+}
+
+func TestSSHBackendRejectsUnknownHostKey(t *testing.T) {
+	keyFile, clientKey := writeTestSSHKey(t)
+	server := newTestSSHServer(t, clientKey, func(string) (string, uint32) {
+		return "[]", 0
+	})
+
+	_, otherHostKey := writeTestSSHKey(t)
+	knownHostsFile := writeTestKnownHosts(t, server.address, otherHostKey)
+
+	backend, err := NewSSHBackend("ssh://root@" + server.address + "?key=" + keyFile + "&knownHosts=" + knownHostsFile)
+	if err != nil {
+		t.Fatalf("NewSSHBackend returned error: %v", err)
+	}
+	t.Cleanup(func() { _ = backend.Close(context.Background()) })
+
+	if _, err := backend.Status(context.Background(), "wg0"); err == nil || !strings.Contains(err.Error(), "key mismatch") {
+		t.Fatalf("expected host key verification to fail, got %v", err)
+	}
+
+	pinned, err := NewSSHBackend("ssh://root@" + server.address + "?key=" + keyFile + "&hostKey=" + ssh.FingerprintSHA256(server.hostKey))
+	if err != nil {
+		t.Fatalf("NewSSHBackend returned error: %v", err)
+	}
+	t.Cleanup(func() { _ = pinned.Close(context.Background()) })
+
+	if _, err := pinned.Status(context.Background(), "wg0"); err != nil {
+		t.Fatalf("expected the pinned host key to be accepted, got %v", err)
+	}
+}
@@ -18,6 +18,17 @@ import (
 
 const defaultConfigDir = "/etc/wireguard"
 
+// execCapabilities are the capabilities of wg-quick, which runs the raw command hooks as part of the configuration.
+func execCapabilities(dns bool) driver.Capabilities {
+	return driver.Capabilities{
+		HookExecution:   true,
+		DNS:             dns,
+		LivePeerUpdates: true,
+		ForeignImport:   true,
+		PeerStats:       true,
+	}
+}
+
 func writeTempFile(content []byte) (string, error) {
 	tmpFile, err := os.CreateTemp("", "wg-ui-*.conf")
 	if err != nil {
@@ -0,0 +1,249 @@
+package exec
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/netip"
+	"strconv"
+	"strings"
+
+	"github.com/UnAfraid/wg-ui/pkg/wireguard/driver"
+)
+
+// ipRoute2 runs an ip command, the interface settings are reconciled through its json output by the backends
+// that cannot use netlink directly.
+type ipRoute2 func(ctx context.Context, args ...string) ([]byte, error)
+
+func (ip ipRoute2) reconcileAddress(ctx context.Context, name string, desiredAddress string) error {
+	state, err := ip.getInterfaceState(ctx, name)
+	if err != nil {
+		return err
+	}
+
+	desired, err := normalizeCIDR(desiredAddress)
+	if err != nil {
+		return fmt.Errorf("failed to parse desired address %q: %w", desiredAddress, err)
+	}
+
+	current := make(map[string]struct{})
+	for _, info := range state.AddrInfo {
+		if info.Scope == "link" {
+			continue
+		}
+		if info.Family != "inet" && info.Family != "inet6" {
+			continue
+		}
+		if info.Local == "" || info.PrefixLen <= 0 {
+			continue
+		}
+
+		addr, err := normalizeCIDR(fmt.Sprintf("%s/%d", info.Local, info.PrefixLen))
+		if err != nil {
+			continue
+		}
+		current[addr] = struct{}{}
+	}
+
+	if _, ok := current[desired]; !ok {
+		if _, err := ip(ctx, "address", "add", desired, "dev", name); err != nil {
+			return fmt.Errorf("failed to add interface address %s: %w", desired, err)
+		}
+	}
+
+	for addr := range current {
+		if addr == desired {
+			continue
+		}
+		if _, err := ip(ctx, "address", "del", addr, "dev", name); err != nil {
+			return fmt.Errorf("failed to remove interface address %s: %w", addr, err)
+		}
+	}
+
+	return nil
+}
+
+func (ip ipRoute2) reconcileMTU(ctx context.Context, name string, desiredMTU int) error {
+	if desiredMTU <= 0 {
+		return nil
+	}
+
+	state, err := ip.getInterfaceState(ctx, name)
+	if err != nil {
+		return err
+	}
+
+	if state.Mtu == desiredMTU {
+		return nil
+	}
+
+	if _, err := ip(ctx, "link", "set", "dev", name, "mtu", strconv.Itoa(desiredMTU)); err != nil {
+		return fmt.Errorf("failed to set mtu to %d: %w", desiredMTU, err)
+	}
+	return nil
+}
+
+func (ip ipRoute2) reconcileRoutes(ctx context.Context, name string, peers []*driver.PeerOptions) error {
+	desiredIPv4 := make(map[string]struct{})
+	desiredIPv6 := make(map[string]struct{})
+
+	for _, p := range peers {
+		for _, allowedIP := range p.AllowedIPs {
+			prefix, err := normalizeCIDR(allowedIP)
+			if err != nil {
+				return fmt.Errorf("failed to parse allowed ip %q: %w", allowedIP, err)
+			}
+
+			parsed, err := netip.ParsePrefix(prefix)
+			if err != nil {
+				return fmt.Errorf("failed to parse normalized allowed ip %q: %w", prefix, err)
+			}
+
+			if parsed.Addr().Is4() {
+				desiredIPv4[prefix] = struct{}{}
+			} else {
+				desiredIPv6[prefix] = struct{}{}
+			}
+		}
+	}
+
+	for prefix := range desiredIPv4 {
+		if _, err := ip(ctx, "-4", "route", "replace", prefix, "dev", name); err != nil {
+			return fmt.Errorf("failed to add or replace ipv4 route %s: %w", prefix, err)
+		}
+	}
+
+	for prefix := range desiredIPv6 {
+		if _, err := ip(ctx, "-6", "route", "replace", prefix, "dev", name); err != nil {
+			return fmt.Errorf("failed to add or replace ipv6 route %s: %w", prefix, err)
+		}
+	}
+
+	currentIPv4, err := ip.listRoutes(ctx, name, false)
+	if err != nil {
+		return err
+	}
+	currentIPv6, err := ip.listRoutes(ctx, name, true)
+	if err != nil {
+		return err
+	}
+
+	for prefix := range currentIPv4 {
+		if _, ok := desiredIPv4[prefix]; ok {
+			continue
+		}
+		if _, err := ip(ctx, "-4", "route", "del", prefix, "dev", name); err != nil {
+			return fmt.Errorf("failed to remove ipv4 route %s: %w", prefix, err)
+		}
+	}
+
+	for prefix := range currentIPv6 {
+		if _, ok := desiredIPv6[prefix]; ok {
+			continue
+		}
+		if _, err := ip(ctx, "-6", "route", "del", prefix, "dev", name); err != nil {
+			return fmt.Errorf("failed to remove ipv6 route %s: %w", prefix, err)
+		}
+	}
+
+	return nil
+}
+
+func (ip ipRoute2) listRoutes(ctx context.Context, name string, ipv6 bool) (map[string]struct{}, error) {
+	familyFlag := "-4"
+	defaultPrefix := "0.0.0.0/0"
+	if ipv6 {
+		familyFlag = "-6"
+		defaultPrefix = "::/0"
+	}
+
+	output, err := ip(ctx, "-j", familyFlag, "route", "show", "dev", name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list routes for %s: %w", name, err)
+	}
+
+	var routes []ipRouteState
+	if err := json.Unmarshal(output, &routes); err != nil {
+		return nil, fmt.Errorf("failed to decode route list: %w", err)
+	}
+
+	result := make(map[string]struct{})
+	for _, route := range routes {
+		dst := strings.TrimSpace(route.Dst)
+		if dst == "" {
+			continue
+		}
+		if dst == "default" {
+			dst = defaultPrefix
+		}
+		prefix, err := normalizeCIDR(dst)
+		if err != nil {
+			continue
+		}
+		result[prefix] = struct{}{}
+	}
+
+	return result, nil
+}
+
+func (ip ipRoute2) getInterfaceState(ctx context.Context, name string) (*ipInterfaceState, error) {
+	output, err := ip(ctx, "-j", "address", "show", "dev", name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get interface state for %s: %w", name, err)
+	}
+
+	var states []ipInterfaceState
+	if err := json.Unmarshal(output, &states); err != nil {
+		return nil, fmt.Errorf("failed to decode interface state for %s: %w", name, err)
+	}
+	if len(states) == 0 {
+		return nil, fmt.Errorf("interface state for %s not found", name)
+	}
+
+	return &states[0], nil
+}
+
+// listWireguardLinks returns the wireguard interfaces with their statistics.
+func (ip ipRoute2) listWireguardLinks(ctx context.Context) ([]ipLinkState, error) {
+	output, err := ip(ctx, "-j", "-s", "link", "show", "type", "wireguard")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list wireguard interfaces: %w", err)
+	}
+
+	var links []ipLinkState
+	if err := json.Unmarshal(output, &links); err != nil {
+		return nil, fmt.Errorf("failed to decode wireguard interface list: %w", err)
+	}
+	return links, nil
+}
+
+type ipInterfaceState struct {
+	Name     string          `json:"ifname"`
+	Mtu      int             `json:"mtu"`
+	AddrInfo []ipAddressInfo `json:"addr_info"`
+}
+
+type ipLinkState struct {
+	Name      string `json:"ifname"`
+	Mtu       int    `json:"mtu"`
+	OperState string `json:"operstate"`
+	Stats64   struct {
+		Rx ipLinkCounters `json:"rx"`
+		Tx ipLinkCounters `json:"tx"`
+	} `json:"stats64"`
+}
+
+type ipLinkCounters struct {
+	Bytes uint64 `json:"bytes"`
+}
+
+type ipAddressInfo struct {
+	Family    string `json:"family"`
+	Local     string `json:"local"`
+	PrefixLen int    `json:"prefixlen"`
+	Scope     string `json:"scope"`
+}
+
+type ipRouteState struct {
+	Dst string `json:"dst"`
+}
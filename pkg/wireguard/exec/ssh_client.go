@@ -0,0 +1,205 @@
+package exec
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+	"golang.org/x/crypto/ssh/knownhosts"
+
+	"github.com/UnAfraid/wg-ui/pkg/wireguard/driver"
+)
+
+const sshDialTimeout = 15 * time.Second
+
+// sshClient runs commands on the remote host, the connection is reused by all commands and redialed
+// when it is no longer usable.
+type sshClient struct {
+	address   string
+	config    *ssh.ClientConfig
+	agentConn net.Conn
+
+	mu     sync.Mutex
+	client *ssh.Client
+}
+
+func newSSHClient(parsed *parsedSSHURL) (*sshClient, error) {
+	hostKeyCallback, err := sshHostKeyCallback(parsed)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &sshClient{
+		address: parsed.address,
+	}
+
+	var authMethods []ssh.AuthMethod
+	switch {
+	case parsed.keyFile != "":
+		keyBytes, err := os.ReadFile(parsed.keyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read ssh key: %w", err)
+		}
+		signer, err := ssh.ParsePrivateKey(keyBytes)
+		if err != nil {
+			var passphraseMissingErr *ssh.PassphraseMissingError
+			if errors.As(err, &passphraseMissingErr) {
+				return nil, errors.New("passphrase protected ssh keys are not supported, load the key into ssh-agent instead")
+			}
+			return nil, fmt.Errorf("failed to parse ssh key: %w", err)
+		}
+		authMethods = append(authMethods, ssh.PublicKeys(signer))
+	case parsed.password != "":
+		authMethods = append(authMethods, ssh.Password(parsed.password))
+	default:
+		socket := os.Getenv("SSH_AUTH_SOCK")
+		if socket == "" {
+			return nil, errors.New("ssh backend requires a key, a password or a running ssh-agent")
+		}
+		c.agentConn, err = net.Dial("unix", socket)
+		if err != nil {
+			return nil, fmt.Errorf("failed to connect to ssh-agent: %w", err)
+		}
+		authMethods = append(authMethods, ssh.PublicKeysCallback(agent.NewClient(c.agentConn).Signers))
+	}
+
+	c.config = &ssh.ClientConfig{
+		User:            parsed.user,
+		Auth:            authMethods,
+		HostKeyCallback: hostKeyCallback,
+		Timeout:         sshDialTimeout,
+	}
+	return c, nil
+}
+
+// sshHostKeyCallback verifies the host key against the pinned fingerprint, or the known hosts file otherwise.
+func sshHostKeyCallback(parsed *parsedSSHURL) (ssh.HostKeyCallback, error) {
+	if parsed.hostKeyFingerprint != "" {
+		fingerprint := parsed.hostKeyFingerprint
+		if !strings.HasPrefix(fingerprint, "SHA256:") {
+			return nil, fmt.Errorf("invalid ssh host key fingerprint %q, expected SHA256:<base64>", fingerprint)
+		}
+		return func(_ string, _ net.Addr, key ssh.PublicKey) error {
+			if ssh.FingerprintSHA256(key) != fingerprint {
+				return fmt.Errorf("ssh host key mismatch: %s", ssh.FingerprintSHA256(key))
+			}
+			return nil
+		}, nil
+	}
+
+	knownHostsFile := parsed.knownHostsFile
+	if knownHostsFile == "" {
+		homeDir, err := os.UserHomeDir()
+		if err != nil {
+			return nil, fmt.Errorf("failed to find known hosts file: %w", err)
+		}
+		knownHostsFile = filepath.Join(homeDir, ".ssh", "known_hosts")
+	}
+
+	callback, err := knownhosts.New(knownHostsFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load known hosts: %w", err)
+	}
+	return callback, nil
+}
+
+// run executes the command, the input is streamed to its stdin. The combined output is returned even on failure.
+func (c *sshClient) run(ctx context.Context, command string, input []byte) ([]byte, error) {
+	session, err := c.newSession(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer session.Close()
+
+	if input != nil {
+		session.Stdin = bytes.NewReader(input)
+	}
+
+	type result struct {
+		output []byte
+		err    error
+	}
+	done := make(chan result, 1)
+	go func() {
+		output, err := session.CombinedOutput(command)
+		done <- result{output: output, err: err}
+	}()
+
+	select {
+	case <-ctx.Done():
+		// Servers are free to ignore signals, closing the session stops waiting for the command regardless.
+		_ = session.Signal(ssh.SIGKILL)
+		_ = session.Close()
+		return nil, ctx.Err()
+	case r := <-done:
+		return r.output, r.err
+	}
+}
+
+func (c *sshClient) newSession(ctx context.Context) (*ssh.Session, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.client != nil {
+		session, err := c.client.NewSession()
+		if err == nil {
+			return session, nil
+		}
+		_ = c.client.Close()
+		c.client = nil
+	}
+
+	client, err := c.dial(ctx)
+	if err != nil {
+		return nil, err
+	}
+	c.client = client
+
+	session, err := client.NewSession()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open ssh session: %w", err)
+	}
+	return session, nil
+}
+
+func (c *sshClient) dial(ctx context.Context) (*ssh.Client, error) {
+	dialer := net.Dialer{Timeout: sshDialTimeout}
+	conn, err := dialer.DialContext(ctx, "tcp", c.address)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to %s: %w", c.address, err)
+	}
+
+	clientConn, channels, requests, err := ssh.NewClientConn(conn, c.address, c.config)
+	if err != nil {
+		_ = conn.Close()
+		if strings.Contains(err.Error(), "unable to authenticate") {
+			return nil, fmt.Errorf("ssh handshake with %s failed: %w: %v", c.address, driver.ErrUnauthorized, err)
+		}
+		return nil, fmt.Errorf("ssh handshake with %s failed: %w", c.address, err)
+	}
+	return ssh.NewClient(clientConn, channels, requests), nil
+}
+
+func (c *sshClient) close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var errs []error
+	if c.client != nil {
+		errs = append(errs, c.client.Close())
+		c.client = nil
+	}
+	if c.agentConn != nil {
+		errs = append(errs, c.agentConn.Close())
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,286 @@
+package exec
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/UnAfraid/wg-ui/pkg/wireguard/driver"
+)
+
+// commandRunner reaches the host managed by the wg-quick flow, the exec backend runs the commands locally
+// and the ssh backend runs them over an ssh session.
+type commandRunner interface {
+	// run executes the binary, the input is passed on its stdin when set.
+	run(ctx context.Context, input []byte, binary string, args ...string) ([]byte, error)
+	interfaceExists(ctx context.Context, name string) (bool, error)
+	resolvconfAvailable(ctx context.Context) bool
+	// writeFile replaces the file atomically, creating its directory when missing.
+	writeFile(ctx context.Context, path string, content []byte) error
+	readFile(ctx context.Context, path string) ([]byte, error)
+	listConfigFiles(ctx context.Context, dir string) ([]string, error)
+}
+
+// wgQuick is the command flow shared by the exec and ssh backends, interfaces are brought up with wg-quick
+// and reconfigured live with wg syncconf.
+type wgQuick struct {
+	configDir string
+	runner    commandRunner
+}
+
+func (q *wgQuick) up(ctx context.Context, options driver.ConfigureOptions) error {
+	if err := options.Validate(); err != nil {
+		return err
+	}
+
+	name := options.InterfaceOptions.Name
+	configPath, err := q.writeConfig(ctx, options)
+	if err != nil {
+		return err
+	}
+
+	exists, err := q.runner.interfaceExists(ctx, name)
+	if err != nil {
+		return err
+	}
+
+	if !exists {
+		if _, err := q.runWGQuick(ctx, "up", configPath); err != nil {
+			return fmt.Errorf("failed to bring interface up: %w", err)
+		}
+		return nil
+	}
+
+	if err := q.syncWithoutRestart(ctx, options, configPath); err != nil {
+		logrus.WithError(err).
+			WithField("interface", name).
+			Warn("live reconfiguration failed, restarting interface")
+
+		if downErr := q.down(ctx, name, configPath); downErr != nil {
+			logrus.WithError(downErr).
+				WithField("interface", name).
+				Warn("failed to stop interface during reconfiguration fallback")
+		}
+
+		if _, err := q.runWGQuick(ctx, "up", configPath); err != nil {
+			return fmt.Errorf("failed to restart interface after live reconfiguration failure: %w", err)
+		}
+	}
+	return nil
+}
+
+func (q *wgQuick) Down(ctx context.Context, name string) error {
+	return q.down(ctx, name, q.configFilePath(name))
+}
+
+func (q *wgQuick) down(ctx context.Context, name string, configPath string) error {
+	exists, err := q.runner.interfaceExists(ctx, name)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return nil
+	}
+
+	if _, err := q.runWGQuick(ctx, "down", configPath); err != nil {
+		if _, fallbackErr := q.runWGQuick(ctx, "down", name); fallbackErr != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (q *wgQuick) Status(ctx context.Context, name string) (bool, error) {
+	return q.runner.interfaceExists(ctx, name)
+}
+
+func (q *wgQuick) PeerStats(ctx context.Context, name string, peerPublicKey string) (*driver.PeerStats, error) {
+	device, err := q.deviceFromDump(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, p := range device.Wireguard.Peers {
+		if p.PublicKey == peerPublicKey {
+			return &p.Stats, nil
+		}
+	}
+
+	return nil, nil
+}
+
+func (q *wgQuick) syncWithoutRestart(ctx context.Context, options driver.ConfigureOptions, configPath string) error {
+	name := options.InterfaceOptions.Name
+
+	strippedConfig, err := q.runWGQuick(ctx, "strip", configPath)
+	if err != nil {
+		return fmt.Errorf("failed to strip config for syncconf: %w", err)
+	}
+
+	if _, err := q.runner.run(ctx, strippedConfig, "wg", "syncconf", name, "/dev/stdin"); err != nil {
+		return fmt.Errorf("failed to apply syncconf: %w", err)
+	}
+
+	ip := ipRoute2(q.runIP)
+	if err := ip.reconcileAddress(ctx, name, options.InterfaceOptions.Address); err != nil {
+		return err
+	}
+
+	if err := ip.reconcileMTU(ctx, name, options.InterfaceOptions.Mtu); err != nil {
+		return err
+	}
+
+	if err := ip.reconcileRoutes(ctx, name, options.WireguardOptions.Peers); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (q *wgQuick) writeConfig(ctx context.Context, options driver.ConfigureOptions) (string, error) {
+	name := options.InterfaceOptions.Name
+	configPath := q.configFilePath(name)
+
+	configOptions := options
+	if len(configOptions.InterfaceOptions.DNS) > 0 && !q.runner.resolvconfAvailable(ctx) {
+		logrus.WithField("interface", name).
+			Warn("resolvconf command not found; omitting DNS configuration for wg-quick")
+		configOptions.InterfaceOptions.DNS = nil
+	}
+
+	if err := q.runner.writeFile(ctx, configPath, []byte(renderConfig(configOptions))); err != nil {
+		return "", fmt.Errorf("failed to write config file %s: %w", configPath, err)
+	}
+	return configPath, nil
+}
+
+func (q *wgQuick) deviceFromConfigFile(ctx context.Context, name string) (*driver.Device, error) {
+	configContent, err := q.runner.readFile(ctx, q.configFilePath(name))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file for interface %s: %w", name, err)
+	}
+
+	parsedConfig, err := parseConfigDevice(name, string(configContent))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse config file for interface %s: %w", name, err)
+	}
+
+	return parsedConfig.Device, nil
+}
+
+func (q *wgQuick) findForeignServersFromConfigFiles(
+	ctx context.Context,
+	knownInterfaceSet map[string]struct{},
+	existingForeignNames map[string]struct{},
+) ([]*driver.ForeignServer, error) {
+	configFiles, err := q.runner.listConfigFiles(ctx, q.configDir)
+	if err != nil {
+		return nil, err
+	}
+
+	var foreignServers []*driver.ForeignServer
+	for _, configPath := range configFiles {
+		base := path.Base(configPath)
+		name := strings.TrimSuffix(base, ".conf")
+		if strings.TrimSpace(name) == "" || name == base {
+			continue
+		}
+		if _, ok := knownInterfaceSet[name]; ok {
+			continue
+		}
+		if _, ok := existingForeignNames[name]; ok {
+			continue
+		}
+
+		configContent, err := q.runner.readFile(ctx, configPath)
+		if err != nil {
+			logrus.WithError(err).
+				WithField("path", configPath).
+				Warn("failed to read foreign server config file")
+			continue
+		}
+
+		parsedConfig, err := parseConfigDevice(name, string(configContent))
+		if err != nil {
+			logrus.WithError(err).
+				WithField("path", configPath).
+				Warn("failed to parse foreign server config file")
+			continue
+		}
+
+		foreignServers = append(foreignServers, &driver.ForeignServer{
+			Interface: &driver.ForeignInterface{
+				Name:      parsedConfig.Device.Interface.Name,
+				Addresses: parsedConfig.Device.Interface.Addresses,
+				Mtu:       parsedConfig.Device.Interface.Mtu,
+				State:     "down",
+			},
+			Hooks:        parsedConfig.Hooks,
+			Name:         parsedConfig.Device.Wireguard.Name,
+			Description:  parsedConfig.Description,
+			Type:         "wireguard",
+			PublicKey:    parsedConfig.Device.Wireguard.PublicKey,
+			ListenPort:   parsedConfig.Device.Wireguard.ListenPort,
+			FirewallMark: parsedConfig.Device.Wireguard.FirewallMark,
+			Peers:        parsedConfig.Device.Wireguard.Peers,
+		})
+	}
+
+	return foreignServers, nil
+}
+
+func (q *wgQuick) deviceFromDump(ctx context.Context, name string) (*driver.Device, error) {
+	output, err := q.runner.run(ctx, nil, "wg", "show", name, "dump")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read device dump for %s: %w", name, err)
+	}
+	return parseDeviceDump(name, string(output))
+}
+
+func (q *wgQuick) configFilePath(name string) string {
+	return path.Join(q.configDir, fmt.Sprintf("%s.conf", name))
+}
+
+func (q *wgQuick) runWGQuick(ctx context.Context, args ...string) ([]byte, error) {
+	return q.runner.run(ctx, nil, "wg-quick", args...)
+}
+
+func (q *wgQuick) runIP(ctx context.Context, args ...string) ([]byte, error) {
+	return q.runner.run(ctx, nil, "ip", args...)
+}
+
+// findConfigFiles lists the config files with find, for runners that reach the files only through commands.
+func findConfigFiles(ctx context.Context, runner commandRunner, dir string) ([]string, error) {
+	output, err := runner.run(ctx, nil, "find", dir, "-maxdepth", "1", "-type", "f", "-name", "*.conf", "-print")
+	if err != nil {
+		if strings.Contains(strings.ToLower(err.Error()), "no such file or directory") {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to list config files in %s: %w", dir, err)
+	}
+
+	trimmed := strings.TrimSpace(string(output))
+	if trimmed == "" {
+		return nil, nil
+	}
+
+	files := strings.Split(trimmed, "\n")
+	for i, file := range files {
+		files[i] = strings.TrimSpace(file)
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// catFile reads the file with cat, for runners that reach the files only through commands.
+func catFile(ctx context.Context, runner commandRunner, path string) ([]byte, error) {
+	output, err := runner.run(ctx, nil, "cat", path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file %s: %w", path, err)
+	}
+	return output, nil
+}
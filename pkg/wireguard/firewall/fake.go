@@ -0,0 +1,39 @@
+package firewall
+
+import (
+	"context"
+	"sync"
+)
+
+// Fake is an in-memory Manager that keeps the last applied ruleset of each interface.
+type Fake struct {
+	mu       sync.Mutex
+	rulesets map[string]*Ruleset
+}
+
+func NewFake() *Fake {
+	return &Fake{
+		rulesets: make(map[string]*Ruleset),
+	}
+}
+
+func (f *Fake) Apply(_ context.Context, ruleset *Ruleset) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.rulesets[ruleset.Interface] = ruleset
+	return nil
+}
+
+func (f *Fake) Remove(_ context.Context, interfaceName string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	delete(f.rulesets, interfaceName)
+	return nil
+}
+
+// Ruleset returns the ruleset applied to the interface, nil when none is.
+func (f *Fake) Ruleset(interfaceName string) *Ruleset {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.rulesets[interfaceName]
+}
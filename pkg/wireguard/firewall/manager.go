@@ -0,0 +1,28 @@
+package firewall
+
+import (
+	"context"
+
+	"github.com/UnAfraid/wg-ui/pkg/wireguard/driver"
+)
+
+// Manager applies the ruleset of a wireguard interface to a firewall implementation.
+type Manager interface {
+	// Apply replaces the rules of the ruleset interface.
+	Apply(ctx context.Context, ruleset *Ruleset) error
+	// Remove deletes the rules of the interface, it is a no-op when there are none.
+	Remove(ctx context.Context, interfaceName string) error
+}
+
+// Sync applies the ruleset of the configuration, or removes the interface rules when the configuration
+// no longer manages the firewall.
+func Sync(ctx context.Context, manager Manager, options driver.ConfigureOptions) error {
+	ruleset, err := Build(options)
+	if err != nil {
+		return err
+	}
+	if ruleset == nil {
+		return manager.Remove(ctx, options.InterfaceOptions.Name)
+	}
+	return manager.Apply(ctx, ruleset)
+}
@@ -0,0 +1,267 @@
+//go:build linux
+
+package firewall
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/netip"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/google/nftables"
+	"github.com/google/nftables/binaryutil"
+	"github.com/google/nftables/expr"
+	"golang.org/x/sys/unix"
+)
+
+const nftablesTablePrefix = "wg-ui-"
+
+// nftConn is the subset of the nftables connection used by the manager.
+type nftConn interface {
+	AddTable(t *nftables.Table) *nftables.Table
+	DelTable(t *nftables.Table)
+	AddChain(c *nftables.Chain) *nftables.Chain
+	AddRule(r *nftables.Rule) *nftables.Rule
+	Flush() error
+}
+
+type nftablesManager struct {
+	mu          sync.Mutex
+	newConn     func() (nftConn, error)
+	procSysPath string
+}
+
+// NewNftablesManager manages an inet table per wireguard interface through nftables netlink.
+func NewNftablesManager() Manager {
+	return &nftablesManager{
+		newConn: func() (nftConn, error) {
+			conn, err := nftables.New()
+			if err != nil {
+				return nil, err
+			}
+			return conn, nil
+		},
+		procSysPath: "/proc/sys",
+	}
+}
+
+func (m *nftablesManager) Apply(_ context.Context, ruleset *Ruleset) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if ruleset.IPForwarding {
+		if err := m.enableForwarding(ruleset); err != nil {
+			return err
+		}
+	}
+
+	conn, err := m.newConn()
+	if err != nil {
+		return fmt.Errorf("failed to open nftables connection: %w", err)
+	}
+
+	// Adding the table before deleting it makes the delete succeed when the table does not exist yet,
+	// the whole batch is applied atomically on flush.
+	table := nftablesTable(ruleset.Interface)
+	conn.AddTable(table)
+	conn.DelTable(table)
+	if !ruleset.Empty() {
+		addNftablesRuleset(conn, table, ruleset)
+	}
+
+	if err := conn.Flush(); err != nil {
+		return fmt.Errorf("failed to apply nftables rules of %s: %w", ruleset.Interface, err)
+	}
+	return nil
+}
+
+func (m *nftablesManager) Remove(_ context.Context, interfaceName string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	conn, err := m.newConn()
+	if err != nil {
+		return fmt.Errorf("failed to open nftables connection: %w", err)
+	}
+
+	table := nftablesTable(interfaceName)
+	conn.AddTable(table)
+	conn.DelTable(table)
+	if err := conn.Flush(); err != nil {
+		return fmt.Errorf("failed to remove nftables rules of %s: %w", interfaceName, err)
+	}
+	return nil
+}
+
+// enableForwarding turns on forwarding for the address families of the ruleset, it is left enabled on removal
+// since other interfaces may depend on it.
+func (m *nftablesManager) enableForwarding(ruleset *Ruleset) error {
+	var keys []string
+	if ruleset.IPv4 {
+		keys = append(keys, "net/ipv4/ip_forward")
+	}
+	if ruleset.IPv6 {
+		keys = append(keys, "net/ipv6/conf/all/forwarding")
+	}
+
+	for _, key := range keys {
+		if err := os.WriteFile(filepath.Join(m.procSysPath, key), []byte("1"), 0o644); err != nil {
+			return fmt.Errorf("failed to enable ip forwarding: %w", err)
+		}
+	}
+	return nil
+}
+
+func nftablesTable(interfaceName string) *nftables.Table {
+	return &nftables.Table{
+		Family: nftables.TableFamilyINet,
+		Name:   nftablesTablePrefix + interfaceName,
+	}
+}
+
+func addNftablesRuleset(conn nftConn, table *nftables.Table, ruleset *Ruleset) {
+	conn.AddTable(table)
+
+	if len(ruleset.Forward) > 0 {
+		policy := nftables.ChainPolicyAccept
+		forward := conn.AddChain(&nftables.Chain{
+			Name:     "forward",
+			Table:    table,
+			Type:     nftables.ChainTypeFilter,
+			Hooknum:  nftables.ChainHookForward,
+			Priority: nftables.ChainPriorityFilter,
+			Policy:   &policy,
+		})
+		for _, rule := range ruleset.Forward {
+			conn.AddRule(&nftables.Rule{
+				Table: table,
+				Chain: forward,
+				Exprs: forwardRuleExprs(ruleset.Interface, rule),
+			})
+		}
+	}
+
+	if len(ruleset.Masquerade) > 0 {
+		postrouting := conn.AddChain(&nftables.Chain{
+			Name:     "postrouting",
+			Table:    table,
+			Type:     nftables.ChainTypeNAT,
+			Hooknum:  nftables.ChainHookPostrouting,
+			Priority: nftables.ChainPriorityNATSource,
+		})
+		for _, rule := range ruleset.Masquerade {
+			conn.AddRule(&nftables.Rule{
+				Table: table,
+				Chain: postrouting,
+				Exprs: masqueradeRuleExprs(rule),
+			})
+		}
+	}
+}
+
+func forwardRuleExprs(interfaceName string, rule ForwardRule) []expr.Any {
+	exprs := interfaceNameExprs(expr.MetaKeyIIFNAME, interfaceName)
+	if rule.OutputInterface != "" {
+		exprs = append(exprs, interfaceNameExprs(expr.MetaKeyOIFNAME, rule.OutputInterface)...)
+	}
+	if rule.Source.IsValid() {
+		exprs = append(exprs, prefixExprs(rule.Source, true)...)
+	}
+	if rule.Destination.IsValid() {
+		exprs = append(exprs, prefixExprs(rule.Destination, false)...)
+	}
+	if rule.Protocol != ProtocolAny {
+		exprs = append(exprs, protocolExprs(rule)...)
+	}
+
+	kind := expr.VerdictAccept
+	if rule.Verdict == VerdictDrop {
+		kind = expr.VerdictDrop
+	}
+	return append(exprs, &expr.Verdict{Kind: kind})
+}
+
+// protocolExprs matches the transport protocol and the destination port range of the rule.
+func protocolExprs(rule ForwardRule) []expr.Any {
+	var protocol byte
+	switch rule.Protocol {
+	case ProtocolTCP:
+		protocol = unix.IPPROTO_TCP
+	case ProtocolUDP:
+		protocol = unix.IPPROTO_UDP
+	case ProtocolICMP:
+		protocol = unix.IPPROTO_ICMP
+		if rule.Source.Addr().Is6() || rule.Destination.Addr().Is6() {
+			protocol = unix.IPPROTO_ICMPV6
+		}
+	}
+
+	exprs := []expr.Any{
+		&expr.Meta{Key: expr.MetaKeyL4PROTO, Register: 1},
+		&expr.Cmp{Op: expr.CmpOpEq, Register: 1, Data: []byte{protocol}},
+	}
+	if rule.FromPort == 0 {
+		return exprs
+	}
+
+	// The destination port is at the same offset of the tcp and udp headers.
+	exprs = append(exprs, &expr.Payload{DestRegister: 1, Base: expr.PayloadBaseTransportHeader, Offset: 2, Len: 2})
+	if rule.FromPort == rule.ToPort {
+		return append(exprs, &expr.Cmp{Op: expr.CmpOpEq, Register: 1, Data: binaryutil.BigEndian.PutUint16(rule.FromPort)})
+	}
+	return append(exprs, &expr.Range{
+		Op:       expr.CmpOpEq,
+		Register: 1,
+		FromData: binaryutil.BigEndian.PutUint16(rule.FromPort),
+		ToData:   binaryutil.BigEndian.PutUint16(rule.ToPort),
+	})
+}
+
+func masqueradeRuleExprs(rule MasqueradeRule) []expr.Any {
+	exprs := interfaceNameExprs(expr.MetaKeyOIFNAME, rule.OutputInterface)
+	exprs = append(exprs, prefixExprs(rule.Source, true)...)
+	return append(exprs, &expr.Masq{})
+}
+
+func interfaceNameExprs(key expr.MetaKey, interfaceName string) []expr.Any {
+	name := make([]byte, unix.IFNAMSIZ)
+	copy(name, interfaceName)
+	return []expr.Any{
+		&expr.Meta{Key: key, Register: 1},
+		&expr.Cmp{Op: expr.CmpOpEq, Register: 1, Data: name},
+	}
+}
+
+// prefixExprs matches the source or destination address of the network header against the prefix,
+// the family is checked first since the table handles both IPv4 and IPv6.
+func prefixExprs(prefix netip.Prefix, source bool) []expr.Any {
+	family, offset, length := byte(unix.NFPROTO_IPV4), uint32(16), uint32(net.IPv4len)
+	if source {
+		offset = 12
+	}
+	if prefix.Addr().Is6() {
+		family, offset, length = unix.NFPROTO_IPV6, 24, net.IPv6len
+		if source {
+			offset = 8
+		}
+	}
+
+	exprs := []expr.Any{
+		&expr.Meta{Key: expr.MetaKeyNFPROTO, Register: 1},
+		&expr.Cmp{Op: expr.CmpOpEq, Register: 1, Data: []byte{family}},
+		&expr.Payload{DestRegister: 1, Base: expr.PayloadBaseNetworkHeader, Offset: offset, Len: length},
+	}
+	if prefix.Bits() < int(length)*8 {
+		exprs = append(exprs, &expr.Bitwise{
+			SourceRegister: 1,
+			DestRegister:   1,
+			Len:            length,
+			Mask:           net.CIDRMask(prefix.Bits(), int(length)*8),
+			Xor:            make([]byte, length),
+		})
+	}
+	return append(exprs, &expr.Cmp{Op: expr.CmpOpEq, Register: 1, Data: prefix.Addr().AsSlice()})
+}
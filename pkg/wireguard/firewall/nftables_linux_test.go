@@ -0,0 +1,140 @@
+//go:build linux
+
+package firewall
+
+import (
+	"bytes"
+	"context"
+	"net/netip"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/nftables"
+	"github.com/google/nftables/expr"
+)
+
+type fakeNftConn struct {
+	tables  []*nftables.Table
+	deleted []*nftables.Table
+	chains  []*nftables.Chain
+	rules   []*nftables.Rule
+	flushes int
+}
+
+func (c *fakeNftConn) AddTable(t *nftables.Table) *nftables.Table {
+	c.tables = append(c.tables, t)
+	return t
+}
+
+func (c *fakeNftConn) DelTable(t *nftables.Table) {
+	c.deleted = append(c.deleted, t)
+}
+
+func (c *fakeNftConn) AddChain(ch *nftables.Chain) *nftables.Chain {
+	c.chains = append(c.chains, ch)
+	return ch
+}
+
+func (c *fakeNftConn) AddRule(r *nftables.Rule) *nftables.Rule {
+	c.rules = append(c.rules, r)
+	return r
+}
+
+func (c *fakeNftConn) Flush() error {
+	c.flushes++
+	return nil
+}
+
+func newTestNftablesManager(t *testing.T) (*nftablesManager, *fakeNftConn) {
+	conn := &fakeNftConn{}
+	procSysPath := t.TempDir()
+	for _, key := range []string{"net/ipv4/ip_forward", "net/ipv6/conf/all/forwarding"} {
+		path := filepath.Join(procSysPath, key)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("failed to create %s: %v", path, err)
+		}
+		if err := os.WriteFile(path, []byte("0"), 0o644); err != nil {
+			t.Fatalf("failed to write %s: %v", path, err)
+		}
+	}
+
+	return &nftablesManager{
+		newConn: func() (nftConn, error) {
+			return conn, nil
+		},
+		procSysPath: procSysPath,
+	}, conn
+}
+
+func TestNftablesManagerApply(t *testing.T) {
+	manager, conn := newTestNftablesManager(t)
+
+	ruleset := &Ruleset{
+		Interface:    "wg0",
+		IPv4:         true,
+		IPForwarding: true,
+		Forward: []ForwardRule{
+			{OutputInterface: "wg0", Verdict: VerdictDrop},
+		},
+		Masquerade: []MasqueradeRule{
+			{Source: netip.MustParsePrefix("10.0.0.0/24"), OutputInterface: "eth0"},
+		},
+	}
+
+	if err := manager.Apply(context.Background(), ruleset); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if conn.flushes != 1 {
+		t.Fatalf("expected a single flush, got %d", conn.flushes)
+	}
+	if len(conn.deleted) != 1 || conn.deleted[0].Name != "wg-ui-wg0" || conn.deleted[0].Family != nftables.TableFamilyINet {
+		t.Fatalf("expected the previous inet table to be replaced, got %+v", conn.deleted)
+	}
+	if len(conn.chains) != 2 || conn.chains[0].Name != "forward" || conn.chains[1].Name != "postrouting" {
+		t.Fatalf("expected forward and postrouting chains, got %+v", conn.chains)
+	}
+	if len(conn.rules) != 2 {
+		t.Fatalf("expected 2 rules, got %d", len(conn.rules))
+	}
+
+	isolation := conn.rules[0].Exprs
+	if verdict, ok := isolation[len(isolation)-1].(*expr.Verdict); !ok || verdict.Kind != expr.VerdictDrop {
+		t.Fatalf("expected the isolation rule to drop, got %+v", isolation[len(isolation)-1])
+	}
+
+	masquerade := conn.rules[1].Exprs
+	if _, ok := masquerade[len(masquerade)-1].(*expr.Masq); !ok {
+		t.Fatalf("expected the nat rule to masquerade, got %+v", masquerade[len(masquerade)-1])
+	}
+	var matchesNetwork bool
+	for _, e := range masquerade {
+		if cmp, ok := e.(*expr.Cmp); ok && bytes.Equal(cmp.Data, []byte{10, 0, 0, 0}) {
+			matchesNetwork = true
+		}
+	}
+	if !matchesNetwork {
+		t.Fatalf("expected the nat rule to match the server network, got %+v", masquerade)
+	}
+
+	forwarding, err := os.ReadFile(filepath.Join(manager.procSysPath, "net/ipv4/ip_forward"))
+	if err != nil || string(forwarding) != "1" {
+		t.Fatalf("expected ipv4 forwarding to be enabled, got %q %v", forwarding, err)
+	}
+	forwarding, err = os.ReadFile(filepath.Join(manager.procSysPath, "net/ipv6/conf/all/forwarding"))
+	if err != nil || string(forwarding) != "0" {
+		t.Fatalf("expected ipv6 forwarding to be left alone, got %q %v", forwarding, err)
+	}
+}
+
+func TestNftablesManagerRemove(t *testing.T) {
+	manager, conn := newTestNftablesManager(t)
+
+	if err := manager.Remove(context.Background(), "wg0"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(conn.deleted) != 1 || conn.deleted[0].Name != "wg-ui-wg0" || len(conn.rules) != 0 || conn.flushes != 1 {
+		t.Fatalf("expected only the table to be deleted, got %+v", conn)
+	}
+}
@@ -0,0 +1,211 @@
+package firewall
+
+import (
+	"fmt"
+	"net/netip"
+	"strings"
+
+	"github.com/UnAfraid/wg-ui/pkg/wireguard/driver"
+)
+
+type Verdict string
+
+const (
+	VerdictAccept Verdict = "accept"
+	VerdictDrop   Verdict = "drop"
+)
+
+type Protocol string
+
+const (
+	ProtocolAny  Protocol = ""
+	ProtocolTCP  Protocol = "tcp"
+	ProtocolUDP  Protocol = "udp"
+	ProtocolICMP Protocol = "icmp"
+)
+
+// ForwardRule matches traffic forwarded from the wireguard interface, zero prefixes, ports and an empty interface
+// match anything.
+type ForwardRule struct {
+	Source          netip.Prefix
+	Destination     netip.Prefix
+	OutputInterface string
+	Protocol        Protocol
+	// FromPort and ToPort are the destination port range of tcp and udp rules.
+	FromPort uint16
+	ToPort   uint16
+	Verdict  Verdict
+}
+
+// MasqueradeRule masquerades traffic from the source network leaving through the output interface.
+type MasqueradeRule struct {
+	Source          netip.Prefix
+	OutputInterface string
+}
+
+// Ruleset is the backend independent description of the firewall of a single wireguard interface,
+// forward rules are evaluated in order and the first match wins.
+type Ruleset struct {
+	Interface    string
+	IPv4         bool
+	IPv6         bool
+	IPForwarding bool
+	Forward      []ForwardRule
+	Masquerade   []MasqueradeRule
+}
+
+// Empty reports whether the ruleset has no rules, IP forwarding aside.
+func (r *Ruleset) Empty() bool {
+	return len(r.Forward) == 0 && len(r.Masquerade) == 0
+}
+
+// Build generates the ruleset of the configuration, nil when the configuration does not manage the firewall.
+func Build(options driver.ConfigureOptions) (*Ruleset, error) {
+	if !options.ManagesFirewall() {
+		return nil, nil
+	}
+
+	interfaceName := options.InterfaceOptions.Name
+	ruleset := &Ruleset{
+		Interface: interfaceName,
+	}
+
+	networks, err := parsePrefixes(strings.Split(options.InterfaceOptions.Address, ","))
+	if err != nil {
+		return nil, fmt.Errorf("invalid address: %w", err)
+	}
+	for _, network := range networks {
+		if network.Addr().Is4() {
+			ruleset.IPv4 = true
+		} else {
+			ruleset.IPv6 = true
+		}
+	}
+
+	firewallOptions := options.InterfaceOptions.Firewall
+	if firewallOptions != nil {
+		ruleset.IPForwarding = firewallOptions.IPForwarding
+
+		if firewallOptions.PeerIsolation {
+			ruleset.Forward = append(ruleset.Forward, ForwardRule{
+				OutputInterface: interfaceName,
+				Verdict:         VerdictDrop,
+			})
+		}
+
+		if firewallOptions.MasqueradeInterface != "" {
+			for _, network := range networks {
+				ruleset.Masquerade = append(ruleset.Masquerade, MasqueradeRule{
+					Source:          network,
+					OutputInterface: firewallOptions.MasqueradeInterface,
+				})
+			}
+		}
+	}
+
+	for _, peer := range options.WireguardOptions.Peers {
+		if peer == nil || (len(peer.AllowedDestinations) == 0 && len(peer.ACL) == 0) {
+			continue
+		}
+
+		sources, err := parsePrefixes(peer.AllowedIPs)
+		if err != nil {
+			return nil, fmt.Errorf("invalid allowed ips of peer %s: %w", peer.PublicKey, err)
+		}
+		aclRules, err := aclForwardRules(peer.ACL)
+		if err != nil {
+			return nil, fmt.Errorf("invalid acl of peer %s: %w", peer.PublicKey, err)
+		}
+		destinations, err := parsePrefixes(peer.AllowedDestinations)
+		if err != nil {
+			return nil, fmt.Errorf("invalid allowed destinations of peer %s: %w", peer.PublicKey, err)
+		}
+
+		for _, source := range sources {
+			for _, rule := range aclRules {
+				if source.Addr().Is4() != rule.Destination.Addr().Is4() {
+					continue
+				}
+				rule.Source = source
+				ruleset.Forward = append(ruleset.Forward, rule)
+			}
+
+			for _, destination := range destinations {
+				if source.Addr().Is4() != destination.Addr().Is4() {
+					continue
+				}
+				ruleset.Forward = append(ruleset.Forward, ForwardRule{
+					Source:      source,
+					Destination: destination,
+					Verdict:     VerdictAccept,
+				})
+			}
+
+			// Allowing some traffic denies the rest, an acl of only deny rules keeps accepting anything else.
+			if len(destinations) == 0 && !hasAcceptRule(aclRules) {
+				continue
+			}
+			ruleset.Forward = append(ruleset.Forward, ForwardRule{
+				Source:  source,
+				Verdict: VerdictDrop,
+			})
+		}
+	}
+
+	return ruleset, nil
+}
+
+// aclForwardRules converts the acl of a peer into forward rules without a source.
+func aclForwardRules(acl []*driver.ACLRule) ([]ForwardRule, error) {
+	rules := make([]ForwardRule, 0, len(acl))
+	for _, aclRule := range acl {
+		destination, err := netip.ParsePrefix(strings.TrimSpace(aclRule.Destination))
+		if err != nil {
+			return nil, err
+		}
+
+		rule := ForwardRule{
+			Destination: destination.Masked(),
+			FromPort:    uint16(aclRule.FromPort),
+			ToPort:      uint16(aclRule.ToPort),
+			Verdict:     VerdictAccept,
+		}
+		if rule.ToPort == 0 {
+			rule.ToPort = rule.FromPort
+		}
+		if aclRule.Action == driver.ACLActionDeny {
+			rule.Verdict = VerdictDrop
+		}
+		if aclRule.Protocol != driver.ACLProtocolAny {
+			rule.Protocol = Protocol(aclRule.Protocol)
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+func hasAcceptRule(rules []ForwardRule) bool {
+	for _, rule := range rules {
+		if rule.Verdict == VerdictAccept {
+			return true
+		}
+	}
+	return false
+}
+
+func parsePrefixes(values []string) ([]netip.Prefix, error) {
+	var prefixes []netip.Prefix
+	for _, value := range values {
+		value = strings.TrimSpace(value)
+		if value == "" {
+			continue
+		}
+
+		prefix, err := netip.ParsePrefix(value)
+		if err != nil {
+			return nil, err
+		}
+		prefixes = append(prefixes, prefix.Masked())
+	}
+	return prefixes, nil
+}
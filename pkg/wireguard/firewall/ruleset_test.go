@@ -0,0 +1,176 @@
+package firewall
+
+import (
+	"context"
+	"net/netip"
+	"testing"
+
+	"github.com/UnAfraid/wg-ui/pkg/wireguard/driver"
+)
+
+func testConfigureOptions(firewallOptions *driver.FirewallOptions, peers ...*driver.PeerOptions) driver.ConfigureOptions {
+	return driver.ConfigureOptions{
+		InterfaceOptions: driver.InterfaceOptions{
+			Name:     "wg0",
+			Address:  "10.0.0.1/24, fd00::1/64",
+			Firewall: firewallOptions,
+		},
+		WireguardOptions: driver.WireguardOptions{
+			PrivateKey: "private-key",
+			Peers:      peers,
+		},
+	}
+}
+
+func TestBuildWithoutFirewallSettings(t *testing.T) {
+	ruleset, err := Build(testConfigureOptions(nil, &driver.PeerOptions{PublicKey: "alpha", AllowedIPs: []string{"10.0.0.2/32"}}))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if ruleset != nil {
+		t.Fatalf("expected no ruleset, got %+v", ruleset)
+	}
+}
+
+func TestBuildGeneratesMasqueradeIsolationAndPeerRules(t *testing.T) {
+	options := testConfigureOptions(
+		&driver.FirewallOptions{MasqueradeInterface: "eth0", IPForwarding: true, PeerIsolation: true},
+		&driver.PeerOptions{PublicKey: "alpha", AllowedIPs: []string{"10.0.0.2/32"}, AllowedDestinations: []string{"192.168.1.0/24", "fd10::/64"}},
+		&driver.PeerOptions{PublicKey: "bravo", AllowedIPs: []string{"10.0.0.3/32"}},
+	)
+
+	ruleset, err := Build(options)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !ruleset.IPv4 || !ruleset.IPv6 || !ruleset.IPForwarding {
+		t.Fatalf("expected both families with forwarding, got %+v", ruleset)
+	}
+
+	expectedMasquerade := []MasqueradeRule{
+		{Source: netip.MustParsePrefix("10.0.0.0/24"), OutputInterface: "eth0"},
+		{Source: netip.MustParsePrefix("fd00::/64"), OutputInterface: "eth0"},
+	}
+	if len(ruleset.Masquerade) != len(expectedMasquerade) {
+		t.Fatalf("expected %d masquerade rules, got %+v", len(expectedMasquerade), ruleset.Masquerade)
+	}
+	for i, rule := range ruleset.Masquerade {
+		if rule != expectedMasquerade[i] {
+			t.Fatalf("masquerade rule %d: expected %+v, got %+v", i, expectedMasquerade[i], rule)
+		}
+	}
+
+	expectedForward := []ForwardRule{
+		{OutputInterface: "wg0", Verdict: VerdictDrop},
+		{Source: netip.MustParsePrefix("10.0.0.2/32"), Destination: netip.MustParsePrefix("192.168.1.0/24"), Verdict: VerdictAccept},
+		{Source: netip.MustParsePrefix("10.0.0.2/32"), Verdict: VerdictDrop},
+	}
+	if len(ruleset.Forward) != len(expectedForward) {
+		t.Fatalf("expected %d forward rules, got %+v", len(expectedForward), ruleset.Forward)
+	}
+	for i, rule := range ruleset.Forward {
+		if rule != expectedForward[i] {
+			t.Fatalf("forward rule %d: expected %+v, got %+v", i, expectedForward[i], rule)
+		}
+	}
+}
+
+func TestSyncAppliesAndRemovesRuleset(t *testing.T) {
+	fake := NewFake()
+	ctx := context.Background()
+
+	if err := Sync(ctx, fake, testConfigureOptions(&driver.FirewallOptions{MasqueradeInterface: "eth0"})); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if ruleset := fake.Ruleset("wg0"); ruleset == nil || len(ruleset.Masquerade) != 2 {
+		t.Fatalf("expected the masquerade ruleset to be applied, got %+v", ruleset)
+	}
+
+	if err := Sync(ctx, fake, testConfigureOptions(nil)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if ruleset := fake.Ruleset("wg0"); ruleset != nil {
+		t.Fatalf("expected the ruleset to be removed, got %+v", ruleset)
+	}
+}
+
+func TestBuildOrdersACLBeforeAllowedDestinations(t *testing.T) {
+	options := testConfigureOptions(nil, &driver.PeerOptions{
+		PublicKey:           "alpha",
+		AllowedIPs:          []string{"10.0.0.2/32"},
+		AllowedDestinations: []string{"192.168.1.0/24"},
+		ACL: []*driver.ACLRule{
+			{Destination: "192.168.1.10/32", Protocol: driver.ACLProtocolTCP, FromPort: 8000, ToPort: 8080, Action: driver.ACLActionDeny},
+			{Destination: "192.168.2.5/32", Protocol: driver.ACLProtocolUDP, FromPort: 53, Action: driver.ACLActionAllow},
+			{Destination: "fd10::/64", Action: driver.ACLActionAllow},
+		},
+	})
+
+	ruleset, err := Build(options)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	source := netip.MustParsePrefix("10.0.0.2/32")
+	expectedForward := []ForwardRule{
+		{Source: source, Destination: netip.MustParsePrefix("192.168.1.10/32"), Protocol: ProtocolTCP, FromPort: 8000, ToPort: 8080, Verdict: VerdictDrop},
+		{Source: source, Destination: netip.MustParsePrefix("192.168.2.5/32"), Protocol: ProtocolUDP, FromPort: 53, ToPort: 53, Verdict: VerdictAccept},
+		{Source: source, Destination: netip.MustParsePrefix("192.168.1.0/24"), Verdict: VerdictAccept},
+		{Source: source, Verdict: VerdictDrop},
+	}
+	if len(ruleset.Forward) != len(expectedForward) {
+		t.Fatalf("expected %d forward rules, got %+v", len(expectedForward), ruleset.Forward)
+	}
+	for i, rule := range ruleset.Forward {
+		if rule != expectedForward[i] {
+			t.Fatalf("forward rule %d: expected %+v, got %+v", i, expectedForward[i], rule)
+		}
+	}
+}
+
+func TestBuildACLWithoutAllowedDestinationsHasNoDefaultDrop(t *testing.T) {
+	options := testConfigureOptions(nil, &driver.PeerOptions{
+		PublicKey:  "alpha",
+		AllowedIPs: []string{"10.0.0.2/32"},
+		ACL: []*driver.ACLRule{
+			{Destination: "192.168.1.0/24", Protocol: driver.ACLProtocolICMP, Action: driver.ACLActionDeny},
+		},
+	})
+
+	ruleset, err := Build(options)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(ruleset.Forward) != 1 || ruleset.Forward[0].Verdict != VerdictDrop || ruleset.Forward[0].Protocol != ProtocolICMP {
+		t.Fatalf("expected a single icmp drop rule, got %+v", ruleset.Forward)
+	}
+}
+
+func TestBuildACLWithAllowRulesDropsEverythingElse(t *testing.T) {
+	options := testConfigureOptions(nil, &driver.PeerOptions{
+		PublicKey:  "alpha",
+		AllowedIPs: []string{"10.0.0.2/32"},
+		ACL: []*driver.ACLRule{
+			{Destination: "192.168.1.10/32", Protocol: driver.ACLProtocolTCP, FromPort: 443, Action: driver.ACLActionAllow},
+		},
+	})
+
+	ruleset, err := Build(options)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	source := netip.MustParsePrefix("10.0.0.2/32")
+	expectedForward := []ForwardRule{
+		{Source: source, Destination: netip.MustParsePrefix("192.168.1.10/32"), Protocol: ProtocolTCP, FromPort: 443, ToPort: 443, Verdict: VerdictAccept},
+		{Source: source, Verdict: VerdictDrop},
+	}
+	if len(ruleset.Forward) != len(expectedForward) {
+		t.Fatalf("expected %d forward rules, got %+v", len(expectedForward), ruleset.Forward)
+	}
+	for i, rule := range ruleset.Forward {
+		if rule != expectedForward[i] {
+			t.Fatalf("forward rule %d: expected %+v, got %+v", i, expectedForward[i], rule)
+		}
+	}
+}
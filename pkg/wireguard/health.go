@@ -0,0 +1,297 @@
+package wireguard
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/UnAfraid/wg-ui/pkg/backend"
+	"github.com/UnAfraid/wg-ui/pkg/wireguard/driver"
+)
+
+const (
+	defaultRetryBackoff = 5 * time.Second
+	defaultProbeTimeout = 30 * time.Second
+	// healthProbeInterface is the interface whose status is queried to check that a backend is reachable again.
+	healthProbeInterface = "wg-ui-health-probe"
+)
+
+// ErrBackendUnavailable is returned without calling the backend while it is unhealthy and waiting to reconnect.
+var ErrBackendUnavailable = errors.New("backend is unavailable")
+
+// HealthPolicy configures when calls to a failing backend are short-circuited and how often it is reconnected.
+type HealthPolicy struct {
+	// FailureThreshold is the number of consecutive failures after which calls are short-circuited, 0 disables short-circuiting.
+	FailureThreshold int
+	// RetryBackoff is the delay of the first reconnect attempt, it doubles with every failed attempt.
+	RetryBackoff time.Duration
+	// MaxRetryBackoff caps the reconnect delay, 0 means no cap.
+	MaxRetryBackoff time.Duration
+	// ProbeTimeout limits a single reconnect attempt.
+	ProbeTimeout time.Duration
+}
+
+func (p HealthPolicy) backoff(consecutiveFailures int) time.Duration {
+	backoff := p.RetryBackoff
+	if backoff <= 0 {
+		backoff = defaultRetryBackoff
+	}
+
+	for i := p.FailureThreshold; i < consecutiveFailures; i++ {
+		backoff *= 2
+		if p.MaxRetryBackoff > 0 && backoff >= p.MaxRetryBackoff {
+			return p.MaxRetryBackoff
+		}
+	}
+	if p.MaxRetryBackoff > 0 && backoff > p.MaxRetryBackoff {
+		return p.MaxRetryBackoff
+	}
+	return backoff
+}
+
+func (p HealthPolicy) probeTimeout() time.Duration {
+	if p.ProbeTimeout <= 0 {
+		return defaultProbeTimeout
+	}
+	return p.ProbeTimeout
+}
+
+type healthTracker struct {
+	health      backend.Health
+	backendType string
+	rawURL      string
+	probeTimer  *time.Timer
+}
+
+type healthNotification struct {
+	backendId string
+	health    backend.Health
+}
+
+// OnHealthChanged sets the function notified when the health status of a backend changes,
+// it is called from a separate goroutine in the order of the changes.
+func (r *Registry) OnHealthChanged(fn func(backendId string, health backend.Health)) {
+	r.healthMu.Lock()
+	defer r.healthMu.Unlock()
+
+	r.onHealthChanged = fn
+}
+
+// Health returns the health of the backend, backends that have not been used yet have an unknown status.
+func (r *Registry) Health(backendId string) backend.Health {
+	r.healthMu.Lock()
+	defer r.healthMu.Unlock()
+
+	tracker, ok := r.health[backendId]
+	if !ok {
+		return backend.Health{Status: backend.HealthStatusUnknown}
+	}
+	return tracker.health
+}
+
+// checkHealth returns ErrBackendUnavailable while the backend is unhealthy.
+func (r *Registry) checkHealth(backendId string) error {
+	r.healthMu.Lock()
+	defer r.healthMu.Unlock()
+
+	tracker, ok := r.health[backendId]
+	if !ok || tracker.health.Status != backend.HealthStatusUnhealthy {
+		return nil
+	}
+
+	if tracker.health.NextRetryAt != nil {
+		return fmt.Errorf("%w: %s, reconnecting at %s", ErrBackendUnavailable, tracker.health.LastError, tracker.health.NextRetryAt.Format(time.RFC3339))
+	}
+	return fmt.Errorf("%w: %s", ErrBackendUnavailable, tracker.health.LastError)
+}
+
+// recordResult updates the health of the backend with the outcome of a call, failed reports that the backend
+// could not be reached rather than that the operation was rejected.
+func (r *Registry) recordResult(backendId string, backendType string, rawURL string, latency time.Duration, failed bool, err error) {
+	r.healthMu.Lock()
+	defer r.healthMu.Unlock()
+
+	tracker, ok := r.health[backendId]
+	if !ok || tracker.backendType != backendType || tracker.rawURL != rawURL {
+		if ok && tracker.probeTimer != nil {
+			tracker.probeTimer.Stop()
+		}
+		tracker = &healthTracker{
+			health:      backend.Health{Status: backend.HealthStatusUnknown},
+			backendType: backendType,
+			rawURL:      rawURL,
+		}
+		r.health[backendId] = tracker
+	}
+
+	// calls that were already running when the backend became unhealthy do not change its health,
+	// only the reconnect probe does
+	if tracker.health.Status == backend.HealthStatusUnhealthy {
+		return
+	}
+
+	r.updateHealth(backendId, tracker, latency, failed, err)
+}
+
+// updateHealth must be called with healthMu held.
+func (r *Registry) updateHealth(backendId string, tracker *healthTracker, latency time.Duration, failed bool, err error) {
+	previousStatus := tracker.health.Status
+	now := time.Now()
+
+	tracker.health.Latency = latency
+	if !failed {
+		tracker.health.Status = backend.HealthStatusHealthy
+		tracker.health.LastSuccessAt = &now
+		tracker.health.ConsecutiveFailures = 0
+		tracker.health.NextRetryAt = nil
+	} else {
+		tracker.health.LastFailureAt = &now
+		tracker.health.LastError = err.Error()
+		tracker.health.ConsecutiveFailures++
+
+		if r.healthPolicy.FailureThreshold > 0 && tracker.health.ConsecutiveFailures >= r.healthPolicy.FailureThreshold {
+			backoff := r.healthPolicy.backoff(tracker.health.ConsecutiveFailures)
+			nextRetryAt := now.Add(backoff)
+			tracker.health.Status = backend.HealthStatusUnhealthy
+			tracker.health.NextRetryAt = &nextRetryAt
+
+			if tracker.probeTimer != nil {
+				tracker.probeTimer.Stop()
+			}
+			tracker.probeTimer = time.AfterFunc(backoff, func() {
+				r.probe(backendId, tracker)
+			})
+		} else {
+			tracker.health.Status = backend.HealthStatusDegraded
+		}
+	}
+
+	if tracker.health.Status == previousStatus {
+		return
+	}
+
+	entry := logrus.
+		WithField("backendId", backendId).
+		WithField("type", tracker.backendType).
+		WithField("status", tracker.health.Status)
+	if failed {
+		entry.WithError(err).Warn("backend health changed")
+	} else {
+		entry.Info("backend health changed")
+	}
+
+	r.queueHealthNotification(backendId, tracker.health)
+}
+
+// probe recreates the backend connection of an unhealthy backend and checks that it responds.
+func (r *Registry) probe(backendId string, tracker *healthTracker) {
+	r.healthMu.Lock()
+	current, ok := r.health[backendId]
+	r.healthMu.Unlock()
+	if !ok || current != tracker {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), r.healthPolicy.probeTimeout())
+	defer cancel()
+
+	start := time.Now()
+	if err := r.remove(ctx, backendId); err != nil {
+		logrus.
+			WithError(err).
+			WithField("backendId", backendId).
+			Warn("failed to close unhealthy backend connection")
+	}
+
+	instance, err := r.GetOrCreate(ctx, backendId, tracker.backendType, tracker.rawURL)
+	failed := err != nil
+	if err == nil {
+		_, err = instance.Status(ctx, healthProbeInterface)
+		failed = isBackendFailure(err)
+	}
+	latency := time.Since(start)
+
+	r.healthMu.Lock()
+	defer r.healthMu.Unlock()
+
+	current, ok = r.health[backendId]
+	if !ok {
+		// the backend was removed while it was probed, close the connection the probe created
+		go func() {
+			_ = r.remove(context.Background(), backendId)
+		}()
+		return
+	}
+	if current != tracker {
+		return
+	}
+
+	tracker.probeTimer = nil
+	r.updateHealth(backendId, tracker, latency, failed, err)
+}
+
+// removeHealth stops tracking the backend, it must be called when the backend is removed.
+func (r *Registry) removeHealth(backendId string) {
+	r.healthMu.Lock()
+	defer r.healthMu.Unlock()
+
+	if tracker, ok := r.health[backendId]; ok && tracker.probeTimer != nil {
+		tracker.probeTimer.Stop()
+	}
+	delete(r.health, backendId)
+}
+
+// queueHealthNotification must be called with healthMu held.
+func (r *Registry) queueHealthNotification(backendId string, health backend.Health) {
+	if r.onHealthChanged == nil {
+		return
+	}
+
+	r.pendingHealthNotifications = append(r.pendingHealthNotifications, healthNotification{
+		backendId: backendId,
+		health:    health,
+	})
+	if r.notifyingHealth {
+		return
+	}
+	r.notifyingHealth = true
+
+	go r.dispatchHealthNotifications()
+}
+
+func (r *Registry) dispatchHealthNotifications() {
+	for {
+		r.healthMu.Lock()
+		if len(r.pendingHealthNotifications) == 0 {
+			r.notifyingHealth = false
+			r.healthMu.Unlock()
+			return
+		}
+		notification := r.pendingHealthNotifications[0]
+		r.pendingHealthNotifications = r.pendingHealthNotifications[1:]
+		fn := r.onHealthChanged
+		r.healthMu.Unlock()
+
+		if fn != nil {
+			fn(notification.backendId, notification.health)
+		}
+	}
+}
+
+// isBackendFailure reports whether the error means that the backend could not be reached,
+// errors returned by a reachable backend are not failures of the backend.
+func isBackendFailure(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) {
+		return false
+	}
+	if errors.Is(err, driver.ErrConnectionStale) || errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
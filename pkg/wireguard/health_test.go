@@ -0,0 +1,145 @@
+package wireguard
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/UnAfraid/wg-ui/pkg/backend"
+	"github.com/UnAfraid/wg-ui/pkg/wireguard/driver"
+)
+
+// unreachableBackend fails every call with a network error while it is unreachable.
+type unreachableBackend struct {
+	testBackend
+	unreachable *atomic.Bool
+	calls       *atomic.Int32
+}
+
+func (b *unreachableBackend) err() error {
+	b.calls.Add(1)
+	if b.unreachable.Load() {
+		return &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")}
+	}
+	return nil
+}
+
+func (b *unreachableBackend) Device(_ context.Context, name string) (*driver.Device, error) {
+	if err := b.err(); err != nil {
+		return nil, err
+	}
+	return &driver.Device{Wireguard: driver.Wireguard{Name: name}}, nil
+}
+
+func (b *unreachableBackend) Status(context.Context, string) (bool, error) {
+	return false, b.err()
+}
+
+func TestServiceShortCircuitsUnhealthyBackend(t *testing.T) {
+	scheme := fmt.Sprintf("health-test-%d", time.Now().UnixNano())
+
+	var unreachable atomic.Bool
+	var calls atomic.Int32
+	var created atomic.Int32
+	driver.Register(scheme, func(_ context.Context, _ string) (driver.Backend, error) {
+		created.Add(1)
+		return &unreachableBackend{unreachable: &unreachable, calls: &calls}, nil
+	}, true, driver.Capabilities{})
+
+	registry := NewRegistry(HealthPolicy{
+		FailureThreshold: 2,
+		RetryBackoff:     20 * time.Millisecond,
+	})
+	healthChanges := make(chan backend.Health, 10)
+	registry.OnHealthChanged(func(_ string, health backend.Health) {
+		healthChanges <- health
+	})
+
+	service := NewService(registry)
+	t.Cleanup(func() { _ = service.Close(context.Background()) })
+	ref := &retryBackendRef{id: "backend-id", backendType: scheme, url: scheme + "://"}
+	ctx := context.Background()
+
+	if health := service.BackendHealth(ref.ID()); health.Status != backend.HealthStatusUnknown {
+		t.Fatalf("expected unknown health before the first call, got %s", health.Status)
+	}
+
+	unreachable.Store(true)
+	for i := 0; i < 2; i++ {
+		if _, err := service.Device(ctx, ref, "wg0"); err == nil || errors.Is(err, ErrBackendUnavailable) {
+			t.Fatalf("expected the backend error, got %v", err)
+		}
+	}
+	health := service.BackendHealth(ref.ID())
+	if health.Status != backend.HealthStatusUnhealthy || health.ConsecutiveFailures != 2 || health.NextRetryAt == nil {
+		t.Fatalf("expected the backend to be unhealthy, got %+v", health)
+	}
+
+	callsBefore := calls.Load()
+	if _, err := service.Device(ctx, ref, "wg0"); !errors.Is(err, ErrBackendUnavailable) {
+		t.Fatalf("expected %v, got %v", ErrBackendUnavailable, err)
+	}
+	if calls.Load() != callsBefore {
+		t.Fatalf("expected the call to be short-circuited")
+	}
+
+	for _, expected := range []backend.HealthStatus{backend.HealthStatusDegraded, backend.HealthStatusUnhealthy} {
+		if health := <-healthChanges; health.Status != expected {
+			t.Fatalf("expected %s health change, got %s", expected, health.Status)
+		}
+	}
+
+	unreachable.Store(false)
+	select {
+	case health := <-healthChanges:
+		if health.Status != backend.HealthStatusHealthy || health.LastSuccessAt == nil {
+			t.Fatalf("expected the backend to recover, got %+v", health)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("expected the backend to be reconnected")
+	}
+
+	if created.Load() < 2 {
+		t.Fatalf("expected the backend to be recreated by the reconnect, got %d creations", created.Load())
+	}
+	if _, err := service.Device(ctx, ref, "wg0"); err != nil {
+		t.Fatalf("expected calls to be allowed again, got %v", err)
+	}
+}
+
+func TestServiceIgnoresErrorsOfReachableBackend(t *testing.T) {
+	scheme := fmt.Sprintf("health-test-%d", time.Now().UnixNano())
+	driver.Register(scheme, func(_ context.Context, _ string) (driver.Backend, error) {
+		return &retryBackend{deviceErr: errors.New("interface not found: wg0")}, nil
+	}, true, driver.Capabilities{})
+
+	service := NewService(NewRegistry(HealthPolicy{FailureThreshold: 1}))
+	ref := &retryBackendRef{id: "backend-id", backendType: scheme, url: scheme + "://"}
+
+	for i := 0; i < 3; i++ {
+		if _, err := service.Device(context.Background(), ref, "wg0"); err == nil || errors.Is(err, ErrBackendUnavailable) {
+			t.Fatalf("expected the backend error, got %v", err)
+		}
+	}
+	if health := service.BackendHealth(ref.ID()); health.Status != backend.HealthStatusHealthy {
+		t.Fatalf("expected the backend to stay healthy, got %+v", health)
+	}
+}
+
+func TestHealthPolicyBackoff(t *testing.T) {
+	policy := HealthPolicy{
+		FailureThreshold: 3,
+		RetryBackoff:     time.Second,
+		MaxRetryBackoff:  5 * time.Second,
+	}
+
+	for failures, expected := range map[int]time.Duration{3: time.Second, 4: 2 * time.Second, 5: 4 * time.Second, 6: 5 * time.Second, 60: 5 * time.Second} {
+		if backoff := policy.backoff(failures); backoff != expected {
+			t.Fatalf("expected backoff %s after %d failures, got %s", expected, failures, backoff)
+		}
+	}
+}
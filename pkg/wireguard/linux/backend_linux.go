@@ -18,16 +18,18 @@ import (
 
 	"github.com/UnAfraid/wg-ui/pkg/internal/adapt"
 	"github.com/UnAfraid/wg-ui/pkg/wireguard/driver"
+	"github.com/UnAfraid/wg-ui/pkg/wireguard/firewall"
 )
 
 func Register() {
 	driver.Register("linux", func(_ context.Context, rawURL string) (driver.Backend, error) {
 		return NewLinuxBackend(rawURL)
-	}, true)
+	}, true, capabilities)
 }
 
 type linuxBackend struct {
-	client *wgctrl.Client
+	client   *wgctrl.Client
+	firewall firewall.Manager
 }
 
 func NewLinuxBackend(_ string) (driver.Backend, error) {
@@ -37,7 +39,8 @@ func NewLinuxBackend(_ string) (driver.Backend, error) {
 	}
 
 	return &linuxBackend{
-		client: client,
+		client:   client,
+		firewall: firewall.NewNftablesManager(),
 	}, nil
 }
 
@@ -50,7 +53,7 @@ func (lb *linuxBackend) Device(_ context.Context, name string) (*driver.Device,
 	return wgDeviceToBackendDevice(device, name)
 }
 
-func (lb *linuxBackend) Up(_ context.Context, options driver.ConfigureOptions) (*driver.Device, error) {
+func (lb *linuxBackend) Up(ctx context.Context, options driver.ConfigureOptions) (*driver.Device, error) {
 	if err := options.Validate(); err != nil {
 		return nil, err
 	}
@@ -125,6 +128,58 @@ func (lb *linuxBackend) Down(_ context.Context, name string) error {
 	return deleteInterface(name)
 }
 
+func (lb *linuxBackend) UpsertPeer(_ context.Context, name string, peer *driver.PeerOptions) error {
+	peerConfig, err := wireguardPeerOptionsToPeerConfig(peer)
+	if err != nil {
+		return err
+	}
+	peerConfig.ReplaceAllowedIPs = true
+
+	return lb.configurePeers(name, peerConfig)
+}
+
+func (lb *linuxBackend) RemovePeer(_ context.Context, name string, publicKey string) error {
+	key, err := wgtypes.ParseKey(publicKey)
+	if err != nil {
+		return fmt.Errorf("invalid peer: %s public key: %w", publicKey, err)
+	}
+
+	return lb.configurePeers(name, wgtypes.PeerConfig{
+		PublicKey: key,
+		Remove:    true,
+	})
+}
+
+// configurePeers applies the peer changes without touching the other peers of the device and syncs its routes.
+func (lb *linuxBackend) configurePeers(name string, peers ...wgtypes.PeerConfig) error {
+	if err := lb.client.ConfigureDevice(name, wgtypes.Config{Peers: peers}); err != nil {
+		return fmt.Errorf("failed to configure device: %w", err)
+	}
+
+	device, err := lb.client.Device(name)
+	if err != nil {
+		return fmt.Errorf("failed to open wireguard device: %w", err)
+	}
+
+	var allowedIPs []net.IPNet
+	for _, p := range device.Peers {
+		allowedIPs = append(allowedIPs, p.AllowedIPs...)
+	}
+
+	if err := configureRoutes(name, allowedIPs); err != nil {
+		return fmt.Errorf("failed to configure routes: %w", err)
+	}
+	return nil
+}
+
+func (lb *linuxBackend) ApplyFirewall(ctx context.Context, options driver.ConfigureOptions) error {
+	return firewall.Sync(ctx, lb.firewall, options)
+}
+
+func (lb *linuxBackend) RemoveFirewall(ctx context.Context, name string) error {
+	return lb.firewall.Remove(ctx, name)
+}
+
 func (lb *linuxBackend) Status(_ context.Context, name string) (bool, error) {
 	link, err := findInterface(name)
 	if err != nil {
@@ -12,7 +12,7 @@ import (
 func Register() {
 	driver.Register("linux", func(_ context.Context, rawURL string) (driver.Backend, error) {
 		return NewLinuxBackend(rawURL)
-	}, false)
+	}, false, capabilities)
 }
 
 func NewLinuxBackend(_ string) (driver.Backend, error) {
@@ -0,0 +1,11 @@
+package linux
+
+import "github.com/UnAfraid/wg-ui/pkg/wireguard/driver"
+
+// capabilities of the netlink backend, DNS is not applied since there is no resolver integration.
+var capabilities = driver.Capabilities{
+	Firewall:        true,
+	LivePeerUpdates: true,
+	ForeignImport:   true,
+	PeerStats:       true,
+}
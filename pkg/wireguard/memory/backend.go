@@ -0,0 +1,383 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.zx2c4.com/wireguard/wgctrl/wgtypes"
+
+	"github.com/UnAfraid/wg-ui/pkg/wireguard/driver"
+)
+
+const (
+	defaultNetworkName = "default"
+	defaultMtu         = 1420
+	defaultByteRate    = 2048
+	foreignNamePrefix  = "wg-foreign"
+	simulatedVersion   = "memory"
+)
+
+// capabilities of the memory backend, everything is simulated so every optional feature is declared.
+var capabilities = driver.Capabilities{
+	DNS:             true,
+	Firewall:        true,
+	LivePeerUpdates: true,
+	ForeignImport:   true,
+	PeerStats:       true,
+}
+
+type parsedURL struct {
+	network     string
+	latency     *time.Duration
+	failureRate *float64
+	byteRate    *int64
+	foreign     int
+}
+
+type memoryBackend struct {
+	network    *Network
+	simulation *simulation
+}
+
+// Register registers the memory backend type, it never touches the host network and is meant for tests and demos,
+// every network name is a separate target.
+func Register() {
+	driver.Register("memory", func(_ context.Context, rawURL string) (driver.Backend, error) {
+		return NewMemoryBackend(rawURL)
+	}, true, capabilities, driver.RemoteTarget(""))
+}
+
+func NewMemoryBackend(rawURL string) (driver.Backend, error) {
+	parsed, err := parseURL(rawURL)
+	if err != nil {
+		return nil, err
+	}
+
+	network := GetNetwork(parsed.network)
+	if err := network.seedForeign(parsed.foreign); err != nil {
+		return nil, err
+	}
+
+	return &memoryBackend{
+		network: network,
+		simulation: &simulation{
+			latency:     parsed.latency,
+			failureRate: parsed.failureRate,
+			byteRate:    parsed.byteRate,
+		},
+	}, nil
+}
+
+func (b *memoryBackend) Device(ctx context.Context, name string) (*driver.Device, error) {
+	if err := b.network.begin(ctx, b.simulation, OperationDevice); err != nil {
+		return nil, err
+	}
+
+	b.network.mu.Lock()
+	defer b.network.mu.Unlock()
+
+	device, err := b.network.device(name, b.simulation)
+	if err != nil {
+		return nil, err
+	}
+	return device.toDevice(), nil
+}
+
+func (b *memoryBackend) Up(ctx context.Context, options driver.ConfigureOptions) (*driver.Device, error) {
+	if err := options.Validate(); err != nil {
+		return nil, err
+	}
+	if err := b.network.begin(ctx, b.simulation, OperationUp); err != nil {
+		return nil, err
+	}
+
+	b.network.mu.Lock()
+	defer b.network.mu.Unlock()
+
+	if err := b.network.up(options, b.simulation); err != nil {
+		return nil, err
+	}
+	return b.network.devices[options.InterfaceOptions.Name].toDevice(), nil
+}
+
+func (b *memoryBackend) Down(ctx context.Context, name string) error {
+	if err := b.network.begin(ctx, b.simulation, OperationDown); err != nil {
+		return err
+	}
+
+	b.network.mu.Lock()
+	defer b.network.mu.Unlock()
+
+	delete(b.network.devices, name)
+	return nil
+}
+
+func (b *memoryBackend) Status(ctx context.Context, name string) (bool, error) {
+	if err := b.network.begin(ctx, b.simulation, OperationStatus); err != nil {
+		return false, err
+	}
+
+	b.network.mu.Lock()
+	defer b.network.mu.Unlock()
+
+	_, ok := b.network.devices[name]
+	return ok, nil
+}
+
+func (b *memoryBackend) Stats(ctx context.Context, name string) (*driver.InterfaceStats, error) {
+	if err := b.network.begin(ctx, b.simulation, OperationStats); err != nil {
+		return nil, err
+	}
+
+	b.network.mu.Lock()
+	defer b.network.mu.Unlock()
+
+	device, err := b.network.device(name, b.simulation)
+	if err != nil {
+		return nil, err
+	}
+	return device.stats(), nil
+}
+
+func (b *memoryBackend) PeerStats(ctx context.Context, name string, peerPublicKey string) (*driver.PeerStats, error) {
+	if err := b.network.begin(ctx, b.simulation, OperationPeerStats); err != nil {
+		return nil, err
+	}
+
+	b.network.mu.Lock()
+	defer b.network.mu.Unlock()
+
+	device, err := b.network.device(name, b.simulation)
+	if err != nil {
+		return nil, err
+	}
+	for _, peer := range device.peers {
+		if peer.options.PublicKey == peerPublicKey {
+			stats := peer.stats
+			return &stats, nil
+		}
+	}
+	return nil, nil
+}
+
+func (b *memoryBackend) FindForeignServers(ctx context.Context, knownInterfaces []string) ([]*driver.ForeignServer, error) {
+	if err := b.network.begin(ctx, b.simulation, OperationFindForeignServers); err != nil {
+		return nil, err
+	}
+
+	known := make(map[string]struct{}, len(knownInterfaces))
+	for _, name := range knownInterfaces {
+		known[name] = struct{}{}
+	}
+
+	b.network.mu.Lock()
+	defer b.network.mu.Unlock()
+
+	var foreignServers []*driver.ForeignServer
+	for _, name := range b.network.sortedDeviceNames() {
+		if _, ok := known[name]; ok {
+			continue
+		}
+
+		state := b.network.devices[name]
+		b.network.advance(state, b.simulation)
+		device := state.toDevice()
+		foreignServers = append(foreignServers, &driver.ForeignServer{
+			Interface: &driver.ForeignInterface{
+				Name:      name,
+				Addresses: device.Interface.Addresses,
+				Mtu:       device.Interface.Mtu,
+				State:     "up",
+			},
+			Name:         name,
+			Description:  state.options.InterfaceOptions.Description,
+			Type:         "wireguard",
+			PublicKey:    device.Wireguard.PublicKey,
+			ListenPort:   device.Wireguard.ListenPort,
+			FirewallMark: device.Wireguard.FirewallMark,
+			Peers:        device.Wireguard.Peers,
+		})
+	}
+	return foreignServers, nil
+}
+
+func (b *memoryBackend) UpsertPeer(ctx context.Context, name string, peer *driver.PeerOptions) error {
+	if err := peer.Validate(); err != nil {
+		return err
+	}
+	if err := b.network.begin(ctx, b.simulation, OperationUpsertPeer); err != nil {
+		return err
+	}
+
+	b.network.mu.Lock()
+	defer b.network.mu.Unlock()
+
+	device, err := b.network.device(name, b.simulation)
+	if err != nil {
+		return err
+	}
+
+	state, err := b.network.upsertPeer(device, peer)
+	if err != nil {
+		return err
+	}
+
+	peers := device.options.WireguardOptions.Peers[:0:0]
+	for _, existing := range device.options.WireguardOptions.Peers {
+		if existing.PublicKey != peer.PublicKey {
+			peers = append(peers, existing)
+		}
+	}
+	device.options.WireguardOptions.Peers = append(peers, peer)
+
+	for _, existing := range device.peers {
+		if existing == state {
+			return nil
+		}
+	}
+	device.peers = append(device.peers, state)
+	return nil
+}
+
+func (b *memoryBackend) RemovePeer(ctx context.Context, name string, publicKey string) error {
+	if err := b.network.begin(ctx, b.simulation, OperationRemovePeer); err != nil {
+		return err
+	}
+
+	b.network.mu.Lock()
+	defer b.network.mu.Unlock()
+
+	device, err := b.network.device(name, b.simulation)
+	if err != nil {
+		return err
+	}
+
+	peerOptions := device.options.WireguardOptions.Peers[:0:0]
+	for _, peer := range device.options.WireguardOptions.Peers {
+		if peer.PublicKey != publicKey {
+			peerOptions = append(peerOptions, peer)
+		}
+	}
+	device.options.WireguardOptions.Peers = peerOptions
+
+	peers := device.peers[:0:0]
+	for _, peer := range device.peers {
+		if peer.options.PublicKey != publicKey {
+			peers = append(peers, peer)
+		}
+	}
+	device.peers = peers
+	return nil
+}
+
+func (b *memoryBackend) ApplyFirewall(ctx context.Context, options driver.ConfigureOptions) error {
+	if err := b.network.begin(ctx, b.simulation, OperationApplyFirewall); err != nil {
+		return err
+	}
+
+	b.network.mu.Lock()
+	defer b.network.mu.Unlock()
+
+	device, err := b.network.device(options.InterfaceOptions.Name, b.simulation)
+	if err != nil {
+		return err
+	}
+
+	device.firewall = nil
+	if options.ManagesFirewall() {
+		device.firewall = &options
+	}
+	return nil
+}
+
+func (b *memoryBackend) RemoveFirewall(ctx context.Context, name string) error {
+	if err := b.network.begin(ctx, b.simulation, OperationRemoveFirewall); err != nil {
+		return err
+	}
+
+	b.network.mu.Lock()
+	defer b.network.mu.Unlock()
+
+	if device, ok := b.network.devices[name]; ok {
+		device.firewall = nil
+	}
+	return nil
+}
+
+func (b *memoryBackend) Version(ctx context.Context) (string, error) {
+	if err := b.network.begin(ctx, b.simulation, OperationVersion); err != nil {
+		return "", err
+	}
+	return simulatedVersion, nil
+}
+
+// Close keeps the network, backends created later for the same url see the same interfaces.
+func (b *memoryBackend) Close(_ context.Context) error {
+	return nil
+}
+
+func publicKeyOf(privateKey string) (string, error) {
+	key, err := parseKey(privateKey)
+	if err != nil {
+		return "", fmt.Errorf("invalid private key: %w", err)
+	}
+	return key.PublicKey().String(), nil
+}
+
+func parseKey(key string) (wgtypes.Key, error) {
+	return wgtypes.ParseKey(strings.TrimSpace(key))
+}
+
+func parseURL(rawURL string) (*parsedURL, error) {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid memory backend url: %w", err)
+	}
+
+	if !strings.EqualFold(parsed.Scheme, "memory") {
+		return nil, fmt.Errorf("invalid backend type: %s", parsed.Scheme)
+	}
+
+	result := &parsedURL{
+		network: strings.ToLower(strings.TrimSpace(parsed.Host)),
+	}
+	if result.network == "" {
+		result.network = defaultNetworkName
+	}
+
+	query := parsed.Query()
+	if raw := strings.TrimSpace(query.Get("latency")); raw != "" {
+		latency, err := time.ParseDuration(raw)
+		if err != nil || latency < 0 {
+			return nil, fmt.Errorf("invalid latency value: %s", raw)
+		}
+		result.latency = &latency
+	}
+	if raw := strings.TrimSpace(query.Get("failureRate")); raw != "" {
+		failureRate, err := strconv.ParseFloat(raw, 64)
+		if err != nil || failureRate < 0 || failureRate > 1 {
+			return nil, fmt.Errorf("invalid failureRate value: %s, expected a number between 0 and 1", raw)
+		}
+		result.failureRate = &failureRate
+	}
+	if raw := strings.TrimSpace(query.Get("byteRate")); raw != "" {
+		byteRate, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil || byteRate < 0 {
+			return nil, fmt.Errorf("invalid byteRate value: %s", raw)
+		}
+		result.byteRate = &byteRate
+	}
+	if raw := strings.TrimSpace(query.Get("foreign")); raw != "" {
+		foreign, err := strconv.Atoi(raw)
+		if err != nil || foreign < 0 {
+			return nil, fmt.Errorf("invalid foreign value: %s", raw)
+		}
+		result.foreign = foreign
+	}
+
+	return result, nil
+}
@@ -0,0 +1,337 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.zx2c4.com/wireguard/wgctrl/wgtypes"
+
+	"github.com/UnAfraid/wg-ui/pkg/wireguard/driver"
+)
+
+type testClock struct {
+	now time.Time
+}
+
+func (c *testClock) Now() time.Time {
+	return c.now
+}
+
+func newTestBackend(t *testing.T, query string) (*memoryBackend, *Network, *testClock) {
+	t.Helper()
+
+	name := strings.ToLower(strings.ReplaceAll(t.Name(), "/", "-"))
+	t.Cleanup(func() { ResetNetwork(name) })
+
+	backend, err := NewMemoryBackend("memory://" + name + query)
+	if err != nil {
+		t.Fatalf("NewMemoryBackend returned error: %v", err)
+	}
+
+	clock := &testClock{now: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)}
+	network := GetNetwork(name)
+	network.SetClock(clock.Now)
+	return backend.(*memoryBackend), network, clock
+}
+
+func generateKey(t *testing.T) wgtypes.Key {
+	t.Helper()
+
+	key, err := wgtypes.GeneratePrivateKey()
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	return key
+}
+
+func testConfigureOptions(t *testing.T, peerPublicKeys ...string) driver.ConfigureOptions {
+	t.Helper()
+
+	var peers []*driver.PeerOptions
+	for _, publicKey := range peerPublicKeys {
+		peers = append(peers, &driver.PeerOptions{PublicKey: publicKey, AllowedIPs: []string{"10.0.0.2/32"}})
+	}
+
+	return driver.ConfigureOptions{
+		InterfaceOptions: driver.InterfaceOptions{
+			Name:    "wg0",
+			Address: "10.0.0.1/24",
+		},
+		WireguardOptions: driver.WireguardOptions{
+			PrivateKey: generateKey(t).String(),
+			Peers:      peers,
+		},
+	}
+}
+
+func TestUpAndDevice(t *testing.T) {
+	ctx := context.Background()
+	backend, _, _ := newTestBackend(t, "")
+
+	options := testConfigureOptions(t, generateKey(t).PublicKey().String())
+	device, err := backend.Up(ctx, options)
+	if err != nil {
+		t.Fatalf("Up returned error: %v", err)
+	}
+
+	privateKey, _ := wgtypes.ParseKey(options.WireguardOptions.PrivateKey)
+	if device.Wireguard.PublicKey != privateKey.PublicKey().String() {
+		t.Fatalf("expected the public key to be derived, got %q", device.Wireguard.PublicKey)
+	}
+	if device.Interface.Mtu != defaultMtu || device.Wireguard.ListenPort == 0 {
+		t.Fatalf("expected defaults to be applied, got %+v", device)
+	}
+	if len(device.Wireguard.Peers) != 1 || device.Wireguard.Peers[0].AllowedIPs[0].String() != "10.0.0.2/32" {
+		t.Fatalf("unexpected peers: %+v", device.Wireguard.Peers)
+	}
+
+	up, err := backend.Status(ctx, "wg0")
+	if err != nil || !up {
+		t.Fatalf("expected wg0 to be up, got %v, %v", up, err)
+	}
+
+	if err := backend.Down(ctx, "wg0"); err != nil {
+		t.Fatalf("Down returned error: %v", err)
+	}
+	if _, err := backend.Device(ctx, "wg0"); err == nil {
+		t.Fatalf("expected the device to be removed")
+	}
+
+	options.WireguardOptions.Peers[0].PublicKey = "invalid"
+	if _, err := backend.Up(ctx, options); err == nil {
+		t.Fatalf("expected an invalid peer key to fail")
+	}
+}
+
+func TestSimulatesHandshakesAndTraffic(t *testing.T) {
+	ctx := context.Background()
+	backend, network, clock := newTestBackend(t, "?byteRate=100")
+
+	alpha := generateKey(t).PublicKey().String()
+	bravo := generateKey(t).PublicKey().String()
+	if _, err := backend.Up(ctx, testConfigureOptions(t, alpha, bravo)); err != nil {
+		t.Fatalf("Up returned error: %v", err)
+	}
+	if err := network.SetPeerOnline("wg0", bravo, false); err != nil {
+		t.Fatalf("SetPeerOnline returned error: %v", err)
+	}
+
+	clock.now = clock.now.Add(10 * time.Second)
+	stats, err := backend.PeerStats(ctx, "wg0", alpha)
+	if err != nil {
+		t.Fatalf("PeerStats returned error: %v", err)
+	}
+	if stats.ReceiveBytes != 1000 || stats.TransmitBytes != 500 {
+		t.Fatalf("unexpected counters: %+v", stats)
+	}
+	if !stats.LastHandshakeTime.Equal(clock.now) || stats.Endpoint == "" {
+		t.Fatalf("expected a handshake from a simulated endpoint, got %+v", stats)
+	}
+
+	handshake := stats.LastHandshakeTime
+	clock.now = clock.now.Add(time.Minute)
+	if stats, _ = backend.PeerStats(ctx, "wg0", alpha); !stats.LastHandshakeTime.Equal(handshake) {
+		t.Fatalf("expected the handshake to be kept within the rekey interval, got %s", stats.LastHandshakeTime)
+	}
+	clock.now = clock.now.Add(handshakeInterval)
+	if stats, _ = backend.PeerStats(ctx, "wg0", alpha); !stats.LastHandshakeTime.Equal(clock.now) {
+		t.Fatalf("expected the handshake to be renewed, got %s", stats.LastHandshakeTime)
+	}
+
+	offline, err := backend.PeerStats(ctx, "wg0", bravo)
+	if err != nil {
+		t.Fatalf("PeerStats returned error: %v", err)
+	}
+	if offline.ReceiveBytes != 0 || !offline.LastHandshakeTime.IsZero() {
+		t.Fatalf("expected the offline peer to stay idle, got %+v", offline)
+	}
+
+	interfaceStats, err := backend.Stats(ctx, "wg0")
+	if err != nil {
+		t.Fatalf("Stats returned error: %v", err)
+	}
+	if interfaceStats.RxBytes != uint64(stats.ReceiveBytes) || interfaceStats.TxBytes != uint64(stats.TransmitBytes) {
+		t.Fatalf("expected the interface counters to be the sum of the peers, got %+v", interfaceStats)
+	}
+}
+
+func TestUpsertAndRemovePeer(t *testing.T) {
+	ctx := context.Background()
+	backend, _, clock := newTestBackend(t, "")
+
+	alpha := generateKey(t).PublicKey().String()
+	if _, err := backend.Up(ctx, testConfigureOptions(t, alpha)); err != nil {
+		t.Fatalf("Up returned error: %v", err)
+	}
+
+	clock.now = clock.now.Add(time.Second)
+	bravo := generateKey(t).PublicKey().String()
+	if err := backend.UpsertPeer(ctx, "wg0", &driver.PeerOptions{PublicKey: bravo, AllowedIPs: []string{"10.0.0.3/32"}}); err != nil {
+		t.Fatalf("UpsertPeer returned error: %v", err)
+	}
+	if err := backend.UpsertPeer(ctx, "wg0", &driver.PeerOptions{PublicKey: alpha, AllowedIPs: []string{"10.0.0.4/32"}}); err != nil {
+		t.Fatalf("UpsertPeer returned error: %v", err)
+	}
+	if err := backend.RemovePeer(ctx, "wg0", bravo); err != nil {
+		t.Fatalf("RemovePeer returned error: %v", err)
+	}
+
+	device, err := backend.Device(ctx, "wg0")
+	if err != nil {
+		t.Fatalf("Device returned error: %v", err)
+	}
+	if len(device.Wireguard.Peers) != 1 || device.Wireguard.Peers[0].AllowedIPs[0].String() != "10.0.0.4/32" {
+		t.Fatalf("unexpected peers: %+v", device.Wireguard.Peers)
+	}
+	if device.Wireguard.Peers[0].Stats.ReceiveBytes == 0 {
+		t.Fatalf("expected the stats of the updated peer to be kept")
+	}
+}
+
+func TestFindForeignServersReportsSeededInterfaces(t *testing.T) {
+	ctx := context.Background()
+	backend, network, _ := newTestBackend(t, "?foreign=2")
+
+	if _, err := backend.Up(ctx, testConfigureOptions(t)); err != nil {
+		t.Fatalf("Up returned error: %v", err)
+	}
+	seeded := testConfigureOptions(t, generateKey(t).PublicKey().String())
+	seeded.InterfaceOptions.Name = "wg-manual"
+	if err := network.Seed(seeded); err != nil {
+		t.Fatalf("Seed returned error: %v", err)
+	}
+
+	servers, err := backend.FindForeignServers(ctx, []string{"wg0"})
+	if err != nil {
+		t.Fatalf("FindForeignServers returned error: %v", err)
+	}
+
+	var names []string
+	for _, server := range servers {
+		names = append(names, server.Name)
+	}
+	if strings.Join(names, ",") != "wg-foreign0,wg-foreign1,wg-manual" {
+		t.Fatalf("unexpected foreign servers: %v", names)
+	}
+	if len(servers[0].Peers) != 2 || servers[0].Interface.State != "up" {
+		t.Fatalf("unexpected seeded server: %+v", servers[0])
+	}
+
+	if _, err := NewMemoryBackend("memory://" + strings.ToLower(t.Name()) + "?foreign=2"); err != nil {
+		t.Fatalf("NewMemoryBackend returned error: %v", err)
+	}
+	if servers, _ = backend.FindForeignServers(ctx, []string{"wg0"}); len(servers) != 3 {
+		t.Fatalf("expected seeding to be idempotent, got %d servers", len(servers))
+	}
+}
+
+func TestFaultInjection(t *testing.T) {
+	ctx := context.Background()
+	backend, network, _ := newTestBackend(t, "")
+
+	network.InjectFault(OperationStatus, driver.ErrConnectionStale, 1)
+	if _, err := backend.Status(ctx, "wg0"); !errors.Is(err, driver.ErrConnectionStale) {
+		t.Fatalf("expected %v, got %v", driver.ErrConnectionStale, err)
+	}
+	if _, err := backend.Status(ctx, "wg0"); err != nil {
+		t.Fatalf("expected the fault to be injected once, got %v", err)
+	}
+
+	injected := errors.New("boom")
+	network.InjectFault(OperationDown, injected, 0)
+	for i := 0; i < 3; i++ {
+		if err := backend.Down(ctx, "wg0"); !errors.Is(err, injected) {
+			t.Fatalf("expected %v, got %v", injected, err)
+		}
+	}
+	network.ClearFaults()
+	if err := backend.Down(ctx, "wg0"); err != nil {
+		t.Fatalf("expected the fault to be cleared, got %v", err)
+	}
+
+	network.SetFailureRate(1)
+	if _, err := backend.Status(ctx, "wg0"); !errors.Is(err, ErrSimulatedFailure) {
+		t.Fatalf("expected %v, got %v", ErrSimulatedFailure, err)
+	}
+	network.ClearFaults()
+
+	network.SetLatency(time.Hour)
+	timeoutCtx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+	if _, err := backend.Status(timeoutCtx, "wg0"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected the latency to respect the context, got %v", err)
+	}
+}
+
+func TestBackendsShareNetworkState(t *testing.T) {
+	ctx := context.Background()
+	backend, _, _ := newTestBackend(t, "")
+
+	if _, err := backend.Up(ctx, testConfigureOptions(t)); err != nil {
+		t.Fatalf("Up returned error: %v", err)
+	}
+	if err := backend.Close(ctx); err != nil {
+		t.Fatalf("Close returned error: %v", err)
+	}
+
+	recreated, err := NewMemoryBackend("memory://" + strings.ToLower(t.Name()))
+	if err != nil {
+		t.Fatalf("NewMemoryBackend returned error: %v", err)
+	}
+	if up, err := recreated.Status(ctx, "wg0"); err != nil || !up {
+		t.Fatalf("expected the recreated backend to see wg0, got %v, %v", up, err)
+	}
+
+	other, err := NewMemoryBackend("memory://other-" + strings.ToLower(t.Name()))
+	if err != nil {
+		t.Fatalf("NewMemoryBackend returned error: %v", err)
+	}
+	t.Cleanup(func() { ResetNetwork("other-" + strings.ToLower(t.Name())) })
+	if up, _ := other.Status(ctx, "wg0"); up {
+		t.Fatalf("expected networks to be isolated")
+	}
+}
+
+func TestURLSettingsApplyPerBackend(t *testing.T) {
+	ctx := context.Background()
+	backend, _, _ := newTestBackend(t, "")
+
+	failing, err := NewMemoryBackend("memory://" + strings.ToLower(t.Name()) + "?failureRate=1&latency=1ms")
+	if err != nil {
+		t.Fatalf("NewMemoryBackend returned error: %v", err)
+	}
+	if _, err := failing.Status(ctx, "wg0"); !errors.Is(err, ErrSimulatedFailure) {
+		t.Fatalf("expected %v, got %v", ErrSimulatedFailure, err)
+	}
+	if _, err := backend.Status(ctx, "wg0"); err != nil {
+		t.Fatalf("expected the settings of another backend not to apply, got %v", err)
+	}
+}
+
+func TestParseURL(t *testing.T) {
+	parsed, err := parseURL("memory://")
+	if err != nil {
+		t.Fatalf("parseURL returned error: %v", err)
+	}
+	if parsed.network != defaultNetworkName || parsed.latency != nil || parsed.failureRate != nil {
+		t.Fatalf("unexpected defaults: %+v", parsed)
+	}
+
+	parsed, err = parseURL("memory://Demo?latency=50ms&failureRate=0.1&byteRate=10&foreign=3")
+	if err != nil {
+		t.Fatalf("parseURL returned error: %v", err)
+	}
+	if parsed.network != "demo" || *parsed.latency != 50*time.Millisecond || *parsed.failureRate != 0.1 || *parsed.byteRate != 10 || parsed.foreign != 3 {
+		t.Fatalf("unexpected options: %+v", parsed)
+	}
+
+	if _, err := parseURL("memory://demo?failureRate=2"); err == nil {
+		t.Fatalf("expected an out of range failure rate to fail")
+	}
+	if _, err := parseURL("memory://demo?latency=soon"); err == nil {
+		t.Fatalf("expected an invalid latency to fail")
+	}
+}
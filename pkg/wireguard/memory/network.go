@@ -0,0 +1,501 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"math/rand/v2"
+	"net"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.zx2c4.com/wireguard/wgctrl/wgtypes"
+
+	"github.com/UnAfraid/wg-ui/pkg/wireguard/driver"
+)
+
+// ErrSimulatedFailure is returned by operations failing because of the configured failure rate.
+var ErrSimulatedFailure = errors.New("simulated memory backend failure")
+
+// handshakeInterval is how often connected peers renew their handshake, the same as wireguard rekeying.
+const handshakeInterval = 2 * time.Minute
+
+type Operation string
+
+const (
+	OperationDevice             Operation = "device"
+	OperationUp                 Operation = "up"
+	OperationDown               Operation = "down"
+	OperationStatus             Operation = "status"
+	OperationStats              Operation = "stats"
+	OperationPeerStats          Operation = "peer stats"
+	OperationFindForeignServers Operation = "find foreign servers"
+	OperationUpsertPeer         Operation = "upsert peer"
+	OperationRemovePeer         Operation = "remove peer"
+	OperationApplyFirewall      Operation = "apply firewall"
+	OperationRemoveFirewall     Operation = "remove firewall"
+	OperationVersion            Operation = "version"
+)
+
+type fault struct {
+	err       error
+	remaining int
+}
+
+type peerState struct {
+	options   driver.PeerOptions
+	stats     driver.PeerStats
+	offline   bool
+	updatedAt time.Time
+}
+
+type deviceState struct {
+	options    driver.ConfigureOptions
+	publicKey  string
+	listenPort int
+	firewall   *driver.ConfigureOptions
+	peers      []*peerState
+}
+
+// simulation holds the settings a backend url overrides, they apply only to the operations of that backend
+// so a backend created with other settings does not change the network seen by the others.
+type simulation struct {
+	latency     *time.Duration
+	failureRate *float64
+	byteRate    *int64
+}
+
+// Network is the simulated host of memory backends, all backends created for the same url host share it,
+// so its state survives the backend being recreated.
+type Network struct {
+	mu          sync.Mutex
+	devices     map[string]*deviceState
+	faults      map[Operation]*fault
+	calls       map[Operation]int
+	latency     time.Duration
+	failureRate float64
+	byteRate    int64
+	now         func() time.Time
+}
+
+var (
+	networksMu sync.Mutex
+	networks   = make(map[string]*Network)
+)
+
+// GetNetwork returns the network with the given name, it is created when it does not exist.
+func GetNetwork(name string) *Network {
+	networksMu.Lock()
+	defer networksMu.Unlock()
+
+	network, ok := networks[name]
+	if !ok {
+		network = newNetwork()
+		networks[name] = network
+	}
+	return network
+}
+
+// ResetNetwork removes the network with the given name, the next backend created for it starts empty.
+func ResetNetwork(name string) {
+	networksMu.Lock()
+	defer networksMu.Unlock()
+
+	delete(networks, name)
+}
+
+func newNetwork() *Network {
+	return &Network{
+		devices:  make(map[string]*deviceState),
+		faults:   make(map[Operation]*fault),
+		calls:    make(map[Operation]int),
+		byteRate: defaultByteRate,
+		now:      time.Now,
+	}
+}
+
+// InjectFault makes the next times calls of the operation fail with err, times <= 0 fails them until the fault is cleared.
+func (n *Network) InjectFault(operation Operation, err error, times int) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	n.faults[operation] = &fault{
+		err:       err,
+		remaining: times,
+	}
+}
+
+// Calls returns how often the operation was called on the network, including the failed calls.
+func (n *Network) Calls(operation Operation) int {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	return n.calls[operation]
+}
+
+// ClearFaults removes all injected faults and the failure rate.
+func (n *Network) ClearFaults() {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	n.faults = make(map[Operation]*fault)
+	n.failureRate = 0
+}
+
+// SetLatency delays every operation by the given duration.
+func (n *Network) SetLatency(latency time.Duration) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	n.latency = latency
+}
+
+// SetFailureRate makes the given fraction of all operations fail with ErrSimulatedFailure.
+func (n *Network) SetFailureRate(rate float64) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	n.failureRate = rate
+}
+
+// SetByteRate sets how many bytes per second connected peers receive, they transmit half as many.
+func (n *Network) SetByteRate(bytesPerSecond int64) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	n.byteRate = bytesPerSecond
+}
+
+// SetClock replaces the clock the traffic and handshakes are simulated with.
+func (n *Network) SetClock(now func() time.Time) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	n.now = now
+}
+
+// SetPeerOnline connects or disconnects a peer, disconnected peers stop handshaking and their counters stop.
+func (n *Network) SetPeerOnline(name string, publicKey string, online bool) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	device, ok := n.devices[name]
+	if !ok {
+		return fmt.Errorf("interface not found: %s", name)
+	}
+
+	n.advance(device, nil)
+	for _, peer := range device.peers {
+		if peer.options.PublicKey == publicKey {
+			peer.offline = !online
+			return nil
+		}
+	}
+	return fmt.Errorf("peer not found: %s", publicKey)
+}
+
+// Seed adds a running interface that is not managed by wg-ui, it is reported by FindForeignServers.
+func (n *Network) Seed(options driver.ConfigureOptions) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	if _, ok := n.devices[options.InterfaceOptions.Name]; ok {
+		return fmt.Errorf("interface already exists: %s", options.InterfaceOptions.Name)
+	}
+	return n.up(options, nil)
+}
+
+// FirewallOptions returns the options of the last applied firewall of the interface, nil when there is none.
+func (n *Network) FirewallOptions(name string) *driver.ConfigureOptions {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	device, ok := n.devices[name]
+	if !ok {
+		return nil
+	}
+	return device.firewall
+}
+
+// seedForeign seeds the given number of foreign interfaces with generated keys, existing ones are kept.
+func (n *Network) seedForeign(count int) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	for i := 0; i < count; i++ {
+		name := fmt.Sprintf("%s%d", foreignNamePrefix, i)
+		if _, ok := n.devices[name]; ok {
+			continue
+		}
+
+		privateKey, err := wgtypes.GeneratePrivateKey()
+		if err != nil {
+			return err
+		}
+
+		var peers []*driver.PeerOptions
+		for j := 0; j < 2; j++ {
+			peerKey, err := wgtypes.GeneratePrivateKey()
+			if err != nil {
+				return err
+			}
+			peers = append(peers, &driver.PeerOptions{
+				Name:       fmt.Sprintf("Peer #%d", j+1),
+				PublicKey:  peerKey.PublicKey().String(),
+				AllowedIPs: []string{fmt.Sprintf("10.200.%d.%d/32", i%256, j+2)},
+			})
+		}
+
+		if err := n.up(driver.ConfigureOptions{
+			InterfaceOptions: driver.InterfaceOptions{
+				Name:    name,
+				Address: fmt.Sprintf("10.200.%d.1/24", i%256),
+			},
+			WireguardOptions: driver.WireguardOptions{
+				PrivateKey: privateKey.String(),
+				Peers:      peers,
+			},
+		}, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (n *Network) sortedDeviceNames() []string {
+	names := make([]string, 0, len(n.devices))
+	for name := range n.devices {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// begin simulates the latency and returns the injected fault of the operation, the settings of sim take precedence
+// over the ones of the network.
+func (n *Network) begin(ctx context.Context, sim *simulation, operation Operation) error {
+	n.mu.Lock()
+	latency := n.latency
+	failureRate := n.failureRate
+	n.mu.Unlock()
+
+	if sim != nil && sim.latency != nil {
+		latency = *sim.latency
+	}
+	if sim != nil && sim.failureRate != nil {
+		failureRate = *sim.failureRate
+	}
+
+	if latency > 0 {
+		timer := time.NewTimer(latency)
+		defer timer.Stop()
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	n.calls[operation]++
+	if f, ok := n.faults[operation]; ok {
+		if f.remaining > 0 {
+			f.remaining--
+			if f.remaining == 0 {
+				delete(n.faults, operation)
+			}
+		}
+		return fmt.Errorf("%s: %w", operation, f.err)
+	}
+
+	if failureRate > 0 && rand.Float64() < failureRate {
+		return fmt.Errorf("%s: %w", operation, ErrSimulatedFailure)
+	}
+	return nil
+}
+
+func (n *Network) up(options driver.ConfigureOptions, sim *simulation) error {
+	publicKey, err := publicKeyOf(options.WireguardOptions.PrivateKey)
+	if err != nil {
+		return err
+	}
+
+	name := options.InterfaceOptions.Name
+	device, ok := n.devices[name]
+	if !ok {
+		device = &deviceState{}
+		n.devices[name] = device
+	} else {
+		n.advance(device, sim)
+	}
+
+	device.options = options
+	device.publicKey = publicKey
+	device.listenPort = listenPortOf(name, options.WireguardOptions.ListenPort)
+
+	peers := make([]*peerState, 0, len(options.WireguardOptions.Peers))
+	for _, peerOptions := range options.WireguardOptions.Peers {
+		peer, err := n.upsertPeer(device, peerOptions)
+		if err != nil {
+			return err
+		}
+		peers = append(peers, peer)
+	}
+	device.peers = peers
+	return nil
+}
+
+// upsertPeer updates the settings of an existing peer keeping its stats, or creates a new one, it is not added to the device.
+func (n *Network) upsertPeer(device *deviceState, options *driver.PeerOptions) (*peerState, error) {
+	if _, err := parseKey(options.PublicKey); err != nil {
+		return nil, fmt.Errorf("invalid peer public key: %w", err)
+	}
+	for _, allowedIP := range options.AllowedIPs {
+		if _, _, err := net.ParseCIDR(strings.TrimSpace(allowedIP)); err != nil {
+			return nil, fmt.Errorf("invalid allowed ip %q: %w", allowedIP, err)
+		}
+	}
+
+	for _, peer := range device.peers {
+		if peer.options.PublicKey == options.PublicKey {
+			peer.options = *options
+			return peer, nil
+		}
+	}
+
+	return &peerState{
+		options:   *options,
+		updatedAt: n.now(),
+	}, nil
+}
+
+// advance simulates the traffic and handshakes of the connected peers since they were last advanced.
+func (n *Network) advance(device *deviceState, sim *simulation) {
+	byteRate := n.byteRate
+	if sim != nil && sim.byteRate != nil {
+		byteRate = *sim.byteRate
+	}
+
+	now := n.now()
+	for _, peer := range device.peers {
+		elapsed := now.Sub(peer.updatedAt)
+		peer.updatedAt = now
+		if peer.offline || elapsed <= 0 {
+			continue
+		}
+
+		if peer.stats.LastHandshakeTime.IsZero() || now.Sub(peer.stats.LastHandshakeTime) >= handshakeInterval {
+			peer.stats.LastHandshakeTime = now
+		}
+		peer.stats.Endpoint = simulatedEndpoint(peer.options)
+		peer.stats.ProtocolVersion = 1
+
+		received := int64(elapsed.Seconds() * float64(byteRate))
+		peer.stats.ReceiveBytes += received
+		peer.stats.TransmitBytes += received / 2
+	}
+}
+
+func (n *Network) device(name string, sim *simulation) (*deviceState, error) {
+	device, ok := n.devices[name]
+	if !ok {
+		return nil, fmt.Errorf("interface not found: %s", name)
+	}
+	n.advance(device, sim)
+	return device, nil
+}
+
+func (d *deviceState) toDevice() *driver.Device {
+	peers := make([]*driver.Peer, 0, len(d.peers))
+	for _, peer := range d.peers {
+		peers = append(peers, peer.toPeer())
+	}
+
+	firewallMark := 0
+	if d.options.WireguardOptions.FirewallMark != nil {
+		firewallMark = *d.options.WireguardOptions.FirewallMark
+	}
+
+	name := d.options.InterfaceOptions.Name
+	return &driver.Device{
+		Interface: driver.Interface{
+			Name:      name,
+			Addresses: []string{d.options.InterfaceOptions.Address},
+			Mtu:       d.mtu(),
+		},
+		Wireguard: driver.Wireguard{
+			Name:         name,
+			PublicKey:    d.publicKey,
+			PrivateKey:   d.options.WireguardOptions.PrivateKey,
+			ListenPort:   d.listenPort,
+			FirewallMark: firewallMark,
+			Peers:        peers,
+		},
+	}
+}
+
+func (d *deviceState) mtu() int {
+	if d.options.InterfaceOptions.Mtu > 0 {
+		return d.options.InterfaceOptions.Mtu
+	}
+	return defaultMtu
+}
+
+func (d *deviceState) stats() *driver.InterfaceStats {
+	stats := &driver.InterfaceStats{}
+	for _, peer := range d.peers {
+		stats.RxBytes += uint64(peer.stats.ReceiveBytes)
+		stats.TxBytes += uint64(peer.stats.TransmitBytes)
+	}
+	return stats
+}
+
+func (p *peerState) toPeer() *driver.Peer {
+	var allowedIPs []net.IPNet
+	for _, allowedIP := range p.options.AllowedIPs {
+		_, ipNet, err := net.ParseCIDR(strings.TrimSpace(allowedIP))
+		if err != nil {
+			continue
+		}
+		allowedIPs = append(allowedIPs, *ipNet)
+	}
+
+	return &driver.Peer{
+		Name:                p.options.Name,
+		Description:         p.options.Description,
+		PublicKey:           p.options.PublicKey,
+		Endpoint:            p.options.Endpoint,
+		AllowedIPs:          allowedIPs,
+		PresharedKey:        p.options.PresharedKey,
+		PersistentKeepalive: time.Duration(p.options.PersistentKeepalive) * time.Second,
+		Stats:               p.stats,
+	}
+}
+
+// simulatedEndpoint returns the configured endpoint, or a stable documentation address derived from the public key.
+func simulatedEndpoint(options driver.PeerOptions) string {
+	if options.Endpoint != "" {
+		return options.Endpoint
+	}
+
+	hash := fnv.New32a()
+	_, _ = hash.Write([]byte(options.PublicKey))
+	sum := hash.Sum32()
+	return fmt.Sprintf("198.51.100.%d:%d", sum%254+1, 49152+sum%16384)
+}
+
+// listenPortOf returns the configured listen port, or a stable port derived from the name like the kernel would pick a random one.
+func listenPortOf(name string, listenPort *int) int {
+	if listenPort != nil && *listenPort > 0 {
+		return *listenPort
+	}
+
+	hash := fnv.New32a()
+	_, _ = hash.Write([]byte(name))
+	return 49152 + int(hash.Sum32()%16384)
+}
@@ -24,7 +24,7 @@ func Register() {
 	supported := isNetworkManagerAvailable()
 	driver.Register("networkmanager", func(_ context.Context, rawURL string) (driver.Backend, error) {
 		return NewNetworkManagerBackend(rawURL)
-	}, supported)
+	}, supported, capabilities)
 }
 
 func isNetworkManagerAvailable() bool {
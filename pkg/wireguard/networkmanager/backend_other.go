@@ -12,7 +12,7 @@ import (
 func Register() {
 	driver.Register("networkmanager", func(_ context.Context, rawURL string) (driver.Backend, error) {
 		return NewNetworkManagerBackend(rawURL)
-	}, false)
+	}, false, capabilities)
 }
 
 func NewNetworkManagerBackend(_ string) (driver.Backend, error) {
@@ -0,0 +1,9 @@
+package networkmanager
+
+import "github.com/UnAfraid/wg-ui/pkg/wireguard/driver"
+
+// capabilities of the NetworkManager backend, updated connection settings only take effect on reactivation.
+var capabilities = driver.Capabilities{
+	ForeignImport: true,
+	PeerStats:     true,
+}
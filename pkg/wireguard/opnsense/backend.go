@@ -0,0 +1,919 @@
+package opnsense
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.zx2c4.com/wireguard/wgctrl/wgtypes"
+
+	"github.com/UnAfraid/wg-ui/pkg/wireguard/driver"
+)
+
+const (
+	defaultHTTPSPort = "443"
+	defaultAPIPath   = "/api"
+	maxNameLength    = 64
+)
+
+// invalidNameCharacters matches the characters OPNsense rejects in instance and peer names.
+var invalidNameCharacters = regexp.MustCompile(`[^a-zA-Z0-9._-]`)
+
+type parsedURL struct {
+	baseURL            string
+	host               string
+	port               string
+	key                string
+	secret             string
+	insecureSkipVerify bool
+}
+
+type opnsenseBackend struct {
+	baseURL  string
+	key      string
+	secret   string
+	endpoint driver.Endpoint
+	client   *http.Client
+}
+
+type entry map[string]string
+
+// Register registers the opnsense backend type. Applying a configuration reconfigures the wireguard service
+// of the appliance, so peer changes are not applied live. The DNS servers are stored on the instance.
+func Register() {
+	driver.Register("opnsense", func(_ context.Context, rawURL string) (driver.Backend, error) {
+		return NewOPNsenseBackend(rawURL)
+	}, true, driver.Capabilities{
+		DNS:           true,
+		ForeignImport: true,
+		PeerStats:     true,
+	}, driver.RemoteTarget(defaultHTTPSPort))
+}
+
+func NewOPNsenseBackend(rawURL string) (driver.Backend, error) {
+	parsed, err := parseURL(rawURL)
+	if err != nil {
+		return nil, err
+	}
+
+	tlsConfig := &tls.Config{
+		InsecureSkipVerify: parsed.insecureSkipVerify, //nolint:gosec // explicitly user-controlled
+	}
+	transport := &http.Transport{
+		Proxy:           http.ProxyFromEnvironment,
+		TLSClientConfig: tlsConfig,
+	}
+
+	return &opnsenseBackend{
+		baseURL: parsed.baseURL,
+		key:     parsed.key,
+		secret:  parsed.secret,
+		endpoint: driver.Endpoint{
+			Host:      parsed.host,
+			Port:      parsed.port,
+			TLSConfig: tlsConfig,
+		},
+		client: &http.Client{
+			Transport: transport,
+			Timeout:   30 * time.Second,
+		},
+	}, nil
+}
+
+func (b *opnsenseBackend) Device(ctx context.Context, name string) (*driver.Device, error) {
+	server, err := b.findServerByName(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	if server == nil {
+		return nil, fmt.Errorf("interface not found: %s", name)
+	}
+
+	details, err := b.getServer(ctx, value(server, "uuid"))
+	if err != nil {
+		return nil, err
+	}
+
+	peers, err := b.serverPeers(ctx, server, details)
+	if err != nil {
+		return nil, err
+	}
+
+	return &driver.Device{
+		Interface: driver.Interface{
+			Name:      name,
+			Addresses: details.tunnelAddresses,
+			Mtu:       intValue(details.values, "mtu"),
+		},
+		Wireguard: driver.Wireguard{
+			Name:       name,
+			PublicKey:  value(details.values, "pubkey"),
+			PrivateKey: value(details.values, "privkey"),
+			ListenPort: intValue(details.values, "port"),
+			Peers:      peers,
+		},
+	}, nil
+}
+
+func (b *opnsenseBackend) Up(ctx context.Context, options driver.ConfigureOptions) (*driver.Device, error) {
+	if err := options.Validate(); err != nil {
+		return nil, err
+	}
+
+	name := options.InterfaceOptions.Name
+	if invalidNameCharacters.MatchString(name) {
+		return nil, fmt.Errorf("invalid opnsense instance name: %s", name)
+	}
+
+	privateKey, err := wgtypes.ParseKey(options.WireguardOptions.PrivateKey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid private key: %w", err)
+	}
+
+	server, err := b.findServerByName(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+
+	var existingPeerIds []string
+	if server != nil {
+		details, err := b.getServer(ctx, value(server, "uuid"))
+		if err != nil {
+			return nil, err
+		}
+		existingPeerIds = details.peerIds
+	}
+
+	peerIds, err := b.reconcileClients(ctx, name, existingPeerIds, options.WireguardOptions.Peers)
+	if err != nil {
+		return nil, err
+	}
+
+	payload := map[string]string{
+		"enabled":       "1",
+		"name":          name,
+		"privkey":       privateKey.String(),
+		"pubkey":        privateKey.PublicKey().String(),
+		"tunneladdress": options.InterfaceOptions.Address,
+		"dns":           strings.Join(options.InterfaceOptions.DNS, ","),
+		"peers":         strings.Join(peerIds, ","),
+		"port":          "",
+		"mtu":           "",
+	}
+	if options.WireguardOptions.ListenPort != nil {
+		payload["port"] = strconv.Itoa(*options.WireguardOptions.ListenPort)
+	}
+	if options.InterfaceOptions.Mtu > 0 {
+		payload["mtu"] = strconv.Itoa(options.InterfaceOptions.Mtu)
+	}
+
+	if server == nil {
+		if _, err := b.save(ctx, "wireguard/server/addServer", "server", payload); err != nil {
+			return nil, err
+		}
+	} else {
+		if _, err := b.save(ctx, "wireguard/server/setServer/"+value(server, "uuid"), "server", payload); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := b.reconfigure(ctx); err != nil {
+		return nil, err
+	}
+
+	return b.Device(ctx, name)
+}
+
+// Down disables the instance instead of deleting it, so that its configuration stays on the appliance.
+func (b *opnsenseBackend) Down(ctx context.Context, name string) error {
+	server, err := b.findServerByName(ctx, name)
+	if err != nil {
+		return err
+	}
+	if server == nil || !boolValue(server, "enabled") {
+		return nil
+	}
+
+	if _, err := b.post(ctx, "wireguard/server/toggleServer/"+value(server, "uuid")+"/0", nil); err != nil {
+		return err
+	}
+	return b.reconfigure(ctx)
+}
+
+func (b *opnsenseBackend) Status(ctx context.Context, name string) (bool, error) {
+	server, err := b.findServerByName(ctx, name)
+	if err != nil {
+		return false, err
+	}
+	if server == nil || !boolValue(server, "enabled") {
+		return false, nil
+	}
+
+	rows, err := b.statusRows(ctx, server)
+	if err != nil {
+		return false, err
+	}
+	for _, row := range rows {
+		if value(row, "type") == "interface" {
+			return !strings.EqualFold(value(row, "status"), "down"), nil
+		}
+	}
+	return false, nil
+}
+
+// Stats sums the transfer counters of the peers, OPNsense does not report the counters of the interface.
+func (b *opnsenseBackend) Stats(ctx context.Context, name string) (*driver.InterfaceStats, error) {
+	server, err := b.findServerByName(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	if server == nil {
+		return nil, fmt.Errorf("interface not found: %s", name)
+	}
+
+	rows, err := b.statusRows(ctx, server)
+	if err != nil {
+		return nil, err
+	}
+
+	stats := &driver.InterfaceStats{}
+	for _, row := range rows {
+		if value(row, "type") != "peer" {
+			continue
+		}
+		stats.RxBytes += uint64(max(0, int64Value(row, "transfer-rx")))
+		stats.TxBytes += uint64(max(0, int64Value(row, "transfer-tx")))
+	}
+	return stats, nil
+}
+
+func (b *opnsenseBackend) PeerStats(ctx context.Context, name string, peerPublicKey string) (*driver.PeerStats, error) {
+	server, err := b.findServerByName(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	if server == nil {
+		return nil, fmt.Errorf("interface not found: %s", name)
+	}
+
+	rows, err := b.statusRows(ctx, server)
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		if value(row, "type") == "peer" && value(row, "public-key") == peerPublicKey {
+			stats := peerStatsFromRow(row)
+			return &stats, nil
+		}
+	}
+	return nil, nil
+}
+
+func (b *opnsenseBackend) FindForeignServers(ctx context.Context, knownInterfaces []string) ([]*driver.ForeignServer, error) {
+	known := make(map[string]struct{}, len(knownInterfaces))
+	for _, name := range knownInterfaces {
+		known[strings.ToLower(strings.TrimSpace(name))] = struct{}{}
+	}
+
+	servers, err := b.search(ctx, "wireguard/server/searchServer")
+	if err != nil {
+		return nil, err
+	}
+
+	foreignServers := make([]*driver.ForeignServer, 0, len(servers))
+	for _, server := range servers {
+		name := value(server, "name")
+		if name == "" {
+			continue
+		}
+		if _, ok := known[strings.ToLower(name)]; ok {
+			continue
+		}
+
+		details, err := b.getServer(ctx, value(server, "uuid"))
+		if err != nil {
+			return nil, err
+		}
+
+		peers, err := b.serverPeers(ctx, server, details)
+		if err != nil {
+			return nil, err
+		}
+
+		state := "down"
+		if up, err := b.Status(ctx, name); err == nil && up {
+			state = "up"
+		}
+
+		foreignServers = append(foreignServers, &driver.ForeignServer{
+			Interface: &driver.ForeignInterface{
+				Name:      name,
+				Addresses: details.tunnelAddresses,
+				Mtu:       intValue(details.values, "mtu"),
+				State:     state,
+			},
+			Name:       name,
+			Type:       "wireguard",
+			PublicKey:  value(details.values, "pubkey"),
+			ListenPort: intValue(details.values, "port"),
+			Peers:      peers,
+		})
+	}
+
+	sort.Slice(foreignServers, func(i, j int) bool {
+		return strings.ToLower(foreignServers[i].Name) < strings.ToLower(foreignServers[j].Name)
+	})
+
+	return foreignServers, nil
+}
+
+func (b *opnsenseBackend) Endpoint() driver.Endpoint {
+	return b.endpoint
+}
+
+func (b *opnsenseBackend) Version(ctx context.Context) (string, error) {
+	responseBytes, err := b.request(ctx, http.MethodGet, "core/firmware/info", nil)
+	if err != nil {
+		return "", err
+	}
+
+	var response struct {
+		ProductVersion string `json:"product_version"`
+		Product        struct {
+			ProductVersion string `json:"product_version"`
+		} `json:"product"`
+	}
+	if err := json.Unmarshal(responseBytes, &response); err != nil {
+		return "", fmt.Errorf("failed to decode opnsense firmware info: %w", err)
+	}
+	if response.Product.ProductVersion != "" {
+		return response.Product.ProductVersion, nil
+	}
+	return response.ProductVersion, nil
+}
+
+func (b *opnsenseBackend) Close(_ context.Context) error {
+	b.client.CloseIdleConnections()
+	return nil
+}
+
+// reconcileClients creates, updates and deletes the peers (clients in OPNsense) of an instance,
+// it returns the ids of the desired peers in order.
+func (b *opnsenseBackend) reconcileClients(ctx context.Context, serverName string, existingPeerIds []string, desiredPeers []*driver.PeerOptions) ([]string, error) {
+	clients, err := b.search(ctx, "wireguard/client/searchClient")
+	if err != nil {
+		return nil, err
+	}
+
+	existingByPublicKey := make(map[string]entry)
+	for _, client := range clients {
+		for _, id := range existingPeerIds {
+			if value(client, "uuid") == id {
+				existingByPublicKey[value(client, "pubkey")] = client
+			}
+		}
+	}
+
+	peerIds := make([]string, 0, len(desiredPeers))
+	for _, peer := range desiredPeers {
+		payload, err := clientPayload(serverName, peer)
+		if err != nil {
+			return nil, err
+		}
+
+		existing, ok := existingByPublicKey[peer.PublicKey]
+		if !ok {
+			id, err := b.save(ctx, "wireguard/client/addClient", "client", payload)
+			if err != nil {
+				return nil, fmt.Errorf("failed to add peer %s: %w", peer.PublicKey, err)
+			}
+			peerIds = append(peerIds, id)
+			continue
+		}
+
+		delete(existingByPublicKey, peer.PublicKey)
+		if _, err := b.save(ctx, "wireguard/client/setClient/"+value(existing, "uuid"), "client", payload); err != nil {
+			return nil, fmt.Errorf("failed to update peer %s: %w", peer.PublicKey, err)
+		}
+		peerIds = append(peerIds, value(existing, "uuid"))
+	}
+
+	for publicKey, client := range existingByPublicKey {
+		if _, err := b.post(ctx, "wireguard/client/delClient/"+value(client, "uuid"), nil); err != nil {
+			return nil, fmt.Errorf("failed to delete peer %s: %w", publicKey, err)
+		}
+	}
+
+	return peerIds, nil
+}
+
+func clientPayload(serverName string, p *driver.PeerOptions) (map[string]string, error) {
+	serverAddress, serverPort, err := splitEndpoint(p.Endpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	payload := map[string]string{
+		"enabled":       "1",
+		"name":          clientName(serverName, p),
+		"pubkey":        p.PublicKey,
+		"psk":           p.PresharedKey,
+		"tunneladdress": strings.Join(p.AllowedIPs, ","),
+		"serveraddress": serverAddress,
+		"serverport":    serverPort,
+		"keepalive":     "",
+	}
+	if p.PersistentKeepalive > 0 {
+		payload["keepalive"] = strconv.Itoa(p.PersistentKeepalive)
+	}
+	return payload, nil
+}
+
+// clientName derives a name OPNsense accepts, peer names have to be unique across all instances.
+func clientName(serverName string, p *driver.PeerOptions) string {
+	name := p.Name
+	if strings.TrimSpace(name) == "" {
+		name = p.PublicKey
+		if len(name) > 8 {
+			name = name[:8]
+		}
+	}
+
+	name = invalidNameCharacters.ReplaceAllString(serverName+"-"+name, "_")
+	if len(name) > maxNameLength {
+		name = name[:maxNameLength]
+	}
+	return name
+}
+
+func splitEndpoint(endpoint string) (string, string, error) {
+	endpoint = strings.TrimSpace(endpoint)
+	if endpoint == "" {
+		return "", "", nil
+	}
+
+	host, port, err := net.SplitHostPort(endpoint)
+	if err != nil {
+		return "", "", fmt.Errorf("invalid endpoint %q: %w", endpoint, err)
+	}
+	if _, err := strconv.ParseUint(port, 10, 16); err != nil {
+		return "", "", fmt.Errorf("invalid endpoint port %q: %w", port, err)
+	}
+	return host, port, nil
+}
+
+// serverPeers returns the peers of an instance with the runtime stats of the ones that are connected.
+func (b *opnsenseBackend) serverPeers(ctx context.Context, server entry, details *serverDetails) ([]*driver.Peer, error) {
+	if len(details.peerIds) == 0 {
+		return nil, nil
+	}
+
+	clients, err := b.search(ctx, "wireguard/client/searchClient")
+	if err != nil {
+		return nil, err
+	}
+
+	statsByPublicKey := make(map[string]driver.PeerStats)
+	if boolValue(server, "enabled") {
+		rows, err := b.statusRows(ctx, server)
+		if err != nil {
+			return nil, err
+		}
+		for _, row := range rows {
+			if value(row, "type") == "peer" {
+				statsByPublicKey[value(row, "public-key")] = peerStatsFromRow(row)
+			}
+		}
+	}
+
+	clientsById := make(map[string]entry, len(clients))
+	for _, client := range clients {
+		clientsById[value(client, "uuid")] = client
+	}
+
+	peers := make([]*driver.Peer, 0, len(details.peerIds))
+	for _, id := range details.peerIds {
+		client, ok := clientsById[id]
+		if !ok {
+			continue
+		}
+
+		endpoint := ""
+		if serverAddress := value(client, "serveraddress"); serverAddress != "" {
+			endpoint = net.JoinHostPort(serverAddress, cmpOr(value(client, "serverport"), "51820"))
+		}
+
+		publicKey := value(client, "pubkey")
+		peers = append(peers, &driver.Peer{
+			Name:                value(client, "name"),
+			PublicKey:           publicKey,
+			Endpoint:            endpoint,
+			AllowedIPs:          parseAllowedIPs(value(client, "tunneladdress")),
+			PersistentKeepalive: time.Duration(intValue(client, "keepalive")) * time.Second,
+			Stats:               statsByPublicKey[publicKey],
+		})
+	}
+	return peers, nil
+}
+
+func cmpOr(v string, fallback string) string {
+	if v == "" {
+		return fallback
+	}
+	return v
+}
+
+func peerStatsFromRow(row entry) driver.PeerStats {
+	var lastHandshakeTime time.Time
+	if handshake := int64Value(row, "latest-handshake"); handshake > 0 {
+		lastHandshakeTime = time.Unix(handshake, 0)
+	}
+
+	return driver.PeerStats{
+		Endpoint:          value(row, "endpoint"),
+		LastHandshakeTime: lastHandshakeTime,
+		ReceiveBytes:      int64Value(row, "transfer-rx"),
+		TransmitBytes:     int64Value(row, "transfer-tx"),
+		ProtocolVersion:   1,
+	}
+}
+
+func parseAllowedIPs(allowed string) []net.IPNet {
+	var ipNets []net.IPNet
+	for _, allowedIP := range strings.Split(allowed, ",") {
+		allowedIP = strings.TrimSpace(allowedIP)
+		if allowedIP == "" {
+			continue
+		}
+		_, ipNet, err := net.ParseCIDR(allowedIP)
+		if err != nil {
+			continue
+		}
+		ipNets = append(ipNets, *ipNet)
+	}
+	return ipNets
+}
+
+// statusRows returns the runtime status rows of the interface of an instance, OPNsense names it by the instance number.
+func (b *opnsenseBackend) statusRows(ctx context.Context, server entry) ([]entry, error) {
+	rows, err := b.search(ctx, "wireguard/service/show")
+	if err != nil {
+		return nil, err
+	}
+
+	interfaceName := "wg" + value(server, "instance")
+	var serverRows []entry
+	for _, row := range rows {
+		if value(row, "if") == interfaceName {
+			serverRows = append(serverRows, row)
+		}
+	}
+	return serverRows, nil
+}
+
+func (b *opnsenseBackend) findServerByName(ctx context.Context, name string) (entry, error) {
+	servers, err := b.search(ctx, "wireguard/server/searchServer")
+	if err != nil {
+		return nil, err
+	}
+	for _, server := range servers {
+		if value(server, "name") == name {
+			return server, nil
+		}
+	}
+	return nil, nil
+}
+
+type serverDetails struct {
+	values          entry
+	tunnelAddresses []string
+	peerIds         []string
+}
+
+// getServer reads an instance, the multi value fields are returned by OPNsense as options with a selected flag.
+func (b *opnsenseBackend) getServer(ctx context.Context, id string) (*serverDetails, error) {
+	responseBytes, err := b.request(ctx, http.MethodGet, "wireguard/server/getServer/"+id, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var response struct {
+		Server map[string]any `json:"server"`
+	}
+	if err := json.Unmarshal(responseBytes, &response); err != nil {
+		return nil, fmt.Errorf("failed to decode opnsense server: %w", err)
+	}
+	if response.Server == nil {
+		return nil, fmt.Errorf("opnsense server not found: %s", id)
+	}
+
+	details := &serverDetails{
+		values:          make(entry, len(response.Server)),
+		tunnelAddresses: selectedOptions(response.Server["tunneladdress"]),
+		peerIds:         selectedOptions(response.Server["peers"]),
+	}
+	for key, raw := range response.Server {
+		if _, ok := raw.(map[string]any); !ok {
+			details.values[key] = stringify(raw)
+		}
+	}
+	return details, nil
+}
+
+func selectedOptions(raw any) []string {
+	switch typed := raw.(type) {
+	case string:
+		var values []string
+		for _, v := range strings.Split(typed, ",") {
+			if v = strings.TrimSpace(v); v != "" {
+				values = append(values, v)
+			}
+		}
+		return values
+	case map[string]any:
+		var values []string
+		for key, option := range typed {
+			optionValues, ok := option.(map[string]any)
+			if !ok || !parseBool(stringify(optionValues["selected"])) {
+				continue
+			}
+			values = append(values, key)
+		}
+		sort.Strings(values)
+		return values
+	default:
+		return nil
+	}
+}
+
+func (b *opnsenseBackend) reconfigure(ctx context.Context) error {
+	responseBytes, err := b.post(ctx, "wireguard/service/reconfigure", nil)
+	if err != nil {
+		return fmt.Errorf("failed to reconfigure wireguard: %w", err)
+	}
+
+	var response struct {
+		Status string `json:"status"`
+	}
+	if err := json.Unmarshal(responseBytes, &response); err != nil {
+		return fmt.Errorf("failed to decode opnsense reconfigure response: %w", err)
+	}
+	if !strings.EqualFold(strings.TrimSpace(response.Status), "ok") {
+		return fmt.Errorf("failed to reconfigure wireguard: %s", response.Status)
+	}
+	return nil
+}
+
+// save adds or updates a model item, it returns the id of added items.
+func (b *opnsenseBackend) save(ctx context.Context, resource string, item string, payload map[string]string) (string, error) {
+	responseBytes, err := b.post(ctx, resource, map[string]map[string]string{item: payload})
+	if err != nil {
+		return "", err
+	}
+
+	var response struct {
+		Result      string            `json:"result"`
+		UUID        string            `json:"uuid"`
+		Validations map[string]string `json:"validations"`
+	}
+	if err := json.Unmarshal(responseBytes, &response); err != nil {
+		return "", fmt.Errorf("failed to decode opnsense response: %w", err)
+	}
+
+	if response.Result != "saved" {
+		messages := make([]string, 0, len(response.Validations))
+		for field, message := range response.Validations {
+			messages = append(messages, fmt.Sprintf("%s: %s", field, message))
+		}
+		sort.Strings(messages)
+		if len(messages) == 0 {
+			messages = append(messages, response.Result)
+		}
+		return "", fmt.Errorf("opnsense %s failed: %s", resource, strings.Join(messages, ", "))
+	}
+	return response.UUID, nil
+}
+
+func (b *opnsenseBackend) search(ctx context.Context, resource string) ([]entry, error) {
+	responseBytes, err := b.request(ctx, http.MethodGet, resource, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var response struct {
+		Rows []map[string]any `json:"rows"`
+	}
+	if err := json.Unmarshal(responseBytes, &response); err != nil {
+		return nil, fmt.Errorf("failed to decode opnsense %s response: %w", resource, err)
+	}
+
+	entries := make([]entry, 0, len(response.Rows))
+	for _, row := range response.Rows {
+		entries = append(entries, normalizeEntry(row))
+	}
+	return entries, nil
+}
+
+func (b *opnsenseBackend) post(ctx context.Context, resource string, payload any) ([]byte, error) {
+	if payload == nil {
+		payload = struct{}{}
+	}
+	return b.request(ctx, http.MethodPost, resource, payload)
+}
+
+func (b *opnsenseBackend) request(ctx context.Context, method string, resource string, payload any) ([]byte, error) {
+	requestURL := strings.TrimRight(b.baseURL, "/") + "/" + strings.TrimPrefix(resource, "/")
+
+	var requestBody io.Reader
+	if payload != nil {
+		bodyBytes, err := json.Marshal(payload)
+		if err != nil {
+			return nil, err
+		}
+		requestBody = bytes.NewReader(bodyBytes)
+	}
+
+	request, err := http.NewRequestWithContext(ctx, method, requestURL, requestBody)
+	if err != nil {
+		return nil, err
+	}
+	request.SetBasicAuth(b.key, b.secret)
+	request.Header.Set("Accept", "application/json")
+	if payload != nil {
+		request.Header.Set("Content-Type", "application/json")
+	}
+
+	response, err := b.client.Do(request)
+	if err != nil {
+		return nil, fmt.Errorf("opnsense api request failed: %w", err)
+	}
+	defer response.Body.Close()
+
+	responseBody, err := io.ReadAll(response.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read opnsense api response: %w", err)
+	}
+
+	if response.StatusCode >= http.StatusOK && response.StatusCode < http.StatusMultipleChoices {
+		return responseBody, nil
+	}
+
+	message := strings.TrimSpace(string(responseBody))
+	if message == "" {
+		message = response.Status
+	}
+
+	switch response.StatusCode {
+	case http.StatusUnauthorized:
+		return nil, fmt.Errorf("opnsense api %s %s failed: %w: %s", method, resource, driver.ErrUnauthorized, message)
+	case http.StatusForbidden:
+		return nil, fmt.Errorf("opnsense api %s %s failed: %w: %s", method, resource, driver.ErrPermissionDenied, message)
+	}
+	return nil, fmt.Errorf("opnsense api %s %s failed: %s", method, resource, message)
+}
+
+func normalizeEntry(raw map[string]any) entry {
+	normalized := make(entry, len(raw))
+	for key, value := range raw {
+		normalized[key] = stringify(value)
+	}
+	return normalized
+}
+
+func stringify(value any) string {
+	switch typed := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return typed
+	case bool:
+		if typed {
+			return "1"
+		}
+		return "0"
+	case float64:
+		if math.Mod(typed, 1) == 0 {
+			return strconv.FormatInt(int64(typed), 10)
+		}
+		return strconv.FormatFloat(typed, 'f', -1, 64)
+	default:
+		return fmt.Sprint(typed)
+	}
+}
+
+func value(values entry, key string) string {
+	return strings.TrimSpace(values[key])
+}
+
+func intValue(values entry, key string) int {
+	parsed, err := strconv.Atoi(value(values, key))
+	if err != nil {
+		return 0
+	}
+	return parsed
+}
+
+func int64Value(values entry, key string) int64 {
+	parsed, err := strconv.ParseInt(value(values, key), 10, 64)
+	if err != nil {
+		return 0
+	}
+	return parsed
+}
+
+func boolValue(values entry, key string) bool {
+	return parseBool(value(values, key))
+}
+
+func parseBool(raw string) bool {
+	switch strings.ToLower(strings.TrimSpace(raw)) {
+	case "true", "yes", "on", "1":
+		return true
+	default:
+		return false
+	}
+}
+
+func parseURL(rawURL string) (*parsedURL, error) {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid opnsense backend url: %w", err)
+	}
+
+	if !strings.EqualFold(parsed.Scheme, "opnsense") {
+		return nil, fmt.Errorf("invalid backend type: %s", parsed.Scheme)
+	}
+
+	host := strings.TrimSpace(parsed.Hostname())
+	if host == "" {
+		return nil, errors.New("opnsense backend requires host")
+	}
+
+	if parsed.User == nil {
+		return nil, errors.New("opnsense backend requires api key and secret")
+	}
+	key := strings.TrimSpace(parsed.User.Username())
+	secret, hasSecret := parsed.User.Password()
+	if key == "" || !hasSecret || secret == "" {
+		return nil, errors.New("opnsense backend requires api key and secret")
+	}
+
+	insecureSkipVerify, err := queryBool(parsed.Query(), "insecureSkipVerify", false)
+	if err != nil {
+		return nil, err
+	}
+
+	port := strings.TrimSpace(parsed.Port())
+	if port == "" {
+		port = defaultHTTPSPort
+	}
+
+	path := strings.TrimRight(strings.TrimSpace(parsed.Path), "/")
+	if path == "" {
+		path = defaultAPIPath
+	} else if !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+
+	return &parsedURL{
+		baseURL:            fmt.Sprintf("https://%s%s", net.JoinHostPort(host, port), path),
+		host:               host,
+		port:               port,
+		key:                key,
+		secret:             secret,
+		insecureSkipVerify: insecureSkipVerify,
+	}, nil
+}
+
+func queryBool(values url.Values, key string, defaultValue bool) (bool, error) {
+	raw := strings.TrimSpace(values.Get(key))
+	if raw == "" {
+		return defaultValue, nil
+	}
+
+	if parsed, err := strconv.ParseBool(raw); err == nil {
+		return parsed, nil
+	}
+
+	switch strings.ToLower(raw) {
+	case "yes", "on":
+		return true, nil
+	case "no", "off":
+		return false, nil
+	default:
+		return false, fmt.Errorf("invalid %s value: %s", key, raw)
+	}
+}
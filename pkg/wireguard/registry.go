@@ -13,11 +13,19 @@ import (
 	"github.com/UnAfraid/wg-ui/pkg/wireguard/driver"
 )
 
-// Registry manages active backend connections keyed by backend entity ID
+// Registry manages active backend connections keyed by backend entity ID, and tracks their health
 type Registry struct {
 	mu          sync.RWMutex
 	backends    map[string]*registryBackend
 	createGroup singleflight.Group
+
+	healthPolicy               HealthPolicy
+	healthMu                   sync.Mutex
+	health                     map[string]*healthTracker
+	onHealthChanged            func(backendId string, health backend.Health)
+	pendingHealthNotifications []healthNotification
+	notifyingHealth            bool
+	fence                      func(ctx context.Context) error
 }
 
 type registryBackend struct {
@@ -27,9 +35,11 @@ type registryBackend struct {
 }
 
 // NewRegistry creates a new connection registry
-func NewRegistry() *Registry {
+func NewRegistry(healthPolicy HealthPolicy) *Registry {
 	return &Registry{
-		backends: make(map[string]*registryBackend),
+		backends:     make(map[string]*registryBackend),
+		healthPolicy: healthPolicy,
+		health:       make(map[string]*healthTracker),
 	}
 }
 
@@ -138,8 +148,34 @@ func (r *Registry) GetOrCreate(ctx context.Context, backendId string, backendTyp
 	return instance, nil
 }
 
-// Remove removes and closes a backend connection
+// SetFence sets the function checked before every device change, an error rejects the change before
+// the backend is contacted.
+func (r *Registry) SetFence(fn func(ctx context.Context) error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.fence = fn
+}
+
+func (r *Registry) checkFence(ctx context.Context) error {
+	r.mu.RLock()
+	fence := r.fence
+	r.mu.RUnlock()
+
+	if fence == nil {
+		return nil
+	}
+	return fence(ctx)
+}
+
+// Remove removes and closes a backend connection and stops tracking its health
 func (r *Registry) Remove(ctx context.Context, backendId string) error {
+	r.removeHealth(backendId)
+	return r.remove(ctx, backendId)
+}
+
+// remove closes a backend connection, the next call creates a new one.
+func (r *Registry) remove(ctx context.Context, backendId string) error {
 	r.mu.Lock()
 	entry, ok := r.backends[backendId]
 	if !ok {
@@ -173,6 +209,15 @@ func (r *Registry) CloseAll(ctx context.Context) error {
 	r.backends = make(map[string]*registryBackend)
 	r.mu.Unlock()
 
+	r.healthMu.Lock()
+	for _, tracker := range r.health {
+		if tracker.probeTimer != nil {
+			tracker.probeTimer.Stop()
+		}
+	}
+	r.health = make(map[string]*healthTracker)
+	r.healthMu.Unlock()
+
 	var errs []error
 	for id, entry := range backends {
 		if err := entry.instance.Close(ctx); err != nil {
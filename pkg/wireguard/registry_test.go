@@ -56,9 +56,9 @@ func TestRegistryRecreatesBackendWhenURLChanges(t *testing.T) {
 		b := &testBackend{}
 		created[rawURL] = b
 		return b, nil
-	}, true)
+	}, true, driver.Capabilities{})
 
-	registry := NewRegistry()
+	registry := NewRegistry(HealthPolicy{})
 	ctx := context.Background()
 
 	backend1, err := registry.GetOrCreate(ctx, "backend-id", scheme, "scheme:///first")
@@ -99,9 +99,9 @@ func TestRegistryGetOrCreateConcurrentSingleflight(t *testing.T) {
 		atomic.AddInt32(&createCalls, 1)
 		time.Sleep(20 * time.Millisecond)
 		return &testBackend{}, nil
-	}, true)
+	}, true, driver.Capabilities{})
 
-	registry := NewRegistry()
+	registry := NewRegistry(HealthPolicy{})
 	ctx := context.Background()
 
 	const workers = 32
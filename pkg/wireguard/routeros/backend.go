@@ -30,6 +30,8 @@ var errRouterOSResourceNotFound = errors.New("routeros resource not found")
 
 type parsedURL struct {
 	baseURL            string
+	host               string
+	port               string
 	username           string
 	password           string
 	insecureSkipVerify bool
@@ -39,6 +41,7 @@ type routerOSBackend struct {
 	baseURL  string
 	username string
 	password string
+	endpoint driver.Endpoint
 	client   *http.Client
 }
 
@@ -47,7 +50,12 @@ type entry map[string]string
 func Register() {
 	driver.Register("routeros", func(_ context.Context, rawURL string) (driver.Backend, error) {
 		return NewRouterOSBackend(rawURL)
-	}, true)
+	}, true, driver.Capabilities{
+		Firewall:        true,
+		LivePeerUpdates: true,
+		ForeignImport:   true,
+		PeerStats:       true,
+	}, driver.RemoteTarget(defaultHTTPSPort))
 }
 
 func NewRouterOSBackend(rawURL string) (driver.Backend, error) {
@@ -56,17 +64,23 @@ func NewRouterOSBackend(rawURL string) (driver.Backend, error) {
 		return nil, err
 	}
 
+	tlsConfig := &tls.Config{
+		InsecureSkipVerify: parsed.insecureSkipVerify, //nolint:gosec // explicitly user-controlled
+	}
 	transport := &http.Transport{
-		Proxy: http.ProxyFromEnvironment,
-		TLSClientConfig: &tls.Config{
-			InsecureSkipVerify: parsed.insecureSkipVerify, //nolint:gosec // explicitly user-controlled
-		},
+		Proxy:           http.ProxyFromEnvironment,
+		TLSClientConfig: tlsConfig,
 	}
 
 	return &routerOSBackend{
 		baseURL:  parsed.baseURL,
 		username: parsed.username,
 		password: parsed.password,
+		endpoint: driver.Endpoint{
+			Host:      parsed.host,
+			Port:      parsed.port,
+			TLSConfig: tlsConfig,
+		},
 		client: &http.Client{
 			Transport: transport,
 			Timeout:   30 * time.Second,
@@ -306,6 +320,21 @@ func (b *routerOSBackend) FindForeignServers(ctx context.Context, knownInterface
 	return foreignServers, nil
 }
 
+func (b *routerOSBackend) Endpoint() driver.Endpoint {
+	return b.endpoint
+}
+
+func (b *routerOSBackend) Version(ctx context.Context) (string, error) {
+	entries, err := b.listEntries(ctx, "system/resource")
+	if err != nil {
+		return "", err
+	}
+	if len(entries) == 0 {
+		return "", errors.New("routeros system resource not found")
+	}
+	return value(entries[0], "version"), nil
+}
+
 func (b *routerOSBackend) Close(_ context.Context) error {
 	return nil
 }
@@ -392,23 +421,10 @@ func (b *routerOSBackend) reconcilePeers(ctx context.Context, iface entry, desir
 			if _, isDynamic := dynamicByPublicKey[publicKey]; isDynamic {
 				continue
 			}
-
-			if err := b.putEntry(ctx, "interface/wireguard/peers", payload); err != nil {
-				return err
-			}
-			continue
-		}
-
-		if peerNeedsPatch(existing[0], payload) {
-			if err := b.patchEntry(ctx, "interface/wireguard/peers", value(existing[0], ".id"), payload); err != nil {
-				return err
-			}
 		}
 
-		for _, duplicate := range existing[1:] {
-			if err := b.deleteEntry(ctx, "interface/wireguard/peers", value(duplicate, ".id")); err != nil {
-				return err
-			}
+		if err := b.applyPeerEntries(ctx, payload, existing); err != nil {
+			return err
 		}
 	}
 
@@ -427,6 +443,80 @@ func (b *routerOSBackend) reconcilePeers(ctx context.Context, iface entry, desir
 	return nil
 }
 
+// applyPeerEntries makes the static entries of a peer match the payload, duplicates are deleted.
+func (b *routerOSBackend) applyPeerEntries(ctx context.Context, payload map[string]string, existing []entry) error {
+	if len(existing) == 0 {
+		return b.putEntry(ctx, "interface/wireguard/peers", payload)
+	}
+
+	if peerNeedsPatch(existing[0], payload) {
+		if err := b.patchEntry(ctx, "interface/wireguard/peers", value(existing[0], ".id"), payload); err != nil {
+			return err
+		}
+	}
+
+	for _, duplicate := range existing[1:] {
+		if err := b.deleteEntry(ctx, "interface/wireguard/peers", value(duplicate, ".id")); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (b *routerOSBackend) UpsertPeer(ctx context.Context, name string, peer *driver.PeerOptions) error {
+	iface, err := b.findWireguardInterfaceByName(ctx, name)
+	if err != nil {
+		return err
+	}
+	if iface == nil {
+		return fmt.Errorf("interface not found: %s", name)
+	}
+
+	payload, err := peerPayload(value(iface, "name"), peer)
+	if err != nil {
+		return err
+	}
+
+	existing, err := b.interfacePeerEntriesByPublicKey(ctx, iface, strings.TrimSpace(peer.PublicKey))
+	if err != nil {
+		return err
+	}
+
+	var static []entry
+	for _, p := range existing {
+		if boolValue(p, "dynamic") {
+			return nil
+		}
+		static = append(static, p)
+	}
+	return b.applyPeerEntries(ctx, payload, static)
+}
+
+func (b *routerOSBackend) RemovePeer(ctx context.Context, name string, publicKey string) error {
+	iface, err := b.findWireguardInterfaceByName(ctx, name)
+	if err != nil {
+		return err
+	}
+	if iface == nil {
+		return nil
+	}
+
+	existing, err := b.interfacePeerEntriesByPublicKey(ctx, iface, strings.TrimSpace(publicKey))
+	if err != nil {
+		return err
+	}
+
+	for _, p := range existing {
+		if boolValue(p, "dynamic") {
+			continue
+		}
+		if err := b.deleteEntry(ctx, "interface/wireguard/peers", value(p, ".id")); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func peerPayload(interfaceName string, p *driver.PeerOptions) (map[string]string, error) {
 	allowedAddress := strings.Join(p.AllowedIPs, ",")
 	endpointAddress, endpointPort, err := splitEndpoint(p.Endpoint)
@@ -549,6 +639,30 @@ func (b *routerOSBackend) interfacePeerEntries(ctx context.Context, iface entry)
 	return filtered, nil
 }
 
+// interfacePeerEntriesByPublicKey queries the peers with the public key instead of listing all peers of the router.
+func (b *routerOSBackend) interfacePeerEntriesByPublicKey(ctx context.Context, iface entry, publicKey string) ([]entry, error) {
+	peerEntries, err := b.listEntries(ctx, "interface/wireguard/peers?public-key="+url.QueryEscape(publicKey))
+	if err != nil {
+		return nil, err
+	}
+
+	interfaceName := value(iface, "name")
+	interfaceID := value(iface, ".id")
+
+	filtered := make([]entry, 0, len(peerEntries))
+	for _, peerEntry := range peerEntries {
+		interfaceValue := value(peerEntry, "interface")
+		if strings.TrimSpace(value(peerEntry, "public-key")) != publicKey {
+			continue
+		}
+		if strings.EqualFold(interfaceValue, interfaceName) || (interfaceID != "" && interfaceValue == interfaceID) {
+			filtered = append(filtered, peerEntry)
+		}
+	}
+
+	return filtered, nil
+}
+
 func peerEntriesToPeers(peerEntries []entry) []*driver.Peer {
 	peers := make([]*driver.Peer, 0, len(peerEntries))
 	for _, peerEntry := range peerEntries {
@@ -1002,8 +1116,13 @@ func (b *routerOSBackend) request(ctx context.Context, method string, resource s
 		message = response.Status
 	}
 
-	if response.StatusCode == http.StatusNotFound {
+	switch response.StatusCode {
+	case http.StatusNotFound:
 		return nil, fmt.Errorf("%w: %s", errRouterOSResourceNotFound, message)
+	case http.StatusUnauthorized:
+		return nil, fmt.Errorf("routeros api %s %s failed: %w: %s", method, resource, driver.ErrUnauthorized, message)
+	case http.StatusForbidden:
+		return nil, fmt.Errorf("routeros api %s %s failed: %w: %s", method, resource, driver.ErrPermissionDenied, message)
 	}
 
 	return nil, fmt.Errorf("routeros api %s %s failed: %s", method, resource, message)
@@ -1228,6 +1347,8 @@ func parseURL(rawURL string) (*parsedURL, error) {
 	baseURL := fmt.Sprintf("https://%s%s", net.JoinHostPort(host, port), path)
 	return &parsedURL{
 		baseURL:            baseURL,
+		host:               host,
+		port:               port,
 		username:           username,
 		password:           password,
 		insecureSkipVerify: insecureSkipVerify,
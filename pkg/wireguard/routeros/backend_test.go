@@ -3,11 +3,15 @@ package routeros
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
+
+	"github.com/UnAfraid/wg-ui/pkg/wireguard/driver"
 )
 
 func TestParseURLDefaults(t *testing.T) {
@@ -353,3 +357,98 @@ func TestEndpointValueFormatsIPv6CurrentEndpoint(t *testing.T) {
 		t.Fatalf("expected IPv6 endpoint to be bracketed, got %q", got)
 	}
 }
+
+func TestUpsertAndRemovePeerQueryByPublicKey(t *testing.T) {
+	var requests []string
+	var patched map[string]string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		requests = append(requests, r.Method+" "+r.URL.Path)
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/rest/interface/wireguard":
+			_, _ = io.WriteString(w, `[{"name":"wg0",".id":"*1"}]`)
+		case r.Method == http.MethodGet && r.URL.Path == "/rest/interface/wireguard/peers":
+			if r.URL.Query().Get("public-key") != "alpha=" {
+				t.Errorf("expected a public key query, got %q", r.URL.RawQuery)
+			}
+			_, _ = io.WriteString(w, `[
+				{".id":"*A","interface":"wg0","public-key":"alpha=","allowed-address":"10.0.0.2/32"},
+				{".id":"*B","interface":"wg1","public-key":"alpha=","allowed-address":"10.1.0.2/32"}
+			]`)
+		case r.Method == http.MethodPatch && r.URL.Path == "/rest/interface/wireguard/peers/*A":
+			if err := json.NewDecoder(r.Body).Decode(&patched); err != nil {
+				t.Errorf("failed to decode patch payload: %v", err)
+			}
+			_, _ = io.WriteString(w, `{}`)
+		case r.Method == http.MethodDelete && r.URL.Path == "/rest/interface/wireguard/peers/*A":
+			_, _ = io.WriteString(w, `{}`)
+		default:
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	}))
+	defer server.Close()
+
+	backend := &routerOSBackend{
+		baseURL:  server.URL + "/rest",
+		username: "api",
+		password: "secret",
+		client:   server.Client(),
+	}
+
+	err := backend.UpsertPeer(context.Background(), "wg0", &driver.PeerOptions{
+		PublicKey:  "alpha=",
+		AllowedIPs: []string{"10.0.0.3/32"},
+	})
+	if err != nil {
+		t.Fatalf("UpsertPeer returned error: %v", err)
+	}
+	if patched["allowed-address"] != "10.0.0.3/32" {
+		t.Fatalf("expected the peer of wg0 to be patched, got %+v", patched)
+	}
+
+	requests = nil
+	if err := backend.RemovePeer(context.Background(), "wg0", "alpha="); err != nil {
+		t.Fatalf("RemovePeer returned error: %v", err)
+	}
+	if deletes := strings.Count(strings.Join(requests, "\n"), http.MethodDelete); deletes != 1 {
+		t.Fatalf("expected only the peer of wg0 to be deleted, got %v", requests)
+	}
+}
+
+func TestVersionReportsRejectedCredentials(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet || r.URL.Path != "/rest/system/resource" {
+			t.Fatalf("unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+		if _, password, _ := r.BasicAuth(); password != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = io.WriteString(w, `{"error":401,"message":"Unauthorized"}`)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = io.WriteString(w, `{"version":"7.15.2 (stable)","board-name":"CHR"}`)
+	}))
+	defer server.Close()
+
+	backend := &routerOSBackend{
+		baseURL:  server.URL + "/rest",
+		username: "api",
+		password: "secret",
+		client:   server.Client(),
+	}
+
+	version, err := backend.Version(context.Background())
+	if err != nil {
+		t.Fatalf("Version returned error: %v", err)
+	}
+	if version != "7.15.2 (stable)" {
+		t.Fatalf("unexpected version: %q", version)
+	}
+
+	backend.password = "wrong"
+	if _, err := backend.Version(context.Background()); !errors.Is(err, driver.ErrUnauthorized) {
+		t.Fatalf("expected %v, got %v", driver.ErrUnauthorized, err)
+	}
+}
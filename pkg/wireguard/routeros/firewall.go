@@ -0,0 +1,185 @@
+package routeros
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"strconv"
+	"strings"
+
+	"github.com/UnAfraid/wg-ui/pkg/wireguard/driver"
+	"github.com/UnAfraid/wg-ui/pkg/wireguard/firewall"
+)
+
+const firewallCommentPrefix = "wg-ui:"
+
+var firewallResources = []string{
+	"ip/firewall/filter",
+	"ip/firewall/nat",
+	"ipv6/firewall/filter",
+	"ipv6/firewall/nat",
+}
+
+// firewallEntryKeys are compared to decide whether the managed entries of a resource are up to date.
+var firewallEntryKeys = []string{"chain", "action", "in-interface", "out-interface", "src-address", "dst-address", "protocol", "dst-port"}
+
+// routerOSFirewall manages the /ip/firewall and /ipv6/firewall entries of an interface, the entries are
+// tagged with a comment and replaced as a whole whenever they differ from the ruleset.
+// IP forwarding is always enabled on RouterOS, so it is not managed.
+type routerOSFirewall struct {
+	backend *routerOSBackend
+}
+
+func (b *routerOSBackend) ApplyFirewall(ctx context.Context, options driver.ConfigureOptions) error {
+	return firewall.Sync(ctx, &routerOSFirewall{backend: b}, options)
+}
+
+func (b *routerOSBackend) RemoveFirewall(ctx context.Context, name string) error {
+	return (&routerOSFirewall{backend: b}).Remove(ctx, name)
+}
+
+func (f *routerOSFirewall) Apply(ctx context.Context, ruleset *firewall.Ruleset) error {
+	desired := firewallEntries(ruleset)
+	for _, resource := range firewallResources {
+		if err := f.reconcile(ctx, resource, firewallComment(ruleset.Interface), desired[resource]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (f *routerOSFirewall) Remove(ctx context.Context, interfaceName string) error {
+	for _, resource := range firewallResources {
+		if err := f.reconcile(ctx, resource, firewallComment(interfaceName), nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (f *routerOSFirewall) reconcile(ctx context.Context, resource string, comment string, desired []map[string]string) error {
+	entries, err := f.backend.listEntries(ctx, resource)
+	if err != nil {
+		return fmt.Errorf("failed to list %s: %w", resource, err)
+	}
+
+	var existing []entry
+	for _, e := range entries {
+		if value(e, "comment") == comment {
+			existing = append(existing, e)
+		}
+	}
+	if firewallEntriesEqual(existing, desired) {
+		return nil
+	}
+
+	for _, e := range existing {
+		if err := f.backend.deleteEntry(ctx, resource, value(e, ".id")); err != nil {
+			return fmt.Errorf("failed to delete %s entry: %w", resource, err)
+		}
+	}
+	for _, payload := range desired {
+		if err := f.backend.putEntry(ctx, resource, payload); err != nil {
+			return fmt.Errorf("failed to add %s entry: %w", resource, err)
+		}
+	}
+	return nil
+}
+
+// firewallEntries renders the ruleset as RouterOS entries by resource, in the order they have to be added.
+func firewallEntries(ruleset *firewall.Ruleset) map[string][]map[string]string {
+	comment := firewallComment(ruleset.Interface)
+	entries := make(map[string][]map[string]string)
+
+	for _, rule := range ruleset.Forward {
+		payload := map[string]string{
+			"chain":        "forward",
+			"action":       string(rule.Verdict),
+			"in-interface": ruleset.Interface,
+			"comment":      comment,
+		}
+		if rule.OutputInterface != "" {
+			payload["out-interface"] = rule.OutputInterface
+		}
+		if rule.Source.IsValid() {
+			payload["src-address"] = rule.Source.String()
+		}
+		if rule.Destination.IsValid() {
+			payload["dst-address"] = rule.Destination.String()
+		}
+		if rule.FromPort != 0 {
+			payload["dst-port"] = strconv.Itoa(int(rule.FromPort))
+			if rule.ToPort != rule.FromPort {
+				payload["dst-port"] += "-" + strconv.Itoa(int(rule.ToPort))
+			}
+		}
+
+		for _, resource := range forwardRuleResources(ruleset, rule) {
+			if rule.Protocol != firewall.ProtocolAny {
+				payload = maps.Clone(payload)
+				payload["protocol"] = string(rule.Protocol)
+				if rule.Protocol == firewall.ProtocolICMP && strings.HasPrefix(resource, "ipv6/") {
+					payload["protocol"] = "icmpv6"
+				}
+			}
+			entries[resource] = append(entries[resource], payload)
+		}
+	}
+
+	for _, rule := range ruleset.Masquerade {
+		resource := "ip/firewall/nat"
+		if rule.Source.Addr().Is6() {
+			resource = "ipv6/firewall/nat"
+		}
+		entries[resource] = append(entries[resource], map[string]string{
+			"chain":         "srcnat",
+			"action":        "masquerade",
+			"src-address":   rule.Source.String(),
+			"out-interface": rule.OutputInterface,
+			"comment":       comment,
+		})
+	}
+
+	return entries
+}
+
+// forwardRuleResources returns the filter resources of the address families a forward rule applies to.
+func forwardRuleResources(ruleset *firewall.Ruleset, rule firewall.ForwardRule) []string {
+	prefix := rule.Source
+	if !prefix.IsValid() {
+		prefix = rule.Destination
+	}
+	if prefix.IsValid() {
+		if prefix.Addr().Is6() {
+			return []string{"ipv6/firewall/filter"}
+		}
+		return []string{"ip/firewall/filter"}
+	}
+
+	var resources []string
+	if ruleset.IPv4 {
+		resources = append(resources, "ip/firewall/filter")
+	}
+	if ruleset.IPv6 {
+		resources = append(resources, "ipv6/firewall/filter")
+	}
+	return resources
+}
+
+func firewallEntriesEqual(existing []entry, desired []map[string]string) bool {
+	if len(existing) != len(desired) {
+		return false
+	}
+	for i, e := range existing {
+		for _, key := range firewallEntryKeys {
+			if !strings.EqualFold(value(e, key), desired[i][key]) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func firewallComment(interfaceName string) string {
+	return firewallCommentPrefix + interfaceName
+}
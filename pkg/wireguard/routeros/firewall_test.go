@@ -0,0 +1,129 @@
+package routeros
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+
+	"github.com/UnAfraid/wg-ui/pkg/wireguard/firewall"
+)
+
+func TestFirewallEntriesSplitByFamily(t *testing.T) {
+	entries := firewallEntries(&firewall.Ruleset{
+		Interface: "wg0",
+		IPv4:      true,
+		IPv6:      true,
+		Forward: []firewall.ForwardRule{
+			{OutputInterface: "wg0", Verdict: firewall.VerdictDrop},
+			{Source: netip.MustParsePrefix("fd00::2/128"), Verdict: firewall.VerdictDrop},
+		},
+		Masquerade: []firewall.MasqueradeRule{
+			{Source: netip.MustParsePrefix("10.0.0.0/24"), OutputInterface: "ether1"},
+		},
+	})
+
+	if len(entries["ip/firewall/filter"]) != 1 || len(entries["ipv6/firewall/filter"]) != 2 {
+		t.Fatalf("unexpected filter entries: %+v", entries)
+	}
+	if source := entries["ipv6/firewall/filter"][1]["src-address"]; source != "fd00::2/128" {
+		t.Fatalf("expected the peer rule in the ipv6 filter, got %q", source)
+	}
+
+	nat := entries["ip/firewall/nat"]
+	if len(nat) != 1 || nat[0]["action"] != "masquerade" || nat[0]["out-interface"] != "ether1" || nat[0]["comment"] != "wg-ui:wg0" {
+		t.Fatalf("unexpected nat entries: %+v", nat)
+	}
+}
+
+func TestFirewallEntriesRenderACLProtocolAndPorts(t *testing.T) {
+	entries := firewallEntries(&firewall.Ruleset{
+		Interface: "wg0",
+		IPv4:      true,
+		IPv6:      true,
+		Forward: []firewall.ForwardRule{
+			{Source: netip.MustParsePrefix("10.0.0.2/32"), Destination: netip.MustParsePrefix("192.168.1.10/32"), Protocol: firewall.ProtocolTCP, FromPort: 8000, ToPort: 8080, Verdict: firewall.VerdictAccept},
+			{Source: netip.MustParsePrefix("10.0.0.2/32"), Destination: netip.MustParsePrefix("192.168.1.10/32"), Protocol: firewall.ProtocolUDP, FromPort: 53, ToPort: 53, Verdict: firewall.VerdictDrop},
+			{Source: netip.MustParsePrefix("fd00::2/128"), Destination: netip.MustParsePrefix("fd10::/64"), Protocol: firewall.ProtocolICMP, Verdict: firewall.VerdictAccept},
+		},
+	})
+
+	filter := entries["ip/firewall/filter"]
+	if len(filter) != 2 {
+		t.Fatalf("unexpected ipv4 filter entries: %+v", filter)
+	}
+	if filter[0]["protocol"] != "tcp" || filter[0]["dst-port"] != "8000-8080" || filter[0]["action"] != "accept" {
+		t.Fatalf("unexpected tcp entry: %+v", filter[0])
+	}
+	if filter[1]["protocol"] != "udp" || filter[1]["dst-port"] != "53" || filter[1]["action"] != "drop" {
+		t.Fatalf("unexpected udp entry: %+v", filter[1])
+	}
+
+	ipv6Filter := entries["ipv6/firewall/filter"]
+	if len(ipv6Filter) != 1 || ipv6Filter[0]["protocol"] != "icmpv6" {
+		t.Fatalf("expected an icmpv6 entry, got %+v", ipv6Filter)
+	}
+	if _, ok := ipv6Filter[0]["dst-port"]; ok {
+		t.Fatalf("expected no port on the icmp entry, got %+v", ipv6Filter[0])
+	}
+}
+
+func TestFirewallApplyReplacesOutdatedEntries(t *testing.T) {
+	var deleted []string
+	var added []map[string]string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/rest/ip/firewall/nat":
+			_, _ = io.WriteString(w, `[
+				{".id":"*1","chain":"srcnat","action":"masquerade","src-address":"10.0.0.0/24","out-interface":"ether2","comment":"wg-ui:wg0"},
+				{".id":"*2","chain":"srcnat","action":"masquerade","out-interface":"ether1","comment":"user rule"}
+			]`)
+		case r.Method == http.MethodGet:
+			_, _ = io.WriteString(w, `[]`)
+		case r.Method == http.MethodDelete:
+			deleted = append(deleted, r.URL.Path)
+			_, _ = io.WriteString(w, `{}`)
+		case r.Method == http.MethodPut && r.URL.Path == "/rest/ip/firewall/nat":
+			var payload map[string]string
+			if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+				t.Errorf("failed to decode payload: %v", err)
+			}
+			added = append(added, payload)
+			_, _ = io.WriteString(w, `{}`)
+		default:
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	}))
+	defer server.Close()
+
+	backend := &routerOSBackend{
+		baseURL:  server.URL + "/rest",
+		username: "api",
+		password: "secret",
+		client:   server.Client(),
+	}
+
+	err := (&routerOSFirewall{backend: backend}).Apply(context.Background(), &firewall.Ruleset{
+		Interface: "wg0",
+		IPv4:      true,
+		Masquerade: []firewall.MasqueradeRule{
+			{Source: netip.MustParsePrefix("10.0.0.0/24"), OutputInterface: "ether1"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Apply returned error: %v", err)
+	}
+
+	if len(deleted) != 1 || deleted[0] != "/rest/ip/firewall/nat/*1" {
+		t.Fatalf("expected only the managed entry to be deleted, got %v", deleted)
+	}
+	if len(added) != 1 || added[0]["out-interface"] != "ether1" {
+		t.Fatalf("expected the updated entry to be added, got %+v", added)
+	}
+}
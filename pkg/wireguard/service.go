@@ -4,7 +4,11 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"time"
 
+	"github.com/sirupsen/logrus"
+
+	"github.com/UnAfraid/wg-ui/pkg/backend"
 	"github.com/UnAfraid/wg-ui/pkg/wireguard/driver"
 )
 
@@ -19,12 +23,18 @@ type BackendRef interface {
 type Service interface {
 	Device(ctx context.Context, b BackendRef, name string) (*driver.Device, error)
 	Up(ctx context.Context, b BackendRef, options driver.ConfigureOptions) (*driver.Device, error)
+	// UpdatePeers applies the peers of the configuration with the given public keys to a running interface,
+	// the keys that are no longer part of the configuration are removed. It returns driver.ErrPeerUpdatesNotSupported
+	// when the backend can only apply the whole configuration.
+	UpdatePeers(ctx context.Context, b BackendRef, options driver.ConfigureOptions, publicKeys []string) (*driver.Device, error)
 	Down(ctx context.Context, b BackendRef, name string) error
 	Status(ctx context.Context, b BackendRef, name string) (bool, error)
 	Stats(ctx context.Context, b BackendRef, name string) (*driver.InterfaceStats, error)
 	PeerStats(ctx context.Context, b BackendRef, name string, peerPublicKey string) (*driver.PeerStats, error)
 	FindForeignServers(ctx context.Context, b BackendRef, knownInterfaces []string) ([]*driver.ForeignServer, error)
 	RemoveBackend(ctx context.Context, backendId string) error
+	// BackendHealth returns the health of the backend connection.
+	BackendHealth(backendId string) backend.Health
 	Close(ctx context.Context) error
 }
 
@@ -49,14 +59,104 @@ func (s *service) Device(ctx context.Context, ref BackendRef, name string) (*dri
 }
 
 func (s *service) Up(ctx context.Context, ref BackendRef, options driver.ConfigureOptions) (*driver.Device, error) {
+	if err := s.registry.checkFence(ctx); err != nil {
+		return nil, err
+	}
+	return withBackendRetry(ctx, s, ref, func(instance driver.Backend) (*driver.Device, error) {
+		firewallBackend, ok := instance.(driver.FirewallBackend)
+		if !ok && options.ManagesFirewall() {
+			return nil, fmt.Errorf("%w: %s", driver.ErrFirewallNotSupported, ref.Type())
+		}
+
+		device, err := instance.Up(ctx, options)
+		if err != nil || !ok {
+			return device, err
+		}
+
+		if err := applyFirewall(ctx, firewallBackend, options); err != nil {
+			return nil, err
+		}
+		return device, nil
+	})
+}
+
+func (s *service) UpdatePeers(ctx context.Context, ref BackendRef, options driver.ConfigureOptions, publicKeys []string) (*driver.Device, error) {
+	if err := s.registry.checkFence(ctx); err != nil {
+		return nil, err
+	}
 	return withBackendRetry(ctx, s, ref, func(instance driver.Backend) (*driver.Device, error) {
-		return instance.Up(ctx, options)
+		peerBackend, ok := instance.(driver.PeerBackend)
+		if !ok {
+			return nil, driver.ErrPeerUpdatesNotSupported
+		}
+
+		firewallBackend, ok := instance.(driver.FirewallBackend)
+		if !ok && options.ManagesFirewall() {
+			return nil, fmt.Errorf("%w: %s", driver.ErrFirewallNotSupported, ref.Type())
+		}
+
+		if err := options.Validate(); err != nil {
+			return nil, err
+		}
+
+		name := options.InterfaceOptions.Name
+		peersByPublicKey := make(map[string]*driver.PeerOptions, len(options.WireguardOptions.Peers))
+		for _, peer := range options.WireguardOptions.Peers {
+			peersByPublicKey[peer.PublicKey] = peer
+		}
+
+		for _, publicKey := range publicKeys {
+			if peer, ok := peersByPublicKey[publicKey]; ok {
+				if err := peerBackend.UpsertPeer(ctx, name, peer); err != nil {
+					return nil, fmt.Errorf("failed to update peer: %s - %w", publicKey, err)
+				}
+			} else if err := peerBackend.RemovePeer(ctx, name, publicKey); err != nil {
+				return nil, fmt.Errorf("failed to remove peer: %s - %w", publicKey, err)
+			}
+		}
+
+		if firewallBackend != nil {
+			if err := applyFirewall(ctx, firewallBackend, options); err != nil {
+				return nil, err
+			}
+		}
+		return instance.Device(ctx, name)
 	})
 }
 
+// applyFirewall syncs the firewall rules of the configuration, failing to remove the rules of a configuration
+// that no longer manages the firewall is only logged.
+func applyFirewall(ctx context.Context, firewallBackend driver.FirewallBackend, options driver.ConfigureOptions) error {
+	if err := firewallBackend.ApplyFirewall(ctx, options); err != nil {
+		if options.ManagesFirewall() {
+			return fmt.Errorf("failed to configure firewall: %s - %w", options.InterfaceOptions.Name, err)
+		}
+		logrus.
+			WithError(err).
+			WithField("interface", options.InterfaceOptions.Name).
+			Warn("failed to remove firewall rules")
+	}
+	return nil
+}
+
 func (s *service) Down(ctx context.Context, ref BackendRef, name string) error {
+	if err := s.registry.checkFence(ctx); err != nil {
+		return err
+	}
 	_, err := withBackendRetry(ctx, s, ref, func(instance driver.Backend) (struct{}, error) {
-		return struct{}{}, instance.Down(ctx, name)
+		if err := instance.Down(ctx, name); err != nil {
+			return struct{}{}, err
+		}
+
+		if firewallBackend, ok := instance.(driver.FirewallBackend); ok {
+			if err := firewallBackend.RemoveFirewall(ctx, name); err != nil {
+				logrus.
+					WithError(err).
+					WithField("interface", name).
+					Warn("failed to remove firewall rules")
+			}
+		}
+		return struct{}{}, nil
 	})
 	return err
 }
@@ -97,6 +197,10 @@ func (s *service) RemoveBackend(ctx context.Context, backendId string) error {
 	return s.registry.Remove(ctx, backendId)
 }
 
+func (s *service) BackendHealth(backendId string) backend.Health {
+	return s.registry.Health(backendId)
+}
+
 func (s *service) Close(ctx context.Context) error {
 	return s.registry.CloseAll(ctx)
 }
@@ -109,24 +213,45 @@ func withBackendRetry[T any](
 ) (T, error) {
 	var zero T
 
+	if err := s.registry.checkHealth(ref.ID()); err != nil {
+		return zero, err
+	}
+
+	start := time.Now()
+	result, failed, err := callWithBackendRetry(ctx, s, ref, operation)
+	s.registry.recordResult(ref.ID(), ref.Type(), ref.URL(), time.Since(start), failed, err)
+	return result, err
+}
+
+// callWithBackendRetry runs the operation and retries it once with a new backend instance when the connection is stale,
+// failed reports whether the backend could not be reached.
+func callWithBackendRetry[T any](
+	ctx context.Context,
+	s *service,
+	ref BackendRef,
+	operation func(instance driver.Backend) (T, error),
+) (T, bool, error) {
+	var zero T
+
 	instance, err := s.getBackend(ctx, ref)
 	if err != nil {
-		return zero, err
+		return zero, true, err
 	}
 
 	result, err := operation(instance)
 	if err == nil || !errors.Is(err, driver.ErrConnectionStale) {
-		return result, err
+		return result, isBackendFailure(err), err
 	}
 
-	if removeErr := s.registry.Remove(ctx, ref.ID()); removeErr != nil {
-		return zero, errors.Join(err, fmt.Errorf("failed to recreate stale backend instance %s: %w", ref.ID(), removeErr))
+	if removeErr := s.registry.remove(ctx, ref.ID()); removeErr != nil {
+		return zero, true, errors.Join(err, fmt.Errorf("failed to recreate stale backend instance %s: %w", ref.ID(), removeErr))
 	}
 
 	instance, err = s.getBackend(ctx, ref)
 	if err != nil {
-		return zero, err
+		return zero, true, err
 	}
 
-	return operation(instance)
+	result, err = operation(instance)
+	return result, isBackendFailure(err), err
 }
@@ -97,9 +97,9 @@ func TestServiceRetriesOnStaleBackendConnection(t *testing.T) {
 			secondBackend = backend
 		}
 		return backend, nil
-	}, true)
+	}, true, driver.Capabilities{})
 
-	registry := NewRegistry()
+	registry := NewRegistry(HealthPolicy{})
 	service := NewService(registry)
 	ref := &retryBackendRef{
 		id:          "backend-id",
@@ -144,9 +144,9 @@ func TestServiceDoesNotRetryOnRegularBackendError(t *testing.T) {
 	driver.Register(scheme, func(_ context.Context, rawURL string) (driver.Backend, error) {
 		created++
 		return &retryBackend{deviceErr: regularErr}, nil
-	}, true)
+	}, true, driver.Capabilities{})
 
-	registry := NewRegistry()
+	registry := NewRegistry(HealthPolicy{})
 	service := NewService(registry)
 	ref := &retryBackendRef{
 		id:          "backend-id",
@@ -163,3 +163,126 @@ func TestServiceDoesNotRetryOnRegularBackendError(t *testing.T) {
 		t.Fatalf("expected 1 backend creation, got %d", created)
 	}
 }
+
+func TestServiceUpRejectsFirewallOnUnsupportedBackend(t *testing.T) {
+	scheme := fmt.Sprintf("service-firewall-%d", time.Now().UnixNano())
+
+	driver.Register(scheme, func(_ context.Context, rawURL string) (driver.Backend, error) {
+		return &retryBackend{}, nil
+	}, true, driver.Capabilities{})
+
+	service := NewService(NewRegistry(HealthPolicy{}))
+	ref := &retryBackendRef{
+		id:          "backend-id",
+		backendType: scheme,
+		url:         scheme + ":///wireguard",
+	}
+
+	_, err := service.Up(context.Background(), ref, driver.ConfigureOptions{
+		InterfaceOptions: driver.InterfaceOptions{Name: "wg0", Address: "10.0.0.1/24"},
+		WireguardOptions: driver.WireguardOptions{
+			Peers: []*driver.PeerOptions{{
+				PublicKey:  "alpha",
+				AllowedIPs: []string{"10.0.0.2/32"},
+				ACL:        []*driver.ACLRule{{Destination: "192.168.1.0/24", Action: driver.ACLActionDeny}},
+			}},
+		},
+	})
+	if !errors.Is(err, driver.ErrFirewallNotSupported) {
+		t.Fatalf("expected %v, got %v", driver.ErrFirewallNotSupported, err)
+	}
+}
+
+type peerUpdateBackend struct {
+	retryBackend
+	upserted []string
+	removed  []string
+}
+
+func (b *peerUpdateBackend) UpsertPeer(_ context.Context, _ string, peer *driver.PeerOptions) error {
+	b.upserted = append(b.upserted, peer.PublicKey)
+	return nil
+}
+
+func (b *peerUpdateBackend) RemovePeer(_ context.Context, _ string, publicKey string) error {
+	b.removed = append(b.removed, publicKey)
+	return nil
+}
+
+func TestServiceUpdatePeers(t *testing.T) {
+	scheme := fmt.Sprintf("service-peers-%d", time.Now().UnixNano())
+	peerBackend := &peerUpdateBackend{}
+	driver.Register(scheme, func(_ context.Context, rawURL string) (driver.Backend, error) {
+		return peerBackend, nil
+	}, true, driver.Capabilities{})
+
+	unsupportedScheme := scheme + "-unsupported"
+	driver.Register(unsupportedScheme, func(_ context.Context, rawURL string) (driver.Backend, error) {
+		return &retryBackend{}, nil
+	}, true, driver.Capabilities{})
+
+	service := NewService(NewRegistry(HealthPolicy{}))
+	options := driver.ConfigureOptions{
+		InterfaceOptions: driver.InterfaceOptions{Name: "wg0", Address: "10.0.0.1/24"},
+		WireguardOptions: driver.WireguardOptions{
+			PrivateKey: "private",
+			Peers:      []*driver.PeerOptions{{PublicKey: "alpha", AllowedIPs: []string{"10.0.0.2/32"}}},
+		},
+	}
+
+	ref := &retryBackendRef{id: "peers", backendType: scheme, url: scheme + ":///wireguard"}
+	if _, err := service.UpdatePeers(context.Background(), ref, options, []string{"alpha", "bravo"}); err != nil {
+		t.Fatalf("UpdatePeers returned error: %v", err)
+	}
+	if len(peerBackend.upserted) != 1 || peerBackend.upserted[0] != "alpha" {
+		t.Fatalf("expected alpha to be upserted, got %v", peerBackend.upserted)
+	}
+	if len(peerBackend.removed) != 1 || peerBackend.removed[0] != "bravo" {
+		t.Fatalf("expected bravo to be removed, got %v", peerBackend.removed)
+	}
+
+	unsupportedRef := &retryBackendRef{id: "unsupported", backendType: unsupportedScheme, url: unsupportedScheme + ":///wireguard"}
+	if _, err := service.UpdatePeers(context.Background(), unsupportedRef, options, []string{"alpha"}); !errors.Is(err, driver.ErrPeerUpdatesNotSupported) {
+		t.Fatalf("expected %v, got %v", driver.ErrPeerUpdatesNotSupported, err)
+	}
+}
+
+func TestServiceFenceRejectsDeviceChanges(t *testing.T) {
+	scheme := fmt.Sprintf("service-fence-%d", time.Now().UnixNano())
+	peerBackend := &peerUpdateBackend{}
+	driver.Register(scheme, func(_ context.Context, rawURL string) (driver.Backend, error) {
+		return peerBackend, nil
+	}, true, driver.Capabilities{})
+
+	fenced := errors.New("fenced")
+	registry := NewRegistry(HealthPolicy{})
+	registry.SetFence(func(context.Context) error {
+		return fenced
+	})
+
+	service := NewService(registry)
+	ref := &retryBackendRef{id: "fence", backendType: scheme, url: scheme + ":///wireguard"}
+	options := driver.ConfigureOptions{
+		InterfaceOptions: driver.InterfaceOptions{Name: "wg0", Address: "10.0.0.1/24"},
+		WireguardOptions: driver.WireguardOptions{
+			PrivateKey: "private",
+			Peers:      []*driver.PeerOptions{{PublicKey: "alpha", AllowedIPs: []string{"10.0.0.2/32"}}},
+		},
+	}
+
+	if _, err := service.Up(context.Background(), ref, options); !errors.Is(err, fenced) {
+		t.Fatalf("expected Up to be fenced, got %v", err)
+	}
+	if _, err := service.UpdatePeers(context.Background(), ref, options, []string{"alpha"}); !errors.Is(err, fenced) {
+		t.Fatalf("expected UpdatePeers to be fenced, got %v", err)
+	}
+	if err := service.Down(context.Background(), ref, "wg0"); !errors.Is(err, fenced) {
+		t.Fatalf("expected Down to be fenced, got %v", err)
+	}
+	if len(peerBackend.upserted) != 0 {
+		t.Fatalf("expected no peer to reach the backend, got %v", peerBackend.upserted)
+	}
+	if _, err := service.Device(context.Background(), ref, "wg0"); err != nil {
+		t.Fatalf("expected reads not to be fenced, got %v", err)
+	}
+}